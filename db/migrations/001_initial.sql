@@ -1,4 +1,5 @@
 -- 001_initial.sql
+-- +migrate Up
 CREATE EXTENSION IF NOT EXISTS "uuid-ossp";
 CREATE EXTENSION IF NOT EXISTS "pgcrypto";
 
@@ -69,4 +70,10 @@ CREATE TRIGGER trg_users_updated BEFORE UPDATE ON users
 
 DROP TRIGGER IF EXISTS trg_sessions_updated ON telegram_sessions;
 CREATE TRIGGER trg_sessions_updated BEFORE UPDATE ON telegram_sessions
-    FOR EACH ROW EXECUTE FUNCTION update_updated_at();
\ No newline at end of file
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at();
+
+-- +migrate Down
+DROP TABLE IF EXISTS refresh_tokens;
+DROP TABLE IF EXISTS telegram_sessions;
+DROP TABLE IF EXISTS users;
+DROP FUNCTION IF EXISTS update_updated_at();
@@ -1,4 +1,5 @@
 -- 002_webhooks.sql
+-- +migrate Up
 CREATE TABLE IF NOT EXISTS webhooks (
     id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
     session_id UUID NOT NULL UNIQUE REFERENCES telegram_sessions(id) ON DELETE CASCADE,
@@ -20,4 +21,7 @@ CREATE INDEX IF NOT EXISTS idx_webhooks_session ON webhooks(session_id);
 -- Trigger para updated_at
 DROP TRIGGER IF EXISTS trg_webhooks_updated ON webhooks;
 CREATE TRIGGER trg_webhooks_updated BEFORE UPDATE ON webhooks
-    FOR EACH ROW EXECUTE FUNCTION update_updated_at();
\ No newline at end of file
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at();
+
+-- +migrate Down
+DROP TABLE IF EXISTS webhooks;
@@ -0,0 +1,6 @@
+-- 003_bot_sessions.sql
+-- +migrate Up
+ALTER TABLE telegram_sessions ADD COLUMN IF NOT EXISTS is_bot BOOLEAN DEFAULT FALSE;
+
+-- +migrate Down
+ALTER TABLE telegram_sessions DROP COLUMN IF EXISTS is_bot;
@@ -0,0 +1,25 @@
+-- 004_webhook_secrets.sql
+-- +migrate Up
+-- Secretos de webhook cifrados con ENCRYPTION_KEY. La columna secret (texto plano)
+-- queda en NULL: al iniciar, la API cifra las filas existentes y la vacía.
+-- Down no puede descifrarlos desde SQL: se niega a ejecutarse mientras quede
+-- algún secreto cifrado, para no dejar webhooks sin firma.
+ALTER TABLE webhooks ADD COLUMN IF NOT EXISTS secret_encrypted BYTEA;
+ALTER TABLE webhooks ADD COLUMN IF NOT EXISTS secret_hint VARCHAR(20);
+
+-- Rotación: el secreto anterior sigue firmando hasta previous_secret_expires_at
+ALTER TABLE webhooks ADD COLUMN IF NOT EXISTS previous_secret_encrypted BYTEA;
+ALTER TABLE webhooks ADD COLUMN IF NOT EXISTS previous_secret_expires_at TIMESTAMPTZ;
+
+-- +migrate Down
+DO $$
+BEGIN
+    IF EXISTS (SELECT 1 FROM webhooks WHERE secret_encrypted IS NOT NULL OR previous_secret_encrypted IS NOT NULL) THEN
+        RAISE EXCEPTION 'webhooks con secretos cifrados: revertir 004 los dejaría sin secreto';
+    END IF;
+END $$;
+
+ALTER TABLE webhooks DROP COLUMN IF EXISTS previous_secret_expires_at;
+ALTER TABLE webhooks DROP COLUMN IF EXISTS previous_secret_encrypted;
+ALTER TABLE webhooks DROP COLUMN IF EXISTS secret_hint;
+ALTER TABLE webhooks DROP COLUMN IF EXISTS secret_encrypted;
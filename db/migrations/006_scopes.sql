@@ -0,0 +1,11 @@
+-- 006_scopes.sql
+-- +migrate Up
+-- Scopes por usuario. NULL = todos (comportamiento previo); los admin siempre tienen todos.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS scopes TEXT[];
+
+-- Scopes por API key. Vacío = los del usuario.
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS scopes TEXT[] DEFAULT '{}';
+
+-- +migrate Down
+ALTER TABLE api_keys DROP COLUMN IF EXISTS scopes;
+ALTER TABLE users DROP COLUMN IF EXISTS scopes;
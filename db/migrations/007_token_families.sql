@@ -0,0 +1,11 @@
+-- 007_token_families.sql
+-- +migrate Up
+-- Familia de rotación: todos los refresh tokens emitidos desde un mismo login.
+-- Reutilizar uno ya rotado revoca la familia completa.
+ALTER TABLE refresh_tokens ADD COLUMN IF NOT EXISTS family_id UUID;
+UPDATE refresh_tokens SET family_id = id WHERE family_id IS NULL;
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_family ON refresh_tokens(family_id);
+
+-- +migrate Down
+DROP INDEX IF EXISTS idx_refresh_tokens_family;
+ALTER TABLE refresh_tokens DROP COLUMN IF EXISTS family_id;
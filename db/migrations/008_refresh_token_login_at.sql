@@ -0,0 +1,9 @@
+-- 008_refresh_token_login_at.sql
+-- +migrate Up
+-- Fecha del login que inició la familia; se copia en cada rotación para
+-- mostrarla en /auth/devices
+ALTER TABLE refresh_tokens ADD COLUMN IF NOT EXISTS login_at TIMESTAMPTZ;
+UPDATE refresh_tokens SET login_at = created_at WHERE login_at IS NULL;
+
+-- +migrate Down
+ALTER TABLE refresh_tokens DROP COLUMN IF EXISTS login_at;
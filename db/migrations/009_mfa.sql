@@ -0,0 +1,24 @@
+-- 009_mfa.sql
+-- +migrate Up
+-- TOTP: secretos cifrados con ENCRYPTION_KEY, hashes SHA-256 de los códigos de
+-- recuperación y último paso usado (evita reutilizar un código)
+ALTER TABLE users ADD COLUMN IF NOT EXISTS mfa_enabled BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS mfa_secret_encrypted BYTEA;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS mfa_pending_secret_encrypted BYTEA;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS mfa_recovery_codes TEXT[];
+ALTER TABLE users ADD COLUMN IF NOT EXISTS mfa_last_step BIGINT;
+
+-- Ajustes globales editables por administradores (políticas)
+CREATE TABLE IF NOT EXISTS app_settings (
+    key VARCHAR(100) PRIMARY KEY,
+    value TEXT NOT NULL,
+    updated_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+-- +migrate Down
+DROP TABLE IF EXISTS app_settings;
+ALTER TABLE users DROP COLUMN IF EXISTS mfa_last_step;
+ALTER TABLE users DROP COLUMN IF EXISTS mfa_recovery_codes;
+ALTER TABLE users DROP COLUMN IF EXISTS mfa_pending_secret_encrypted;
+ALTER TABLE users DROP COLUMN IF EXISTS mfa_secret_encrypted;
+ALTER TABLE users DROP COLUMN IF EXISTS mfa_enabled;
@@ -0,0 +1,23 @@
+-- 011_invitations.sql
+-- +migrate Up
+-- Invitaciones de registro (REGISTRATION_MODE=invite); solo se guarda el hash
+CREATE TABLE IF NOT EXISTS invitations (
+    id UUID PRIMARY KEY,
+    token_hash VARCHAR(64) NOT NULL UNIQUE,
+    email VARCHAR(255),
+    role VARCHAR(20) NOT NULL DEFAULT 'user' CHECK (role IN ('admin', 'user')),
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    used_at TIMESTAMPTZ,
+    used_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_invitations_pending ON invitations(expires_at) WHERE used_at IS NULL;
+
+-- Búsqueda de usuarios en /admin/users
+CREATE INDEX IF NOT EXISTS idx_users_created_at ON users(created_at DESC);
+
+-- +migrate Down
+DROP INDEX IF EXISTS idx_users_created_at;
+DROP TABLE IF EXISTS invitations;
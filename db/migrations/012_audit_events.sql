@@ -0,0 +1,26 @@
+-- 012_audit_events.sql
+-- +migrate Up
+-- Auditoría de operaciones sensibles. Sin claves foráneas: los eventos deben
+-- sobrevivir al borrado del usuario o de la sesión a la que se refieren.
+CREATE TABLE IF NOT EXISTS audit_events (
+    id UUID PRIMARY KEY,
+    actor_id UUID,
+    actor_name VARCHAR(50),
+    api_key_id UUID,
+    action VARCHAR(64) NOT NULL,
+    session_id UUID,
+    ip_address VARCHAR(45),
+    request_id VARCHAR(64),
+    outcome VARCHAR(10) NOT NULL CHECK (outcome IN ('success', 'failure', 'denied')),
+    error TEXT,
+    details JSONB,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_events_created_at ON audit_events(created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_audit_events_actor ON audit_events(actor_id, created_at DESC) WHERE actor_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_audit_events_session ON audit_events(session_id, created_at DESC) WHERE session_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_audit_events_action ON audit_events(action, created_at DESC);
+
+-- +migrate Down
+DROP TABLE IF EXISTS audit_events;
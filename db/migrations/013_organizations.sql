@@ -0,0 +1,33 @@
+-- 013_organizations.sql
+-- +migrate Up
+-- Organizaciones: varios usuarios comparten el acceso a las mismas sesiones
+CREATE TABLE IF NOT EXISTS organizations (
+    id UUID PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    updated_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS organization_members (
+    organization_id UUID NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    role VARCHAR(20) NOT NULL CHECK (role IN ('owner', 'operator', 'viewer')),
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    PRIMARY KEY (organization_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_organization_members_user ON organization_members(user_id);
+
+-- Sesión compartida: organization_id. Al eliminar la organización la sesión
+-- vuelve a ser personal de su user_id.
+ALTER TABLE telegram_sessions
+    ADD COLUMN IF NOT EXISTS organization_id UUID REFERENCES organizations(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_sessions_organization ON telegram_sessions(organization_id) WHERE organization_id IS NOT NULL;
+
+-- +migrate Down
+DROP INDEX IF EXISTS idx_sessions_organization;
+ALTER TABLE telegram_sessions DROP COLUMN IF EXISTS organization_id;
+DROP TABLE IF EXISTS organization_members;
+DROP TABLE IF EXISTS organizations;
@@ -0,0 +1,27 @@
+-- 014_quotas.sql
+-- +migrate Up
+-- Cuotas propias de un usuario; NULL hereda el valor global (QUOTA_*)
+CREATE TABLE IF NOT EXISTS user_quotas (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    max_sessions INTEGER CHECK (max_sessions >= 0),
+    daily_sends INTEGER CHECK (daily_sends >= 0),
+    monthly_sends INTEGER CHECK (monthly_sends >= 0),
+    bulk_recipients INTEGER CHECK (bulk_recipients >= 0),
+    monthly_media_mb INTEGER CHECK (monthly_media_mb >= 0),
+    updated_at TIMESTAMPTZ DEFAULT NOW()
+);
+
+-- Contadores de consumo por periodo (día o mes en UTC)
+CREATE TABLE IF NOT EXISTS usage_counters (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    metric VARCHAR(32) NOT NULL,
+    period_start DATE NOT NULL,
+    amount BIGINT NOT NULL DEFAULT 0,
+    PRIMARY KEY (user_id, metric, period_start)
+);
+
+CREATE INDEX IF NOT EXISTS idx_usage_counters_period ON usage_counters(period_start);
+
+-- +migrate Down
+DROP TABLE IF EXISTS usage_counters;
+DROP TABLE IF EXISTS user_quotas;
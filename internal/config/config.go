@@ -3,15 +3,23 @@ package config
 import (
 	"os"
 	"strconv"
+	"strings"
 )
 
 type Config struct {
-	Database   DatabaseConfig
-	Redis      RedisConfig
-	JWT        JWTConfig
-	Encryption EncryptionConfig
-	Log        LogConfig
-	Cache      CacheConfig // Nuevo
+	Database     DatabaseConfig
+	Redis        RedisConfig
+	JWT          JWTConfig
+	Encryption   EncryptionConfig
+	Log          LogConfig
+	Cache        CacheConfig // Nuevo
+	Contacts     ContactsConfig
+	RateLimit    RateLimitConfig
+	Password     PasswordConfig
+	Registration RegistrationConfig
+	Audit        AuditConfig
+	Quota        QuotaConfig
+	Metrics      MetricsConfig
 }
 
 type DatabaseConfig struct {
@@ -24,12 +32,19 @@ type RedisConfig struct {
 }
 
 type JWTConfig struct {
-	Secret      string
-	ExpiryHours int
+	Secret          string
+	ExpiryHours     int
+	CleanupInterval int // minutos entre limpiezas de refresh tokens expirados (0 = desactivado)
+	// Aceptar tokens si la blacklist (Redis) no responde. Por defecto se rechazan.
+	RevocationFailOpen bool
 }
 
+// EncryptionConfig define la clave primaria (cifra) y las claves anteriores
+// (solo descifran) para poder rotar ENCRYPTION_KEY sin perder sesiones
 type EncryptionConfig struct {
-	Key string
+	Key           string
+	OldKeys       []string // ENCRYPTION_OLD_KEYS, separadas por coma
+	RotateOnStart bool     // re-cifrar en background al iniciar si hay claves anteriores
 }
 
 type LogConfig struct {
@@ -44,6 +59,70 @@ type CacheConfig struct {
 	ResolveTTL   int // TTL para resolve peer (default 600 = 10 min)
 }
 
+// Modos de resolución de destinatarios +phone
+const (
+	PhoneResolveLookup = "resolve" // contacts.resolvePhone primero; importa temporalmente solo si hace falta
+	PhoneResolveImport = "import"  // importa siempre el número como contacto y lo conserva
+)
+
+// ContactsConfig configura cómo se resuelven los números de teléfono al enviar
+type ContactsConfig struct {
+	PhoneResolveMode string
+}
+
+// RateLimitConfig configura los límites de peticiones (ventana deslizante en Redis).
+// Un límite 0 desactiva esa regla.
+type RateLimitConfig struct {
+	Enabled bool
+	// Por API key o usuario en las rutas protegidas
+	APIRequests int
+	APIWindow   int // segundos
+	// Por IP en /auth/login y /auth/register
+	AuthRequests int
+	AuthWindow   int
+	// Por sesión de Telegram en los endpoints de envío
+	SendRequests int
+	SendWindow   int
+}
+
+// PasswordConfig configura el bloqueo progresivo por intentos de login
+// fallidos y la validez de los tokens de restablecimiento
+type PasswordConfig struct {
+	LockoutThreshold  int // fallos seguidos antes del primer bloqueo (0 = sin bloqueo)
+	LockoutMinutes    int // duración del primer bloqueo; se duplica en cada fallo posterior
+	LockoutMaxMinutes int // tope del bloqueo
+	ResetTTLHours     int // validez de un token de restablecimiento
+}
+
+// RegistrationConfig controla quién puede crear cuentas en /auth/register
+type RegistrationConfig struct {
+	Mode           string // open | invite | disabled (domain.Registration*)
+	InviteTTLHours int    // validez por defecto de una invitación
+}
+
+// AuditConfig configura la retención de la auditoría (audit_events)
+type AuditConfig struct {
+	RetentionDays   int // días que se conservan los eventos (0 = para siempre)
+	CleanupInterval int // minutos entre purgas
+}
+
+// QuotaConfig cuotas por defecto de cada usuario (0 = sin límite). Un
+// administrador puede sobrescribirlas por usuario.
+type QuotaConfig struct {
+	MaxSessions     int // sesiones de las que un usuario es responsable
+	DailySends      int // mensajes encolados por día (UTC)
+	MonthlySends    int // mensajes encolados por mes (UTC)
+	BulkRecipients  int // destinatarios por petición de /messages/bulk
+	MonthlyMediaMB  int // MB de media descargados y enviados por mes
+	CleanupInterval int // minutos entre purgas de contadores antiguos
+}
+
+// MetricsConfig configura el endpoint /metrics (formato Prometheus)
+type MetricsConfig struct {
+	Enabled bool
+	Token   string // si no está vacío, /metrics exige Authorization: Bearer <token>
+}
+
 func Load() (*Config, error) {
 	expiry, _ := strconv.Atoi(os.Getenv("JWT_EXPIRY_HOURS"))
 	if expiry == 0 {
@@ -64,16 +143,50 @@ func Load() (*Config, error) {
 			Password: os.Getenv("REDIS_PASSWORD"),
 		},
 		JWT: JWTConfig{
-			Secret:      os.Getenv("JWT_SECRET"),
-			ExpiryHours: expiry,
+			Secret:             os.Getenv("JWT_SECRET"),
+			ExpiryHours:        expiry,
+			CleanupInterval:    getEnvInt("TOKEN_CLEANUP_INTERVAL_MINUTES", 60),
+			RevocationFailOpen: getEnv("JWT_REVOCATION_FAIL_OPEN", "false") == "true",
 		},
 		Encryption: EncryptionConfig{
-			Key: os.Getenv("ENCRYPTION_KEY"),
+			Key:           os.Getenv("ENCRYPTION_KEY"),
+			OldKeys:       getEnvList("ENCRYPTION_OLD_KEYS"),
+			RotateOnStart: getEnv("ENCRYPTION_ROTATE_ON_START", "true") == "true",
 		},
 		Log: LogConfig{
 			Level: logLevel,
 		},
 		Cache: loadCacheConfig(),
+		Contacts: ContactsConfig{
+			PhoneResolveMode: getEnv("CONTACTS_PHONE_RESOLVE", PhoneResolveLookup),
+		},
+		RateLimit: loadRateLimitConfig(),
+		Password: PasswordConfig{
+			LockoutThreshold:  getEnvInt("LOGIN_LOCKOUT_THRESHOLD", 5),
+			LockoutMinutes:    getEnvInt("LOGIN_LOCKOUT_MINUTES", 1),
+			LockoutMaxMinutes: getEnvInt("LOGIN_LOCKOUT_MAX_MINUTES", 60),
+			ResetTTLHours:     getEnvInt("PASSWORD_RESET_TTL_HOURS", 24),
+		},
+		Registration: RegistrationConfig{
+			Mode:           getEnv("REGISTRATION_MODE", "open"),
+			InviteTTLHours: getEnvInt("INVITE_TTL_HOURS", 72),
+		},
+		Audit: AuditConfig{
+			RetentionDays:   getEnvInt("AUDIT_RETENTION_DAYS", 90),
+			CleanupInterval: getEnvInt("AUDIT_CLEANUP_INTERVAL_MINUTES", 60),
+		},
+		Quota: QuotaConfig{
+			MaxSessions:     getEnvInt("QUOTA_MAX_SESSIONS", 0),
+			DailySends:      getEnvInt("QUOTA_DAILY_SENDS", 0),
+			MonthlySends:    getEnvInt("QUOTA_MONTHLY_SENDS", 0),
+			BulkRecipients:  getEnvInt("QUOTA_BULK_RECIPIENTS", 0),
+			MonthlyMediaMB:  getEnvInt("QUOTA_MONTHLY_MEDIA_MB", 0),
+			CleanupInterval: getEnvInt("QUOTA_CLEANUP_INTERVAL_MINUTES", 360),
+		},
+		Metrics: MetricsConfig{
+			Enabled: getEnv("METRICS_ENABLED", "true") == "true",
+			Token:   os.Getenv("METRICS_TOKEN"),
+		},
 	}, nil
 }
 
@@ -86,6 +199,18 @@ func loadCacheConfig() CacheConfig {
 	}
 }
 
+func loadRateLimitConfig() RateLimitConfig {
+	return RateLimitConfig{
+		Enabled:      getEnv("RATE_LIMIT_ENABLED", "true") == "true",
+		APIRequests:  getEnvInt("RATE_LIMIT_API_REQUESTS", 300), // 300/min por key o usuario
+		APIWindow:    getEnvInt("RATE_LIMIT_API_WINDOW", 60),
+		AuthRequests: getEnvInt("RATE_LIMIT_AUTH_REQUESTS", 10), // 10/min por IP
+		AuthWindow:   getEnvInt("RATE_LIMIT_AUTH_WINDOW", 60),
+		SendRequests: getEnvInt("RATE_LIMIT_SEND_REQUESTS", 30), // 30/min por sesión
+		SendWindow:   getEnvInt("RATE_LIMIT_SEND_WINDOW", 60),
+	}
+}
+
 func getEnvInt(key string, defaultVal int) int {
 	val := os.Getenv(key)
 	if val == "" {
@@ -96,4 +221,21 @@ func getEnvInt(key string, defaultVal int) int {
 		return defaultVal
 	}
 	return intVal
-}
\ No newline at end of file
+}
+
+func getEnvList(key string) []string {
+	var out []string
+	for _, v := range strings.Split(os.Getenv(key), ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+func getEnv(key, defaultVal string) string {
+	if val := os.Getenv(key); val != "" {
+		return val
+	}
+	return defaultVal
+}
@@ -5,7 +5,6 @@ import (
 	"fmt"
 	"log"
 	"os"
-	"path/filepath"
 	"time"
 
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -61,25 +60,13 @@ func NewServices(ctx context.Context) (*Services, error) {
 	return &Services{DB: pool, Redis: rdb}, nil
 }
 
+// Migrate aplica las migraciones pendientes (ver Migrator)
 func (s *Services) Migrate() error {
-	files, err := filepath.Glob("db/migrations/*.sql")
+	migrator, err := NewMigrator(s.DB)
 	if err != nil {
-		return fmt.Errorf("glob migraciones: %w", err)
+		return err
 	}
-
-	for _, f := range files {
-		schema, err := os.ReadFile(f)
-		if err != nil {
-			return fmt.Errorf("leer %s: %w", f, err)
-		}
-
-		if _, err := s.DB.Exec(context.Background(), string(schema)); err != nil {
-			return fmt.Errorf("ejecutar %s: %w", f, err)
-		}
-		log.Printf("✅ Migración aplicada: %s", filepath.Base(f))
-	}
-
-	return nil
+	return migrator.Up(context.Background())
 }
 
 func (s *Services) Close() {
@@ -0,0 +1,375 @@
+package database
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"telegram-api/pkg/logger"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Los archivos de migración se llaman NNN_nombre.sql. Si incluyen las marcas
+// "-- +migrate Up" / "-- +migrate Down" se separan los pasos; sin marcas todo
+// el archivo es el paso Up y la migración no se puede revertir.
+const (
+	markerUp   = "-- +migrate Up"
+	markerDown = "-- +migrate Down"
+)
+
+// migrationLockKey identifica el advisory lock que serializa las migraciones
+// entre réplicas ("tgapimg" en ASCII)
+const migrationLockKey int64 = 0x74676170696d67
+
+var (
+	ErrChecksumMismatch = errors.New("migración modificada después de aplicarse")
+	ErrNoDownStep       = errors.New("la migración no tiene paso Down")
+	ErrUnknownVersion   = errors.New("versión de migración desconocida")
+)
+
+var migrationFileRe = regexp.MustCompile(`^(\d+)_([\w\-]+)\.sql$`)
+
+// MigrationPaths rutas donde se buscan las migraciones (local y contenedor)
+var MigrationPaths = []string{"db/migrations", "/db/migrations", "migrations"}
+
+// Migration es un archivo de migración parseado
+type Migration struct {
+	File     string
+	Version  int64
+	Name     string
+	Checksum string
+	Up       string
+	Down     string
+}
+
+// MigrationStatus estado de una migración frente a schema_migrations
+type MigrationStatus struct {
+	Migration
+	AppliedAt *time.Time
+	Modified  bool // checksum aplicado distinto del archivo actual
+}
+
+// Migrator aplica migraciones versionadas registrándolas en schema_migrations
+type Migrator struct {
+	db         *pgxpool.Pool
+	migrations []Migration
+}
+
+// NewMigrator carga las migraciones del primer directorio existente de MigrationPaths
+func NewMigrator(db *pgxpool.Pool) (*Migrator, error) {
+	for _, dir := range MigrationPaths {
+		if info, err := os.Stat(dir); err == nil && info.IsDir() {
+			return NewMigratorFS(db, os.DirFS(dir))
+		}
+	}
+	logger.Warn().Msg("⚠️ No se encontraron archivos de migración")
+	return &Migrator{db: db}, nil
+}
+
+// NewMigratorFS carga las migraciones desde un fs.FS
+func NewMigratorFS(db *pgxpool.Pool, fsys fs.FS) (*Migrator, error) {
+	migrations, err := loadMigrations(fsys)
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{db: db, migrations: migrations}, nil
+}
+
+func loadMigrations(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("leer migraciones: %w", err)
+	}
+
+	var migrations []Migration
+	seen := make(map[int64]string)
+	for _, e := range entries {
+		m := migrationFileRe.FindStringSubmatch(e.Name())
+		if e.IsDir() || m == nil {
+			continue
+		}
+		version, _ := strconv.ParseInt(m[1], 10, 64)
+		if prev, ok := seen[version]; ok {
+			return nil, fmt.Errorf("versión %d duplicada: %s y %s", version, prev, e.Name())
+		}
+		seen[version] = e.Name()
+
+		raw, err := fs.ReadFile(fsys, e.Name())
+		if err != nil {
+			return nil, fmt.Errorf("leer %s: %w", e.Name(), err)
+		}
+		up, down := splitMigration(string(raw))
+		sum := sha256.Sum256(raw)
+
+		migrations = append(migrations, Migration{
+			File:     e.Name(),
+			Version:  version,
+			Name:     m[2],
+			Checksum: hex.EncodeToString(sum[:]),
+			Up:       up,
+			Down:     down,
+		})
+	}
+
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// splitMigration separa los pasos Up y Down según las marcas, en cualquier
+// orden. Sin marca Up, lo anterior a la marca Down es el paso Up.
+func splitMigration(sql string) (up, down string) {
+	upIdx := strings.Index(sql, markerUp)
+	downIdx := strings.Index(sql, markerDown)
+
+	switch {
+	case downIdx < 0 && upIdx < 0:
+		up = sql
+	case downIdx < 0:
+		up = sql[upIdx+len(markerUp):]
+	case upIdx < 0:
+		up, down = sql[:downIdx], sql[downIdx+len(markerDown):]
+	case upIdx < downIdx:
+		up, down = sql[upIdx+len(markerUp):downIdx], sql[downIdx+len(markerDown):]
+	default:
+		down, up = sql[downIdx+len(markerDown):upIdx], sql[upIdx+len(markerUp):]
+	}
+	return strings.TrimSpace(up), strings.TrimSpace(down)
+}
+
+// ==================== COMANDOS ====================
+
+// Status retorna todas las migraciones conocidas y si están aplicadas
+func (m *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	var out []MigrationStatus
+	err := m.withLock(ctx, func(conn *pgxpool.Conn) error {
+		applied, err := m.applied(ctx, conn)
+		if err != nil {
+			return err
+		}
+		for _, mig := range m.migrations {
+			st := MigrationStatus{Migration: mig}
+			if a, ok := applied[mig.Version]; ok {
+				st.AppliedAt = &a.appliedAt
+				st.Modified = a.checksum != mig.Checksum
+			}
+			out = append(out, st)
+		}
+		return nil
+	})
+	return out, err
+}
+
+// Up aplica todas las migraciones pendientes
+func (m *Migrator) Up(ctx context.Context) error {
+	if len(m.migrations) == 0 {
+		return nil
+	}
+	return m.To(ctx, m.migrations[len(m.migrations)-1].Version)
+}
+
+// Down revierte las últimas steps migraciones aplicadas
+func (m *Migrator) Down(ctx context.Context, steps int) error {
+	return m.withLock(ctx, func(conn *pgxpool.Conn) error {
+		applied, err := m.applied(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		// No revertir con un Down que ya no corresponde a lo aplicado
+		if err := m.verifyChecksums(applied); err != nil {
+			return err
+		}
+
+		for i := len(m.migrations) - 1; i >= 0 && steps > 0; i-- {
+			mig := m.migrations[i]
+			if _, ok := applied[mig.Version]; !ok {
+				continue
+			}
+			if err := m.revert(ctx, conn, mig); err != nil {
+				return err
+			}
+			steps--
+		}
+		return nil
+	})
+}
+
+// To deja el esquema exactamente en la versión indicada: aplica las pendientes
+// hasta ella y revierte las aplicadas posteriores. La versión 0 revierte todo.
+func (m *Migrator) To(ctx context.Context, version int64) error {
+	if version != 0 && m.find(version) < 0 {
+		return fmt.Errorf("%w: %d", ErrUnknownVersion, version)
+	}
+
+	return m.withLock(ctx, func(conn *pgxpool.Conn) error {
+		applied, err := m.applied(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		// No continuar si alguna migración aplicada fue editada
+		if err := m.verifyChecksums(applied); err != nil {
+			return err
+		}
+
+		for i := len(m.migrations) - 1; i >= 0; i-- {
+			mig := m.migrations[i]
+			if _, ok := applied[mig.Version]; ok && mig.Version > version {
+				if err := m.revert(ctx, conn, mig); err != nil {
+					return err
+				}
+			}
+		}
+
+		pending := 0
+		for _, mig := range m.migrations {
+			if _, ok := applied[mig.Version]; !ok && mig.Version <= version {
+				if err := m.apply(ctx, conn, mig); err != nil {
+					return err
+				}
+				pending++
+			}
+		}
+		if pending == 0 {
+			logger.Info().Int64("version", version).Msg("✅ Esquema al día")
+		}
+		return nil
+	})
+}
+
+// ==================== INTERNOS ====================
+
+type appliedMigration struct {
+	checksum  string
+	appliedAt time.Time
+}
+
+// withLock toma el advisory lock en una conexión dedicada para que varias
+// réplicas arrancando a la vez no apliquen la misma migración
+func (m *Migrator) withLock(ctx context.Context, fn func(conn *pgxpool.Conn) error) error {
+	conn, err := m.db.Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("conexión para migraciones: %w", err)
+	}
+	defer conn.Release()
+
+	if _, err := conn.Exec(ctx, `SELECT pg_advisory_lock($1)`, migrationLockKey); err != nil {
+		return fmt.Errorf("advisory lock: %w", err)
+	}
+	defer func() {
+		if _, err := conn.Exec(context.Background(), `SELECT pg_advisory_unlock($1)`, migrationLockKey); err != nil {
+			logger.Warn().Err(err).Msg("⚠️ Error liberando advisory lock de migraciones")
+		}
+	}()
+
+	_, err = conn.Exec(ctx, `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version BIGINT PRIMARY KEY,
+			name TEXT NOT NULL,
+			checksum TEXT NOT NULL,
+			applied_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+			duration_ms INT NOT NULL DEFAULT 0
+		)
+	`)
+	if err != nil {
+		return fmt.Errorf("crear schema_migrations: %w", err)
+	}
+
+	return fn(conn)
+}
+
+func (m *Migrator) applied(ctx context.Context, conn *pgxpool.Conn) (map[int64]appliedMigration, error) {
+	rows, err := conn.Query(ctx, `SELECT version, checksum, applied_at FROM schema_migrations`)
+	if err != nil {
+		return nil, fmt.Errorf("leer schema_migrations: %w", err)
+	}
+	defer rows.Close()
+
+	out := make(map[int64]appliedMigration)
+	for rows.Next() {
+		var (
+			version int64
+			a       appliedMigration
+		)
+		if err := rows.Scan(&version, &a.checksum, &a.appliedAt); err != nil {
+			return nil, err
+		}
+		out[version] = a
+	}
+	return out, rows.Err()
+}
+
+func (m *Migrator) apply(ctx context.Context, conn *pgxpool.Conn, mig Migration) error {
+	start := time.Now()
+	err := pgx.BeginFunc(ctx, conn, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, mig.Up); err != nil {
+			return err
+		}
+		_, err := tx.Exec(ctx,
+			`INSERT INTO schema_migrations (version, name, checksum, duration_ms) VALUES ($1, $2, $3, $4)`,
+			mig.Version, mig.Name, mig.Checksum, time.Since(start).Milliseconds(),
+		)
+		return err
+	})
+	if err != nil {
+		logger.Error().Err(err).Str("file", mig.File).Msg("Error ejecutando migración")
+		return fmt.Errorf("migración %s: %w", mig.File, err)
+	}
+
+	logger.Info().
+		Str("file", mig.File).
+		Dur("duration", time.Since(start)).
+		Msg("✅ Migración aplicada")
+	return nil
+}
+
+func (m *Migrator) revert(ctx context.Context, conn *pgxpool.Conn, mig Migration) error {
+	if mig.Down == "" {
+		return fmt.Errorf("%w: %s", ErrNoDownStep, mig.File)
+	}
+
+	err := pgx.BeginFunc(ctx, conn, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, mig.Down); err != nil {
+			return err
+		}
+		_, err := tx.Exec(ctx, `DELETE FROM schema_migrations WHERE version = $1`, mig.Version)
+		return err
+	})
+	if err != nil {
+		logger.Error().Err(err).Str("file", mig.File).Msg("Error revirtiendo migración")
+		return fmt.Errorf("revertir %s: %w", mig.File, err)
+	}
+
+	logger.Info().Str("file", mig.File).Msg("↩️ Migración revertida")
+	return nil
+}
+
+// verifyChecksums falla si alguna migración aplicada cambió desde entonces
+func (m *Migrator) verifyChecksums(applied map[int64]appliedMigration) error {
+	for _, mig := range m.migrations {
+		if a, ok := applied[mig.Version]; ok && a.checksum != mig.Checksum {
+			return fmt.Errorf("%w: %s", ErrChecksumMismatch, mig.File)
+		}
+	}
+	return nil
+}
+
+func (m *Migrator) find(version int64) int {
+	for i, mig := range m.migrations {
+		if mig.Version == version {
+			return i
+		}
+	}
+	return -1
+}
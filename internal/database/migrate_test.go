@@ -0,0 +1,199 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"testing/fstest"
+)
+
+func TestSplitMigration(t *testing.T) {
+	tests := []struct {
+		name     string
+		sql      string
+		wantUp   string
+		wantDown string
+	}{
+		{
+			name:   "sin marcas",
+			sql:    "CREATE TABLE a (id INT);\n",
+			wantUp: "CREATE TABLE a (id INT);",
+		},
+		{
+			name:     "up y down",
+			sql:      "-- +migrate Up\nCREATE TABLE a (id INT);\n\n-- +migrate Down\nDROP TABLE a;\n",
+			wantUp:   "CREATE TABLE a (id INT);",
+			wantDown: "DROP TABLE a;",
+		},
+		{
+			name:     "down antes que up",
+			sql:      "-- +migrate Down\nDROP TABLE a;\n-- +migrate Up\nCREATE TABLE a (id INT);\n",
+			wantUp:   "CREATE TABLE a (id INT);",
+			wantDown: "DROP TABLE a;",
+		},
+		{
+			name:   "solo up",
+			sql:    "-- +migrate Up\nCREATE TABLE a (id INT);\n",
+			wantUp: "CREATE TABLE a (id INT);",
+		},
+		{
+			name:     "solo down",
+			sql:      "CREATE TABLE a (id INT);\n-- +migrate Down\nDROP TABLE a;\n",
+			wantUp:   "CREATE TABLE a (id INT);",
+			wantDown: "DROP TABLE a;",
+		},
+		{
+			name:   "down vacío",
+			sql:    "-- +migrate Up\nCREATE TABLE a (id INT);\n-- +migrate Down\n",
+			wantUp: "CREATE TABLE a (id INT);",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			up, down := splitMigration(tt.sql)
+			if up != tt.wantUp {
+				t.Errorf("up = %q, want %q", up, tt.wantUp)
+			}
+			if down != tt.wantDown {
+				t.Errorf("down = %q, want %q", down, tt.wantDown)
+			}
+		})
+	}
+}
+
+func TestLoadMigrations(t *testing.T) {
+	fsys := fstest.MapFS{
+		"002_second.sql": {Data: []byte("-- +migrate Up\nSELECT 2;\n-- +migrate Down\nSELECT -2;\n")},
+		"001_first.sql":  {Data: []byte("SELECT 1;\n")},
+		"010_tenth.sql":  {Data: []byte("SELECT 10;\n")},
+		"README.md":      {Data: []byte("no es una migración")},
+		"003_bad.txt":    {Data: []byte("SELECT 3;\n")},
+		"old":            {Mode: os.ModeDir},
+	}
+
+	migrations, err := loadMigrations(fsys)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []struct {
+		version int64
+		name    string
+		down    string
+	}{
+		{1, "first", ""},
+		{2, "second", "SELECT -2;"},
+		{10, "tenth", ""},
+	}
+	if len(migrations) != len(want) {
+		t.Fatalf("len = %d, want %d", len(migrations), len(want))
+	}
+	for i, w := range want {
+		m := migrations[i]
+		if m.Version != w.version || m.Name != w.name || m.Down != w.down {
+			t.Errorf("[%d] = {%d %s %q}, want {%d %s %q}", i, m.Version, m.Name, m.Down, w.version, w.name, w.down)
+		}
+		if len(m.Checksum) != 64 {
+			t.Errorf("[%d] checksum %q no es sha256 hex", i, m.Checksum)
+		}
+	}
+}
+
+func TestLoadMigrationsDuplicateVersion(t *testing.T) {
+	tests := []struct {
+		name string
+		fsys fstest.MapFS
+	}{
+		{"mismo número", fstest.MapFS{
+			"001_a.sql": {Data: []byte("SELECT 1;")},
+			"001_b.sql": {Data: []byte("SELECT 1;")},
+		}},
+		{"ceros a la izquierda", fstest.MapFS{
+			"1_a.sql":   {Data: []byte("SELECT 1;")},
+			"001_b.sql": {Data: []byte("SELECT 1;")},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := loadMigrations(tt.fsys); err == nil {
+				t.Fatal("se esperaba error por versión duplicada")
+			}
+		})
+	}
+}
+
+func TestVerifyChecksums(t *testing.T) {
+	fsys := fstest.MapFS{
+		"001_a.sql": {Data: []byte("SELECT 1;")},
+		"002_b.sql": {Data: []byte("SELECT 2;")},
+	}
+	m, err := NewMigratorFS(nil, fsys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sum := func(i int) string { return m.migrations[i].Checksum }
+
+	tests := []struct {
+		name    string
+		applied map[int64]appliedMigration
+		wantErr error
+	}{
+		{"nada aplicado", map[int64]appliedMigration{}, nil},
+		{"sin cambios", map[int64]appliedMigration{1: {checksum: sum(0)}, 2: {checksum: sum(1)}}, nil},
+		{"editada después de aplicarse", map[int64]appliedMigration{1: {checksum: sum(0)}, 2: {checksum: "otro"}}, ErrChecksumMismatch},
+		// Una versión aplicada que ya no existe en disco no es un cambio de checksum
+		{"versión desconocida aplicada", map[int64]appliedMigration{99: {checksum: "x"}}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := m.verifyChecksums(tt.applied)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	// Editar el archivo cambia el checksum calculado
+	fsys["002_b.sql"] = &fstest.MapFile{Data: []byte("SELECT 2; -- editada")}
+	edited, err := loadMigrations(fsys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if edited[1].Checksum == sum(1) {
+		t.Error("el checksum no cambió al editar la migración")
+	}
+}
+
+func TestToUnknownVersion(t *testing.T) {
+	m, err := NewMigratorFS(nil, fstest.MapFS{"001_a.sql": {Data: []byte("SELECT 1;")}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Se rechaza antes de tocar la base de datos
+	if err := m.To(context.Background(), 5); !errors.Is(err, ErrUnknownVersion) {
+		t.Fatalf("err = %v, want ErrUnknownVersion", err)
+	}
+}
+
+// Las migraciones del repositorio deben cargar y poder revertirse
+func TestRepositoryMigrations(t *testing.T) {
+	migrations, err := loadMigrations(os.DirFS("../../db/migrations"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(migrations) == 0 {
+		t.Fatal("no se encontraron migraciones")
+	}
+	for _, m := range migrations {
+		if m.Up == "" {
+			t.Errorf("%s: paso Up vacío", m.File)
+		}
+		if m.Down == "" {
+			t.Errorf("%s: sin paso Down", m.File)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package domain
+
+import (
+	"context"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// APIKeyPrefix prefijo de las API keys (facilita detectarlas en logs y escáneres de secretos)
+const APIKeyPrefix = "tgapi_"
+
+// APIKey credencial de larga duración para integraciones servidor a servidor.
+// Solo se guarda el hash; la clave completa se muestra una vez al crearla.
+type APIKey struct {
+	ID         uuid.UUID   `json:"id"`
+	UserID     uuid.UUID   `json:"user_id"`
+	Name       string      `json:"name"`
+	Prefix     string      `json:"prefix"` // Primeros caracteres, para identificarla
+	KeyHash    string      `json:"-"`
+	SessionIDs []uuid.UUID `json:"session_ids,omitempty"` // Vacío = todas las sesiones del usuario
+	AllowedIPs []string    `json:"allowed_ips,omitempty"` // IPs o CIDRs; vacío = cualquier IP
+	Scopes     []Scope     `json:"scopes,omitempty"`      // Vacío = los scopes del usuario
+	ExpiresAt  *time.Time  `json:"expires_at,omitempty"`
+	LastUsedAt *time.Time  `json:"last_used_at,omitempty"`
+	LastUsedIP string      `json:"last_used_ip,omitempty"`
+	RevokedAt  *time.Time  `json:"revoked_at,omitempty"`
+	CreatedAt  time.Time   `json:"created_at"`
+}
+
+// IsValid indica si la key no está revocada ni expirada
+func (k *APIKey) IsValid(now time.Time) bool {
+	return k.RevokedAt == nil && (k.ExpiresAt == nil || now.Before(*k.ExpiresAt))
+}
+
+// AllowsIP verifica la IP contra la allowlist
+func (k *APIKey) AllowsIP(ip string) bool {
+	if len(k.AllowedIPs) == 0 {
+		return true
+	}
+	addr := net.ParseIP(ip)
+	if addr == nil {
+		return false
+	}
+	for _, allowed := range k.AllowedIPs {
+		if strings.Contains(allowed, "/") {
+			if _, network, err := net.ParseCIDR(allowed); err == nil && network.Contains(addr) {
+				return true
+			}
+		} else if parsed := net.ParseIP(allowed); parsed != nil && parsed.Equal(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsSessionScoped indica si la key está limitada a sesiones concretas
+func (k *APIKey) IsSessionScoped() bool {
+	return len(k.SessionIDs) > 0
+}
+
+// AllowsSession verifica si la key puede operar sobre la sesión
+func (k *APIKey) AllowsSession(sessionID uuid.UUID) bool {
+	if !k.IsSessionScoped() {
+		return true
+	}
+	for _, id := range k.SessionIDs {
+		if id == sessionID {
+			return true
+		}
+	}
+	return false
+}
+
+// EffectiveScopes scopes de la key acotados a los que el usuario tiene hoy;
+// si se restringe al usuario, sus keys quedan restringidas también
+func (k *APIKey) EffectiveScopes(user *User) []Scope {
+	granted := user.EffectiveScopes()
+	if len(k.Scopes) == 0 {
+		return granted
+	}
+	return IntersectScopes(granted, k.Scopes)
+}
+
+// CreateAPIKeyRequest petición para crear una API key
+type CreateAPIKeyRequest struct {
+	Name          string      `json:"name" validate:"required,max=100" example:"crm-produccion"`
+	SessionIDs    []uuid.UUID `json:"session_ids,omitempty"`
+	AllowedIPs    []string    `json:"allowed_ips,omitempty" validate:"omitempty,max=20,dive,ip|cidr" example:"10.0.0.0/8"`
+	Scopes        []Scope     `json:"scopes,omitempty" validate:"omitempty,dive,oneof=sessions:read sessions:write messages:send chats:read chats:write chats:admin webhooks:read webhooks:write" example:"messages:send"`
+	ExpiresInDays int         `json:"expires_in_days,omitempty" validate:"omitempty,min=1,max=3650" example:"365"`
+}
+
+// CreateAPIKeyResponse incluye la clave completa (única vez que se muestra)
+type CreateAPIKeyResponse struct {
+	Key    string  `json:"key"`
+	APIKey *APIKey `json:"api_key"`
+}
+
+// ==================== REPOSITORY INTERFACE ====================
+
+type APIKeyRepository interface {
+	Create(ctx context.Context, key *APIKey) error
+	GetByHash(ctx context.Context, keyHash string) (*APIKey, error)
+	ListByUserID(ctx context.Context, userID uuid.UUID) ([]*APIKey, error)
+	Revoke(ctx context.Context, id, userID uuid.UUID) error
+	TouchLastUsed(ctx context.Context, id uuid.UUID, ip string) error
+}
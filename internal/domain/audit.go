@@ -0,0 +1,141 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Acciones auditadas
+const (
+	AuditSessionCreate   = "session.create"
+	AuditSessionVerify   = "session.verify"
+	AuditSessionImport   = "session.import"
+	AuditSessionExport   = "session.export"
+	AuditSessionDelete   = "session.delete"
+	AuditSessionTransfer = "session.transfer"
+
+	AuditMessageSend = "message.send"
+
+	AuditWebhookConfigure = "webhook.configure"
+	AuditWebhookDelete    = "webhook.delete"
+	AuditWebhookStart     = "webhook.start"
+	AuditWebhookStop      = "webhook.stop"
+	AuditWebhookRotate    = "webhook.rotate_secret"
+
+	AuditAuthRegister       = "auth.register"
+	AuditAuthLogin          = "auth.login"
+	AuditAuthLogout         = "auth.logout"
+	AuditAuthLogoutAll      = "auth.logout_all"
+	AuditAuthDeviceRevoke   = "auth.device_revoke"
+	AuditAuthTokenReuse     = "auth.token_reuse"
+	AuditAuthPasswordChange = "auth.password_change"
+	AuditAuthPasswordReset  = "auth.password_reset"
+	AuditAuthMFAEnable      = "auth.mfa_enable"
+	AuditAuthMFADisable     = "auth.mfa_disable"
+	AuditAPIKeyCreate       = "apikey.create"
+	AuditAPIKeyRevoke       = "apikey.revoke"
+
+	AuditAdminUserCreate       = "admin.user_create"
+	AuditAdminUserStatus       = "admin.user_status"
+	AuditAdminUserRole         = "admin.user_role"
+	AuditAdminUserDelete       = "admin.user_delete"
+	AuditAdminUserScopes       = "admin.user_scopes"
+	AuditAdminUserQuotas       = "admin.user_quotas"
+	AuditAdminUserUnlock       = "admin.user_unlock"
+	AuditAdminMFAReset         = "admin.mfa_reset"
+	AuditAdminPasswordReset    = "admin.password_reset"
+	AuditAdminInvitationCreate = "admin.invitation_create"
+	AuditAdminInvitationRevoke = "admin.invitation_revoke"
+	AuditAdminPolicy           = "admin.policy_update"
+
+	AuditOrgCreate       = "org.create"
+	AuditOrgUpdate       = "org.update"
+	AuditOrgDelete       = "org.delete"
+	AuditOrgMemberAdd    = "org.member_add"
+	AuditOrgMemberRole   = "org.member_role"
+	AuditOrgMemberRemove = "org.member_remove"
+)
+
+// AuditOutcome resultado de una acción auditada
+type AuditOutcome string
+
+const (
+	AuditSuccess AuditOutcome = "success"
+	AuditFailure AuditOutcome = "failure"
+	AuditDenied  AuditOutcome = "denied" // Sin permiso, credenciales o dueño incorrecto
+)
+
+// AuditOutcomeOf clasifica el error de una operación
+func AuditOutcomeOf(err error) AuditOutcome {
+	switch {
+	case err == nil:
+		return AuditSuccess
+	case errors.Is(err, ErrUnauthorized), errors.Is(err, ErrForbidden),
+		errors.Is(err, ErrInvalidCredentials), errors.Is(err, ErrInvalidMFACode),
+		errors.Is(err, ErrAccountLocked), errors.Is(err, ErrUserInactive),
+		errors.Is(err, ErrInsufficientOrgRole), errors.Is(err, ErrQuotaExceeded):
+		return AuditDenied
+	default:
+		return AuditFailure
+	}
+}
+
+// AuditEvent registro de una operación sensible
+type AuditEvent struct {
+	ID        uuid.UUID      `json:"id"`
+	ActorID   *uuid.UUID     `json:"actor_id,omitempty"` // nil = anónimo o sistema
+	ActorName string         `json:"actor_name,omitempty"`
+	APIKeyID  *uuid.UUID     `json:"api_key_id,omitempty"` // Si se autenticó con API key
+	Action    string         `json:"action"`
+	SessionID *uuid.UUID     `json:"session_id,omitempty"`
+	IPAddress string         `json:"ip_address,omitempty"`
+	RequestID string         `json:"request_id,omitempty"`
+	Outcome   AuditOutcome   `json:"outcome"`
+	Error     string         `json:"error,omitempty"`
+	Details   map[string]any `json:"details,omitempty"`
+	CreatedAt time.Time      `json:"created_at"`
+}
+
+// AuditFilter filtros y paginación de GET /admin/audit
+type AuditFilter struct {
+	ActorID   *uuid.UUID
+	SessionID *uuid.UUID
+	Action    string // Exacta, o prefijo si termina en "." (p.ej. "session.")
+	Outcome   AuditOutcome
+	From      *time.Time
+	To        *time.Time
+	Limit     int
+	Offset    int
+}
+
+type AuditRepository interface {
+	Insert(ctx context.Context, event *AuditEvent) error
+	List(ctx context.Context, filter AuditFilter) ([]*AuditEvent, int64, error)
+	DeleteBefore(ctx context.Context, before time.Time) (int64, error)
+}
+
+// AuditContextKey clave (Locals de Fiber) del *AuditActor de la petición. El
+// contexto de fasthttp expone los Locals vía Value, así que los servicios lo
+// leen del ctx que reciben.
+const AuditContextKey = "auditActor"
+
+// AuditActor quién hace la petición y desde dónde
+type AuditActor struct {
+	UserID    uuid.UUID
+	Username  string
+	APIKeyID  *uuid.UUID
+	IPAddress string
+	RequestID string
+}
+
+// AuditActorFromContext retorna el actor de la petición (nil fuera de una petición HTTP)
+func AuditActorFromContext(ctx context.Context) *AuditActor {
+	if ctx == nil {
+		return nil
+	}
+	actor, _ := ctx.Value(AuditContextKey).(*AuditActor)
+	return actor
+}
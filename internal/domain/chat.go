@@ -31,6 +31,19 @@ type Chat struct {
 	IsPinned      bool      `json:"is_pinned"`
 	IsMuted       bool      `json:"is_muted"`
 	IsArchived    bool      `json:"is_archived"`
+
+	// Detalle completo (solo GET /chats/:chatId)
+	Bio             string           `json:"bio,omitempty"` // Bio de usuario o descripción de grupo/canal
+	PhotoID         int64            `json:"photo_id,omitempty"`
+	MembersCount    int              `json:"members_count,omitempty"`
+	OnlineCount     int              `json:"online_count,omitempty"`
+	AdminsCount     int              `json:"admins_count,omitempty"`
+	LinkedChatID    int64            `json:"linked_chat_id,omitempty"` // Grupo de discusión / canal vinculado
+	PinnedMessageID int              `json:"pinned_message_id,omitempty"`
+	SlowModeSeconds int              `json:"slow_mode_seconds,omitempty"`
+	Permissions     *ChatPermissions `json:"permissions,omitempty"`
+	IsVerified      bool             `json:"is_verified,omitempty"`
+	IsBot           bool             `json:"is_bot,omitempty"`
 }
 
 // ==================== CONTACT ====================
@@ -64,6 +77,25 @@ type ChatMessage struct {
 	MediaType   string    `json:"media_type,omitempty"`
 	MediaURL    string    `json:"media_url,omitempty"`
 	ForwardFrom string    `json:"forward_from,omitempty"`
+
+	Reactions []MessageReaction `json:"reactions,omitempty"`
+}
+
+// MessageReaction conteo de una reacción en un mensaje
+type MessageReaction struct {
+	Emoji         string `json:"emoji,omitempty"`
+	CustomEmojiID int64  `json:"custom_emoji_id,omitempty"`
+	Count         int    `json:"count"`
+	Chosen        bool   `json:"chosen"` // La cuenta reaccionó con esta reacción
+}
+
+// SendReactionRequest para añadir o quitar una reacción
+// @Description Indicar emoji o custom_emoji_id. Con remove=true y sin reacción se quitan todas.
+type SendReactionRequest struct {
+	Emoji         string `json:"emoji,omitempty" example:"👍"`
+	CustomEmojiID int64  `json:"custom_emoji_id,omitempty"` // Requiere Telegram Premium
+	Remove        bool   `json:"remove,omitempty"`
+	Big           bool   `json:"big,omitempty"` // Animación grande (solo chats privados)
 }
 
 // ==================== RESOLVED PEER ====================
@@ -79,6 +111,12 @@ type ResolvedPeer struct {
 	AccessHash int64    `json:"-"`
 	IsBot      bool     `json:"is_bot"`
 	IsVerified bool     `json:"is_verified"`
+
+	// Solo para enlaces de invitación (vista previa antes de unirse)
+	About         string `json:"about,omitempty"`
+	MembersCount  int    `json:"members_count,omitempty"`
+	IsMember      bool   `json:"is_member,omitempty"`
+	RequestNeeded bool   `json:"request_needed,omitempty"`
 }
 
 // ==================== REQUEST DTOs ====================
@@ -104,8 +142,9 @@ type GetContactsRequest struct {
 }
 
 type ResolveRequest struct {
-	Username string `json:"username,omitempty" example:"@durov"`
-	Phone    string `json:"phone,omitempty" example:"+573001234567"`
+	Username   string `json:"username,omitempty" example:"@durov"`
+	Phone      string `json:"phone,omitempty" example:"+573001234567"`
+	InviteLink string `json:"invite_link,omitempty" example:"https://t.me/+AbCdEfGhIjK"`
 }
 
 // ==================== RESPONSE DTOs ====================
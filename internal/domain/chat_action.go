@@ -0,0 +1,42 @@
+package domain
+
+// ==================== CHAT ACTIONS ====================
+
+// ChatActionType acción visible para el otro lado del chat ("escribiendo...", etc.)
+type ChatActionType string
+
+const (
+	ChatActionTyping         ChatActionType = "typing"
+	ChatActionUploadPhoto    ChatActionType = "upload_photo"
+	ChatActionUploadVideo    ChatActionType = "upload_video"
+	ChatActionUploadDocument ChatActionType = "upload_document"
+	ChatActionUploadAudio    ChatActionType = "upload_audio"
+	ChatActionRecordAudio    ChatActionType = "record_audio"
+	ChatActionRecordVideo    ChatActionType = "record_video"
+	ChatActionChooseSticker  ChatActionType = "choose_sticker"
+	ChatActionCancel         ChatActionType = "cancel"
+)
+
+// MarkReadRequest para marcar mensajes como leídos
+type MarkReadRequest struct {
+	MaxID int `json:"max_id,omitempty" validate:"gte=0" example:"12345"` // 0 = todo el historial
+}
+
+// ChatActionRequest para mostrar una acción durante unos segundos
+type ChatActionRequest struct {
+	Action   ChatActionType `json:"action" validate:"required,oneof=typing upload_photo upload_video upload_document upload_audio record_audio record_video choose_sticker cancel" example:"typing"`
+	Duration int            `json:"duration,omitempty" validate:"gte=0,lte=30" example:"5"` // Segundos, 0 = un solo envío (~5s en clientes)
+}
+
+// ==================== PRESENCE ====================
+
+// PresenceRequest para aparecer en línea o desconectado
+type PresenceRequest struct {
+	Online bool `json:"online"`
+}
+
+// PresenceStatus estado de presencia aplicado a la sesión
+type PresenceStatus struct {
+	Online    bool `json:"online"`
+	KeepAlive bool `json:"keep_alive"` // true si la sesión está en el pool y se mantiene en línea
+}
@@ -0,0 +1,145 @@
+package domain
+
+import "time"
+
+// ==================== ADMIN RIGHTS / PERMISSIONS ====================
+
+// AdminRights define los permisos de un administrador de grupo/canal
+type AdminRights struct {
+	ChangeInfo     bool `json:"change_info"`
+	PostMessages   bool `json:"post_messages"` // Solo canales
+	EditMessages   bool `json:"edit_messages"` // Solo canales
+	DeleteMessages bool `json:"delete_messages"`
+	BanUsers       bool `json:"ban_users"`
+	InviteUsers    bool `json:"invite_users"`
+	PinMessages    bool `json:"pin_messages"`
+	AddAdmins      bool `json:"add_admins"`
+	Anonymous      bool `json:"anonymous"`
+	ManageCall     bool `json:"manage_call"`
+	ManageTopics   bool `json:"manage_topics"`
+}
+
+// ChatPermissions define lo que pueden hacer los miembros (true = permitido)
+type ChatPermissions struct {
+	SendMessages bool `json:"send_messages"`
+	SendMedia    bool `json:"send_media"`
+	SendStickers bool `json:"send_stickers"`
+	SendGifs     bool `json:"send_gifs"`
+	SendPolls    bool `json:"send_polls"`
+	EmbedLinks   bool `json:"embed_links"`
+	InviteUsers  bool `json:"invite_users"`
+	PinMessages  bool `json:"pin_messages"`
+	ChangeInfo   bool `json:"change_info"`
+}
+
+// ==================== REQUEST DTOs ====================
+
+// CreateChatRequest para crear grupo, supergrupo o canal
+// @Description Creación de grupo/supergrupo/canal
+type CreateChatRequest struct {
+	Type    ChatType `json:"type" validate:"required,oneof=group supergroup channel" example:"supergroup"`
+	Title   string   `json:"title" validate:"required,max=128" example:"Mi Comunidad"`
+	About   string   `json:"about,omitempty" validate:"max=255" example:"Grupo oficial"`
+	Members []string `json:"members,omitempty" example:"@user1,+573001234567"`
+}
+
+// InviteMembersRequest para invitar usuarios a un chat
+type InviteMembersRequest struct {
+	Users []string `json:"users" validate:"required,min=1" example:"@user1,@user2"`
+}
+
+// BanMemberRequest para banear a un miembro
+type BanMemberRequest struct {
+	User          string `json:"user" validate:"required" example:"@username"`
+	UntilDate     int64  `json:"until_date,omitempty" example:"0"` // Unix timestamp, 0 = permanente
+	RevokeHistory bool   `json:"revoke_history,omitempty"`         // Solo grupos básicos
+}
+
+// PromoteAdminRequest para promover a un miembro como administrador
+type PromoteAdminRequest struct {
+	User   string      `json:"user" validate:"required" example:"@username"`
+	Rights AdminRights `json:"rights"`
+	Rank   string      `json:"rank,omitempty" validate:"max=16" example:"Moderador"`
+}
+
+// EditChatRequest para editar título y descripción
+type EditChatRequest struct {
+	Title string  `json:"title,omitempty" validate:"max=128" example:"Nuevo título"`
+	About *string `json:"about,omitempty" validate:"omitempty,max=255" example:"Nueva descripción"`
+}
+
+// EditChatPhotoRequest para cambiar la foto del chat
+type EditChatPhotoRequest struct {
+	PhotoURL string `json:"photo_url" validate:"required,url" example:"https://example.com/logo.jpg"`
+}
+
+// SlowModeRequest para configurar slow mode (solo supergrupos)
+type SlowModeRequest struct {
+	Seconds int `json:"seconds" validate:"oneof=0 10 30 60 300 900 3600" example:"30"`
+}
+
+// ==================== RESPONSE DTOs ====================
+
+// CreatedChat respuesta de creación de chat
+type CreatedChat struct {
+	ID              int64    `json:"id"` // Formato Bot API: -ID grupos, -100ID canales
+	Type            ChatType `json:"type"`
+	Title           string   `json:"title"`
+	MissingInvitees []int64  `json:"missing_invitees,omitempty"`
+}
+
+// InviteMembersResult resultado de invitación de miembros
+type InviteMembersResult struct {
+	Invited         int     `json:"invited"`
+	MissingInvitees []int64 `json:"missing_invitees,omitempty"`
+}
+
+// ==================== JOIN / INVITE LINKS ====================
+
+// JoinChatRequest para unirse a un chat público o privado
+// @Description Acepta @username, t.me/username, t.me/+hash o t.me/joinchat/hash
+type JoinChatRequest struct {
+	Link string `json:"link" validate:"required" example:"https://t.me/+AbCdEfGhIjK"`
+}
+
+// JoinedChat resultado de unirse a un chat
+type JoinedChat struct {
+	ID            int64    `json:"id,omitempty"`
+	Type          ChatType `json:"type,omitempty"`
+	Title         string   `json:"title,omitempty"`
+	AlreadyMember bool     `json:"already_member"`
+	Pending       bool     `json:"pending"` // Solicitud enviada, requiere aprobación de un admin
+}
+
+// CreateInviteLinkRequest para crear un enlace de invitación
+type CreateInviteLinkRequest struct {
+	Title         string `json:"title,omitempty" validate:"max=32" example:"Campaña enero"`
+	ExpireDate    int64  `json:"expire_date,omitempty" example:"1735689600"` // Unix timestamp, 0 = sin expiración
+	UsageLimit    int    `json:"usage_limit,omitempty" validate:"gte=0,lte=99999" example:"100"`
+	RequestNeeded bool   `json:"request_needed,omitempty"` // Requiere aprobación de admin
+}
+
+// RevokeInviteLinkRequest para revocar un enlace de invitación
+type RevokeInviteLinkRequest struct {
+	Link string `json:"link" validate:"required" example:"https://t.me/+AbCdEfGhIjK"`
+}
+
+// InviteLink representa un enlace de invitación exportado
+type InviteLink struct {
+	Link          string     `json:"link"`
+	Title         string     `json:"title,omitempty"`
+	IsPermanent   bool       `json:"is_permanent"`
+	IsRevoked     bool       `json:"is_revoked"`
+	RequestNeeded bool       `json:"request_needed"`
+	UsageLimit    int        `json:"usage_limit,omitempty"`
+	Usage         int        `json:"usage"`
+	Requested     int        `json:"requested,omitempty"`
+	ExpireDate    *time.Time `json:"expire_date,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+// InviteLinksResponse lista de enlaces de invitación
+type InviteLinksResponse struct {
+	Links      []InviteLink `json:"links"`
+	TotalCount int          `json:"total_count"`
+}
@@ -0,0 +1,49 @@
+package domain
+
+import "time"
+
+// ==================== CONTACT MANAGEMENT ====================
+
+// ImportContact contacto a importar en la agenda de la cuenta
+type ImportContact struct {
+	Phone     string `json:"phone" validate:"required,e164" example:"+573001234567"`
+	FirstName string `json:"first_name" validate:"required,max=64" example:"Juan"`
+	LastName  string `json:"last_name,omitempty" validate:"max=64" example:"Pérez"`
+}
+
+// ImportContactsRequest importación en lote
+type ImportContactsRequest struct {
+	Contacts []ImportContact `json:"contacts" validate:"required,min=1,max=500,dive"`
+}
+
+// ImportContactsResult resultado de una importación
+type ImportContactsResult struct {
+	Imported      []Contact `json:"imported"`
+	NotOnTelegram []string  `json:"not_on_telegram,omitempty"` // Números sin cuenta de Telegram
+	RetryLater    []string  `json:"retry_later,omitempty"`     // Telegram limitó la importación, reintentar luego
+}
+
+// DeleteContactsRequest para eliminar contactos
+type DeleteContactsRequest struct {
+	Users []string `json:"users" validate:"required,min=1" example:"+573001234567,@username,123456789"`
+}
+
+// BlockUserRequest para bloquear un usuario
+type BlockUserRequest struct {
+	User string `json:"user" validate:"required" example:"@spammer"`
+}
+
+// BlockedUser usuario bloqueado por la cuenta
+type BlockedUser struct {
+	ID        int64     `json:"id"`
+	FirstName string    `json:"first_name,omitempty"`
+	LastName  string    `json:"last_name,omitempty"`
+	Username  string    `json:"username,omitempty"`
+	BlockedAt time.Time `json:"blocked_at"`
+}
+
+// BlockedUsersResponse lista paginada de bloqueados
+type BlockedUsersResponse struct {
+	Users      []BlockedUser `json:"users"`
+	TotalCount int           `json:"total_count"`
+}
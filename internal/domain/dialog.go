@@ -0,0 +1,74 @@
+package domain
+
+// ==================== DIALOG STATE ====================
+
+// PinDialogRequest para fijar/desfijar un chat en la lista de diálogos
+type PinDialogRequest struct {
+	Pinned bool `json:"pinned"`
+}
+
+// PinMessageRequest para fijar un mensaje dentro del chat
+type PinMessageRequest struct {
+	Silent    bool `json:"silent,omitempty"`      // No notificar a los miembros
+	OnlyForMe bool `json:"only_for_me,omitempty"` // Solo chats privados: fijar solo de este lado
+}
+
+// MuteDialogRequest para silenciar un chat
+type MuteDialogRequest struct {
+	Muted     bool  `json:"muted"`
+	MuteUntil int64 `json:"mute_until,omitempty" example:"1735689600"` // Unix timestamp, 0 = para siempre
+}
+
+// ArchiveDialogRequest para mover un chat a/desde el archivo
+type ArchiveDialogRequest struct {
+	Archived bool `json:"archived"`
+}
+
+// UnreadDialogRequest para marcar un chat como no leído
+type UnreadDialogRequest struct {
+	Unread bool `json:"unread"`
+}
+
+// ==================== FOLDERS ====================
+
+// ChatFolder representa una carpeta de chats (dialog filter)
+type ChatFolder struct {
+	ID              int     `json:"id"`
+	Title           string  `json:"title"`
+	Emoticon        string  `json:"emoticon,omitempty"`
+	IsChatlist      bool    `json:"is_chatlist,omitempty"` // Carpeta compartida (solo lectura)
+	Contacts        bool    `json:"contacts"`
+	NonContacts     bool    `json:"non_contacts"`
+	Groups          bool    `json:"groups"`
+	Broadcasts      bool    `json:"broadcasts"`
+	Bots            bool    `json:"bots"`
+	ExcludeMuted    bool    `json:"exclude_muted"`
+	ExcludeRead     bool    `json:"exclude_read"`
+	ExcludeArchived bool    `json:"exclude_archived"`
+	PinnedChats     []int64 `json:"pinned_chats,omitempty"`
+	IncludeChats    []int64 `json:"include_chats,omitempty"`
+	ExcludeChats    []int64 `json:"exclude_chats,omitempty"`
+}
+
+// ChatFolderRequest para crear o editar una carpeta
+// @Description Los chats se indican en formato Bot API (ID usuarios, -ID grupos, -100ID canales)
+type ChatFolderRequest struct {
+	Title           string  `json:"title" validate:"required,max=12" example:"Clientes"`
+	Emoticon        string  `json:"emoticon,omitempty" example:"💼"`
+	Contacts        bool    `json:"contacts"`
+	NonContacts     bool    `json:"non_contacts"`
+	Groups          bool    `json:"groups"`
+	Broadcasts      bool    `json:"broadcasts"`
+	Bots            bool    `json:"bots"`
+	ExcludeMuted    bool    `json:"exclude_muted"`
+	ExcludeRead     bool    `json:"exclude_read"`
+	ExcludeArchived bool    `json:"exclude_archived"`
+	PinnedChats     []int64 `json:"pinned_chats,omitempty"`
+	IncludeChats    []int64 `json:"include_chats,omitempty" validate:"max=100"`
+	ExcludeChats    []int64 `json:"exclude_chats,omitempty" validate:"max=100"`
+}
+
+// ChatFoldersResponse lista de carpetas
+type ChatFoldersResponse struct {
+	Folders []ChatFolder `json:"folders"`
+}
@@ -12,13 +12,34 @@ ErrUserAlreadyExists  = errors.New("el usuario ya existe")
 ErrEmailAlreadyExists = errors.New("el email ya está registrado")
 ErrInvalidCredentials = errors.New("credenciales inválidas")
 ErrUserInactive       = errors.New("usuario desactivado")
+ErrAccountLocked      = errors.New("cuenta bloqueada temporalmente por intentos fallidos")
+ErrPasswordUnchanged  = errors.New("la nueva contraseña debe ser distinta de la actual")
 
 // Errores de Autenticación
 ErrInvalidToken   = errors.New("token inválido")
 ErrTokenExpired   = errors.New("token expirado")
 ErrTokenRevoked   = errors.New("token revocado")
+ErrTokenReused    = errors.New("refresh token reutilizado, sesión revocada")
 ErrUnauthorized   = errors.New("no autorizado")
 ErrForbidden      = errors.New("acceso denegado")
+ErrAPIKeyNotFound = errors.New("API key no encontrada")
+ErrDeviceNotFound = errors.New("dispositivo no encontrado")
+
+// Sin acceso a la blacklist de tokens (Redis)
+ErrRevocationUnavailable = errors.New("no se pudo verificar si el token fue revocado")
+
+// Errores de registro
+ErrRegistrationDisabled = errors.New("el registro está deshabilitado")
+ErrInvitationRequired   = errors.New("el registro requiere una invitación")
+ErrInvalidInvitation    = errors.New("invitación inválida, usada o expirada")
+ErrInvitationNotFound   = errors.New("invitación no encontrada")
+
+// Errores de MFA
+ErrMFAEnrollmentRequired = errors.New("la política exige activar MFA")
+ErrMFANotEnrolled        = errors.New("no hay un enrolamiento MFA pendiente")
+ErrMFANotEnabled         = errors.New("MFA no está activado")
+ErrMFAAlreadyEnabled     = errors.New("MFA ya está activado")
+ErrInvalidMFACode        = errors.New("código MFA inválido")
 
 // Errores de Sesión Telegram
 ErrSessionNotFound         = errors.New("sesión no encontrada")
@@ -33,12 +54,35 @@ ErrPasswordRequired        = errors.New("se requiere contraseña 2FA")
 ErrInvalidPassword         = errors.New("contraseña 2FA incorrecta")
 ErrTelegramError           = errors.New("error de Telegram")
 ErrTelegramFloodWait       = errors.New("demasiados intentos, espere")
+ErrInvalidBotToken         = errors.New("token de bot inválido")
+ErrBotOnly                 = errors.New("operación disponible solo para sesiones de bot")
+ErrInvalidSessionData      = errors.New("datos de sesión inválidos")
+ErrSessionRevoked          = errors.New("la sesión fue revocada o cerrada en Telegram")
 
 // Errores de Mensajes
 ErrMessageNotFound   = errors.New("mensaje no encontrado")
 ErrChatNotFound      = errors.New("chat no encontrado")
 ErrPeerNotFound      = errors.New("destinatario no encontrado")
 ErrMediaNotSupported = errors.New("tipo de media no soportado")
+ErrFolderNotFound    = errors.New("carpeta no encontrada")
+
+// Errores de Organizaciones
+ErrOrganizationNotFound = errors.New("organización no encontrada")
+ErrMemberNotFound       = errors.New("miembro no encontrado")
+ErrAlreadyMember        = errors.New("el usuario ya es miembro de la organización")
+ErrLastOwner            = errors.New("la organización debe conservar al menos un owner")
+ErrInsufficientOrgRole  = errors.New("tu rol en la organización no permite esta operación")
+
+// Errores de Cuotas: todos envuelven ErrQuotaExceeded
+ErrQuotaExceeded            = errors.New("cuota excedida")
+ErrSessionQuotaExceeded     = fmt.Errorf("%w: máximo de sesiones alcanzado", ErrQuotaExceeded)
+ErrDailySendQuotaExceeded   = fmt.Errorf("%w: envíos diarios agotados", ErrQuotaExceeded)
+ErrMonthlySendQuotaExceeded = fmt.Errorf("%w: envíos mensuales agotados", ErrQuotaExceeded)
+ErrBulkQuotaExceeded        = fmt.Errorf("%w: demasiados destinatarios en un envío masivo", ErrQuotaExceeded)
+ErrMediaQuotaExceeded       = fmt.Errorf("%w: volumen mensual de media agotado", ErrQuotaExceeded)
+
+// Errores de Webhooks
+ErrWebhookNotFound = errors.New("webhook no configurado")
 
 // Errores de Validación
 ErrValidation   = errors.New("error de validación")
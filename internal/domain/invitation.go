@@ -0,0 +1,61 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Modos de registro (REGISTRATION_MODE)
+const (
+	RegistrationOpen     = "open"     // Cualquiera puede registrarse
+	RegistrationInvite   = "invite"   // Solo con una invitación vigente
+	RegistrationDisabled = "disabled" // Solo los administradores crean cuentas (/admin/users)
+)
+
+// Invitation invitación de registro de un solo uso (solo se guarda el hash)
+type Invitation struct {
+	ID        uuid.UUID  `json:"id"`
+	TokenHash string     `json:"-"`
+	Email     string     `json:"email,omitempty"` // Si se indica, solo ese email puede usarla
+	Role      Role       `json:"role"`
+	CreatedBy uuid.UUID  `json:"created_by"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at,omitempty"`
+	UsedBy    *uuid.UUID `json:"used_by,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// CreateInvitationRequest petición para invitar a un usuario
+type CreateInvitationRequest struct {
+	Email          string `json:"email,omitempty" validate:"omitempty,email"`
+	Role           Role   `json:"role,omitempty" validate:"omitempty,oneof=admin user"`
+	ExpiresInHours int    `json:"expires_in_hours,omitempty" validate:"omitempty,min=1,max=720"`
+}
+
+// CreateInvitationResponse el token solo se muestra al crearla
+type CreateInvitationResponse struct {
+	Token string `json:"invite_token"`
+	*Invitation
+}
+
+// AdminCreateUserRequest alta directa de una cuenta por un administrador
+type AdminCreateUserRequest struct {
+	Username string `json:"username" validate:"required,min=3,max=50,alphanum"`
+	Email    string `json:"email" validate:"required,email"`
+	Password string `json:"password" validate:"required,min=8,max=72"`
+	Role     Role   `json:"role,omitempty" validate:"omitempty,oneof=admin user"`
+}
+
+type InvitationRepository interface {
+	Create(ctx context.Context, inv *Invitation) error
+	ListPending(ctx context.Context) ([]*Invitation, error)
+	// Consume marca la invitación como usada y la retorna; ErrInvalidToken si
+	// no existe, ya se usó o expiró
+	Consume(ctx context.Context, tokenHash string) (*Invitation, error)
+	// Release deshace Consume si el registro falló
+	Release(ctx context.Context, id uuid.UUID) error
+	SetUsedBy(ctx context.Context, id, userID uuid.UUID) error
+	Delete(ctx context.Context, id uuid.UUID) error
+}
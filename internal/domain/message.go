@@ -28,45 +28,55 @@ const (
 	MessageStatusFailed    MessageStatus = "failed"
 )
 
+// IsFinal indica si el job ya terminó (enviado o fallido)
+func (s MessageStatus) IsFinal() bool {
+	return s == MessageStatusSent || s == MessageStatusFailed
+}
+
 // ==================== REQUEST DTOs ====================
 
 // TextMessageRequest para enviar mensaje de texto
 // @Description Mensaje de texto simple
 type TextMessageRequest struct {
-	To   string `json:"to" validate:"required" example:"@username o +573001234567"`
-	Text string `json:"text" validate:"required" example:"Hola desde la API!"`
+	To             string         `json:"to" validate:"required" example:"@username o +573001234567"`
+	Text           string         `json:"text" validate:"required" example:"Hola desde la API!"`
+	InlineKeyboard InlineKeyboard `json:"inline_keyboard,omitempty"` // Solo sesiones de bot
 }
 
 // PhotoMessageRequest para enviar foto
 // @Description Mensaje con foto
 type PhotoMessageRequest struct {
-	To       string `json:"to" validate:"required" example:"@username"`
-	PhotoURL string `json:"photo_url" validate:"required,url" example:"https://example.com/image.jpg"`
-	Caption  string `json:"caption,omitempty" example:"Mira esta imagen"`
+	To             string         `json:"to" validate:"required" example:"@username"`
+	PhotoURL       string         `json:"photo_url" validate:"required,url" example:"https://example.com/image.jpg"`
+	Caption        string         `json:"caption,omitempty" example:"Mira esta imagen"`
+	InlineKeyboard InlineKeyboard `json:"inline_keyboard,omitempty"` // Solo sesiones de bot
 }
 
 // VideoMessageRequest para enviar video
 // @Description Mensaje con video
 type VideoMessageRequest struct {
-	To       string `json:"to" validate:"required" example:"@username"`
-	VideoURL string `json:"video_url" validate:"required,url" example:"https://example.com/video.mp4"`
-	Caption  string `json:"caption,omitempty" example:"Video interesante"`
+	To             string         `json:"to" validate:"required" example:"@username"`
+	VideoURL       string         `json:"video_url" validate:"required,url" example:"https://example.com/video.mp4"`
+	Caption        string         `json:"caption,omitempty" example:"Video interesante"`
+	InlineKeyboard InlineKeyboard `json:"inline_keyboard,omitempty"` // Solo sesiones de bot
 }
 
 // AudioMessageRequest para enviar audio
 // @Description Mensaje con audio
 type AudioMessageRequest struct {
-	To       string `json:"to" validate:"required" example:"@username"`
-	AudioURL string `json:"audio_url" validate:"required,url" example:"https://example.com/audio.mp3"`
-	Caption  string `json:"caption,omitempty" example:"Escucha esto"`
+	To             string         `json:"to" validate:"required" example:"@username"`
+	AudioURL       string         `json:"audio_url" validate:"required,url" example:"https://example.com/audio.mp3"`
+	Caption        string         `json:"caption,omitempty" example:"Escucha esto"`
+	InlineKeyboard InlineKeyboard `json:"inline_keyboard,omitempty"` // Solo sesiones de bot
 }
 
 // FileMessageRequest para enviar documento
 // @Description Mensaje con archivo/documento
 type FileMessageRequest struct {
-	To      string `json:"to" validate:"required" example:"@username"`
-	FileURL string `json:"file_url" validate:"required,url" example:"https://example.com/doc.pdf"`
-	Caption string `json:"caption,omitempty" example:"Documento adjunto"`
+	To             string         `json:"to" validate:"required" example:"@username"`
+	FileURL        string         `json:"file_url" validate:"required,url" example:"https://example.com/doc.pdf"`
+	Caption        string         `json:"caption,omitempty" example:"Documento adjunto"`
+	InlineKeyboard InlineKeyboard `json:"inline_keyboard,omitempty"` // Solo sesiones de bot
 }
 
 // BulkTextRequest para envío masivo
@@ -77,15 +87,41 @@ type BulkTextRequest struct {
 	DelayMs    int      `json:"delay_ms,omitempty" example:"3000"`
 }
 
+// ==================== INLINE KEYBOARD (bots) ====================
+
+// InlineButton botón de teclado inline. Debe tener callback_data o url, no ambos.
+type InlineButton struct {
+	Text         string `json:"text" validate:"required" example:"Confirmar"`
+	CallbackData string `json:"callback_data,omitempty" validate:"max=64" example:"confirm:42"`
+	URL          string `json:"url,omitempty" validate:"omitempty,url" example:"https://example.com"`
+}
+
+// InlineKeyboard filas de botones inline adjuntas a un mensaje
+type InlineKeyboard [][]InlineButton
+
+// AnswerCallbackRequest respuesta a un callback query de un botón inline
+type AnswerCallbackRequest struct {
+	Text      string `json:"text,omitempty" validate:"max=200" example:"¡Listo!"`
+	ShowAlert bool   `json:"show_alert,omitempty"`
+	URL       string `json:"url,omitempty" validate:"omitempty,url"`
+	CacheTime int    `json:"cache_time,omitempty" validate:"gte=0" example:"0"`
+}
+
 // ==================== INTERNAL REQUEST (para el servicio) ====================
 
 type SendMessageRequest struct {
-	To       string      `json:"to"`
-	Text     string      `json:"text,omitempty"`
-	Type     MessageType `json:"type,omitempty"`
-	MediaURL string      `json:"media_url,omitempty"`
-	Caption  string      `json:"caption,omitempty"`
-	DelayMs  int         `json:"delay_ms,omitempty"`
+	To             string         `json:"to"`
+	Text           string         `json:"text,omitempty"`
+	Type           MessageType    `json:"type,omitempty"`
+	MediaURL       string         `json:"media_url,omitempty"`
+	Caption        string         `json:"caption,omitempty"`
+	DelayMs        int            `json:"delay_ms,omitempty"`
+	InlineKeyboard InlineKeyboard `json:"inline_keyboard,omitempty"`
+
+	// Cuota de media: límite de bytes a descargar (0 = sin límite) y bytes
+	// efectivamente enviados, que completa el sender
+	MaxMediaBytes int64 `json:"-"`
+	MediaBytes    int64 `json:"-"`
 }
 
 type BulkMessageRequest struct {
@@ -111,18 +147,20 @@ type MessageResponse struct {
 // MessageJob estado completo del job
 // @Description Estado detallado del mensaje
 type MessageJob struct {
-	ID        string        `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
-	SessionID uuid.UUID     `json:"session_id"`
-	To        string        `json:"to" example:"@username"`
-	Text      string        `json:"text,omitempty"`
-	Type      MessageType   `json:"type" example:"text"`
-	MediaURL  string        `json:"media_url,omitempty"`
-	Caption   string        `json:"caption,omitempty"`
-	Status    MessageStatus `json:"status" example:"sent"`
-	Error     string        `json:"error,omitempty"`
-	SendAt    time.Time     `json:"send_at"`
-	SentAt    *time.Time    `json:"sent_at,omitempty"`
-	CreatedAt time.Time     `json:"created_at"`
+	ID             string         `json:"id" example:"550e8400-e29b-41d4-a716-446655440000"`
+	SessionID      uuid.UUID      `json:"session_id"`
+	RequestedBy    uuid.UUID      `json:"requested_by"` // Usuario al que se imputa la cuota
+	To             string         `json:"to" example:"@username"`
+	Text           string         `json:"text,omitempty"`
+	Type           MessageType    `json:"type" example:"text"`
+	MediaURL       string         `json:"media_url,omitempty"`
+	Caption        string         `json:"caption,omitempty"`
+	InlineKeyboard InlineKeyboard `json:"inline_keyboard,omitempty"`
+	Status         MessageStatus  `json:"status" example:"sent"`
+	Error          string         `json:"error,omitempty"`
+	SendAt         time.Time      `json:"send_at"`
+	SentAt         *time.Time     `json:"sent_at,omitempty"`
+	CreatedAt      time.Time      `json:"created_at"`
 }
 
 // Nota: Los errores están en errors.go (ErrSessionNotActive, etc.)
\ No newline at end of file
@@ -0,0 +1,61 @@
+package domain
+
+import "context"
+
+// MFARecoveryCodeCount códigos de recuperación generados por enrolamiento
+const MFARecoveryCodeCount = 10
+
+// MFAEnrollResponse datos para registrar la app autenticadora
+type MFAEnrollResponse struct {
+	Secret        string `json:"secret"`          // Para introducirlo a mano
+	URI           string `json:"otpauth_uri"`     // otpauth://totp/...
+	QRImageBase64 string `json:"qr_image_base64"` // PNG del QR de la URI
+}
+
+// MFACodeRequest código TOTP de 6 dígitos
+type MFACodeRequest struct {
+	Code string `json:"code" validate:"required,min=6,max=20" example:"123456"`
+}
+
+// MFAConfirmResponse códigos de recuperación (se muestran una única vez). Si
+// el enrolamiento lo exigía la política durante el login, incluye los tokens.
+type MFAConfirmResponse struct {
+	RecoveryCodes []string       `json:"recovery_codes"`
+	Login         *LoginResponse `json:"login,omitempty"`
+}
+
+// MFAVerifyRequest segundo paso del login: token de desafío + código TOTP o
+// código de recuperación
+type MFAVerifyRequest struct {
+	MFAToken string `json:"mfa_token" validate:"required"`
+	Code     string `json:"code" validate:"required,min=6,max=20" example:"123456"`
+}
+
+// MFADisableRequest desactivar MFA exige contraseña y un código vigente
+type MFADisableRequest struct {
+	Password string `json:"password" validate:"required"`
+	Code     string `json:"code" validate:"required,min=6,max=20"`
+}
+
+// ==================== POLÍTICAS ====================
+
+// Claves de app_settings
+const (
+	SettingMFARequired = "mfa_required"
+)
+
+// SecurityPolicy políticas de seguridad configurables por un administrador
+type SecurityPolicy struct {
+	MFARequired bool `json:"mfa_required"`
+}
+
+// UpdateSecurityPolicyRequest actualización parcial de políticas
+type UpdateSecurityPolicyRequest struct {
+	MFARequired *bool `json:"mfa_required,omitempty"`
+}
+
+// SettingsRepository guarda ajustes globales clave/valor editables en caliente
+type SettingsRepository interface {
+	Get(ctx context.Context, key string) (string, bool, error)
+	Set(ctx context.Context, key, value string) error
+}
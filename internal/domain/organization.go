@@ -0,0 +1,117 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// OrgRole rol de un miembro dentro de una organización
+type OrgRole string
+
+const (
+	OrgRoleOwner    OrgRole = "owner"    // Gestiona miembros, transfiere, exporta y elimina sesiones
+	OrgRoleOperator OrgRole = "operator" // Usa las sesiones: envía mensajes, administra chats, autentica
+	OrgRoleViewer   OrgRole = "viewer"   // Solo lectura: sesiones, chats, historial, contactos, jobs
+)
+
+// level orden de privilegio; 0 = rol desconocido
+func (r OrgRole) level() int {
+	switch r {
+	case OrgRoleOwner:
+		return 3
+	case OrgRoleOperator:
+		return 2
+	case OrgRoleViewer:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// IsValid indica si el rol es conocido
+func (r OrgRole) IsValid() bool {
+	return r.level() > 0
+}
+
+// Allows indica si el rol alcanza el nivel need
+func (r OrgRole) Allows(need OrgRole) bool {
+	return r.level() > 0 && r.level() >= need.level()
+}
+
+// Organization agrupa usuarios que comparten sesiones de Telegram
+type Organization struct {
+	ID        uuid.UUID `json:"id"`
+	Name      string    `json:"name"`
+	CreatedBy uuid.UUID `json:"created_by"`
+	Role      OrgRole   `json:"role,omitempty"` // Rol del usuario que consulta
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// OrganizationMember pertenencia de un usuario a una organización
+type OrganizationMember struct {
+	OrganizationID uuid.UUID `json:"organization_id"`
+	UserID         uuid.UUID `json:"user_id"`
+	Username       string    `json:"username"`
+	Email          string    `json:"email"`
+	Role           OrgRole   `json:"role"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// CreateOrganizationRequest petición para crear una organización; el creador
+// queda como owner
+type CreateOrganizationRequest struct {
+	Name string `json:"name" validate:"required,min=2,max=100" example:"Soporte"`
+}
+
+// UpdateOrganizationRequest renombra la organización
+type UpdateOrganizationRequest struct {
+	Name string `json:"name" validate:"required,min=2,max=100" example:"Soporte LATAM"`
+}
+
+// AddMemberRequest añade un usuario existente por username o email
+type AddMemberRequest struct {
+	Username string  `json:"username,omitempty" example:"maria"`
+	Email    string  `json:"email,omitempty" validate:"omitempty,email"`
+	Role     OrgRole `json:"role" validate:"required,oneof=owner operator viewer" example:"operator"`
+}
+
+// UpdateMemberRoleRequest cambia el rol de un miembro
+type UpdateMemberRoleRequest struct {
+	Role OrgRole `json:"role" validate:"required,oneof=owner operator viewer" example:"viewer"`
+}
+
+// TransferSessionRequest mueve una sesión a una organización; null la vuelve
+// personal del usuario que la transfiere
+type TransferSessionRequest struct {
+	OrganizationID *uuid.UUID `json:"organization_id"`
+}
+
+type OrganizationRepository interface {
+	// Create guarda la organización y a owner como su primer owner
+	Create(ctx context.Context, org *Organization, ownerID uuid.UUID) error
+	GetByID(ctx context.Context, id uuid.UUID) (*Organization, error)
+	// ListByUser organizaciones del usuario con su rol en cada una
+	ListByUser(ctx context.Context, userID uuid.UUID) ([]*Organization, error)
+	Update(ctx context.Context, org *Organization) error
+	// Delete elimina la organización; sus sesiones vuelven a ser personales
+	// de su user_id
+	Delete(ctx context.Context, id uuid.UUID) error
+
+	AddMember(ctx context.Context, m *OrganizationMember) error
+	GetMember(ctx context.Context, orgID, userID uuid.UUID) (*OrganizationMember, error)
+	ListMembers(ctx context.Context, orgID uuid.UUID) ([]*OrganizationMember, error)
+	// UpdateMemberRole y RemoveMember fallan con ErrLastOwner si dejarían la
+	// organización sin owners
+	UpdateMemberRole(ctx context.Context, orgID, userID uuid.UUID, role OrgRole) error
+	RemoveMember(ctx context.Context, orgID, userID uuid.UUID) error
+
+	// SetSessionOwner fija el usuario responsable y la organización de una sesión
+	SetSessionOwner(ctx context.Context, sessionID, userID uuid.UUID, orgID *uuid.UUID) error
+	// ReleaseUser prepara la baja de un usuario: asciende a otro miembro en
+	// las organizaciones donde era el único owner, elimina las que se quedan
+	// sin miembros y reasigna sus sesiones compartidas a otro owner
+	ReleaseUser(ctx context.Context, userID uuid.UUID) error
+}
@@ -0,0 +1,37 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ChangePasswordRequest cambio de contraseña del propio usuario
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" validate:"required"`
+	NewPassword     string `json:"new_password" validate:"required,min=8,max=72"`
+}
+
+// ResetPasswordRequest nueva contraseña con el token emitido por un administrador
+type ResetPasswordRequest struct {
+	Token       string `json:"token" validate:"required"`
+	NewPassword string `json:"new_password" validate:"required,min=8,max=72"`
+}
+
+// PasswordResetToken token de restablecimiento (solo se guarda el hash)
+type PasswordResetToken struct {
+	ID        uuid.UUID  `json:"id"`
+	UserID    uuid.UUID  `json:"user_id"`
+	TokenHash string     `json:"-"`
+	CreatedBy uuid.UUID  `json:"created_by"` // Administrador que lo emitió
+	ExpiresAt time.Time  `json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// PasswordResetResponse el token solo se muestra al emitirlo; el
+// administrador se lo hace llegar al usuario
+type PasswordResetResponse struct {
+	Token     string    `json:"reset_token"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
@@ -0,0 +1,104 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// QuotaLimits cuotas efectivas de un usuario; 0 = sin límite
+type QuotaLimits struct {
+	MaxSessions    int `json:"max_sessions" example:"10"`
+	DailySends     int `json:"daily_sends" example:"1000"`
+	MonthlySends   int `json:"monthly_sends" example:"20000"`
+	BulkRecipients int `json:"bulk_recipients" example:"100"`
+	MonthlyMediaMB int `json:"monthly_media_mb" example:"1024"`
+}
+
+// QuotaOverrides cuotas propias de un usuario. Un campo null hereda el valor
+// global (QUOTA_*); 0 lo deja sin límite.
+type QuotaOverrides struct {
+	MaxSessions    *int `json:"max_sessions,omitempty" validate:"omitempty,min=0"`
+	DailySends     *int `json:"daily_sends,omitempty" validate:"omitempty,min=0"`
+	MonthlySends   *int `json:"monthly_sends,omitempty" validate:"omitempty,min=0"`
+	BulkRecipients *int `json:"bulk_recipients,omitempty" validate:"omitempty,min=0"`
+	MonthlyMediaMB *int `json:"monthly_media_mb,omitempty" validate:"omitempty,min=0"`
+}
+
+// Apply retorna base con los campos sobrescritos por o
+func (o *QuotaOverrides) Apply(base QuotaLimits) QuotaLimits {
+	if o == nil {
+		return base
+	}
+	pick := func(v *int, def int) int {
+		if v != nil {
+			return *v
+		}
+		return def
+	}
+	return QuotaLimits{
+		MaxSessions:    pick(o.MaxSessions, base.MaxSessions),
+		DailySends:     pick(o.DailySends, base.DailySends),
+		MonthlySends:   pick(o.MonthlySends, base.MonthlySends),
+		BulkRecipients: pick(o.BulkRecipients, base.BulkRecipients),
+		MonthlyMediaMB: pick(o.MonthlyMediaMB, base.MonthlyMediaMB),
+	}
+}
+
+// UpdateQuotasRequest petición para fijar las cuotas de un usuario.
+// null restablece las cuotas globales.
+type UpdateQuotasRequest struct {
+	Quotas *QuotaOverrides `json:"quotas"`
+}
+
+// UsageMetric contador de consumo por periodo
+type UsageMetric string
+
+const (
+	UsageSendsDaily        UsageMetric = "sends_daily"         // Mensajes encolados en el día (UTC)
+	UsageSendsMonthly      UsageMetric = "sends_monthly"       // Mensajes encolados en el mes (UTC)
+	UsageMediaBytesMonthly UsageMetric = "media_bytes_monthly" // Bytes de media enviados en el mes (UTC)
+)
+
+// UsageCharge incremento de un contador; solo se aplica si no supera Limit
+// (0 = sin límite)
+type UsageCharge struct {
+	Metric UsageMetric
+	Period time.Time // Inicio del periodo: día o mes en UTC
+	Amount int64
+	Limit  int64
+}
+
+// UsageCounter consumo de una cuota en el periodo actual
+type UsageCounter struct {
+	Used     int64      `json:"used"`
+	Limit    int64      `json:"limit"` // 0 = sin límite
+	ResetsAt *time.Time `json:"resets_at,omitempty"`
+}
+
+// QuotaUsage respuesta de /me/usage
+type QuotaUsage struct {
+	Limits            QuotaLimits  `json:"limits"`
+	Overridden        bool         `json:"overridden"` // Un administrador fijó cuotas propias
+	Sessions          UsageCounter `json:"sessions"`
+	DailySends        UsageCounter `json:"daily_sends"`
+	MonthlySends      UsageCounter `json:"monthly_sends"`
+	MonthlyMediaBytes UsageCounter `json:"monthly_media_bytes"`
+}
+
+type QuotaRepository interface {
+	// GetOverrides retorna nil si el usuario usa las cuotas globales
+	GetOverrides(ctx context.Context, userID uuid.UUID) (*QuotaOverrides, error)
+	// SetOverrides guarda las cuotas propias; nil las elimina
+	SetOverrides(ctx context.Context, userID uuid.UUID, o *QuotaOverrides) error
+	// Consume aplica todos los cargos o ninguno; retorna la métrica del primer
+	// cargo que superaría su límite ("" si se aplicaron)
+	Consume(ctx context.Context, userID uuid.UUID, charges []UsageCharge) (UsageMetric, error)
+	// Refund descuenta cargos ya aplicados (sin bajar de 0); Limit se ignora
+	Refund(ctx context.Context, userID uuid.UUID, charges []UsageCharge) error
+	// Usage valor actual de un contador (0 si no existe)
+	Usage(ctx context.Context, userID uuid.UUID, metric UsageMetric, period time.Time) (int64, error)
+	// DeleteBefore elimina los contadores de periodos anteriores a before
+	DeleteBefore(ctx context.Context, before time.Time) (int64, error)
+}
@@ -2,6 +2,7 @@ package domain
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -14,8 +15,25 @@ type UserRepository interface {
 	Update(ctx context.Context, user *User) error
 	UpdateLastLogin(ctx context.Context, id uuid.UUID) error
 	Delete(ctx context.Context, id uuid.UUID) error
+	List(ctx context.Context, filter UserFilter) ([]*User, int64, error)
 	ExistsByUsername(ctx context.Context, username string) (bool, error)
 	ExistsByEmail(ctx context.Context, email string) (bool, error)
+	UpdateScopes(ctx context.Context, id uuid.UUID, scopes []Scope) error
+	UpdatePassword(ctx context.Context, id uuid.UUID, passwordHash string) error
+
+	// Bloqueo por intentos fallidos
+	RecordFailedLogin(ctx context.Context, id uuid.UUID) (int, error)
+	LockUntil(ctx context.Context, id uuid.UUID, until time.Time) error
+	ResetFailedLogins(ctx context.Context, id uuid.UUID) error
+
+	// MFA (TOTP)
+	SetMFAPendingSecret(ctx context.Context, id uuid.UUID, secret string) error
+	EnableMFA(ctx context.Context, id uuid.UUID, recoveryCodeHashes []string) error
+	DisableMFA(ctx context.Context, id uuid.UUID) error
+	SetMFARecoveryCodes(ctx context.Context, id uuid.UUID, recoveryCodeHashes []string) error
+	ConsumeMFARecoveryCode(ctx context.Context, id uuid.UUID, codeHash string) (bool, error)
+	UseMFAStep(ctx context.Context, id uuid.UUID, step int64) (bool, error)
+	ReencryptMFASecrets(ctx context.Context) (int, error)
 }
 
 type RefreshTokenRepository interface {
@@ -23,9 +41,20 @@ type RefreshTokenRepository interface {
 	GetByTokenHash(ctx context.Context, tokenHash string) (*RefreshToken, error)
 	Revoke(ctx context.Context, id uuid.UUID) error
 	RevokeAllForUser(ctx context.Context, userID uuid.UUID) error
+	RevokeFamily(ctx context.Context, familyID uuid.UUID) (int64, error)
+	GetActiveByUserID(ctx context.Context, userID uuid.UUID) ([]*RefreshToken, error)
 	DeleteExpired(ctx context.Context) (int64, error)
 }
 
+// PasswordResetRepository tokens de restablecimiento de un solo uso
+type PasswordResetRepository interface {
+	Create(ctx context.Context, token *PasswordResetToken) error
+	// Consume marca el token como usado y retorna su usuario; ErrInvalidToken
+	// si no existe, ya se usó o expiró
+	Consume(ctx context.Context, tokenHash string) (uuid.UUID, error)
+	RevokeForUser(ctx context.Context, userID uuid.UUID) error
+}
+
 type CacheRepository interface {
 	Set(ctx context.Context, key string, value interface{}, ttlSeconds int) error
 	Get(ctx context.Context, key string) (string, error)
@@ -35,4 +64,6 @@ type CacheRepository interface {
 	GetJSON(ctx context.Context, key string, dest interface{}) error
 	IncrementRateLimit(ctx context.Context, key string, windowSeconds int) (int64, error)
 	ScanKeys(ctx context.Context, pattern string, count int64) ([]string, error) // ✅ Nuevo
+	BlacklistToken(ctx context.Context, tokenID string, ttlSeconds int) error
+	IsTokenBlacklisted(ctx context.Context, tokenID string) (bool, error)
 }
\ No newline at end of file
@@ -0,0 +1,65 @@
+package domain
+
+// Scope permiso granular que se exige por ruta. Los usuarios, las API keys y
+// los JWT llevan la lista de scopes concedidos.
+type Scope string
+
+const (
+	ScopeSessionsRead  Scope = "sessions:read"  // Listar y consultar sesiones, estado del pool
+	ScopeSessionsWrite Scope = "sessions:write" // Crear, autenticar, importar, exportar y eliminar sesiones
+	ScopeMessagesSend  Scope = "messages:send"  // Enviar mensajes, responder callbacks y consultar jobs
+	ScopeChatsRead     Scope = "chats:read"     // Chats, historial, contactos, bloqueados y carpetas
+	ScopeChatsWrite    Scope = "chats:write"    // Leer/marcar, reacciones, presencia, diálogos, contactos
+	ScopeChatsAdmin    Scope = "chats:admin"    // Crear y administrar grupos y canales
+	ScopeWebhooksRead  Scope = "webhooks:read"  // Consultar la configuración del webhook
+	ScopeWebhooksWrite Scope = "webhooks:write" // Configurar, rotar, iniciar y detener webhooks
+)
+
+// AllScopes todos los scopes conocidos; es lo que tiene un usuario sin restricciones
+var AllScopes = []Scope{
+	ScopeSessionsRead,
+	ScopeSessionsWrite,
+	ScopeMessagesSend,
+	ScopeChatsRead,
+	ScopeChatsWrite,
+	ScopeChatsAdmin,
+	ScopeWebhooksRead,
+	ScopeWebhooksWrite,
+}
+
+// IsValid indica si el scope es conocido
+func (s Scope) IsValid() bool {
+	for _, known := range AllScopes {
+		if s == known {
+			return true
+		}
+	}
+	return false
+}
+
+// HasScope verifica si scope está en granted
+func HasScope(granted []Scope, scope Scope) bool {
+	for _, s := range granted {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// IntersectScopes retorna los scopes de b que también están en a
+func IntersectScopes(a, b []Scope) []Scope {
+	out := make([]Scope, 0, len(b))
+	for _, s := range b {
+		if HasScope(a, s) {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// UpdateScopesRequest petición para fijar los scopes de un usuario.
+// null restablece los scopes por defecto (todos); [] le quita todos.
+type UpdateScopesRequest struct {
+	Scopes []Scope `json:"scopes" validate:"omitempty,dive,oneof=sessions:read sessions:write messages:send chats:read chats:write chats:admin webhooks:read webhooks:write" example:"sessions:read,chats:read"`
+}
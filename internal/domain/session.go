@@ -22,11 +22,13 @@ type AuthMethod string
 const (
 	AuthMethodSMS AuthMethod = "sms"
 	AuthMethodQR  AuthMethod = "qr"
+	AuthMethodBot AuthMethod = "bot"
 )
 
 type TelegramSession struct {
 	ID               uuid.UUID     `json:"id"`
-	UserID           uuid.UUID     `json:"user_id"`
+	UserID           uuid.UUID     `json:"user_id"`                   // Creador o responsable
+	OrganizationID   *uuid.UUID    `json:"organization_id,omitempty"` // nil = sesión personal
 	PhoneNumber      string        `json:"phone_number"`
 	ApiID            int           `json:"api_id"`
 	ApiHash          string        `json:"-"`
@@ -36,6 +38,7 @@ type TelegramSession struct {
 	AuthState        SessionStatus `json:"auth_state"`
 	TelegramUserID   int64         `json:"telegram_user_id,omitempty"`
 	TelegramUsername string        `json:"telegram_username,omitempty"`
+	IsBot            bool          `json:"is_bot"`
 	IsActive         bool          `json:"is_active"`
 	CreatedAt        time.Time     `json:"created_at"`
 	UpdatedAt        time.Time     `json:"updated_at"`
@@ -46,9 +49,80 @@ type CreateSessionRequest struct {
 	ApiID       int        `json:"api_id" validate:"required,gt=0"`
 	ApiHash     string     `json:"api_hash" validate:"required,len=32"`
 	SessionName string     `json:"session_name,omitempty"`
-	AuthMethod  AuthMethod `json:"auth_method,omitempty"`
+	AuthMethod  AuthMethod `json:"auth_method,omitempty" validate:"omitempty,oneof=sms qr bot"`
+	BotToken    string     `json:"bot_token,omitempty" example:"123456789:AAF..."` // Requerido si auth_method=bot
 }
 
+// SessionImportFormat formato de origen de una sesión importada
+type SessionImportFormat string
+
+const (
+	ImportTelethonString SessionImportFormat = "telethon_string"
+	ImportTelethonSQLite SessionImportFormat = "telethon_sqlite"
+	ImportPyrogram       SessionImportFormat = "pyrogram"
+	ImportTDesktop       SessionImportFormat = "tdesktop"
+	ImportGotdJSON       SessionImportFormat = "gotd_json"
+)
+
+// ImportSessionRequest importa una sesión ya autenticada en otra librería.
+// Los formatos binarios (telethon_sqlite, tdesktop como zip de tdata) se envían
+// en base64 o como archivo multipart.
+type ImportSessionRequest struct {
+	Format      SessionImportFormat `json:"format" form:"format" validate:"required,oneof=telethon_string telethon_sqlite pyrogram tdesktop gotd_json" example:"telethon_string"`
+	Session     string              `json:"session,omitempty" form:"session" example:"1BVtsOHYBu..."`
+	ApiID       int                 `json:"api_id" form:"api_id" validate:"required,gt=0"`
+	ApiHash     string              `json:"api_hash" form:"api_hash" validate:"required,len=32"`
+	SessionName string              `json:"session_name,omitempty" form:"session_name"`
+	Passcode    string              `json:"passcode,omitempty" form:"passcode"` // Passcode local de Telegram Desktop
+}
+
+// ==================== EXPORT / BUNDLE ====================
+
+// SessionBundleVersion versión actual del contenido de un bundle exportado
+const SessionBundleVersion = 1
+
+// SessionBundle contenido (antes de cifrar con passphrase) de una sesión exportada
+type SessionBundle struct {
+	Version     int                `json:"version"`
+	ExportedAt  time.Time          `json:"exported_at"`
+	ApiID       int                `json:"api_id"`
+	ApiHash     string             `json:"api_hash"`
+	SessionData []byte             `json:"session_data"` // Formato gotd, sin cifrar
+	Metadata    SessionBundleMeta  `json:"metadata"`
+	Webhook     *WebhookBundleConf `json:"webhook,omitempty"`
+}
+
+// SessionBundleMeta metadatos de la sesión exportada
+type SessionBundleMeta struct {
+	SessionName      string    `json:"session_name"`
+	PhoneNumber      string    `json:"phone_number"`
+	TelegramUserID   int64     `json:"telegram_user_id"`
+	TelegramUsername string    `json:"telegram_username,omitempty"`
+	IsBot            bool      `json:"is_bot"`
+	CreatedAt        time.Time `json:"created_at"`
+}
+
+// WebhookBundleConf configuración de webhook incluida en el bundle
+type WebhookBundleConf struct {
+	URL        string   `json:"url"`
+	Secret     string   `json:"secret,omitempty"`
+	Events     []string `json:"events"`
+	IsActive   bool     `json:"is_active"`
+	MaxRetries int      `json:"max_retries"`
+	TimeoutMs  int      `json:"timeout_ms"`
+}
+
+// ImportBundleRequest restaura un bundle generado por GET /sessions/:id/export.
+// El bundle se envía en base64 o como archivo multipart "file".
+type ImportBundleRequest struct {
+	Bundle      string `json:"bundle,omitempty" form:"bundle"`
+	Passphrase  string `json:"passphrase" form:"passphrase" validate:"required,min=12"`
+	SessionName string `json:"session_name,omitempty" form:"session_name"`
+}
+
+// MinExportPassphrase longitud mínima de la passphrase de exportación
+const MinExportPassphrase = 12
+
 type VerifyCodeRequest struct {
 	Code string `json:"code" validate:"required,min=5,max=6"`
 }
@@ -66,10 +140,24 @@ type QRCodeResponse struct {
 
 type SessionRepository interface {
 	Create(ctx context.Context, session *TelegramSession) error
+	// CreateWithinLimit crea la sesión de forma atómica solo si el usuario es
+	// responsable de menos de limit sesiones (0 = sin límite); si no, retorna
+	// ErrSessionQuotaExceeded
+	CreateWithinLimit(ctx context.Context, session *TelegramSession, limit int) error
 	GetByID(ctx context.Context, id uuid.UUID) (*TelegramSession, error)
 	GetByPhone(ctx context.Context, phone string) (*TelegramSession, error)
 	GetByUserAndPhone(ctx context.Context, userID uuid.UUID, phone string) (*TelegramSession, error)
 	Update(ctx context.Context, session *TelegramSession) error
 	Delete(ctx context.Context, id uuid.UUID) error
 	ListByUserID(ctx context.Context, userID uuid.UUID) ([]TelegramSession, error)
+	// CountByUserID sesiones de las que el usuario es responsable (user_id),
+	// incluidas las compartidas con sus organizaciones
+	CountByUserID(ctx context.Context, userID uuid.UUID) (int, error)
+	// ListAccessible sesiones personales del usuario y las de sus organizaciones
+	ListAccessible(ctx context.Context, userID uuid.UUID) ([]TelegramSession, error)
+	// ListCiphertexts pagina por id (solo id, api_hash_encrypted y session_data)
+	ListCiphertexts(ctx context.Context, after uuid.UUID, limit int) ([]TelegramSession, error)
+	// UpdateCiphertexts reemplaza los datos cifrados solo si no cambiaron desde
+	// que se leyeron; retorna false si otra escritura se adelantó
+	UpdateCiphertexts(ctx context.Context, id uuid.UUID, oldApiHash, oldSessionData, apiHash, sessionData []byte) (bool, error)
 }
\ No newline at end of file
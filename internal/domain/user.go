@@ -23,9 +23,21 @@ type User struct {
 	PasswordHash string     `json:"-"` // Nunca serializar
 	IsActive     bool       `json:"is_active"`
 	Role         Role       `json:"role"`
+	Scopes       []Scope    `json:"scopes"` // nil = todos los scopes
+	MFAEnabled   bool       `json:"mfa_enabled"`
 	LastLoginAt  *time.Time `json:"last_login_at,omitempty"`
 	CreatedAt    time.Time  `json:"created_at"`
 	UpdatedAt    time.Time  `json:"updated_at"`
+
+	// Secretos TOTP (descifrados por el repositorio) y hashes de los códigos de recuperación
+	MFASecret        string   `json:"-"`
+	MFAPendingSecret string   `json:"-"` // Enrolamiento sin confirmar
+	MFARecoveryCodes []string `json:"-"`
+
+	// Bloqueo progresivo por intentos de login fallidos
+	FailedLoginAttempts int        `json:"-"`
+	LockedUntil         *time.Time `json:"locked_until,omitempty"`
+	PasswordChangedAt   *time.Time `json:"password_changed_at,omitempty"`
 }
 
 // CreateUserRequest representa la petición para crear usuario
@@ -33,6 +45,27 @@ type CreateUserRequest struct {
 	Username string `json:"username" validate:"required,min=3,max=50,alphanum"`
 	Email    string `json:"email" validate:"required,email"`
 	Password string `json:"password" validate:"required,min=8,max=72"`
+	// InviteToken obligatorio con REGISTRATION_MODE=invite; fija el rol de la cuenta
+	InviteToken string `json:"invite_token,omitempty"`
+}
+
+// UserFilter filtros y paginación del listado de usuarios (admin)
+type UserFilter struct {
+	Search   string // Coincidencia parcial en username o email
+	Role     Role
+	IsActive *bool
+	Limit    int
+	Offset   int
+}
+
+// UpdateUserStatusRequest activa o desactiva una cuenta
+type UpdateUserStatusRequest struct {
+	IsActive *bool `json:"is_active" validate:"required"`
+}
+
+// UpdateUserRoleRequest cambia el rol de una cuenta
+type UpdateUserRoleRequest struct {
+	Role Role `json:"role" validate:"required,oneof=admin user"`
 }
 
 // LoginRequest representa la petición de login
@@ -42,45 +75,88 @@ type LoginRequest struct {
 }
 
 // LoginResponse representa la respuesta de login exitoso
+// Si el usuario tiene MFA (o la política lo exige y no lo tiene) no incluye
+// tokens sino MFAToken, válido solo para /auth/mfa/verify o para enrolarse.
 type LoginResponse struct {
-	AccessToken  string    `json:"access_token"`
-	RefreshToken string    `json:"refresh_token"`
-	TokenType    string    `json:"token_type"`
-	ExpiresIn    int       `json:"expires_in"`
-	User         *UserInfo `json:"user"`
+	AccessToken           string    `json:"access_token,omitempty"`
+	RefreshToken          string    `json:"refresh_token,omitempty"`
+	TokenType             string    `json:"token_type,omitempty"`
+	ExpiresIn             int       `json:"expires_in"`
+	User                  *UserInfo `json:"user"`
+	MFARequired           bool      `json:"mfa_required,omitempty"`
+	MFAEnrollmentRequired bool      `json:"mfa_enrollment_required,omitempty"`
+	MFAToken              string    `json:"mfa_token,omitempty"`
 }
 
 // UserInfo representa información pública del usuario
 type UserInfo struct {
-	ID       uuid.UUID `json:"id"`
-	Username string    `json:"username"`
-	Email    string    `json:"email"`
-	Role     Role      `json:"role"`
+	ID         uuid.UUID `json:"id"`
+	Username   string    `json:"username"`
+	Email      string    `json:"email"`
+	Role       Role      `json:"role"`
+	Scopes     []Scope   `json:"scopes"`
+	MFAEnabled bool      `json:"mfa_enabled"`
+	// Solo mientras la cuenta esté bloqueada por intentos fallidos
+	LockedUntil *time.Time `json:"locked_until,omitempty"`
 }
 
 // RefreshToken representa un token de refresco
 type RefreshToken struct {
 	ID         uuid.UUID  `json:"id"`
 	UserID     uuid.UUID  `json:"user_id"`
+	FamilyID   uuid.UUID  `json:"family_id"` // Cadena de rotaciones desde el login
 	TokenHash  string     `json:"-"`
 	DeviceInfo string     `json:"device_info,omitempty"`
 	IPAddress  string     `json:"ip_address,omitempty"`
 	ExpiresAt  time.Time  `json:"expires_at"`
 	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	LoginAt    time.Time  `json:"login_at"` // Login que inició la familia
 	CreatedAt  time.Time  `json:"created_at"`
 }
 
+// Device login activo de un usuario: una familia de refresh tokens. El ID es
+// el de la familia, estable aunque el token se rote en cada refresh.
+type Device struct {
+	ID         uuid.UUID `json:"id"`
+	UserAgent  string    `json:"user_agent"`
+	IPAddress  string    `json:"ip_address,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`   // Login
+	LastUsedAt time.Time `json:"last_used_at"` // Último refresh
+	ExpiresAt  time.Time `json:"expires_at"`
+	Current    bool      `json:"current"` // Es el login que hace la petición
+}
+
 // ToUserInfo convierte User a UserInfo (datos públicos)
 func (u *User) ToUserInfo() *UserInfo {
-	return &UserInfo{
-		ID:       u.ID,
-		Username: u.Username,
-		Email:    u.Email,
-		Role:     u.Role,
+	info := &UserInfo{
+		ID:         u.ID,
+		Username:   u.Username,
+		Email:      u.Email,
+		Role:       u.Role,
+		Scopes:     u.EffectiveScopes(),
+		MFAEnabled: u.MFAEnabled,
 	}
+	if u.IsLocked(time.Now()) {
+		info.LockedUntil = u.LockedUntil
+	}
+	return info
+}
+
+// IsLocked indica si la cuenta está bloqueada por intentos fallidos en t
+func (u *User) IsLocked(t time.Time) bool {
+	return u.LockedUntil != nil && t.Before(*u.LockedUntil)
 }
 
 // IsAdmin verifica si el usuario es administrador
 func (u *User) IsAdmin() bool {
 	return u.Role == RoleAdmin
+}
+
+// EffectiveScopes scopes concedidos al usuario: los administradores y los
+// usuarios sin restricciones configuradas tienen todos
+func (u *User) EffectiveScopes() []Scope {
+	if u.IsAdmin() || u.Scopes == nil {
+		return AllScopes
+	}
+	return u.Scopes
 }
\ No newline at end of file
@@ -10,18 +10,42 @@ import (
 // ==================== WEBHOOK CONFIG ====================
 
 type WebhookConfig struct {
-	ID           uuid.UUID  `json:"id"`
-	SessionID    uuid.UUID  `json:"session_id"`
-	URL          string     `json:"url"`
-	Secret       string     `json:"secret,omitempty"`       // Para firmar requests
-	Events       []string   `json:"events"`                 // Tipos de eventos a enviar
-	IsActive     bool       `json:"is_active"`
-	MaxRetries   int        `json:"max_retries"`
-	TimeoutMs    int        `json:"timeout_ms"`
-	CreatedAt    time.Time  `json:"created_at"`
-	UpdatedAt    time.Time  `json:"updated_at"`
-	LastErrorAt  *time.Time `json:"last_error_at,omitempty"`
-	LastError    string     `json:"last_error,omitempty"`
+	ID          uuid.UUID  `json:"id"`
+	SessionID   uuid.UUID  `json:"session_id"`
+	URL         string     `json:"url"`
+	Secret      string     `json:"-"`                     // Para firmar requests (cifrado en DB, nunca se expone)
+	SecretHint  string     `json:"secret_hint,omitempty"` // Últimos caracteres del secreto
+	Events      []string   `json:"events"`                // Tipos de eventos a enviar
+	IsActive    bool       `json:"is_active"`
+	MaxRetries  int        `json:"max_retries"`
+	TimeoutMs   int        `json:"timeout_ms"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	LastErrorAt *time.Time `json:"last_error_at,omitempty"`
+	LastError   string     `json:"last_error,omitempty"`
+
+	// Secreto anterior tras una rotación: se sigue firmando con él hasta que expire
+	PreviousSecret          string     `json:"-"`
+	PreviousSecretExpiresAt *time.Time `json:"previous_secret_expires_at,omitempty"`
+}
+
+// ActivePreviousSecret retorna el secreto anterior si la ventana de solapamiento sigue abierta
+func (w *WebhookConfig) ActivePreviousSecret(now time.Time) string {
+	if w.PreviousSecret == "" || w.PreviousSecretExpiresAt == nil || now.After(*w.PreviousSecretExpiresAt) {
+		return ""
+	}
+	return w.PreviousSecret
+}
+
+// MaskSecret retorna una pista del secreto sin revelarlo (solo los últimos 4 caracteres)
+func MaskSecret(secret string) string {
+	if secret == "" {
+		return ""
+	}
+	if len(secret) < 12 {
+		return "****"
+	}
+	return "****" + secret[len(secret)-4:]
 }
 
 // ==================== EVENT TYPES ====================
@@ -32,10 +56,12 @@ const (
 	EventNewMessage      EventType = "message.new"
 	EventEditMessage     EventType = "message.edit"
 	EventDeleteMessage   EventType = "message.delete"
+	EventMessageReaction EventType = "message.reaction"
 	EventUserOnline      EventType = "user.online"
 	EventUserOffline     EventType = "user.offline"
 	EventUserTyping      EventType = "user.typing"
 	EventChatAction      EventType = "chat.action"
+	EventCallbackQuery   EventType = "callback.query"
 	EventSessionStarted  EventType = "session.started"
 	EventSessionStopped  EventType = "session.stopped"
 	EventSessionError    EventType = "session.error"
@@ -46,10 +72,12 @@ var AllEvents = []EventType{
 	EventNewMessage,
 	EventEditMessage,
 	EventDeleteMessage,
+	EventMessageReaction,
 	EventUserOnline,
 	EventUserOffline,
 	EventUserTyping,
 	EventChatAction,
+	EventCallbackQuery,
 	EventSessionStarted,
 	EventSessionStopped,
 	EventSessionError,
@@ -79,6 +107,26 @@ type MessageEventData struct {
 	Date        time.Time `json:"date"`
 }
 
+type ReactionEventData struct {
+	MessageID int64             `json:"message_id"`
+	ChatID    int64             `json:"chat_id"`
+	ChatType  string            `json:"chat_type"` // private, group, channel
+	Reactions []MessageReaction `json:"reactions"`
+}
+
+// CallbackQueryEventData pulsación de un botón inline (solo sesiones de bot).
+// query_id se envía como string para no perder precisión en clientes JS.
+type CallbackQueryEventData struct {
+	QueryID   string `json:"query_id"`
+	MessageID int64  `json:"message_id"`
+	ChatID    int64  `json:"chat_id"`
+	ChatType  string `json:"chat_type"` // private, group, channel
+	FromID    int64  `json:"from_id"`
+	FromName  string `json:"from_name"`
+	Username  string `json:"username,omitempty"`
+	Data      string `json:"data"`
+}
+
 type UserStatusEventData struct {
 	UserID   int64     `json:"user_id"`
 	Username string    `json:"username,omitempty"`
@@ -112,13 +160,33 @@ type WebhookCreateRequest struct {
 	TimeoutMs  int      `json:"timeout_ms,omitempty" example:"5000"`
 }
 
-// WebhookResponse respuesta de webhook
+// WebhookResponse respuesta de webhook. Secret solo se incluye al crear o
+// rotar el secreto; después solo se expone SecretHint.
 type WebhookResponse struct {
-	ID        uuid.UUID `json:"id"`
-	SessionID uuid.UUID `json:"session_id"`
-	URL       string    `json:"url"`
-	Events    []string  `json:"events"`
-	IsActive  bool      `json:"is_active"`
+	ID         uuid.UUID `json:"id"`
+	SessionID  uuid.UUID `json:"session_id"`
+	URL        string    `json:"url"`
+	Secret     string    `json:"secret,omitempty"`
+	SecretHint string    `json:"secret_hint,omitempty"`
+	Events     []string  `json:"events"`
+	IsActive   bool      `json:"is_active"`
+}
+
+// DefaultSecretOverlap ventana por defecto en la que se firma también con el secreto anterior
+const DefaultSecretOverlap = 24 * time.Hour
+
+// RotateSecretRequest rota el secreto del webhook. Sin secret se genera uno aleatorio;
+// overlap_seconds=0 invalida el secreto anterior de inmediato.
+type RotateSecretRequest struct {
+	Secret         string `json:"secret,omitempty" validate:"omitempty,min=16,max=128"`
+	OverlapSeconds *int   `json:"overlap_seconds,omitempty" validate:"omitempty,min=0,max=604800" example:"86400"`
+}
+
+// RotateSecretResponse contiene el nuevo secreto (única vez que se muestra)
+type RotateSecretResponse struct {
+	Secret                  string     `json:"secret"`
+	SecretHint              string     `json:"secret_hint"`
+	PreviousSecretExpiresAt *time.Time `json:"previous_secret_expires_at,omitempty"`
 }
 
 // ==================== REPOSITORY INTERFACE ====================
@@ -129,4 +197,10 @@ type WebhookRepository interface {
 	GetBySessionID(ctx context.Context, sessionID uuid.UUID) (*WebhookConfig, error)
 	Delete(ctx context.Context, sessionID uuid.UUID) error
 	ListActive(ctx context.Context) ([]WebhookConfig, error)
+	// RotateSecret reemplaza el secreto; el actual queda como anterior hasta previousExpiresAt (nil = sin solapamiento)
+	RotateSecret(ctx context.Context, sessionID uuid.UUID, secret string, previousExpiresAt *time.Time) error
+	// EncryptLegacySecrets cifra los secretos guardados en texto plano (migración de datos)
+	EncryptLegacySecrets(ctx context.Context) (int, error)
+	// ReencryptSecrets re-cifra con la clave primaria los secretos cifrados con claves anteriores
+	ReencryptSecrets(ctx context.Context) (int, error)
 }
\ No newline at end of file
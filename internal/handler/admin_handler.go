@@ -0,0 +1,567 @@
+package handler
+
+import (
+	"time"
+
+	"telegram-api/internal/domain"
+	"telegram-api/internal/middleware"
+	"telegram-api/internal/service"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type AdminHandler struct {
+	auth   *service.AuthService
+	users  *service.UserAdminService
+	quotas *service.QuotaService
+	audit  *service.AuditService
+}
+
+func NewAdminHandler(auth *service.AuthService, users *service.UserAdminService, quotas *service.QuotaService, audit *service.AuditService) *AdminHandler {
+	return &AdminHandler{auth: auth, users: users, quotas: quotas, audit: audit}
+}
+
+// Límites de paginación de /admin/users y /admin/audit
+const (
+	defaultUsersPerPage = 20
+	maxUsersPerPage     = 100
+	defaultAuditPerPage = 50
+	maxAuditPerPage     = 200
+)
+
+func (h *AdminHandler) RegisterRoutes(r fiber.Router) {
+	admin := r.Group("/admin", requireJWT, middleware.RequireRole(domain.RoleAdmin))
+	admin.Get("/users", h.ListUsers)
+	admin.Post("/users", h.CreateUser)
+	admin.Get("/users/:userId", h.GetUser)
+	admin.Put("/users/:userId/status", h.SetUserStatus)
+	admin.Put("/users/:userId/role", h.SetUserRole)
+	admin.Delete("/users/:userId", h.DeleteUser)
+	admin.Post("/invitations", h.CreateInvitation)
+	admin.Get("/invitations", h.ListInvitations)
+	admin.Delete("/invitations/:invitationId", h.RevokeInvitation)
+	admin.Put("/users/:userId/scopes", h.SetUserScopes)
+	admin.Get("/users/:userId/usage", h.GetUserUsage)
+	admin.Put("/users/:userId/quotas", h.SetUserQuotas)
+	admin.Delete("/users/:userId/mfa", h.ResetUserMFA)
+	admin.Post("/users/:userId/unlock", h.UnlockUser)
+	admin.Post("/users/:userId/password-reset", h.CreatePasswordReset)
+	admin.Get("/policy", h.GetPolicy)
+	admin.Put("/policy", h.UpdatePolicy)
+	admin.Get("/audit", h.ListAudit)
+}
+
+// ListUsers godoc
+// @Summary Listar usuarios
+// @Description Lista paginada de usuarios, más recientes primero. Solo administradores.
+// @Tags Admin
+// @Produce json
+// @Security BearerAuth
+// @Param search query string false "Coincidencia parcial en username o email"
+// @Param role query string false "admin | user"
+// @Param active query bool false "Filtrar por cuentas activas o desactivadas"
+// @Param page query int false "Página (default 1)"
+// @Param per_page query int false "Resultados por página (default 20, max 100)"
+// @Success 200 {object} Response{data=[]domain.User}
+// @Router /admin/users [get]
+func (h *AdminHandler) ListUsers(c *fiber.Ctx) error {
+	page := c.QueryInt("page", 1)
+	if page < 1 {
+		page = 1
+	}
+	perPage := c.QueryInt("per_page", defaultUsersPerPage)
+	if perPage < 1 || perPage > maxUsersPerPage {
+		perPage = defaultUsersPerPage
+	}
+
+	filter := domain.UserFilter{
+		Search: c.Query("search"),
+		Limit:  perPage,
+		Offset: (page - 1) * perPage,
+	}
+	switch role := domain.Role(c.Query("role")); role {
+	case "", domain.RoleAdmin, domain.RoleUser:
+		filter.Role = role
+	default:
+		return c.Status(400).JSON(NewErrorResponse("INVALID_ROLE", "Rol inválido"))
+	}
+	if active := c.Query("active"); active != "" {
+		value := c.QueryBool("active")
+		filter.IsActive = &value
+	}
+
+	users, total, err := h.users.ListUsers(c.Context(), filter)
+	if err != nil {
+		return handleErr(c, err)
+	}
+	return c.JSON(NewPaginatedResponse(users, page, perPage, total))
+}
+
+// CreateUser godoc
+// @Summary Crear usuario
+// @Description Alta directa de una cuenta, con cualquier REGISTRATION_MODE. Solo administradores.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param body body domain.AdminCreateUserRequest true "Datos del usuario"
+// @Success 201 {object} Response{data=domain.User}
+// @Failure 409 {object} Response
+// @Router /admin/users [post]
+func (h *AdminHandler) CreateUser(c *fiber.Ctx) error {
+	var req domain.AdminCreateUserRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(NewErrorResponse("INVALID_BODY", "JSON inválido"))
+	}
+	if errs := ValidateStruct(&req); errs != nil {
+		return c.Status(400).JSON(Response{Success: false, Error: &ErrorResponse{Code: "VALIDATION", Details: errs}})
+	}
+
+	user, err := h.auth.CreateUser(c.Context(), &req)
+	if err != nil {
+		return handleErr(c, err)
+	}
+	return c.Status(201).JSON(NewSuccessResponse(user))
+}
+
+// GetUser godoc
+// @Summary Obtener usuario
+// @Description Solo administradores.
+// @Tags Admin
+// @Produce json
+// @Security BearerAuth
+// @Param userId path string true "User ID"
+// @Success 200 {object} Response{data=domain.User}
+// @Failure 404 {object} Response
+// @Router /admin/users/{userId} [get]
+func (h *AdminHandler) GetUser(c *fiber.Ctx) error {
+	userID, err := uuid.Parse(c.Params("userId"))
+	if err != nil {
+		return c.Status(400).JSON(NewErrorResponse("INVALID_ID", "ID inválido"))
+	}
+	user, err := h.users.GetUser(c.Context(), userID)
+	if err != nil {
+		return handleErr(c, err)
+	}
+	return c.JSON(NewSuccessResponse(user))
+}
+
+// SetUserStatus godoc
+// @Summary Activar o desactivar usuario
+// @Description Una cuenta desactivada no puede hacer login, sus logins se cierran y sus API keys dejan de funcionar. No se puede aplicar a la propia cuenta. Solo administradores.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param userId path string true "User ID"
+// @Param body body domain.UpdateUserStatusRequest true "Estado"
+// @Success 200 {object} Response{data=domain.User}
+// @Failure 403 {object} Response
+// @Failure 404 {object} Response
+// @Router /admin/users/{userId}/status [put]
+func (h *AdminHandler) SetUserStatus(c *fiber.Ctx) error {
+	userID, err := uuid.Parse(c.Params("userId"))
+	if err != nil {
+		return c.Status(400).JSON(NewErrorResponse("INVALID_ID", "ID inválido"))
+	}
+
+	var req domain.UpdateUserStatusRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(NewErrorResponse("INVALID_BODY", "JSON inválido"))
+	}
+	if errs := ValidateStruct(&req); errs != nil {
+		return c.Status(400).JSON(Response{Success: false, Error: &ErrorResponse{Code: "VALIDATION", Details: errs}})
+	}
+
+	adminID, _ := middleware.GetUserID(c)
+	user, err := h.users.SetActive(c.Context(), adminID, userID, *req.IsActive)
+	if err != nil {
+		return handleErr(c, err)
+	}
+	return c.JSON(NewSuccessResponse(user))
+}
+
+// SetUserRole godoc
+// @Summary Cambiar rol de usuario
+// @Description Cierra los logins del usuario para que el nuevo rol se aplique de inmediato. No se puede aplicar a la propia cuenta. Solo administradores.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param userId path string true "User ID"
+// @Param body body domain.UpdateUserRoleRequest true "Rol"
+// @Success 200 {object} Response{data=domain.User}
+// @Failure 403 {object} Response
+// @Failure 404 {object} Response
+// @Router /admin/users/{userId}/role [put]
+func (h *AdminHandler) SetUserRole(c *fiber.Ctx) error {
+	userID, err := uuid.Parse(c.Params("userId"))
+	if err != nil {
+		return c.Status(400).JSON(NewErrorResponse("INVALID_ID", "ID inválido"))
+	}
+
+	var req domain.UpdateUserRoleRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(NewErrorResponse("INVALID_BODY", "JSON inválido"))
+	}
+	if errs := ValidateStruct(&req); errs != nil {
+		return c.Status(400).JSON(Response{Success: false, Error: &ErrorResponse{Code: "VALIDATION", Details: errs}})
+	}
+
+	adminID, _ := middleware.GetUserID(c)
+	user, err := h.users.SetRole(c.Context(), adminID, userID, req.Role)
+	if err != nil {
+		return handleErr(c, err)
+	}
+	return c.JSON(NewSuccessResponse(user))
+}
+
+// DeleteUser godoc
+// @Summary Eliminar usuario
+// @Description Cierra en Telegram todas las sesiones del usuario, revoca sus logins y elimina la cuenta con sus API keys. No se puede aplicar a la propia cuenta. Solo administradores.
+// @Tags Admin
+// @Produce json
+// @Security BearerAuth
+// @Param userId path string true "User ID"
+// @Success 200 {object} Response{data=object{deleted=bool,sessions_closed=int}}
+// @Failure 403 {object} Response
+// @Failure 404 {object} Response
+// @Router /admin/users/{userId} [delete]
+func (h *AdminHandler) DeleteUser(c *fiber.Ctx) error {
+	userID, err := uuid.Parse(c.Params("userId"))
+	if err != nil {
+		return c.Status(400).JSON(NewErrorResponse("INVALID_ID", "ID inválido"))
+	}
+
+	adminID, _ := middleware.GetUserID(c)
+	closed, err := h.users.DeleteUser(c.Context(), adminID, userID)
+	if err != nil {
+		return handleErr(c, err)
+	}
+	return c.JSON(NewSuccessResponse(fiber.Map{"deleted": true, "sessions_closed": closed}))
+}
+
+// CreateInvitation godoc
+// @Summary Crear invitación
+// @Description Genera un token de registro de un solo uso (invite_token en /auth/register). Opcionalmente limitado a un email y con rol. El token solo se muestra en esta respuesta. Solo administradores.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param body body domain.CreateInvitationRequest true "Invitación"
+// @Success 201 {object} Response{data=domain.CreateInvitationResponse}
+// @Router /admin/invitations [post]
+func (h *AdminHandler) CreateInvitation(c *fiber.Ctx) error {
+	var req domain.CreateInvitationRequest
+	if err := c.BodyParser(&req); err != nil && len(c.Body()) > 0 {
+		return c.Status(400).JSON(NewErrorResponse("INVALID_BODY", "JSON inválido"))
+	}
+	if errs := ValidateStruct(&req); errs != nil {
+		return c.Status(400).JSON(Response{Success: false, Error: &ErrorResponse{Code: "VALIDATION", Details: errs}})
+	}
+
+	adminID, _ := middleware.GetUserID(c)
+	resp, err := h.auth.CreateInvitation(c.Context(), adminID, &req)
+	if err != nil {
+		return handleErr(c, err)
+	}
+	return c.Status(201).JSON(NewSuccessResponse(resp))
+}
+
+// ListInvitations godoc
+// @Summary Listar invitaciones pendientes
+// @Description Invitaciones sin usar y sin expirar. Solo administradores.
+// @Tags Admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} Response{data=[]domain.Invitation}
+// @Router /admin/invitations [get]
+func (h *AdminHandler) ListInvitations(c *fiber.Ctx) error {
+	invitations, err := h.auth.ListInvitations(c.Context())
+	if err != nil {
+		return handleErr(c, err)
+	}
+	return c.JSON(NewSuccessResponse(invitations))
+}
+
+// RevokeInvitation godoc
+// @Summary Revocar invitación
+// @Description Elimina una invitación pendiente. Solo administradores.
+// @Tags Admin
+// @Produce json
+// @Security BearerAuth
+// @Param invitationId path string true "Invitation ID"
+// @Success 200 {object} Response
+// @Failure 404 {object} Response
+// @Router /admin/invitations/{invitationId} [delete]
+func (h *AdminHandler) RevokeInvitation(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("invitationId"))
+	if err != nil {
+		return c.Status(400).JSON(NewErrorResponse("INVALID_ID", "ID inválido"))
+	}
+	if err := h.auth.RevokeInvitation(c.Context(), id); err != nil {
+		return handleErr(c, err)
+	}
+	return c.JSON(NewSuccessResponse(fiber.Map{"revoked": true}))
+}
+
+// SetUserScopes godoc
+// @Summary Fijar scopes de un usuario
+// @Description Limita los permisos de un usuario (y de sus API keys). scopes=null restablece todos; [] los quita todos. Revoca todos los logins del usuario para que sus JWT no conserven los scopes anteriores. Solo administradores.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param userId path string true "User ID"
+// @Param body body domain.UpdateScopesRequest true "Scopes"
+// @Success 200 {object} Response{data=domain.UserInfo}
+// @Failure 403 {object} Response
+// @Failure 404 {object} Response
+// @Router /admin/users/{userId}/scopes [put]
+func (h *AdminHandler) SetUserScopes(c *fiber.Ctx) error {
+	userID, err := uuid.Parse(c.Params("userId"))
+	if err != nil {
+		return c.Status(400).JSON(NewErrorResponse("INVALID_ID", "ID inválido"))
+	}
+
+	var req domain.UpdateScopesRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(NewErrorResponse("INVALID_BODY", "JSON inválido"))
+	}
+	if errs := ValidateStruct(&req); errs != nil {
+		return c.Status(400).JSON(Response{Success: false, Error: &ErrorResponse{Code: "VALIDATION", Details: errs}})
+	}
+
+	user, err := h.auth.SetUserScopes(c.Context(), userID, req.Scopes)
+	if err != nil {
+		return handleErr(c, err)
+	}
+	return c.JSON(NewSuccessResponse(user.ToUserInfo()))
+}
+
+// GetUserUsage godoc
+// @Summary Consumo de un usuario
+// @Description Consumo del usuario en el día y mes actuales (UTC) junto a sus cuotas efectivas. Solo administradores.
+// @Tags Admin
+// @Produce json
+// @Security BearerAuth
+// @Param userId path string true "User ID"
+// @Success 200 {object} Response{data=domain.QuotaUsage}
+// @Failure 404 {object} Response
+// @Router /admin/users/{userId}/usage [get]
+func (h *AdminHandler) GetUserUsage(c *fiber.Ctx) error {
+	userID, err := uuid.Parse(c.Params("userId"))
+	if err != nil {
+		return c.Status(400).JSON(NewErrorResponse("INVALID_ID", "ID inválido"))
+	}
+	if _, err := h.users.GetUser(c.Context(), userID); err != nil {
+		return handleErr(c, err)
+	}
+
+	usage, err := h.quotas.Usage(c.Context(), userID)
+	if err != nil {
+		return handleErr(c, err)
+	}
+	return c.JSON(NewSuccessResponse(usage))
+}
+
+// SetUserQuotas godoc
+// @Summary Fijar cuotas de un usuario
+// @Description Sobrescribe las cuotas globales (QUOTA_*) para un usuario. Un campo omitido hereda el valor global y 0 lo deja sin límite; quotas=null restablece todas las globales. Solo administradores.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param userId path string true "User ID"
+// @Param body body domain.UpdateQuotasRequest true "Cuotas"
+// @Success 200 {object} Response{data=domain.QuotaUsage}
+// @Failure 404 {object} Response
+// @Router /admin/users/{userId}/quotas [put]
+func (h *AdminHandler) SetUserQuotas(c *fiber.Ctx) error {
+	userID, err := uuid.Parse(c.Params("userId"))
+	if err != nil {
+		return c.Status(400).JSON(NewErrorResponse("INVALID_ID", "ID inválido"))
+	}
+
+	var req domain.UpdateQuotasRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(NewErrorResponse("INVALID_BODY", "JSON inválido"))
+	}
+	if errs := ValidateStruct(&req); errs != nil {
+		return c.Status(400).JSON(Response{Success: false, Error: &ErrorResponse{Code: "VALIDATION", Details: errs}})
+	}
+
+	usage, err := h.quotas.SetOverrides(c.Context(), userID, req.Quotas)
+	if err != nil {
+		return handleErr(c, err)
+	}
+	return c.JSON(NewSuccessResponse(usage))
+}
+
+// ResetUserMFA godoc
+// @Summary Resetear MFA de un usuario
+// @Description Desactiva el MFA y borra los códigos de recuperación de un usuario que perdió su dispositivo. Si la política exige MFA, deberá enrolarse en su próximo login. Solo administradores.
+// @Tags Admin
+// @Produce json
+// @Security BearerAuth
+// @Param userId path string true "User ID"
+// @Success 200 {object} Response
+// @Failure 404 {object} Response
+// @Router /admin/users/{userId}/mfa [delete]
+func (h *AdminHandler) ResetUserMFA(c *fiber.Ctx) error {
+	userID, err := uuid.Parse(c.Params("userId"))
+	if err != nil {
+		return c.Status(400).JSON(NewErrorResponse("INVALID_ID", "ID inválido"))
+	}
+	if err := h.auth.ResetUserMFA(c.Context(), userID); err != nil {
+		return handleErr(c, err)
+	}
+	return c.JSON(NewSuccessResponse(fiber.Map{"mfa_enabled": false}))
+}
+
+// GetPolicy godoc
+// @Summary Políticas de seguridad
+// @Description Retorna las políticas vigentes (p.ej. si MFA es obligatorio). Solo administradores.
+// @Tags Admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} Response{data=domain.SecurityPolicy}
+// @Router /admin/policy [get]
+func (h *AdminHandler) GetPolicy(c *fiber.Ctx) error {
+	policy, err := h.auth.GetSecurityPolicy(c.Context())
+	if err != nil {
+		return handleErr(c, err)
+	}
+	return c.JSON(NewSuccessResponse(policy))
+}
+
+// UpdatePolicy godoc
+// @Summary Actualizar políticas de seguridad
+// @Description Con mfa_required=true los usuarios sin MFA deben enrolarse en el login y no pueden renovar tokens hasta hacerlo. Solo administradores.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param body body domain.UpdateSecurityPolicyRequest true "Políticas"
+// @Success 200 {object} Response{data=domain.SecurityPolicy}
+// @Router /admin/policy [put]
+func (h *AdminHandler) UpdatePolicy(c *fiber.Ctx) error {
+	var req domain.UpdateSecurityPolicyRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(NewErrorResponse("INVALID_BODY", "JSON inválido"))
+	}
+	policy, err := h.auth.UpdateSecurityPolicy(c.Context(), &req)
+	if err != nil {
+		return handleErr(c, err)
+	}
+	return c.JSON(NewSuccessResponse(policy))
+}
+
+// UnlockUser godoc
+// @Summary Desbloquear usuario
+// @Description Levanta el bloqueo por intentos de login fallidos y pone el contador a cero. Solo administradores.
+// @Tags Admin
+// @Produce json
+// @Security BearerAuth
+// @Param userId path string true "User ID"
+// @Success 200 {object} Response
+// @Failure 404 {object} Response
+// @Router /admin/users/{userId}/unlock [post]
+func (h *AdminHandler) UnlockUser(c *fiber.Ctx) error {
+	userID, err := uuid.Parse(c.Params("userId"))
+	if err != nil {
+		return c.Status(400).JSON(NewErrorResponse("INVALID_ID", "ID inválido"))
+	}
+	if err := h.auth.UnlockUser(c.Context(), userID); err != nil {
+		return handleErr(c, err)
+	}
+	return c.JSON(NewSuccessResponse(fiber.Map{"unlocked": true}))
+}
+
+// CreatePasswordReset godoc
+// @Summary Emitir token de restablecimiento
+// @Description Genera un token de un solo uso (PASSWORD_RESET_TTL_HOURS) para que el usuario fije una contraseña nueva en /auth/password/reset. Invalida los anteriores. Solo se muestra en esta respuesta. Solo administradores.
+// @Tags Admin
+// @Produce json
+// @Security BearerAuth
+// @Param userId path string true "User ID"
+// @Success 201 {object} Response{data=domain.PasswordResetResponse}
+// @Failure 404 {object} Response
+// @Router /admin/users/{userId}/password-reset [post]
+func (h *AdminHandler) CreatePasswordReset(c *fiber.Ctx) error {
+	userID, err := uuid.Parse(c.Params("userId"))
+	if err != nil {
+		return c.Status(400).JSON(NewErrorResponse("INVALID_ID", "ID inválido"))
+	}
+	adminID, _ := middleware.GetUserID(c)
+
+	resp, err := h.auth.CreatePasswordReset(c.Context(), adminID, userID)
+	if err != nil {
+		return handleErr(c, err)
+	}
+	return c.Status(201).JSON(NewSuccessResponse(resp))
+}
+
+// ListAudit godoc
+// @Summary Consultar auditoría
+// @Description Eventos de auditoría (sesiones, mensajes, webhooks, autenticación y administración), más recientes primero. Solo administradores.
+// @Tags Admin
+// @Produce json
+// @Security BearerAuth
+// @Param actor_id query string false "Usuario que realizó la acción"
+// @Param session_id query string false "Sesión de Telegram afectada"
+// @Param action query string false "Acción exacta (session.export) o prefijo terminado en punto (session.)"
+// @Param outcome query string false "success | failure | denied"
+// @Param from query string false "Desde (RFC3339)"
+// @Param to query string false "Hasta, excluido (RFC3339)"
+// @Param page query int false "Página (default 1)"
+// @Param per_page query int false "Resultados por página (default 50, max 200)"
+// @Success 200 {object} Response{data=[]domain.AuditEvent}
+// @Router /admin/audit [get]
+func (h *AdminHandler) ListAudit(c *fiber.Ctx) error {
+	page := c.QueryInt("page", 1)
+	if page < 1 {
+		page = 1
+	}
+	perPage := c.QueryInt("per_page", defaultAuditPerPage)
+	if perPage < 1 || perPage > maxAuditPerPage {
+		perPage = defaultAuditPerPage
+	}
+
+	filter := domain.AuditFilter{
+		Action: c.Query("action"),
+		Limit:  perPage,
+		Offset: (page - 1) * perPage,
+	}
+	for param, dst := range map[string]**uuid.UUID{"actor_id": &filter.ActorID, "session_id": &filter.SessionID} {
+		if raw := c.Query(param); raw != "" {
+			id, err := uuid.Parse(raw)
+			if err != nil {
+				return c.Status(400).JSON(NewErrorResponse("INVALID_ID", param+" inválido"))
+			}
+			*dst = &id
+		}
+	}
+	for param, dst := range map[string]**time.Time{"from": &filter.From, "to": &filter.To} {
+		if raw := c.Query(param); raw != "" {
+			t, err := time.Parse(time.RFC3339, raw)
+			if err != nil {
+				return c.Status(400).JSON(NewErrorResponse("INVALID_DATE", param+" debe estar en formato RFC3339"))
+			}
+			*dst = &t
+		}
+	}
+	switch outcome := domain.AuditOutcome(c.Query("outcome")); outcome {
+	case "", domain.AuditSuccess, domain.AuditFailure, domain.AuditDenied:
+		filter.Outcome = outcome
+	default:
+		return c.Status(400).JSON(NewErrorResponse("INVALID_OUTCOME", "outcome debe ser success, failure o denied"))
+	}
+
+	events, total, err := h.audit.List(c.Context(), filter)
+	if err != nil {
+		return handleErr(c, err)
+	}
+	return c.JSON(NewPaginatedResponse(events, page, perPage, total))
+}
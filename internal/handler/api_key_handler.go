@@ -0,0 +1,113 @@
+package handler
+
+import (
+	"telegram-api/internal/domain"
+	"telegram-api/internal/middleware"
+	"telegram-api/internal/service"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type APIKeyHandler struct {
+	auth *service.AuthService
+}
+
+func NewAPIKeyHandler(auth *service.AuthService) *APIKeyHandler {
+	return &APIKeyHandler{auth: auth}
+}
+
+func (h *APIKeyHandler) RegisterRoutes(r fiber.Router) {
+	keys := r.Group("/api-keys", requireJWT)
+	keys.Post("/", h.Create)
+	keys.Get("/", h.List)
+	keys.Delete("/:keyId", h.Revoke)
+}
+
+// requireJWT impide gestionar API keys autenticándose con otra API key
+func requireJWT(c *fiber.Ctx) error {
+	if middleware.GetAPIKey(c) != nil {
+		return c.Status(403).JSON(NewErrorResponse("JWT_REQUIRED", "Las API keys solo se gestionan con un token de usuario"))
+	}
+	return c.Next()
+}
+
+// Create godoc
+// @Summary Crear API key
+// @Description Crea una API key de larga duración para integraciones (header X-API-Key). Puede limitarse a sesiones concretas, a una allowlist de IPs/CIDRs y expirar. La clave completa solo se muestra en esta respuesta.
+// @Tags API Keys
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param body body domain.CreateAPIKeyRequest true "Configuración de la key"
+// @Success 201 {object} Response{data=domain.CreateAPIKeyResponse}
+// @Failure 400 {object} Response
+// @Router /api-keys [post]
+func (h *APIKeyHandler) Create(c *fiber.Ctx) error {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		return c.Status(401).JSON(NewErrorResponse("UNAUTHORIZED", "No autenticado"))
+	}
+
+	var req domain.CreateAPIKeyRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(NewErrorResponse("INVALID_BODY", "JSON inválido"))
+	}
+	if errs := ValidateStruct(&req); errs != nil {
+		return c.Status(400).JSON(Response{Success: false, Error: &ErrorResponse{Code: "VALIDATION", Details: errs}})
+	}
+
+	resp, err := h.auth.CreateAPIKey(c.Context(), userID, &req)
+	if err != nil {
+		return handleErr(c, err)
+	}
+	return c.Status(201).JSON(NewSuccessResponse(resp))
+}
+
+// List godoc
+// @Summary Listar API keys
+// @Description Lista las API keys del usuario (sin la clave), con último uso y estado
+// @Tags API Keys
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} Response{data=[]domain.APIKey}
+// @Router /api-keys [get]
+func (h *APIKeyHandler) List(c *fiber.Ctx) error {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		return c.Status(401).JSON(NewErrorResponse("UNAUTHORIZED", "No autenticado"))
+	}
+
+	keys, err := h.auth.ListAPIKeys(c.Context(), userID)
+	if err != nil {
+		return handleErr(c, err)
+	}
+	return c.JSON(NewSuccessResponse(keys))
+}
+
+// Revoke godoc
+// @Summary Revocar API key
+// @Description Revoca una API key; deja de aceptarse de inmediato
+// @Tags API Keys
+// @Produce json
+// @Security BearerAuth
+// @Param keyId path string true "API key ID"
+// @Success 200 {object} Response
+// @Failure 404 {object} Response
+// @Router /api-keys/{keyId} [delete]
+func (h *APIKeyHandler) Revoke(c *fiber.Ctx) error {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		return c.Status(401).JSON(NewErrorResponse("UNAUTHORIZED", "No autenticado"))
+	}
+
+	keyID, err := uuid.Parse(c.Params("keyId"))
+	if err != nil {
+		return c.Status(400).JSON(NewErrorResponse("INVALID_ID", "ID inválido"))
+	}
+
+	if err := h.auth.RevokeAPIKey(c.Context(), userID, keyID); err != nil {
+		return handleErr(c, err)
+	}
+	return c.JSON(NewSuccessResponse(fiber.Map{"revoked": true}))
+}
\ No newline at end of file
@@ -1,11 +1,14 @@
 package handler
 
 import (
+	"errors"
+
 	"telegram-api/internal/domain"
 	"telegram-api/internal/middleware"
 	"telegram-api/internal/service"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
 )
 
 type AuthHandler struct {
@@ -23,17 +26,30 @@ func (h *AuthHandler) RegisterRoutes(r fiber.Router) {
 	auth.Post("/refresh", h.Refresh)
 	auth.Post("/logout", middleware.JWTMiddleware(h.auth), h.Logout)
 	auth.Get("/me", middleware.JWTMiddleware(h.auth), h.Me)
+	auth.Post("/logout-all", middleware.JWTMiddleware(h.auth), requireJWT, h.LogoutAll)
+	auth.Get("/devices", middleware.JWTMiddleware(h.auth), requireJWT, h.ListDevices)
+	auth.Delete("/devices/:deviceId", middleware.JWTMiddleware(h.auth), requireJWT, h.RevokeDevice)
+
+	auth.Post("/password", middleware.JWTMiddleware(h.auth), requireJWT, h.ChangePassword)
+	auth.Post("/password/reset", h.ResetPassword)
+
+	auth.Post("/mfa/verify", h.VerifyMFA)
+	auth.Post("/mfa/enroll", middleware.MFAEnrollmentMiddleware(h.auth), requireJWT, h.EnrollMFA)
+	auth.Post("/mfa/confirm", middleware.MFAEnrollmentMiddleware(h.auth), requireJWT, h.ConfirmMFA)
+	auth.Post("/mfa/disable", middleware.JWTMiddleware(h.auth), requireJWT, h.DisableMFA)
+	auth.Post("/mfa/recovery-codes", middleware.JWTMiddleware(h.auth), requireJWT, h.RegenerateRecoveryCodes)
 }
 
 // Register godoc
 // @Summary Registrar usuario
-// @Description Crea una nueva cuenta de usuario
+// @Description Crea una nueva cuenta de usuario. Según REGISTRATION_MODE: open (libre), invite (requiere invite_token) o disabled (403 REGISTRATION_DISABLED). Con invite_token la cuenta recibe el rol de la invitación.
 // @Tags Auth
 // @Accept json
 // @Produce json
 // @Param body body domain.CreateUserRequest true "Datos del usuario"
 // @Success 201 {object} handler.Response{data=domain.UserInfo}
 // @Failure 400 {object} handler.Response
+// @Failure 403 {object} handler.Response
 // @Failure 409 {object} handler.Response
 // @Router /auth/register [post]
 func (h *AuthHandler) Register(c *fiber.Ctx) error {
@@ -53,13 +69,14 @@ func (h *AuthHandler) Register(c *fiber.Ctx) error {
 
 // Login godoc
 // @Summary Login
-// @Description Autentica usuario y retorna tokens
+// @Description Autentica usuario y retorna tokens. Tras varios fallos seguidos la cuenta se bloquea temporalmente (423 ACCOUNT_LOCKED), con bloqueos cada vez más largos. Con MFA activado retorna mfa_required=true y un mfa_token para /auth/mfa/verify; si la política exige MFA y el usuario no lo tiene, mfa_enrollment_required=true y un mfa_token para /auth/mfa/enroll y /auth/mfa/confirm.
 // @Tags Auth
 // @Accept json
 // @Produce json
 // @Param body body domain.LoginRequest true "Credenciales"
 // @Success 200 {object} handler.Response{data=domain.LoginResponse}
 // @Failure 401 {object} handler.Response
+// @Failure 423 {object} handler.Response
 // @Router /auth/login [post]
 func (h *AuthHandler) Login(c *fiber.Ctx) error {
 	var req domain.LoginRequest
@@ -99,7 +116,7 @@ func (h *AuthHandler) Refresh(c *fiber.Ctx) error {
 
 // Logout godoc
 // @Summary Cerrar sesión
-// @Description Revoca el refresh token
+// @Description Revoca el refresh token y el access token usado en la petición
 // @Tags Auth
 // @Accept json
 // @Produce json
@@ -112,10 +129,268 @@ func (h *AuthHandler) Logout(c *fiber.Ctx) error {
 		RefreshToken string `json:"refresh_token"`
 	}
 	c.BodyParser(&req)
-	h.auth.Logout(c.Context(), req.RefreshToken)
+	h.auth.Logout(c.Context(), req.RefreshToken, middleware.GetClaims(c))
+	return c.JSON(NewSuccessResponse(fiber.Map{"message": "ok"}))
+}
+
+// LogoutAll godoc
+// @Summary Cerrar sesión en todos los dispositivos
+// @Description Revoca todos los refresh tokens del usuario y los access tokens de cada login, incluido el actual
+// @Tags Auth
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} handler.Response
+// @Router /auth/logout-all [post]
+func (h *AuthHandler) LogoutAll(c *fiber.Ctx) error {
+	userID, _ := middleware.GetUserID(c)
+	if err := h.auth.LogoutAll(c.Context(), userID); err != nil {
+		return handleErr(c, err)
+	}
+	return c.JSON(NewSuccessResponse(fiber.Map{"message": "ok"}))
+}
+
+// ListDevices godoc
+// @Summary Listar dispositivos
+// @Description Lista los logins activos (user agent, IP, fecha de login y último uso). current=true marca el de esta petición.
+// @Tags Auth
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} handler.Response{data=[]domain.Device}
+// @Router /auth/devices [get]
+func (h *AuthHandler) ListDevices(c *fiber.Ctx) error {
+	userID, _ := middleware.GetUserID(c)
+
+	var current uuid.UUID
+	if claims := middleware.GetClaims(c); claims != nil {
+		current, _ = uuid.Parse(claims.FamilyID)
+	}
+
+	devices, err := h.auth.ListDevices(c.Context(), userID, current)
+	if err != nil {
+		return handleErr(c, err)
+	}
+	return c.JSON(NewSuccessResponse(devices))
+}
+
+// RevokeDevice godoc
+// @Summary Revocar dispositivo
+// @Description Cierra un login: revoca su refresh token y sus access tokens
+// @Tags Auth
+// @Produce json
+// @Security BearerAuth
+// @Param deviceId path string true "Device ID"
+// @Success 200 {object} handler.Response
+// @Failure 404 {object} handler.Response
+// @Router /auth/devices/{deviceId} [delete]
+func (h *AuthHandler) RevokeDevice(c *fiber.Ctx) error {
+	userID, _ := middleware.GetUserID(c)
+
+	deviceID, err := uuid.Parse(c.Params("deviceId"))
+	if err != nil {
+		return c.Status(400).JSON(NewErrorResponse("INVALID_ID", "ID inválido"))
+	}
+
+	if err := h.auth.RevokeDevice(c.Context(), userID, deviceID); err != nil {
+		return handleErr(c, err)
+	}
+	return c.JSON(NewSuccessResponse(fiber.Map{"revoked": true}))
+}
+
+// ChangePassword godoc
+// @Summary Cambiar contraseña
+// @Description Cambia la contraseña con la actual y cierra los demás logins del usuario (el de esta petición se conserva)
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param body body domain.ChangePasswordRequest true "Contraseñas"
+// @Success 200 {object} handler.Response{data=object{revoked_devices=int}}
+// @Failure 400 {object} handler.Response
+// @Failure 401 {object} handler.Response
+// @Router /auth/password [post]
+func (h *AuthHandler) ChangePassword(c *fiber.Ctx) error {
+	userID, _ := middleware.GetUserID(c)
+
+	var req domain.ChangePasswordRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(NewErrorResponse("INVALID_BODY", "Cuerpo inválido"))
+	}
+	if errs := ValidateStruct(&req); errs != nil {
+		return c.Status(400).JSON(Response{Success: false, Error: &ErrorResponse{Code: "VALIDATION", Details: errs}})
+	}
+
+	var current uuid.UUID
+	if claims := middleware.GetClaims(c); claims != nil {
+		current, _ = uuid.Parse(claims.FamilyID)
+	}
+
+	revoked, err := h.auth.ChangePassword(c.Context(), userID, current, &req)
+	if err != nil {
+		return handleErr(c, err)
+	}
+	return c.JSON(NewSuccessResponse(fiber.Map{"revoked_devices": revoked}))
+}
+
+// ResetPassword godoc
+// @Summary Restablecer contraseña
+// @Description Fija una contraseña nueva con el token de un solo uso emitido por un administrador. Desbloquea la cuenta y cierra todos sus logins.
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param body body domain.ResetPasswordRequest true "Token y contraseña nueva"
+// @Success 200 {object} handler.Response
+// @Failure 401 {object} handler.Response
+// @Router /auth/password/reset [post]
+func (h *AuthHandler) ResetPassword(c *fiber.Ctx) error {
+	var req domain.ResetPasswordRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(NewErrorResponse("INVALID_BODY", "Cuerpo inválido"))
+	}
+	if errs := ValidateStruct(&req); errs != nil {
+		return c.Status(400).JSON(Response{Success: false, Error: &ErrorResponse{Code: "VALIDATION", Details: errs}})
+	}
+	if err := h.auth.ResetPassword(c.Context(), &req); err != nil {
+		return handleErr(c, err)
+	}
 	return c.JSON(NewSuccessResponse(fiber.Map{"message": "ok"}))
 }
 
+// VerifyMFA godoc
+// @Summary Verificar segundo factor
+// @Description Completa el login con el mfa_token y un código TOTP o un código de recuperación (cada uno sirve una sola vez). Tras 5 códigos erróneos hay que repetir el login.
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param body body domain.MFAVerifyRequest true "Desafío y código"
+// @Success 200 {object} handler.Response{data=domain.LoginResponse}
+// @Failure 401 {object} handler.Response
+// @Router /auth/mfa/verify [post]
+func (h *AuthHandler) VerifyMFA(c *fiber.Ctx) error {
+	var req domain.MFAVerifyRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(NewErrorResponse("INVALID_BODY", "Cuerpo inválido"))
+	}
+	if errs := ValidateStruct(&req); errs != nil {
+		return c.Status(400).JSON(Response{Success: false, Error: &ErrorResponse{Code: "VALIDATION", Details: errs}})
+	}
+	resp, err := h.auth.VerifyMFA(c.Context(), &req, c.IP(), c.Get("User-Agent"))
+	if err != nil {
+		return handleErr(c, err)
+	}
+	return c.JSON(NewSuccessResponse(resp))
+}
+
+// EnrollMFA godoc
+// @Summary Iniciar enrolamiento MFA
+// @Description Genera un secreto TOTP y su QR para la app autenticadora. Se activa con /auth/mfa/confirm. Acepta un JWT o el mfa_token de enrolamiento del login.
+// @Tags Auth
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} handler.Response{data=domain.MFAEnrollResponse}
+// @Failure 409 {object} handler.Response
+// @Router /auth/mfa/enroll [post]
+func (h *AuthHandler) EnrollMFA(c *fiber.Ctx) error {
+	userID, _ := middleware.GetUserID(c)
+	resp, err := h.auth.EnrollMFA(c.Context(), userID)
+	if err != nil {
+		return handleErr(c, err)
+	}
+	return c.JSON(NewSuccessResponse(resp))
+}
+
+// ConfirmMFA godoc
+// @Summary Confirmar enrolamiento MFA
+// @Description Activa MFA con el primer código de la app y retorna los códigos de recuperación (solo se muestran aquí). Con el mfa_token de enrolamiento también retorna los tokens del login.
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param body body domain.MFACodeRequest true "Código TOTP"
+// @Success 200 {object} handler.Response{data=domain.MFAConfirmResponse}
+// @Failure 401 {object} handler.Response
+// @Failure 409 {object} handler.Response
+// @Router /auth/mfa/confirm [post]
+func (h *AuthHandler) ConfirmMFA(c *fiber.Ctx) error {
+	userID, _ := middleware.GetUserID(c)
+
+	var req domain.MFACodeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(NewErrorResponse("INVALID_BODY", "Cuerpo inválido"))
+	}
+	if errs := ValidateStruct(&req); errs != nil {
+		return c.Status(400).JSON(Response{Success: false, Error: &ErrorResponse{Code: "VALIDATION", Details: errs}})
+	}
+
+	var enrollClaims *service.JWTClaims
+	if claims := middleware.GetClaims(c); claims != nil && claims.Purpose != "" {
+		enrollClaims = claims
+	}
+
+	resp, err := h.auth.ConfirmMFA(c.Context(), userID, req.Code, enrollClaims, c.IP(), c.Get("User-Agent"))
+	if err != nil {
+		return handleErr(c, err)
+	}
+	return c.JSON(NewSuccessResponse(resp))
+}
+
+// DisableMFA godoc
+// @Summary Desactivar MFA
+// @Description Requiere la contraseña y un código TOTP o de recuperación. No se permite si la política exige MFA.
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param body body domain.MFADisableRequest true "Contraseña y código"
+// @Success 200 {object} handler.Response
+// @Failure 401 {object} handler.Response
+// @Failure 403 {object} handler.Response
+// @Router /auth/mfa/disable [post]
+func (h *AuthHandler) DisableMFA(c *fiber.Ctx) error {
+	userID, _ := middleware.GetUserID(c)
+
+	var req domain.MFADisableRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(NewErrorResponse("INVALID_BODY", "Cuerpo inválido"))
+	}
+	if errs := ValidateStruct(&req); errs != nil {
+		return c.Status(400).JSON(Response{Success: false, Error: &ErrorResponse{Code: "VALIDATION", Details: errs}})
+	}
+
+	if err := h.auth.DisableMFA(c.Context(), userID, &req); err != nil {
+		return handleErr(c, err)
+	}
+	return c.JSON(NewSuccessResponse(fiber.Map{"mfa_enabled": false}))
+}
+
+// RegenerateRecoveryCodes godoc
+// @Summary Regenerar códigos de recuperación
+// @Description Invalida los códigos de recuperación anteriores y genera otros. Requiere un código TOTP o de recuperación.
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param body body domain.MFACodeRequest true "Código"
+// @Success 200 {object} handler.Response{data=object{recovery_codes=[]string}}
+// @Failure 401 {object} handler.Response
+// @Router /auth/mfa/recovery-codes [post]
+func (h *AuthHandler) RegenerateRecoveryCodes(c *fiber.Ctx) error {
+	userID, _ := middleware.GetUserID(c)
+
+	var req domain.MFACodeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(NewErrorResponse("INVALID_BODY", "Cuerpo inválido"))
+	}
+	if errs := ValidateStruct(&req); errs != nil {
+		return c.Status(400).JSON(Response{Success: false, Error: &ErrorResponse{Code: "VALIDATION", Details: errs}})
+	}
+
+	codes, err := h.auth.RegenerateRecoveryCodes(c.Context(), userID, req.Code)
+	if err != nil {
+		return handleErr(c, err)
+	}
+	return c.JSON(NewSuccessResponse(fiber.Map{"recovery_codes": codes}))
+}
+
 // Me godoc
 // @Summary Info usuario actual
 // @Description Retorna información del usuario autenticado
@@ -135,13 +410,36 @@ func (h *AuthHandler) Me(c *fiber.Ctx) error {
 }
 
 func handleErr(c *fiber.Ctx, err error) error {
+	if errors.Is(err, domain.ErrQuotaExceeded) {
+		return c.Status(429).JSON(NewErrorResponse("QUOTA_EXCEEDED", err.Error()))
+	}
+
 	switch err {
-	case domain.ErrUserAlreadyExists, domain.ErrEmailAlreadyExists:
+	case domain.ErrUserAlreadyExists, domain.ErrEmailAlreadyExists,
+		domain.ErrMFAAlreadyEnabled, domain.ErrMFANotEnabled, domain.ErrMFANotEnrolled:
 		return c.Status(409).JSON(NewErrorResponse("CONFLICT", err.Error()))
-	case domain.ErrInvalidCredentials, domain.ErrInvalidToken, domain.ErrTokenExpired:
+	case domain.ErrInvalidCredentials, domain.ErrInvalidToken, domain.ErrTokenExpired,
+		domain.ErrTokenRevoked, domain.ErrTokenReused:
 		return c.Status(401).JSON(NewErrorResponse("UNAUTHORIZED", err.Error()))
-	case domain.ErrUserInactive:
+	case domain.ErrInvalidMFACode:
+		return c.Status(401).JSON(NewErrorResponse("INVALID_MFA_CODE", err.Error()))
+	case domain.ErrMFAEnrollmentRequired:
+		return c.Status(403).JSON(NewErrorResponse("MFA_ENROLLMENT_REQUIRED", err.Error()))
+	case domain.ErrUserInactive, domain.ErrForbidden:
 		return c.Status(403).JSON(NewErrorResponse("FORBIDDEN", err.Error()))
+	case domain.ErrRegistrationDisabled:
+		return c.Status(403).JSON(NewErrorResponse("REGISTRATION_DISABLED", err.Error()))
+	case domain.ErrInvitationRequired, domain.ErrInvalidInvitation:
+		return c.Status(403).JSON(NewErrorResponse("INVITATION_REQUIRED", err.Error()))
+	case domain.ErrRevocationUnavailable:
+		return c.Status(503).JSON(NewErrorResponse("REVOCATION_UNAVAILABLE", err.Error()))
+	case domain.ErrAccountLocked:
+		return c.Status(423).JSON(NewErrorResponse("ACCOUNT_LOCKED", err.Error()))
+	case domain.ErrPasswordUnchanged:
+		return c.Status(400).JSON(NewErrorResponse("INVALID_PASSWORD", err.Error()))
+	case domain.ErrAPIKeyNotFound, domain.ErrSessionNotFound, domain.ErrUserNotFound, domain.ErrDeviceNotFound,
+		domain.ErrInvitationNotFound:
+		return c.Status(404).JSON(NewErrorResponse("NOT_FOUND", err.Error()))
 	default:
 		return c.Status(500).JSON(NewErrorResponse("INTERNAL", "Error interno"))
 	}
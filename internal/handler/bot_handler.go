@@ -0,0 +1,66 @@
+package handler
+
+import (
+	"strconv"
+
+	"telegram-api/internal/domain"
+	"telegram-api/internal/middleware"
+	"telegram-api/internal/service"
+	"telegram-api/pkg/logger"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type BotHandler struct {
+	botService *service.BotService
+}
+
+func NewBotHandler(botService *service.BotService) *BotHandler {
+	return &BotHandler{botService: botService}
+}
+
+func (h *BotHandler) RegisterRoutes(r fiber.Router) {
+	r.Post("/sessions/:id/callbacks/:queryId/answer", middleware.RequireScope(domain.ScopeMessagesSend), h.AnswerCallback)
+}
+
+// AnswerCallback godoc
+// @Summary Responder callback query
+// @Description Responde a la pulsación de un botón inline (evento callback.query). Solo sesiones de bot; Telegram espera la respuesta en pocos segundos.
+// @Tags Bots
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Session ID"
+// @Param queryId path string true "Query ID recibido en el webhook"
+// @Param body body domain.AnswerCallbackRequest false "Respuesta"
+// @Success 200 {object} Response
+// @Failure 400 {object} Response
+// @Router /sessions/{id}/callbacks/{queryId}/answer [post]
+func (h *BotHandler) AnswerCallback(c *fiber.Ctx) error {
+	sessionID, userID, ok := parseSessionRoute(c)
+	if !ok {
+		return nil
+	}
+
+	queryID, err := strconv.ParseInt(c.Params("queryId"), 10, 64)
+	if err != nil {
+		return c.Status(400).JSON(NewErrorResponse("INVALID_QUERY_ID", "ID de callback query inválido"))
+	}
+
+	var req domain.AnswerCallbackRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(400).JSON(NewErrorResponse("INVALID_BODY", "JSON inválido"))
+		}
+	}
+	if errs := ValidateStruct(&req); errs != nil {
+		return c.Status(400).JSON(Response{Success: false, Error: &ErrorResponse{Code: "VALIDATION", Details: errs}})
+	}
+
+	if err := h.botService.AnswerCallbackQuery(c.Context(), userID, sessionID, queryID, req); err != nil {
+		logger.Error().Err(err).Int64("query_id", queryID).Msg("error respondiendo callback query")
+		return handleChatAdminError(c, err)
+	}
+
+	return c.JSON(NewSuccessResponse(fiber.Map{"answered": true}))
+}
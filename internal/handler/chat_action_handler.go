@@ -0,0 +1,177 @@
+package handler
+
+import (
+	"telegram-api/internal/domain"
+	"telegram-api/internal/middleware"
+	"telegram-api/internal/service"
+	"telegram-api/pkg/logger"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type ChatActionHandler struct {
+	actionService *service.ChatActionService
+}
+
+func NewChatActionHandler(actionService *service.ChatActionService) *ChatActionHandler {
+	return &ChatActionHandler{actionService: actionService}
+}
+
+func (h *ChatActionHandler) RegisterRoutes(r fiber.Router) {
+	write := middleware.RequireScope(domain.ScopeChatsWrite)
+
+	r.Post("/sessions/:id/chats/:chatId/read", write, h.MarkAsRead)
+	r.Post("/sessions/:id/chats/:chatId/action", write, h.SendChatAction)
+	r.Post("/sessions/:id/chats/:chatId/messages/:msgId/reactions", write, h.SendReaction)
+	r.Put("/sessions/:id/presence", write, h.SetPresence)
+}
+
+// MarkAsRead godoc
+// @Summary Marcar como leído
+// @Description Marca los mensajes del chat como leídos hasta max_id (0 = todos), incluyendo menciones y reacciones
+// @Tags Chats
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Session ID"
+// @Param chatId path int true "Chat ID"
+// @Param body body domain.MarkReadRequest false "Mensaje máximo a marcar"
+// @Success 200 {object} Response
+// @Router /sessions/{id}/chats/{chatId}/read [post]
+func (h *ChatActionHandler) MarkAsRead(c *fiber.Ctx) error {
+	route, ok := parseChatRoute(c)
+	if !ok {
+		return nil
+	}
+
+	var req domain.MarkReadRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(400).JSON(NewErrorResponse("INVALID_BODY", "JSON inválido"))
+		}
+	}
+	if errs := ValidateStruct(&req); errs != nil {
+		return c.Status(400).JSON(Response{Success: false, Error: &ErrorResponse{Code: "VALIDATION", Details: errs}})
+	}
+
+	if err := h.actionService.MarkAsRead(c.Context(), route.userID, route.sessionID, route.chatID, req); err != nil {
+		logger.Error().Err(err).Int64("chat_id", route.chatID).Msg("error marcando como leído")
+		return handleChatAdminError(c, err)
+	}
+
+	return c.JSON(NewSuccessResponse(fiber.Map{"read": true, "max_id": req.MaxID}))
+}
+
+// SendReaction godoc
+// @Summary Reaccionar a un mensaje
+// @Description Añade o quita una reacción (emoji o custom emoji) conservando las demás reacciones de la cuenta
+// @Tags Chats
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Session ID"
+// @Param chatId path int true "Chat ID"
+// @Param msgId path int true "Message ID"
+// @Param body body domain.SendReactionRequest true "Reacción"
+// @Success 200 {object} Response{data=[]domain.MessageReaction}
+// @Router /sessions/{id}/chats/{chatId}/messages/{msgId}/reactions [post]
+func (h *ChatActionHandler) SendReaction(c *fiber.Ctx) error {
+	route, ok := parseChatRoute(c)
+	if !ok {
+		return nil
+	}
+	msgID, ok := parseMessageID(c)
+	if !ok {
+		return nil
+	}
+
+	var req domain.SendReactionRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(NewErrorResponse("INVALID_BODY", "JSON inválido"))
+	}
+	if req.Emoji == "" && req.CustomEmojiID == 0 && !req.Remove {
+		return c.Status(400).JSON(NewErrorResponse("VALIDATION", "Se requiere emoji o custom_emoji_id"))
+	}
+
+	result, err := h.actionService.SendReaction(c.Context(), route.userID, route.sessionID, route.chatID, msgID, req)
+	if err != nil {
+		logger.Error().Err(err).Int64("chat_id", route.chatID).Int("msg_id", msgID).Msg("error enviando reacción")
+		return handleChatAdminError(c, err)
+	}
+
+	return c.JSON(NewSuccessResponse(result))
+}
+
+// SendChatAction godoc
+// @Summary Enviar acción de chat
+// @Description Muestra "escribiendo...", "subiendo foto", "grabando audio", etc. durante duration segundos (máx 30).
+// @Description Responde 202 tras el primer envío; la acción se renueva en segundo plano. Otra acción en el mismo chat
+// @Description (por ejemplo "cancel") detiene la que esté en curso.
+// @Tags Chats
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Session ID"
+// @Param chatId path int true "Chat ID"
+// @Param body body domain.ChatActionRequest true "Acción y duración"
+// @Success 202 {object} Response
+// @Router /sessions/{id}/chats/{chatId}/action [post]
+func (h *ChatActionHandler) SendChatAction(c *fiber.Ctx) error {
+	route, ok := parseChatRoute(c)
+	if !ok {
+		return nil
+	}
+
+	var req domain.ChatActionRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(NewErrorResponse("INVALID_BODY", "JSON inválido"))
+	}
+	if errs := ValidateStruct(&req); errs != nil {
+		return c.Status(400).JSON(Response{Success: false, Error: &ErrorResponse{Code: "VALIDATION", Details: errs}})
+	}
+
+	if err := h.actionService.SendChatAction(c.Context(), route.userID, route.sessionID, route.chatID, req); err != nil {
+		logger.Error().Err(err).Int64("chat_id", route.chatID).Str("action", string(req.Action)).Msg("error enviando acción de chat")
+		return handleChatAdminError(c, err)
+	}
+
+	return c.Status(202).JSON(NewSuccessResponse(fiber.Map{"action": req.Action, "duration": req.Duration}))
+}
+
+// SetPresence godoc
+// @Summary Presencia en línea
+// @Description Hace que la cuenta aparezca en línea o desconectada (account.updateStatus).
+// @Description Si la sesión está escuchando eventos, el estado en línea se renueva automáticamente.
+// @Tags Sessions
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Session ID"
+// @Param body body domain.PresenceRequest true "Estado"
+// @Success 200 {object} Response{data=domain.PresenceStatus}
+// @Router /sessions/{id}/presence [put]
+func (h *ChatActionHandler) SetPresence(c *fiber.Ctx) error {
+	sessionID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(NewErrorResponse("INVALID_ID", "ID de sesión inválido"))
+	}
+
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		return c.Status(401).JSON(NewErrorResponse("UNAUTHORIZED", "No autorizado"))
+	}
+
+	var req domain.PresenceRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(NewErrorResponse("INVALID_BODY", "JSON inválido"))
+	}
+
+	result, err := h.actionService.SetPresence(c.Context(), userID, sessionID, req)
+	if err != nil {
+		logger.Error().Err(err).Str("session_id", sessionID.String()).Msg("error actualizando presencia")
+		return handleChatAdminError(c, err)
+	}
+
+	return c.JSON(NewSuccessResponse(result))
+}
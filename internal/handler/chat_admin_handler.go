@@ -0,0 +1,649 @@
+package handler
+
+import (
+	"errors"
+	"strconv"
+
+	"telegram-api/internal/domain"
+	"telegram-api/internal/middleware"
+	"telegram-api/internal/service"
+	"telegram-api/pkg/logger"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type ChatAdminHandler struct {
+	adminService *service.ChatAdminService
+}
+
+func NewChatAdminHandler(adminService *service.ChatAdminService) *ChatAdminHandler {
+	return &ChatAdminHandler{adminService: adminService}
+}
+
+func (h *ChatAdminHandler) RegisterRoutes(r fiber.Router) {
+	// Scope por ruta: un middleware en el grupo afectaría también a las rutas
+	// de ChatHandler que comparten el prefijo /sessions/:id/chats
+	admin := middleware.RequireScope(domain.ScopeChatsAdmin)
+
+	chats := r.Group("/sessions/:id/chats")
+	chats.Post("/", admin, h.CreateChat)
+	chats.Post("/join", admin, h.JoinChat)
+	chats.Put("/:chatId", admin, h.EditChat)
+	chats.Put("/:chatId/photo", admin, h.EditChatPhoto)
+	chats.Put("/:chatId/slow-mode", admin, h.SetSlowMode)
+	chats.Put("/:chatId/permissions", admin, h.SetDefaultPermissions)
+
+	chats.Post("/:chatId/members", admin, h.InviteMembers)
+	chats.Delete("/:chatId/members/:userId", admin, h.KickMember)
+	chats.Post("/:chatId/bans", admin, h.BanMember)
+	chats.Delete("/:chatId/bans/:userId", admin, h.UnbanMember)
+	chats.Post("/:chatId/admins", admin, h.PromoteAdmin)
+	chats.Delete("/:chatId/admins/:userId", admin, h.DemoteAdmin)
+
+	chats.Post("/:chatId/leave", admin, h.LeaveChat)
+	chats.Get("/:chatId/invite-links", admin, h.ListInviteLinks)
+	chats.Post("/:chatId/invite-links", admin, h.CreateInviteLink)
+	chats.Post("/:chatId/invite-links/revoke", admin, h.RevokeInviteLink)
+}
+
+// CreateChat godoc
+// @Summary Crear grupo o canal
+// @Description Crea un grupo básico, supergrupo o canal e invita a los miembros indicados
+// @Tags Chat Admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Session ID"
+// @Param body body domain.CreateChatRequest true "Datos del chat"
+// @Success 201 {object} Response{data=domain.CreatedChat}
+// @Failure 400 {object} Response
+// @Router /sessions/{id}/chats [post]
+func (h *ChatAdminHandler) CreateChat(c *fiber.Ctx) error {
+	sessionID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(NewErrorResponse("INVALID_ID", "ID de sesión inválido"))
+	}
+
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		return c.Status(401).JSON(NewErrorResponse("UNAUTHORIZED", "No autorizado"))
+	}
+
+	var req domain.CreateChatRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(NewErrorResponse("INVALID_BODY", "JSON inválido"))
+	}
+	if errs := ValidateStruct(&req); errs != nil {
+		return c.Status(400).JSON(Response{Success: false, Error: &ErrorResponse{Code: "VALIDATION", Details: errs}})
+	}
+
+	logger.Info().
+		Str("session_id", sessionID.String()).
+		Str("type", string(req.Type)).
+		Str("title", req.Title).
+		Int("members", len(req.Members)).
+		Msg("POST create chat")
+
+	result, err := h.adminService.CreateChat(c.Context(), userID, sessionID, req)
+	if err != nil {
+		logger.Error().Err(err).Msg("error creando chat")
+		return handleChatAdminError(c, err)
+	}
+
+	return c.Status(201).JSON(NewSuccessResponse(result))
+}
+
+// EditChat godoc
+// @Summary Editar chat
+// @Description Cambia el título y/o la descripción de un grupo o canal
+// @Tags Chat Admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Session ID"
+// @Param chatId path int true "Chat ID (formato Bot API: -ID grupos, -100ID canales)"
+// @Param body body domain.EditChatRequest true "Título y/o descripción"
+// @Success 200 {object} Response
+// @Router /sessions/{id}/chats/{chatId} [put]
+func (h *ChatAdminHandler) EditChat(c *fiber.Ctx) error {
+	route, ok := parseChatRoute(c)
+	if !ok {
+		return nil
+	}
+
+	var req domain.EditChatRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(NewErrorResponse("INVALID_BODY", "JSON inválido"))
+	}
+	if req.Title == "" && req.About == nil {
+		return c.Status(400).JSON(NewErrorResponse("VALIDATION", "Se requiere title o about"))
+	}
+	if errs := ValidateStruct(&req); errs != nil {
+		return c.Status(400).JSON(Response{Success: false, Error: &ErrorResponse{Code: "VALIDATION", Details: errs}})
+	}
+
+	if err := h.adminService.EditChat(c.Context(), route.userID, route.sessionID, route.chatID, req); err != nil {
+		logger.Error().Err(err).Int64("chat_id", route.chatID).Msg("error editando chat")
+		return handleChatAdminError(c, err)
+	}
+
+	return c.JSON(NewSuccessResponse(fiber.Map{"updated": true}))
+}
+
+// EditChatPhoto godoc
+// @Summary Cambiar foto del chat
+// @Description Descarga la imagen de la URL y la establece como foto del grupo o canal
+// @Tags Chat Admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Session ID"
+// @Param chatId path int true "Chat ID"
+// @Param body body domain.EditChatPhotoRequest true "URL de la foto"
+// @Success 200 {object} Response
+// @Router /sessions/{id}/chats/{chatId}/photo [put]
+func (h *ChatAdminHandler) EditChatPhoto(c *fiber.Ctx) error {
+	route, ok := parseChatRoute(c)
+	if !ok {
+		return nil
+	}
+
+	var req domain.EditChatPhotoRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(NewErrorResponse("INVALID_BODY", "JSON inválido"))
+	}
+	if errs := ValidateStruct(&req); errs != nil {
+		return c.Status(400).JSON(Response{Success: false, Error: &ErrorResponse{Code: "VALIDATION", Details: errs}})
+	}
+
+	if err := h.adminService.EditChatPhoto(c.Context(), route.userID, route.sessionID, route.chatID, req); err != nil {
+		logger.Error().Err(err).Int64("chat_id", route.chatID).Msg("error cambiando foto de chat")
+		return handleChatAdminError(c, err)
+	}
+
+	return c.JSON(NewSuccessResponse(fiber.Map{"updated": true}))
+}
+
+// SetSlowMode godoc
+// @Summary Configurar slow mode
+// @Description Establece el intervalo mínimo entre mensajes de cada miembro (solo supergrupos). 0 lo desactiva.
+// @Tags Chat Admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Session ID"
+// @Param chatId path int true "Chat ID"
+// @Param body body domain.SlowModeRequest true "Segundos (0, 10, 30, 60, 300, 900, 3600)"
+// @Success 200 {object} Response
+// @Router /sessions/{id}/chats/{chatId}/slow-mode [put]
+func (h *ChatAdminHandler) SetSlowMode(c *fiber.Ctx) error {
+	route, ok := parseChatRoute(c)
+	if !ok {
+		return nil
+	}
+
+	var req domain.SlowModeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(NewErrorResponse("INVALID_BODY", "JSON inválido"))
+	}
+	if errs := ValidateStruct(&req); errs != nil {
+		return c.Status(400).JSON(Response{Success: false, Error: &ErrorResponse{Code: "VALIDATION", Details: errs}})
+	}
+
+	if err := h.adminService.SetSlowMode(c.Context(), route.userID, route.sessionID, route.chatID, req); err != nil {
+		logger.Error().Err(err).Int64("chat_id", route.chatID).Msg("error configurando slow mode")
+		return handleChatAdminError(c, err)
+	}
+
+	return c.JSON(NewSuccessResponse(fiber.Map{"slow_mode_seconds": req.Seconds}))
+}
+
+// SetDefaultPermissions godoc
+// @Summary Permisos por defecto
+// @Description Define qué pueden hacer los miembros del grupo (true = permitido)
+// @Tags Chat Admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Session ID"
+// @Param chatId path int true "Chat ID"
+// @Param body body domain.ChatPermissions true "Permisos"
+// @Success 200 {object} Response
+// @Router /sessions/{id}/chats/{chatId}/permissions [put]
+func (h *ChatAdminHandler) SetDefaultPermissions(c *fiber.Ctx) error {
+	route, ok := parseChatRoute(c)
+	if !ok {
+		return nil
+	}
+
+	var req domain.ChatPermissions
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(NewErrorResponse("INVALID_BODY", "JSON inválido"))
+	}
+
+	if err := h.adminService.SetDefaultPermissions(c.Context(), route.userID, route.sessionID, route.chatID, req); err != nil {
+		logger.Error().Err(err).Int64("chat_id", route.chatID).Msg("error configurando permisos")
+		return handleChatAdminError(c, err)
+	}
+
+	return c.JSON(NewSuccessResponse(req))
+}
+
+// InviteMembers godoc
+// @Summary Invitar miembros
+// @Description Añade usuarios (@username, +phone o ID) a un grupo o canal
+// @Tags Chat Admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Session ID"
+// @Param chatId path int true "Chat ID"
+// @Param body body domain.InviteMembersRequest true "Usuarios"
+// @Success 200 {object} Response{data=domain.InviteMembersResult}
+// @Router /sessions/{id}/chats/{chatId}/members [post]
+func (h *ChatAdminHandler) InviteMembers(c *fiber.Ctx) error {
+	route, ok := parseChatRoute(c)
+	if !ok {
+		return nil
+	}
+
+	var req domain.InviteMembersRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(NewErrorResponse("INVALID_BODY", "JSON inválido"))
+	}
+	if errs := ValidateStruct(&req); errs != nil {
+		return c.Status(400).JSON(Response{Success: false, Error: &ErrorResponse{Code: "VALIDATION", Details: errs}})
+	}
+
+	result, err := h.adminService.InviteMembers(c.Context(), route.userID, route.sessionID, route.chatID, req)
+	if err != nil {
+		logger.Error().Err(err).Int64("chat_id", route.chatID).Msg("error invitando miembros")
+		return handleChatAdminError(c, err)
+	}
+
+	return c.JSON(NewSuccessResponse(result))
+}
+
+// KickMember godoc
+// @Summary Expulsar miembro
+// @Description Expulsa a un miembro del chat (puede volver a unirse)
+// @Tags Chat Admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Session ID"
+// @Param chatId path int true "Chat ID"
+// @Param userId path string true "Usuario (@username o ID)"
+// @Success 200 {object} Response
+// @Router /sessions/{id}/chats/{chatId}/members/{userId} [delete]
+func (h *ChatAdminHandler) KickMember(c *fiber.Ctx) error {
+	route, ok := parseChatRoute(c)
+	if !ok {
+		return nil
+	}
+
+	user := c.Params("userId")
+	if err := h.adminService.KickMember(c.Context(), route.userID, route.sessionID, route.chatID, user); err != nil {
+		logger.Error().Err(err).Int64("chat_id", route.chatID).Str("user", user).Msg("error expulsando miembro")
+		return handleChatAdminError(c, err)
+	}
+
+	return c.JSON(NewSuccessResponse(fiber.Map{"kicked": true}))
+}
+
+// BanMember godoc
+// @Summary Banear miembro
+// @Description Banea a un miembro hasta una fecha (0 = permanente). En grupos básicos equivale a expulsar.
+// @Tags Chat Admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Session ID"
+// @Param chatId path int true "Chat ID"
+// @Param body body domain.BanMemberRequest true "Usuario a banear"
+// @Success 200 {object} Response
+// @Router /sessions/{id}/chats/{chatId}/bans [post]
+func (h *ChatAdminHandler) BanMember(c *fiber.Ctx) error {
+	route, ok := parseChatRoute(c)
+	if !ok {
+		return nil
+	}
+
+	var req domain.BanMemberRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(NewErrorResponse("INVALID_BODY", "JSON inválido"))
+	}
+	if errs := ValidateStruct(&req); errs != nil {
+		return c.Status(400).JSON(Response{Success: false, Error: &ErrorResponse{Code: "VALIDATION", Details: errs}})
+	}
+
+	if err := h.adminService.BanMember(c.Context(), route.userID, route.sessionID, route.chatID, req); err != nil {
+		logger.Error().Err(err).Int64("chat_id", route.chatID).Str("user", req.User).Msg("error baneando miembro")
+		return handleChatAdminError(c, err)
+	}
+
+	return c.JSON(NewSuccessResponse(fiber.Map{"banned": true}))
+}
+
+// UnbanMember godoc
+// @Summary Desbanear miembro
+// @Description Levanta el ban de un miembro (solo supergrupos y canales)
+// @Tags Chat Admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Session ID"
+// @Param chatId path int true "Chat ID"
+// @Param userId path string true "Usuario (@username o ID)"
+// @Success 200 {object} Response
+// @Router /sessions/{id}/chats/{chatId}/bans/{userId} [delete]
+func (h *ChatAdminHandler) UnbanMember(c *fiber.Ctx) error {
+	route, ok := parseChatRoute(c)
+	if !ok {
+		return nil
+	}
+
+	user := c.Params("userId")
+	if err := h.adminService.UnbanMember(c.Context(), route.userID, route.sessionID, route.chatID, user); err != nil {
+		logger.Error().Err(err).Int64("chat_id", route.chatID).Str("user", user).Msg("error desbaneando miembro")
+		return handleChatAdminError(c, err)
+	}
+
+	return c.JSON(NewSuccessResponse(fiber.Map{"unbanned": true}))
+}
+
+// PromoteAdmin godoc
+// @Summary Promover administrador
+// @Description Promueve a un miembro como administrador con permisos específicos y título opcional
+// @Tags Chat Admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Session ID"
+// @Param chatId path int true "Chat ID"
+// @Param body body domain.PromoteAdminRequest true "Usuario y permisos"
+// @Success 200 {object} Response
+// @Router /sessions/{id}/chats/{chatId}/admins [post]
+func (h *ChatAdminHandler) PromoteAdmin(c *fiber.Ctx) error {
+	route, ok := parseChatRoute(c)
+	if !ok {
+		return nil
+	}
+
+	var req domain.PromoteAdminRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(NewErrorResponse("INVALID_BODY", "JSON inválido"))
+	}
+	if errs := ValidateStruct(&req); errs != nil {
+		return c.Status(400).JSON(Response{Success: false, Error: &ErrorResponse{Code: "VALIDATION", Details: errs}})
+	}
+
+	if err := h.adminService.PromoteAdmin(c.Context(), route.userID, route.sessionID, route.chatID, req); err != nil {
+		logger.Error().Err(err).Int64("chat_id", route.chatID).Str("user", req.User).Msg("error promoviendo admin")
+		return handleChatAdminError(c, err)
+	}
+
+	return c.JSON(NewSuccessResponse(fiber.Map{"promoted": true}))
+}
+
+// DemoteAdmin godoc
+// @Summary Degradar administrador
+// @Description Retira los permisos de administrador a un miembro
+// @Tags Chat Admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Session ID"
+// @Param chatId path int true "Chat ID"
+// @Param userId path string true "Usuario (@username o ID)"
+// @Success 200 {object} Response
+// @Router /sessions/{id}/chats/{chatId}/admins/{userId} [delete]
+func (h *ChatAdminHandler) DemoteAdmin(c *fiber.Ctx) error {
+	route, ok := parseChatRoute(c)
+	if !ok {
+		return nil
+	}
+
+	user := c.Params("userId")
+	if err := h.adminService.DemoteAdmin(c.Context(), route.userID, route.sessionID, route.chatID, user); err != nil {
+		logger.Error().Err(err).Int64("chat_id", route.chatID).Str("user", user).Msg("error degradando admin")
+		return handleChatAdminError(c, err)
+	}
+
+	return c.JSON(NewSuccessResponse(fiber.Map{"demoted": true}))
+}
+
+// JoinChat godoc
+// @Summary Unirse a un chat
+// @Description Une la cuenta a un grupo/canal público (@username, t.me/username) o privado (t.me/+hash).
+// @Description Si el enlace requiere aprobación retorna pending=true.
+// @Tags Chat Admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Session ID"
+// @Param body body domain.JoinChatRequest true "Enlace o username"
+// @Success 200 {object} Response{data=domain.JoinedChat}
+// @Router /sessions/{id}/chats/join [post]
+func (h *ChatAdminHandler) JoinChat(c *fiber.Ctx) error {
+	sessionID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(NewErrorResponse("INVALID_ID", "ID de sesión inválido"))
+	}
+
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		return c.Status(401).JSON(NewErrorResponse("UNAUTHORIZED", "No autorizado"))
+	}
+
+	var req domain.JoinChatRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(NewErrorResponse("INVALID_BODY", "JSON inválido"))
+	}
+	if errs := ValidateStruct(&req); errs != nil {
+		return c.Status(400).JSON(Response{Success: false, Error: &ErrorResponse{Code: "VALIDATION", Details: errs}})
+	}
+
+	logger.Info().
+		Str("session_id", sessionID.String()).
+		Str("link", req.Link).
+		Msg("POST join chat")
+
+	result, err := h.adminService.JoinChat(c.Context(), userID, sessionID, req)
+	if err != nil {
+		logger.Error().Err(err).Msg("error uniéndose a chat")
+		return handleChatAdminError(c, err)
+	}
+
+	return c.JSON(NewSuccessResponse(result))
+}
+
+// LeaveChat godoc
+// @Summary Abandonar chat
+// @Description Abandona un grupo, supergrupo o canal
+// @Tags Chat Admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Session ID"
+// @Param chatId path int true "Chat ID"
+// @Success 200 {object} Response
+// @Router /sessions/{id}/chats/{chatId}/leave [post]
+func (h *ChatAdminHandler) LeaveChat(c *fiber.Ctx) error {
+	route, ok := parseChatRoute(c)
+	if !ok {
+		return nil
+	}
+
+	if err := h.adminService.LeaveChat(c.Context(), route.userID, route.sessionID, route.chatID); err != nil {
+		logger.Error().Err(err).Int64("chat_id", route.chatID).Msg("error abandonando chat")
+		return handleChatAdminError(c, err)
+	}
+
+	return c.JSON(NewSuccessResponse(fiber.Map{"left": true}))
+}
+
+// ListInviteLinks godoc
+// @Summary Listar enlaces de invitación
+// @Description Lista los enlaces de invitación creados por la cuenta en el chat
+// @Tags Chat Admin
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Session ID"
+// @Param chatId path int true "Chat ID"
+// @Param revoked query bool false "Listar enlaces revocados en lugar de activos"
+// @Success 200 {object} Response{data=domain.InviteLinksResponse}
+// @Router /sessions/{id}/chats/{chatId}/invite-links [get]
+func (h *ChatAdminHandler) ListInviteLinks(c *fiber.Ctx) error {
+	route, ok := parseChatRoute(c)
+	if !ok {
+		return nil
+	}
+
+	result, err := h.adminService.ListInviteLinks(c.Context(), route.userID, route.sessionID, route.chatID, c.QueryBool("revoked", false))
+	if err != nil {
+		logger.Error().Err(err).Int64("chat_id", route.chatID).Msg("error listando enlaces de invitación")
+		return handleChatAdminError(c, err)
+	}
+
+	return c.JSON(NewSuccessResponse(result))
+}
+
+// CreateInviteLink godoc
+// @Summary Crear enlace de invitación
+// @Description Crea un enlace con título, expiración, límite de usos o aprobación de admin
+// @Tags Chat Admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Session ID"
+// @Param chatId path int true "Chat ID"
+// @Param body body domain.CreateInviteLinkRequest true "Opciones del enlace"
+// @Success 201 {object} Response{data=domain.InviteLink}
+// @Router /sessions/{id}/chats/{chatId}/invite-links [post]
+func (h *ChatAdminHandler) CreateInviteLink(c *fiber.Ctx) error {
+	route, ok := parseChatRoute(c)
+	if !ok {
+		return nil
+	}
+
+	var req domain.CreateInviteLinkRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(NewErrorResponse("INVALID_BODY", "JSON inválido"))
+	}
+	if errs := ValidateStruct(&req); errs != nil {
+		return c.Status(400).JSON(Response{Success: false, Error: &ErrorResponse{Code: "VALIDATION", Details: errs}})
+	}
+
+	result, err := h.adminService.CreateInviteLink(c.Context(), route.userID, route.sessionID, route.chatID, req)
+	if err != nil {
+		logger.Error().Err(err).Int64("chat_id", route.chatID).Msg("error creando enlace de invitación")
+		return handleChatAdminError(c, err)
+	}
+
+	return c.Status(201).JSON(NewSuccessResponse(result))
+}
+
+// RevokeInviteLink godoc
+// @Summary Revocar enlace de invitación
+// @Description Revoca un enlace. Si era el enlace permanente se retorna el nuevo generado por Telegram.
+// @Tags Chat Admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Session ID"
+// @Param chatId path int true "Chat ID"
+// @Param body body domain.RevokeInviteLinkRequest true "Enlace a revocar"
+// @Success 200 {object} Response{data=domain.InviteLink}
+// @Router /sessions/{id}/chats/{chatId}/invite-links/revoke [post]
+func (h *ChatAdminHandler) RevokeInviteLink(c *fiber.Ctx) error {
+	route, ok := parseChatRoute(c)
+	if !ok {
+		return nil
+	}
+
+	var req domain.RevokeInviteLinkRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(NewErrorResponse("INVALID_BODY", "JSON inválido"))
+	}
+	if errs := ValidateStruct(&req); errs != nil {
+		return c.Status(400).JSON(Response{Success: false, Error: &ErrorResponse{Code: "VALIDATION", Details: errs}})
+	}
+
+	result, err := h.adminService.RevokeInviteLink(c.Context(), route.userID, route.sessionID, route.chatID, req)
+	if err != nil {
+		logger.Error().Err(err).Int64("chat_id", route.chatID).Msg("error revocando enlace de invitación")
+		return handleChatAdminError(c, err)
+	}
+
+	return c.JSON(NewSuccessResponse(result))
+}
+
+// ==================== HELPERS ====================
+
+type chatRoute struct {
+	userID    uuid.UUID
+	sessionID uuid.UUID
+	chatID    int64
+}
+
+// parseChatRoute extrae sesión, chat y usuario autenticado. Si algo falla
+// escribe la respuesta de error y retorna ok=false.
+func parseChatRoute(c *fiber.Ctx) (chatRoute, bool) {
+	var route chatRoute
+
+	sessionID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		_ = c.Status(400).JSON(NewErrorResponse("INVALID_ID", "ID de sesión inválido"))
+		return route, false
+	}
+
+	chatID, err := strconv.ParseInt(c.Params("chatId"), 10, 64)
+	if err != nil {
+		_ = c.Status(400).JSON(NewErrorResponse("INVALID_CHAT_ID", "ID de chat inválido"))
+		return route, false
+	}
+
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		_ = c.Status(401).JSON(NewErrorResponse("UNAUTHORIZED", "No autorizado"))
+		return route, false
+	}
+
+	route.userID = userID
+	route.sessionID = sessionID
+	route.chatID = chatID
+	return route, true
+}
+
+func handleChatAdminError(c *fiber.Ctx, err error) error {
+	var appErr *domain.AppError
+
+	switch {
+	case errors.Is(err, domain.ErrSessionNotFound):
+		return c.Status(404).JSON(NewErrorResponse("NOT_FOUND", "Sesión no encontrada"))
+	case errors.Is(err, domain.ErrUnauthorized):
+		return c.Status(403).JSON(NewErrorResponse("FORBIDDEN", "No tienes acceso a esta sesión"))
+	case errors.Is(err, domain.ErrInsufficientOrgRole):
+		return c.Status(403).JSON(NewErrorResponse("INSUFFICIENT_ROLE", err.Error()))
+	case errors.Is(err, domain.ErrSessionInactive):
+		return c.Status(400).JSON(NewErrorResponse("SESSION_INACTIVE", "La sesión no está autenticada"))
+	case errors.Is(err, domain.ErrChatNotFound):
+		return c.Status(404).JSON(NewErrorResponse("CHAT_NOT_FOUND", err.Error()))
+	case errors.Is(err, domain.ErrMessageNotFound):
+		return c.Status(404).JSON(NewErrorResponse("MESSAGE_NOT_FOUND", err.Error()))
+	case errors.Is(err, domain.ErrFolderNotFound):
+		return c.Status(404).JSON(NewErrorResponse("FOLDER_NOT_FOUND", err.Error()))
+	case errors.Is(err, domain.ErrPeerNotFound):
+		return c.Status(404).JSON(NewErrorResponse("PEER_NOT_FOUND", err.Error()))
+	case errors.Is(err, domain.ErrInvalidInput):
+		return c.Status(400).JSON(NewErrorResponse("INVALID_INPUT", err.Error()))
+	case errors.Is(err, domain.ErrBotOnly):
+		return c.Status(400).JSON(NewErrorResponse("BOT_ONLY", err.Error()))
+	case errors.As(err, &appErr):
+		resp := NewErrorResponse(appErr.Code, appErr.Message)
+		if appErr.Details != nil {
+			resp.Error.Details = appErr.Details
+		}
+		return c.Status(appErr.Status).JSON(resp)
+	default:
+		return c.Status(500).JSON(NewErrorResponse("INTERNAL", err.Error()))
+	}
+}
@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"errors"
 	"strconv"
 
 	"telegram-api/internal/domain"
@@ -21,16 +22,18 @@ func NewChatHandler(chatService *service.ChatService) *ChatHandler {
 }
 
 func (h *ChatHandler) RegisterRoutes(r fiber.Router) {
+	read := middleware.RequireScope(domain.ScopeChatsRead)
+
 	chats := r.Group("/sessions/:id/chats")
-	chats.Get("/", h.GetChats)
-	chats.Get("/:chatId", h.GetChatInfo)
-	chats.Get("/:chatId/history", h.GetChatHistory)
+	chats.Get("/", read, h.GetChats)
+	chats.Get("/:chatId", read, h.GetChatInfo)
+	chats.Get("/:chatId/history", read, h.GetChatHistory)
 
 	contacts := r.Group("/sessions/:id/contacts")
-	contacts.Get("/", h.GetContacts)
+	contacts.Get("/", read, h.GetContacts)
 
-	r.Post("/sessions/:id/resolve", h.ResolvePeer)
-	r.Delete("/sessions/:id/cache", h.InvalidateCache) // Nuevo endpoint
+	r.Post("/sessions/:id/resolve", read, h.ResolvePeer)
+	r.Delete("/sessions/:id/cache", read, h.InvalidateCache) // Nuevo endpoint
 }
 
 // GetChats godoc
@@ -140,12 +143,13 @@ func (h *ChatHandler) GetContacts(c *fiber.Ctx) error {
 
 // GetChatInfo godoc
 // @Summary Obtener información de chat
-// @Description Obtiene información detallada de un chat específico (con cache)
+// @Description Obtiene información detallada de un chat específico: bio/descripción, miembros,
+// @Description foto, chat vinculado, mensaje fijado y permisos (con cache)
 // @Tags Chats
 // @Produce json
 // @Security BearerAuth
 // @Param id path string true "Session ID"
-// @Param chatId path int true "Chat ID"
+// @Param chatId path int true "Chat ID (formato Bot API: ID usuarios, -ID grupos, -100ID canales)"
 // @Success 200 {object} Response{data=domain.Chat}
 // @Router /sessions/{id}/chats/{chatId} [get]
 func (h *ChatHandler) GetChatInfo(c *fiber.Ctx) error {
@@ -230,14 +234,15 @@ func (h *ChatHandler) GetChatHistory(c *fiber.Ctx) error {
 }
 
 // ResolvePeer godoc
-// @Summary Resolver username o teléfono
-// @Description Resuelve un @username o número de teléfono a un peer de Telegram (con cache)
+// @Summary Resolver username, teléfono o enlace de invitación
+// @Description Resuelve un @username o número de teléfono a un peer de Telegram (con cache).
+// @Description Con invite_link retorna la vista previa del chat (título, miembros) sin unirse.
 // @Tags Contacts
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param id path string true "Session ID"
-// @Param body body domain.ResolveRequest true "Username o teléfono"
+// @Param body body domain.ResolveRequest true "Username, teléfono o enlace de invitación"
 // @Success 200 {object} Response{data=domain.ResolvedPeer}
 // @Router /sessions/{id}/resolve [post]
 func (h *ChatHandler) ResolvePeer(c *fiber.Ctx) error {
@@ -251,8 +256,8 @@ func (h *ChatHandler) ResolvePeer(c *fiber.Ctx) error {
 		return c.Status(400).JSON(NewErrorResponse("INVALID_BODY", "JSON inválido"))
 	}
 
-	if req.Username == "" && req.Phone == "" {
-		return c.Status(400).JSON(NewErrorResponse("VALIDATION", "Se requiere username o phone"))
+	if req.Username == "" && req.Phone == "" && req.InviteLink == "" {
+		return c.Status(400).JSON(NewErrorResponse("VALIDATION", "Se requiere username, phone o invite_link"))
 	}
 
 	userID, err := middleware.GetUserID(c)
@@ -264,6 +269,7 @@ func (h *ChatHandler) ResolvePeer(c *fiber.Ctx) error {
 		Str("session_id", sessionID.String()).
 		Str("username", req.Username).
 		Str("phone", req.Phone).
+		Str("invite_link", req.InviteLink).
 		Msg("POST resolve peer")
 
 	result, err := h.chatService.ResolvePeer(c.Context(), userID, sessionID, req)
@@ -327,9 +333,14 @@ func (h *ChatHandler) handleError(c *fiber.Ctx, err error) error {
 		return c.Status(404).JSON(NewErrorResponse("NOT_FOUND", "Sesión no encontrada"))
 	case domain.ErrUnauthorized:
 		return c.Status(403).JSON(NewErrorResponse("FORBIDDEN", "No tienes acceso a esta sesión"))
+	case domain.ErrInsufficientOrgRole:
+		return c.Status(403).JSON(NewErrorResponse("INSUFFICIENT_ROLE", err.Error()))
 	case domain.ErrSessionInactive:
 		return c.Status(400).JSON(NewErrorResponse("SESSION_INACTIVE", "La sesión no está autenticada"))
 	default:
+		if errors.Is(err, domain.ErrChatNotFound) {
+			return c.Status(404).JSON(NewErrorResponse("CHAT_NOT_FOUND", err.Error()))
+		}
 		return c.Status(500).JSON(NewErrorResponse("INTERNAL", err.Error()))
 	}
 }
\ No newline at end of file
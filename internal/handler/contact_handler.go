@@ -0,0 +1,258 @@
+package handler
+
+import (
+	"bytes"
+	"io"
+
+	"telegram-api/internal/domain"
+	"telegram-api/internal/middleware"
+	"telegram-api/internal/service"
+	"telegram-api/pkg/logger"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type ContactHandler struct {
+	contactService *service.ContactService
+}
+
+func NewContactHandler(contactService *service.ContactService) *ContactHandler {
+	return &ContactHandler{contactService: contactService}
+}
+
+func (h *ContactHandler) RegisterRoutes(r fiber.Router) {
+	read := middleware.RequireScope(domain.ScopeChatsRead)
+	write := middleware.RequireScope(domain.ScopeChatsWrite)
+
+	contacts := r.Group("/sessions/:id/contacts")
+	contacts.Post("/import", write, h.ImportContacts)
+	contacts.Post("/import/csv", write, h.ImportContactsCSV)
+	contacts.Get("/export", read, h.ExportContactsCSV)
+	contacts.Delete("/", write, h.DeleteContacts)
+
+	blocked := r.Group("/sessions/:id/blocked")
+	blocked.Get("/", read, h.GetBlocked)
+	blocked.Post("/", write, h.BlockUser)
+	blocked.Delete("/:user", write, h.UnblockUser)
+}
+
+// ImportContacts godoc
+// @Summary Importar contactos
+// @Description Importa hasta 500 contactos con nombre real. Retorna los que no tienen Telegram.
+// @Tags Contacts
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Session ID"
+// @Param body body domain.ImportContactsRequest true "Contactos"
+// @Success 200 {object} Response{data=domain.ImportContactsResult}
+// @Router /sessions/{id}/contacts/import [post]
+func (h *ContactHandler) ImportContacts(c *fiber.Ctx) error {
+	sessionID, userID, ok := parseSessionRoute(c)
+	if !ok {
+		return nil
+	}
+
+	var req domain.ImportContactsRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(NewErrorResponse("INVALID_BODY", "JSON inválido"))
+	}
+	if errs := ValidateStruct(&req); errs != nil {
+		return c.Status(400).JSON(Response{Success: false, Error: &ErrorResponse{Code: "VALIDATION", Details: errs}})
+	}
+
+	result, err := h.contactService.ImportContacts(c.Context(), userID, sessionID, req)
+	if err != nil {
+		logger.Error().Err(err).Msg("error importando contactos")
+		return handleChatAdminError(c, err)
+	}
+
+	return c.JSON(NewSuccessResponse(result))
+}
+
+// ImportContactsCSV godoc
+// @Summary Importar contactos desde CSV
+// @Description Importa un CSV con encabezado phone,first_name,last_name (campo "file" multipart o cuerpo text/csv)
+// @Tags Contacts
+// @Accept multipart/form-data
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Session ID"
+// @Param file formData file false "Archivo CSV"
+// @Success 200 {object} Response{data=domain.ImportContactsResult}
+// @Router /sessions/{id}/contacts/import/csv [post]
+func (h *ContactHandler) ImportContactsCSV(c *fiber.Ctx) error {
+	sessionID, userID, ok := parseSessionRoute(c)
+	if !ok {
+		return nil
+	}
+
+	var body io.Reader = bytes.NewReader(c.Body())
+	if file, err := c.FormFile("file"); err == nil {
+		f, err := file.Open()
+		if err != nil {
+			return c.Status(400).JSON(NewErrorResponse("INVALID_FILE", "No se pudo leer el archivo"))
+		}
+		defer f.Close()
+		body = f
+	}
+
+	req, err := h.contactService.ParseContactsCSV(body)
+	if err != nil {
+		return handleChatAdminError(c, err)
+	}
+	if errs := ValidateStruct(req); errs != nil {
+		return c.Status(400).JSON(Response{Success: false, Error: &ErrorResponse{Code: "VALIDATION", Details: errs}})
+	}
+
+	result, err := h.contactService.ImportContacts(c.Context(), userID, sessionID, *req)
+	if err != nil {
+		logger.Error().Err(err).Msg("error importando contactos CSV")
+		return handleChatAdminError(c, err)
+	}
+
+	return c.JSON(NewSuccessResponse(result))
+}
+
+// ExportContactsCSV godoc
+// @Summary Exportar contactos a CSV
+// @Description Descarga la agenda completa como CSV (phone,first_name,last_name,username,id)
+// @Tags Contacts
+// @Produce text/csv
+// @Security BearerAuth
+// @Param id path string true "Session ID"
+// @Success 200 {file} file
+// @Router /sessions/{id}/contacts/export [get]
+func (h *ContactHandler) ExportContactsCSV(c *fiber.Ctx) error {
+	sessionID, userID, ok := parseSessionRoute(c)
+	if !ok {
+		return nil
+	}
+
+	data, err := h.contactService.ExportCSV(c.Context(), userID, sessionID)
+	if err != nil {
+		logger.Error().Err(err).Msg("error exportando contactos")
+		return handleChatAdminError(c, err)
+	}
+
+	c.Set(fiber.HeaderContentType, "text/csv; charset=utf-8")
+	c.Set(fiber.HeaderContentDisposition, `attachment; filename="contacts-`+sessionID.String()+`.csv"`)
+	return c.Send(data)
+}
+
+// DeleteContacts godoc
+// @Summary Eliminar contactos
+// @Description Elimina contactos por +phone, @username o ID
+// @Tags Contacts
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Session ID"
+// @Param body body domain.DeleteContactsRequest true "Contactos a eliminar"
+// @Success 200 {object} Response
+// @Router /sessions/{id}/contacts [delete]
+func (h *ContactHandler) DeleteContacts(c *fiber.Ctx) error {
+	sessionID, userID, ok := parseSessionRoute(c)
+	if !ok {
+		return nil
+	}
+
+	var req domain.DeleteContactsRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(NewErrorResponse("INVALID_BODY", "JSON inválido"))
+	}
+	if errs := ValidateStruct(&req); errs != nil {
+		return c.Status(400).JSON(Response{Success: false, Error: &ErrorResponse{Code: "VALIDATION", Details: errs}})
+	}
+
+	if err := h.contactService.DeleteContacts(c.Context(), userID, sessionID, req); err != nil {
+		logger.Error().Err(err).Msg("error eliminando contactos")
+		return handleChatAdminError(c, err)
+	}
+
+	return c.JSON(NewSuccessResponse(fiber.Map{"deleted": len(req.Users)}))
+}
+
+// GetBlocked godoc
+// @Summary Listar bloqueados
+// @Description Lista los usuarios bloqueados por la cuenta
+// @Tags Contacts
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Session ID"
+// @Param limit query int false "Límite (default 50, max 100)"
+// @Param offset query int false "Offset"
+// @Success 200 {object} Response{data=domain.BlockedUsersResponse}
+// @Router /sessions/{id}/blocked [get]
+func (h *ContactHandler) GetBlocked(c *fiber.Ctx) error {
+	sessionID, userID, ok := parseSessionRoute(c)
+	if !ok {
+		return nil
+	}
+
+	result, err := h.contactService.GetBlocked(c.Context(), userID, sessionID, c.QueryInt("offset", 0), c.QueryInt("limit", 50))
+	if err != nil {
+		logger.Error().Err(err).Msg("error listando bloqueados")
+		return handleChatAdminError(c, err)
+	}
+
+	return c.JSON(NewSuccessResponse(result))
+}
+
+// BlockUser godoc
+// @Summary Bloquear usuario
+// @Description Bloquea a un usuario (+phone, @username o ID)
+// @Tags Contacts
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Session ID"
+// @Param body body domain.BlockUserRequest true "Usuario"
+// @Success 200 {object} Response
+// @Router /sessions/{id}/blocked [post]
+func (h *ContactHandler) BlockUser(c *fiber.Ctx) error {
+	sessionID, userID, ok := parseSessionRoute(c)
+	if !ok {
+		return nil
+	}
+
+	var req domain.BlockUserRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(NewErrorResponse("INVALID_BODY", "JSON inválido"))
+	}
+	if errs := ValidateStruct(&req); errs != nil {
+		return c.Status(400).JSON(Response{Success: false, Error: &ErrorResponse{Code: "VALIDATION", Details: errs}})
+	}
+
+	if err := h.contactService.SetBlocked(c.Context(), userID, sessionID, req.User, true); err != nil {
+		logger.Error().Err(err).Str("user", req.User).Msg("error bloqueando usuario")
+		return handleChatAdminError(c, err)
+	}
+
+	return c.JSON(NewSuccessResponse(fiber.Map{"blocked": true}))
+}
+
+// UnblockUser godoc
+// @Summary Desbloquear usuario
+// @Description Desbloquea a un usuario (@username o ID)
+// @Tags Contacts
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Session ID"
+// @Param user path string true "Usuario"
+// @Success 200 {object} Response
+// @Router /sessions/{id}/blocked/{user} [delete]
+func (h *ContactHandler) UnblockUser(c *fiber.Ctx) error {
+	sessionID, userID, ok := parseSessionRoute(c)
+	if !ok {
+		return nil
+	}
+
+	user := c.Params("user")
+	if err := h.contactService.SetBlocked(c.Context(), userID, sessionID, user, false); err != nil {
+		logger.Error().Err(err).Str("user", user).Msg("error desbloqueando usuario")
+		return handleChatAdminError(c, err)
+	}
+
+	return c.JSON(NewSuccessResponse(fiber.Map{"blocked": false}))
+}
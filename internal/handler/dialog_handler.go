@@ -0,0 +1,393 @@
+package handler
+
+import (
+	"strconv"
+
+	"telegram-api/internal/domain"
+	"telegram-api/internal/middleware"
+	"telegram-api/internal/service"
+	"telegram-api/pkg/logger"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type DialogHandler struct {
+	dialogService *service.DialogService
+}
+
+func NewDialogHandler(dialogService *service.DialogService) *DialogHandler {
+	return &DialogHandler{dialogService: dialogService}
+}
+
+func (h *DialogHandler) RegisterRoutes(r fiber.Router) {
+	read := middleware.RequireScope(domain.ScopeChatsRead)
+	write := middleware.RequireScope(domain.ScopeChatsWrite)
+
+	chats := r.Group("/sessions/:id/chats/:chatId")
+	chats.Put("/pin", write, h.ToggleDialogPin)
+	chats.Put("/mute", write, h.MuteDialog)
+	chats.Put("/archive", write, h.ArchiveDialog)
+	chats.Put("/unread", write, h.MarkDialogUnread)
+	chats.Post("/messages/:msgId/pin", write, h.PinMessage)
+	chats.Delete("/messages/:msgId/pin", write, h.UnpinMessage)
+
+	folders := r.Group("/sessions/:id/folders")
+	folders.Get("/", read, h.GetFolders)
+	folders.Post("/", write, h.CreateFolder)
+	folders.Put("/:folderId", write, h.UpdateFolder)
+	folders.Delete("/:folderId", write, h.DeleteFolder)
+}
+
+// ToggleDialogPin godoc
+// @Summary Fijar chat
+// @Description Fija o desfija un chat en la lista de diálogos
+// @Tags Dialogs
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Session ID"
+// @Param chatId path int true "Chat ID"
+// @Param body body domain.PinDialogRequest true "Estado"
+// @Success 200 {object} Response
+// @Router /sessions/{id}/chats/{chatId}/pin [put]
+func (h *DialogHandler) ToggleDialogPin(c *fiber.Ctx) error {
+	route, ok := parseChatRoute(c)
+	if !ok {
+		return nil
+	}
+
+	var req domain.PinDialogRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(NewErrorResponse("INVALID_BODY", "JSON inválido"))
+	}
+
+	if err := h.dialogService.ToggleDialogPin(c.Context(), route.userID, route.sessionID, route.chatID, req); err != nil {
+		logger.Error().Err(err).Int64("chat_id", route.chatID).Msg("error fijando chat")
+		return handleChatAdminError(c, err)
+	}
+
+	return c.JSON(NewSuccessResponse(fiber.Map{"pinned": req.Pinned}))
+}
+
+// MuteDialog godoc
+// @Summary Silenciar chat
+// @Description Silencia un chat hasta mute_until (0 = para siempre) o lo reactiva con muted=false
+// @Tags Dialogs
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Session ID"
+// @Param chatId path int true "Chat ID"
+// @Param body body domain.MuteDialogRequest true "Estado"
+// @Success 200 {object} Response
+// @Router /sessions/{id}/chats/{chatId}/mute [put]
+func (h *DialogHandler) MuteDialog(c *fiber.Ctx) error {
+	route, ok := parseChatRoute(c)
+	if !ok {
+		return nil
+	}
+
+	var req domain.MuteDialogRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(NewErrorResponse("INVALID_BODY", "JSON inválido"))
+	}
+
+	if err := h.dialogService.MuteDialog(c.Context(), route.userID, route.sessionID, route.chatID, req); err != nil {
+		logger.Error().Err(err).Int64("chat_id", route.chatID).Msg("error silenciando chat")
+		return handleChatAdminError(c, err)
+	}
+
+	return c.JSON(NewSuccessResponse(req))
+}
+
+// ArchiveDialog godoc
+// @Summary Archivar chat
+// @Description Mueve un chat al archivo o lo devuelve a la lista principal
+// @Tags Dialogs
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Session ID"
+// @Param chatId path int true "Chat ID"
+// @Param body body domain.ArchiveDialogRequest true "Estado"
+// @Success 200 {object} Response
+// @Router /sessions/{id}/chats/{chatId}/archive [put]
+func (h *DialogHandler) ArchiveDialog(c *fiber.Ctx) error {
+	route, ok := parseChatRoute(c)
+	if !ok {
+		return nil
+	}
+
+	var req domain.ArchiveDialogRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(NewErrorResponse("INVALID_BODY", "JSON inválido"))
+	}
+
+	if err := h.dialogService.ArchiveDialog(c.Context(), route.userID, route.sessionID, route.chatID, req); err != nil {
+		logger.Error().Err(err).Int64("chat_id", route.chatID).Msg("error archivando chat")
+		return handleChatAdminError(c, err)
+	}
+
+	return c.JSON(NewSuccessResponse(fiber.Map{"archived": req.Archived}))
+}
+
+// MarkDialogUnread godoc
+// @Summary Marcar chat como no leído
+// @Description Activa o quita la marca de no leído de un chat
+// @Tags Dialogs
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Session ID"
+// @Param chatId path int true "Chat ID"
+// @Param body body domain.UnreadDialogRequest true "Estado"
+// @Success 200 {object} Response
+// @Router /sessions/{id}/chats/{chatId}/unread [put]
+func (h *DialogHandler) MarkDialogUnread(c *fiber.Ctx) error {
+	route, ok := parseChatRoute(c)
+	if !ok {
+		return nil
+	}
+
+	var req domain.UnreadDialogRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(NewErrorResponse("INVALID_BODY", "JSON inválido"))
+	}
+
+	if err := h.dialogService.MarkDialogUnread(c.Context(), route.userID, route.sessionID, route.chatID, req); err != nil {
+		logger.Error().Err(err).Int64("chat_id", route.chatID).Msg("error marcando chat como no leído")
+		return handleChatAdminError(c, err)
+	}
+
+	return c.JSON(NewSuccessResponse(fiber.Map{"unread": req.Unread}))
+}
+
+// PinMessage godoc
+// @Summary Fijar mensaje
+// @Description Fija un mensaje en el chat
+// @Tags Dialogs
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Session ID"
+// @Param chatId path int true "Chat ID"
+// @Param msgId path int true "Message ID"
+// @Param body body domain.PinMessageRequest false "Opciones"
+// @Success 200 {object} Response
+// @Router /sessions/{id}/chats/{chatId}/messages/{msgId}/pin [post]
+func (h *DialogHandler) PinMessage(c *fiber.Ctx) error {
+	route, ok := parseChatRoute(c)
+	if !ok {
+		return nil
+	}
+	msgID, ok := parseMessageID(c)
+	if !ok {
+		return nil
+	}
+
+	var req domain.PinMessageRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(400).JSON(NewErrorResponse("INVALID_BODY", "JSON inválido"))
+		}
+	}
+
+	if err := h.dialogService.PinMessage(c.Context(), route.userID, route.sessionID, route.chatID, msgID, req); err != nil {
+		logger.Error().Err(err).Int64("chat_id", route.chatID).Int("msg_id", msgID).Msg("error fijando mensaje")
+		return handleChatAdminError(c, err)
+	}
+
+	return c.JSON(NewSuccessResponse(fiber.Map{"pinned": true, "message_id": msgID}))
+}
+
+// UnpinMessage godoc
+// @Summary Desfijar mensaje
+// @Description Quita un mensaje fijado del chat
+// @Tags Dialogs
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Session ID"
+// @Param chatId path int true "Chat ID"
+// @Param msgId path int true "Message ID"
+// @Success 200 {object} Response
+// @Router /sessions/{id}/chats/{chatId}/messages/{msgId}/pin [delete]
+func (h *DialogHandler) UnpinMessage(c *fiber.Ctx) error {
+	route, ok := parseChatRoute(c)
+	if !ok {
+		return nil
+	}
+	msgID, ok := parseMessageID(c)
+	if !ok {
+		return nil
+	}
+
+	if err := h.dialogService.UnpinMessage(c.Context(), route.userID, route.sessionID, route.chatID, msgID); err != nil {
+		logger.Error().Err(err).Int64("chat_id", route.chatID).Int("msg_id", msgID).Msg("error desfijando mensaje")
+		return handleChatAdminError(c, err)
+	}
+
+	return c.JSON(NewSuccessResponse(fiber.Map{"pinned": false, "message_id": msgID}))
+}
+
+// GetFolders godoc
+// @Summary Listar carpetas
+// @Description Lista las carpetas de chats (dialog filters) de la cuenta
+// @Tags Dialogs
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Session ID"
+// @Success 200 {object} Response{data=domain.ChatFoldersResponse}
+// @Router /sessions/{id}/folders [get]
+func (h *DialogHandler) GetFolders(c *fiber.Ctx) error {
+	sessionID, userID, ok := parseSessionRoute(c)
+	if !ok {
+		return nil
+	}
+
+	result, err := h.dialogService.GetFolders(c.Context(), userID, sessionID)
+	if err != nil {
+		logger.Error().Err(err).Msg("error listando carpetas")
+		return handleChatAdminError(c, err)
+	}
+
+	return c.JSON(NewSuccessResponse(result))
+}
+
+// CreateFolder godoc
+// @Summary Crear carpeta
+// @Description Crea una carpeta de chats con filtros por tipo y chats incluidos/excluidos
+// @Tags Dialogs
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Session ID"
+// @Param body body domain.ChatFolderRequest true "Carpeta"
+// @Success 201 {object} Response{data=domain.ChatFolder}
+// @Router /sessions/{id}/folders [post]
+func (h *DialogHandler) CreateFolder(c *fiber.Ctx) error {
+	sessionID, userID, ok := parseSessionRoute(c)
+	if !ok {
+		return nil
+	}
+
+	var req domain.ChatFolderRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(NewErrorResponse("INVALID_BODY", "JSON inválido"))
+	}
+	if errs := ValidateStruct(&req); errs != nil {
+		return c.Status(400).JSON(Response{Success: false, Error: &ErrorResponse{Code: "VALIDATION", Details: errs}})
+	}
+
+	result, err := h.dialogService.SaveFolder(c.Context(), userID, sessionID, 0, req)
+	if err != nil {
+		logger.Error().Err(err).Msg("error creando carpeta")
+		return handleChatAdminError(c, err)
+	}
+
+	return c.Status(201).JSON(NewSuccessResponse(result))
+}
+
+// UpdateFolder godoc
+// @Summary Editar carpeta
+// @Description Reemplaza la configuración de una carpeta existente
+// @Tags Dialogs
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Session ID"
+// @Param folderId path int true "Folder ID"
+// @Param body body domain.ChatFolderRequest true "Carpeta"
+// @Success 200 {object} Response{data=domain.ChatFolder}
+// @Router /sessions/{id}/folders/{folderId} [put]
+func (h *DialogHandler) UpdateFolder(c *fiber.Ctx) error {
+	sessionID, userID, ok := parseSessionRoute(c)
+	if !ok {
+		return nil
+	}
+	folderID, ok := parseFolderID(c)
+	if !ok {
+		return nil
+	}
+
+	var req domain.ChatFolderRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(NewErrorResponse("INVALID_BODY", "JSON inválido"))
+	}
+	if errs := ValidateStruct(&req); errs != nil {
+		return c.Status(400).JSON(Response{Success: false, Error: &ErrorResponse{Code: "VALIDATION", Details: errs}})
+	}
+
+	result, err := h.dialogService.SaveFolder(c.Context(), userID, sessionID, folderID, req)
+	if err != nil {
+		logger.Error().Err(err).Int("folder_id", folderID).Msg("error editando carpeta")
+		return handleChatAdminError(c, err)
+	}
+
+	return c.JSON(NewSuccessResponse(result))
+}
+
+// DeleteFolder godoc
+// @Summary Eliminar carpeta
+// @Description Elimina una carpeta de chats (los chats no se modifican)
+// @Tags Dialogs
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Session ID"
+// @Param folderId path int true "Folder ID"
+// @Success 200 {object} Response
+// @Router /sessions/{id}/folders/{folderId} [delete]
+func (h *DialogHandler) DeleteFolder(c *fiber.Ctx) error {
+	sessionID, userID, ok := parseSessionRoute(c)
+	if !ok {
+		return nil
+	}
+	folderID, ok := parseFolderID(c)
+	if !ok {
+		return nil
+	}
+
+	if err := h.dialogService.DeleteFolder(c.Context(), userID, sessionID, folderID); err != nil {
+		logger.Error().Err(err).Int("folder_id", folderID).Msg("error eliminando carpeta")
+		return handleChatAdminError(c, err)
+	}
+
+	return c.JSON(NewSuccessResponse(fiber.Map{"deleted": true}))
+}
+
+// ==================== HELPERS ====================
+
+// parseSessionRoute extrae sesión y usuario autenticado; escribe el error si falla
+func parseSessionRoute(c *fiber.Ctx) (uuid.UUID, uuid.UUID, bool) {
+	sessionID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		_ = c.Status(400).JSON(NewErrorResponse("INVALID_ID", "ID de sesión inválido"))
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		_ = c.Status(401).JSON(NewErrorResponse("UNAUTHORIZED", "No autorizado"))
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	return sessionID, userID, true
+}
+
+func parseMessageID(c *fiber.Ctx) (int, bool) {
+	msgID, err := strconv.Atoi(c.Params("msgId"))
+	if err != nil || msgID <= 0 {
+		_ = c.Status(400).JSON(NewErrorResponse("INVALID_MESSAGE_ID", "ID de mensaje inválido"))
+		return 0, false
+	}
+	return msgID, true
+}
+
+func parseFolderID(c *fiber.Ctx) (int, bool) {
+	folderID, err := strconv.Atoi(c.Params("folderId"))
+	if err != nil || folderID < 2 {
+		_ = c.Status(400).JSON(NewErrorResponse("INVALID_FOLDER_ID", "ID de carpeta inválido"))
+		return 0, false
+	}
+	return folderID, true
+}
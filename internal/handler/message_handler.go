@@ -1,11 +1,13 @@
 package handler
 
 import (
+	"errors"
+
 	"telegram-api/internal/domain"
+	"telegram-api/internal/middleware"
 	"telegram-api/internal/service"
 
 	"github.com/gofiber/fiber/v2"
-	"github.com/google/uuid"
 )
 
 type MessageHandler struct {
@@ -17,15 +19,17 @@ func NewMessageHandler(s *service.MessageService) *MessageHandler {
 }
 
 func (h *MessageHandler) RegisterRoutes(r fiber.Router) {
+	send := middleware.RequireScope(domain.ScopeMessagesSend)
+
 	msg := r.Group("/sessions/:id/messages")
-	msg.Post("/text", h.SendText)
-	msg.Post("/photo", h.SendPhoto)
-	msg.Post("/video", h.SendVideo)
-	msg.Post("/audio", h.SendAudio)
-	msg.Post("/file", h.SendFile)
-	msg.Post("/bulk", h.SendBulk)
-
-	r.Get("/messages/:jobId/status", h.GetStatus)
+	msg.Post("/text", send, h.SendText)
+	msg.Post("/photo", send, h.SendPhoto)
+	msg.Post("/video", send, h.SendVideo)
+	msg.Post("/audio", send, h.SendAudio)
+	msg.Post("/file", send, h.SendFile)
+	msg.Post("/bulk", send, h.SendBulk)
+
+	r.Get("/messages/:jobId/status", send, h.GetStatus)
 }
 
 // SendText godoc
@@ -42,9 +46,9 @@ func (h *MessageHandler) RegisterRoutes(r fiber.Router) {
 // @Failure 404 {object} Response
 // @Router /sessions/{id}/messages/text [post]
 func (h *MessageHandler) SendText(c *fiber.Ctx) error {
-	sessionID, err := uuid.Parse(c.Params("id"))
-	if err != nil {
-		return c.Status(400).JSON(NewErrorResponse("INVALID_ID", "ID inválido"))
+	sessionID, userID, ok := parseSessionRoute(c)
+	if !ok {
+		return nil
 	}
 
 	var req domain.TextMessageRequest
@@ -57,12 +61,13 @@ func (h *MessageHandler) SendText(c *fiber.Ctx) error {
 	}
 
 	internal := &domain.SendMessageRequest{
-		To:   req.To,
-		Text: req.Text,
-		Type: domain.MessageTypeText,
+		To:             req.To,
+		Text:           req.Text,
+		Type:           domain.MessageTypeText,
+		InlineKeyboard: req.InlineKeyboard,
 	}
 
-	resp, err := h.service.SendMessage(c.Context(), sessionID, internal)
+	resp, err := h.service.SendMessage(c.Context(), userID, sessionID, internal)
 	if err != nil {
 		return handleMessageError(c, err)
 	}
@@ -83,9 +88,9 @@ func (h *MessageHandler) SendText(c *fiber.Ctx) error {
 // @Failure 400 {object} Response
 // @Router /sessions/{id}/messages/photo [post]
 func (h *MessageHandler) SendPhoto(c *fiber.Ctx) error {
-	sessionID, err := uuid.Parse(c.Params("id"))
-	if err != nil {
-		return c.Status(400).JSON(NewErrorResponse("INVALID_ID", "ID inválido"))
+	sessionID, userID, ok := parseSessionRoute(c)
+	if !ok {
+		return nil
 	}
 
 	var req domain.PhotoMessageRequest
@@ -98,13 +103,14 @@ func (h *MessageHandler) SendPhoto(c *fiber.Ctx) error {
 	}
 
 	internal := &domain.SendMessageRequest{
-		To:       req.To,
-		Type:     domain.MessageTypePhoto,
-		MediaURL: req.PhotoURL,
-		Caption:  req.Caption,
+		To:             req.To,
+		Type:           domain.MessageTypePhoto,
+		MediaURL:       req.PhotoURL,
+		Caption:        req.Caption,
+		InlineKeyboard: req.InlineKeyboard,
 	}
 
-	resp, err := h.service.SendMessage(c.Context(), sessionID, internal)
+	resp, err := h.service.SendMessage(c.Context(), userID, sessionID, internal)
 	if err != nil {
 		return handleMessageError(c, err)
 	}
@@ -125,9 +131,9 @@ func (h *MessageHandler) SendPhoto(c *fiber.Ctx) error {
 // @Failure 400 {object} Response
 // @Router /sessions/{id}/messages/video [post]
 func (h *MessageHandler) SendVideo(c *fiber.Ctx) error {
-	sessionID, err := uuid.Parse(c.Params("id"))
-	if err != nil {
-		return c.Status(400).JSON(NewErrorResponse("INVALID_ID", "ID inválido"))
+	sessionID, userID, ok := parseSessionRoute(c)
+	if !ok {
+		return nil
 	}
 
 	var req domain.VideoMessageRequest
@@ -140,13 +146,14 @@ func (h *MessageHandler) SendVideo(c *fiber.Ctx) error {
 	}
 
 	internal := &domain.SendMessageRequest{
-		To:       req.To,
-		Type:     domain.MessageTypeVideo,
-		MediaURL: req.VideoURL,
-		Caption:  req.Caption,
+		To:             req.To,
+		Type:           domain.MessageTypeVideo,
+		MediaURL:       req.VideoURL,
+		Caption:        req.Caption,
+		InlineKeyboard: req.InlineKeyboard,
 	}
 
-	resp, err := h.service.SendMessage(c.Context(), sessionID, internal)
+	resp, err := h.service.SendMessage(c.Context(), userID, sessionID, internal)
 	if err != nil {
 		return handleMessageError(c, err)
 	}
@@ -167,9 +174,9 @@ func (h *MessageHandler) SendVideo(c *fiber.Ctx) error {
 // @Failure 400 {object} Response
 // @Router /sessions/{id}/messages/audio [post]
 func (h *MessageHandler) SendAudio(c *fiber.Ctx) error {
-	sessionID, err := uuid.Parse(c.Params("id"))
-	if err != nil {
-		return c.Status(400).JSON(NewErrorResponse("INVALID_ID", "ID inválido"))
+	sessionID, userID, ok := parseSessionRoute(c)
+	if !ok {
+		return nil
 	}
 
 	var req domain.AudioMessageRequest
@@ -182,13 +189,14 @@ func (h *MessageHandler) SendAudio(c *fiber.Ctx) error {
 	}
 
 	internal := &domain.SendMessageRequest{
-		To:       req.To,
-		Type:     domain.MessageTypeAudio,
-		MediaURL: req.AudioURL,
-		Caption:  req.Caption,
+		To:             req.To,
+		Type:           domain.MessageTypeAudio,
+		MediaURL:       req.AudioURL,
+		Caption:        req.Caption,
+		InlineKeyboard: req.InlineKeyboard,
 	}
 
-	resp, err := h.service.SendMessage(c.Context(), sessionID, internal)
+	resp, err := h.service.SendMessage(c.Context(), userID, sessionID, internal)
 	if err != nil {
 		return handleMessageError(c, err)
 	}
@@ -209,9 +217,9 @@ func (h *MessageHandler) SendAudio(c *fiber.Ctx) error {
 // @Failure 400 {object} Response
 // @Router /sessions/{id}/messages/file [post]
 func (h *MessageHandler) SendFile(c *fiber.Ctx) error {
-	sessionID, err := uuid.Parse(c.Params("id"))
-	if err != nil {
-		return c.Status(400).JSON(NewErrorResponse("INVALID_ID", "ID inválido"))
+	sessionID, userID, ok := parseSessionRoute(c)
+	if !ok {
+		return nil
 	}
 
 	var req domain.FileMessageRequest
@@ -224,13 +232,14 @@ func (h *MessageHandler) SendFile(c *fiber.Ctx) error {
 	}
 
 	internal := &domain.SendMessageRequest{
-		To:       req.To,
-		Type:     domain.MessageTypeFile,
-		MediaURL: req.FileURL,
-		Caption:  req.Caption,
+		To:             req.To,
+		Type:           domain.MessageTypeFile,
+		MediaURL:       req.FileURL,
+		Caption:        req.Caption,
+		InlineKeyboard: req.InlineKeyboard,
 	}
 
-	resp, err := h.service.SendMessage(c.Context(), sessionID, internal)
+	resp, err := h.service.SendMessage(c.Context(), userID, sessionID, internal)
 	if err != nil {
 		return handleMessageError(c, err)
 	}
@@ -251,9 +260,9 @@ func (h *MessageHandler) SendFile(c *fiber.Ctx) error {
 // @Failure 400 {object} Response
 // @Router /sessions/{id}/messages/bulk [post]
 func (h *MessageHandler) SendBulk(c *fiber.Ctx) error {
-	sessionID, err := uuid.Parse(c.Params("id"))
-	if err != nil {
-		return c.Status(400).JSON(NewErrorResponse("INVALID_ID", "ID inválido"))
+	sessionID, userID, ok := parseSessionRoute(c)
+	if !ok {
+		return nil
 	}
 
 	var req domain.BulkTextRequest
@@ -272,7 +281,7 @@ func (h *MessageHandler) SendBulk(c *fiber.Ctx) error {
 		DelayMs:    req.DelayMs,
 	}
 
-	resp, err := h.service.SendBulk(c.Context(), sessionID, internal)
+	resp, err := h.service.SendBulk(c.Context(), userID, sessionID, internal)
 	if err != nil {
 		return handleMessageError(c, err)
 	}
@@ -291,9 +300,13 @@ func (h *MessageHandler) SendBulk(c *fiber.Ctx) error {
 // @Failure 404 {object} Response
 // @Router /messages/{jobId}/status [get]
 func (h *MessageHandler) GetStatus(c *fiber.Ctx) error {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		return c.Status(401).JSON(NewErrorResponse("UNAUTHORIZED", "No autorizado"))
+	}
 	jobID := c.Params("jobId")
 
-	job, err := h.service.GetJobStatus(c.Context(), jobID)
+	job, err := h.service.GetJobStatus(c.Context(), userID, middleware.GetAPIKey(c), jobID)
 	if err != nil {
 		return c.Status(404).JSON(NewErrorResponse("NOT_FOUND", "Job no encontrado"))
 	}
@@ -307,7 +320,16 @@ func handleMessageError(c *fiber.Ctx, err error) error {
 		return c.Status(404).JSON(NewErrorResponse("NOT_FOUND", "Sesión no encontrada"))
 	case domain.ErrSessionNotActive:
 		return c.Status(400).JSON(NewErrorResponse("SESSION_INACTIVE", "Sesión no autenticada"))
+	case domain.ErrUnauthorized:
+		return c.Status(403).JSON(NewErrorResponse("FORBIDDEN", "No tienes acceso a esta sesión"))
+	case domain.ErrInsufficientOrgRole:
+		return c.Status(403).JSON(NewErrorResponse("INSUFFICIENT_ROLE", err.Error()))
+	case domain.ErrBotOnly:
+		return c.Status(400).JSON(NewErrorResponse("BOT_ONLY", "inline_keyboard solo está disponible para sesiones de bot"))
 	default:
+		if errors.Is(err, domain.ErrQuotaExceeded) {
+			return handleErr(c, err)
+		}
 		if appErr, ok := err.(*domain.AppError); ok {
 			return c.Status(appErr.Status).JSON(NewErrorResponse(appErr.Code, appErr.Message))
 		}
@@ -0,0 +1,360 @@
+package handler
+
+import (
+	"telegram-api/internal/domain"
+	"telegram-api/internal/middleware"
+	"telegram-api/internal/service"
+	"telegram-api/pkg/logger"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type OrganizationHandler struct {
+	orgs *service.OrganizationService
+}
+
+func NewOrganizationHandler(orgs *service.OrganizationService) *OrganizationHandler {
+	return &OrganizationHandler{orgs: orgs}
+}
+
+func (h *OrganizationHandler) RegisterRoutes(r fiber.Router) {
+	orgs := r.Group("/organizations", requireJWT)
+	orgs.Post("/", h.Create)
+	orgs.Get("/", h.List)
+	orgs.Get("/:orgId", h.Get)
+	orgs.Put("/:orgId", h.Update)
+	orgs.Delete("/:orgId", h.Delete)
+	orgs.Get("/:orgId/members", h.ListMembers)
+	orgs.Post("/:orgId/members", h.AddMember)
+	orgs.Put("/:orgId/members/:userId", h.UpdateMember)
+	orgs.Delete("/:orgId/members/:userId", h.RemoveMember)
+
+	r.Put("/sessions/:id/organization", middleware.RequireScope(domain.ScopeSessionsWrite), h.TransferSession)
+}
+
+// Create godoc
+// @Summary Crear organización
+// @Description Crea una organización para compartir sesiones; quien la crea queda como owner
+// @Tags Organizations
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param body body domain.CreateOrganizationRequest true "Nombre"
+// @Success 201 {object} Response{data=domain.Organization}
+// @Failure 400 {object} Response
+// @Router /organizations [post]
+func (h *OrganizationHandler) Create(c *fiber.Ctx) error {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		return c.Status(401).JSON(NewErrorResponse("UNAUTHORIZED", "No autenticado"))
+	}
+
+	var req domain.CreateOrganizationRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(NewErrorResponse("INVALID_BODY", "JSON inválido"))
+	}
+	if errs := ValidateStruct(&req); errs != nil {
+		return c.Status(400).JSON(Response{Success: false, Error: &ErrorResponse{Code: "VALIDATION", Details: errs}})
+	}
+
+	org, err := h.orgs.Create(c.Context(), userID, &req)
+	if err != nil {
+		return handleOrganizationError(c, err)
+	}
+	return c.Status(201).JSON(NewSuccessResponse(org))
+}
+
+// List godoc
+// @Summary Listar organizaciones
+// @Description Organizaciones de las que el usuario es miembro, con su rol en cada una
+// @Tags Organizations
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} Response{data=[]domain.Organization}
+// @Router /organizations [get]
+func (h *OrganizationHandler) List(c *fiber.Ctx) error {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		return c.Status(401).JSON(NewErrorResponse("UNAUTHORIZED", "No autenticado"))
+	}
+
+	orgs, err := h.orgs.List(c.Context(), userID)
+	if err != nil {
+		return handleOrganizationError(c, err)
+	}
+	return c.JSON(NewSuccessResponse(orgs))
+}
+
+// Get godoc
+// @Summary Obtener organización
+// @Description Detalle de una organización de la que el usuario es miembro
+// @Tags Organizations
+// @Produce json
+// @Security BearerAuth
+// @Param orgId path string true "Organization ID"
+// @Success 200 {object} Response{data=domain.Organization}
+// @Failure 404 {object} Response
+// @Router /organizations/{orgId} [get]
+func (h *OrganizationHandler) Get(c *fiber.Ctx) error {
+	userID, orgID, ok := parseOrganizationRoute(c)
+	if !ok {
+		return nil
+	}
+
+	org, err := h.orgs.Get(c.Context(), userID, orgID)
+	if err != nil {
+		return handleOrganizationError(c, err)
+	}
+	return c.JSON(NewSuccessResponse(org))
+}
+
+// Update godoc
+// @Summary Renombrar organización
+// @Description Cambia el nombre de la organización (solo owners)
+// @Tags Organizations
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param orgId path string true "Organization ID"
+// @Param body body domain.UpdateOrganizationRequest true "Nombre"
+// @Success 200 {object} Response{data=domain.Organization}
+// @Failure 403 {object} Response
+// @Failure 404 {object} Response
+// @Router /organizations/{orgId} [put]
+func (h *OrganizationHandler) Update(c *fiber.Ctx) error {
+	userID, orgID, ok := parseOrganizationRoute(c)
+	if !ok {
+		return nil
+	}
+
+	var req domain.UpdateOrganizationRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(NewErrorResponse("INVALID_BODY", "JSON inválido"))
+	}
+	if errs := ValidateStruct(&req); errs != nil {
+		return c.Status(400).JSON(Response{Success: false, Error: &ErrorResponse{Code: "VALIDATION", Details: errs}})
+	}
+
+	org, err := h.orgs.Update(c.Context(), userID, orgID, &req)
+	if err != nil {
+		return handleOrganizationError(c, err)
+	}
+	return c.JSON(NewSuccessResponse(org))
+}
+
+// Delete godoc
+// @Summary Eliminar organización
+// @Description Elimina la organización (solo owners). Sus sesiones vuelven a ser personales de su responsable.
+// @Tags Organizations
+// @Produce json
+// @Security BearerAuth
+// @Param orgId path string true "Organization ID"
+// @Success 200 {object} Response
+// @Failure 403 {object} Response
+// @Failure 404 {object} Response
+// @Router /organizations/{orgId} [delete]
+func (h *OrganizationHandler) Delete(c *fiber.Ctx) error {
+	userID, orgID, ok := parseOrganizationRoute(c)
+	if !ok {
+		return nil
+	}
+
+	if err := h.orgs.Delete(c.Context(), userID, orgID); err != nil {
+		return handleOrganizationError(c, err)
+	}
+	return c.JSON(NewSuccessResponse(fiber.Map{"deleted": true}))
+}
+
+// ListMembers godoc
+// @Summary Listar miembros
+// @Description Miembros de la organización y sus roles
+// @Tags Organizations
+// @Produce json
+// @Security BearerAuth
+// @Param orgId path string true "Organization ID"
+// @Success 200 {object} Response{data=[]domain.OrganizationMember}
+// @Failure 404 {object} Response
+// @Router /organizations/{orgId}/members [get]
+func (h *OrganizationHandler) ListMembers(c *fiber.Ctx) error {
+	userID, orgID, ok := parseOrganizationRoute(c)
+	if !ok {
+		return nil
+	}
+
+	members, err := h.orgs.ListMembers(c.Context(), userID, orgID)
+	if err != nil {
+		return handleOrganizationError(c, err)
+	}
+	return c.JSON(NewSuccessResponse(members))
+}
+
+// AddMember godoc
+// @Summary Añadir miembro
+// @Description Añade un usuario existente (por username o email) con un rol: owner, operator o viewer (solo owners)
+// @Tags Organizations
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param orgId path string true "Organization ID"
+// @Param body body domain.AddMemberRequest true "Usuario y rol"
+// @Success 201 {object} Response{data=domain.OrganizationMember}
+// @Failure 404 {object} Response
+// @Failure 409 {object} Response
+// @Router /organizations/{orgId}/members [post]
+func (h *OrganizationHandler) AddMember(c *fiber.Ctx) error {
+	userID, orgID, ok := parseOrganizationRoute(c)
+	if !ok {
+		return nil
+	}
+
+	var req domain.AddMemberRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(NewErrorResponse("INVALID_BODY", "JSON inválido"))
+	}
+	if errs := ValidateStruct(&req); errs != nil {
+		return c.Status(400).JSON(Response{Success: false, Error: &ErrorResponse{Code: "VALIDATION", Details: errs}})
+	}
+	if req.Username == "" && req.Email == "" {
+		return c.Status(400).JSON(NewErrorResponse("VALIDATION", "Se requiere username o email"))
+	}
+
+	member, err := h.orgs.AddMember(c.Context(), userID, orgID, &req)
+	if err != nil {
+		return handleOrganizationError(c, err)
+	}
+	return c.Status(201).JSON(NewSuccessResponse(member))
+}
+
+// UpdateMember godoc
+// @Summary Cambiar rol de un miembro
+// @Description Cambia el rol de un miembro (solo owners). La organización debe conservar al menos un owner.
+// @Tags Organizations
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param orgId path string true "Organization ID"
+// @Param userId path string true "User ID del miembro"
+// @Param body body domain.UpdateMemberRoleRequest true "Rol"
+// @Success 200 {object} Response{data=domain.OrganizationMember}
+// @Failure 404 {object} Response
+// @Failure 409 {object} Response
+// @Router /organizations/{orgId}/members/{userId} [put]
+func (h *OrganizationHandler) UpdateMember(c *fiber.Ctx) error {
+	userID, orgID, ok := parseOrganizationRoute(c)
+	if !ok {
+		return nil
+	}
+	memberID, err := uuid.Parse(c.Params("userId"))
+	if err != nil {
+		return c.Status(400).JSON(NewErrorResponse("INVALID_ID", "ID de usuario inválido"))
+	}
+
+	var req domain.UpdateMemberRoleRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(NewErrorResponse("INVALID_BODY", "JSON inválido"))
+	}
+	if errs := ValidateStruct(&req); errs != nil {
+		return c.Status(400).JSON(Response{Success: false, Error: &ErrorResponse{Code: "VALIDATION", Details: errs}})
+	}
+
+	member, err := h.orgs.UpdateMemberRole(c.Context(), userID, orgID, memberID, req.Role)
+	if err != nil {
+		return handleOrganizationError(c, err)
+	}
+	return c.JSON(NewSuccessResponse(member))
+}
+
+// RemoveMember godoc
+// @Summary Quitar miembro
+// @Description Saca a un miembro (solo owners) o abandona la organización (userId propio)
+// @Tags Organizations
+// @Produce json
+// @Security BearerAuth
+// @Param orgId path string true "Organization ID"
+// @Param userId path string true "User ID del miembro"
+// @Success 200 {object} Response
+// @Failure 404 {object} Response
+// @Failure 409 {object} Response
+// @Router /organizations/{orgId}/members/{userId} [delete]
+func (h *OrganizationHandler) RemoveMember(c *fiber.Ctx) error {
+	userID, orgID, ok := parseOrganizationRoute(c)
+	if !ok {
+		return nil
+	}
+	memberID, err := uuid.Parse(c.Params("userId"))
+	if err != nil {
+		return c.Status(400).JSON(NewErrorResponse("INVALID_ID", "ID de usuario inválido"))
+	}
+
+	if err := h.orgs.RemoveMember(c.Context(), userID, orgID, memberID); err != nil {
+		return handleOrganizationError(c, err)
+	}
+	return c.JSON(NewSuccessResponse(fiber.Map{"removed": true}))
+}
+
+// TransferSession godoc
+// @Summary Transferir sesión
+// @Description Mueve la sesión a una organización (los miembros la usan según su rol) o, con organization_id null, la vuelve personal. Requiere ser owner de la sesión y al menos operator en la organización de destino.
+// @Tags Organizations
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Session ID"
+// @Param body body domain.TransferSessionRequest true "Organización de destino"
+// @Success 200 {object} Response{data=domain.TelegramSession}
+// @Failure 403 {object} Response
+// @Failure 404 {object} Response
+// @Router /sessions/{id}/organization [put]
+func (h *OrganizationHandler) TransferSession(c *fiber.Ctx) error {
+	sessionID, userID, ok := parseSessionRoute(c)
+	if !ok {
+		return nil
+	}
+
+	var req domain.TransferSessionRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(NewErrorResponse("INVALID_BODY", "JSON inválido"))
+	}
+
+	session, err := h.orgs.TransferSession(c.Context(), userID, sessionID, &req)
+	if err != nil {
+		return handleOrganizationError(c, err)
+	}
+	return c.JSON(NewSuccessResponse(session))
+}
+
+func parseOrganizationRoute(c *fiber.Ctx) (uuid.UUID, uuid.UUID, bool) {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		_ = c.Status(401).JSON(NewErrorResponse("UNAUTHORIZED", "No autenticado"))
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	orgID, err := uuid.Parse(c.Params("orgId"))
+	if err != nil {
+		_ = c.Status(400).JSON(NewErrorResponse("INVALID_ID", "ID de organización inválido"))
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	return userID, orgID, true
+}
+
+func handleOrganizationError(c *fiber.Ctx, err error) error {
+	switch err {
+	case domain.ErrOrganizationNotFound, domain.ErrMemberNotFound, domain.ErrUserNotFound,
+		domain.ErrSessionNotFound:
+		return c.Status(404).JSON(NewErrorResponse("NOT_FOUND", err.Error()))
+	case domain.ErrAlreadyMember, domain.ErrLastOwner, domain.ErrSessionAlreadyExists:
+		return c.Status(409).JSON(NewErrorResponse("CONFLICT", err.Error()))
+	case domain.ErrUnauthorized:
+		return c.Status(403).JSON(NewErrorResponse("FORBIDDEN", "No tienes acceso a esta sesión"))
+	case domain.ErrInsufficientOrgRole:
+		return c.Status(403).JSON(NewErrorResponse("INSUFFICIENT_ROLE", err.Error()))
+	case domain.ErrInvalidInput:
+		return c.Status(400).JSON(NewErrorResponse("VALIDATION", err.Error()))
+	default:
+		logger.Error().Err(err).Msg("❌ Error en organizaciones")
+		return c.Status(500).JSON(NewErrorResponse("INTERNAL", "Error interno"))
+	}
+}
@@ -0,0 +1,38 @@
+package handler
+
+import (
+	"telegram-api/internal/middleware"
+	"telegram-api/internal/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type QuotaHandler struct {
+	quotas *service.QuotaService
+}
+
+func NewQuotaHandler(quotas *service.QuotaService) *QuotaHandler {
+	return &QuotaHandler{quotas: quotas}
+}
+
+func (h *QuotaHandler) RegisterRoutes(r fiber.Router) {
+	r.Get("/me/usage", h.Usage)
+}
+
+// Usage godoc
+// @Summary Consumo y cuotas propias
+// @Description Consumo del usuario autenticado en el día y mes actuales (UTC) junto a sus cuotas efectivas. Un límite 0 significa sin límite. Con API key se informa el consumo de su propietario.
+// @Tags Auth
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} Response{data=domain.QuotaUsage}
+// @Failure 401 {object} Response
+// @Router /me/usage [get]
+func (h *QuotaHandler) Usage(c *fiber.Ctx) error {
+	userID, _ := middleware.GetUserID(c)
+	usage, err := h.quotas.Usage(c.Context(), userID)
+	if err != nil {
+		return handleErr(c, err)
+	}
+	return c.JSON(NewSuccessResponse(usage))
+}
@@ -1,7 +1,10 @@
 package handler
 
 import (
+	"encoding/base64"
+	"errors"
 	"fmt"
+	"io"
 
 	"telegram-api/internal/domain"
 	"telegram-api/internal/middleware"
@@ -9,7 +12,6 @@ import (
 	"telegram-api/pkg/logger"
 
 	"github.com/gofiber/fiber/v2"
-	"github.com/google/uuid"
 )
 type SessionHandler struct {
 	service *service.SessionService
@@ -20,18 +22,24 @@ func NewSessionHandler(s *service.SessionService) *SessionHandler {
 }
 
 func (h *SessionHandler) RegisterRoutes(r fiber.Router) {
+	read := middleware.RequireScope(domain.ScopeSessionsRead)
+	write := middleware.RequireScope(domain.ScopeSessionsWrite)
+
 	sessions := r.Group("/sessions")
-	sessions.Post("/", h.Create)
-	sessions.Post("/:id/verify", h.VerifyCode)
-	sessions.Post("/:id/qr/regenerate", h.RegenerateQR) // ✅ NUEVA RUTA
-	sessions.Get("/", h.List)
-	sessions.Get("/:id", h.Get)
-	sessions.Delete("/:id", h.Delete)
+	sessions.Post("/", write, h.Create)
+	sessions.Post("/import", write, h.Import)
+	sessions.Post("/import/bundle", write, h.ImportBundle)
+	sessions.Post("/:id/verify", write, h.VerifyCode)
+	sessions.Post("/:id/qr/regenerate", write, h.RegenerateQR) // ✅ NUEVA RUTA
+	sessions.Get("/", read, h.List)
+	sessions.Get("/:id", read, h.Get)
+	sessions.Get("/:id/export", write, h.Export)
+	sessions.Delete("/:id", write, h.Delete)
 }
 
 // Create godoc
 // @Summary Crear sesión Telegram
-// @Description Inicia autenticación con Telegram (SMS o QR). Para QR, el sistema escucha automáticamente en background.
+// @Description Inicia autenticación con Telegram (SMS, QR o bot). Para QR, el sistema escucha automáticamente en background. Con auth_method=bot y bot_token la sesión queda autenticada de inmediato.
 // @Tags Sessions
 // @Accept json
 // @Produce json
@@ -74,10 +82,13 @@ func (h *SessionHandler) Create(c *fiber.Ctx) error {
 		"session": session,
 	}
 
-	if req.AuthMethod == domain.AuthMethodQR {
+	switch req.AuthMethod {
+	case domain.AuthMethodQR:
 		response["qr_image_base64"] = data
 		response["message"] = "QR generado. El sistema escucha automáticamente (3 intentos, 2 min c/u). Use GET /sessions/:id para verificar estado."
-	} else {
+	case domain.AuthMethodBot:
+		response["message"] = "Bot autenticado. Configure un webhook para recibir mensajes y callback queries."
+	default:
 		response["phone_code_hash"] = data
 		response["next_step"] = "POST /sessions/" + session.ID.String() + "/verify con {code}"
 	}
@@ -85,6 +96,157 @@ func (h *SessionHandler) Create(c *fiber.Ctx) error {
 	return c.Status(201).JSON(NewSuccessResponse(response))
 }
 
+// Import godoc
+// @Summary Importar sesión existente
+// @Description Importa una sesión autenticada de Telethon (StringSession o archivo .session), Pyrogram (session string), Telegram Desktop (tdata en zip) o gotd (JSON). Se valida con users.getSelf y se guarda cifrada. Los formatos binarios se envían en base64 en "session" o como archivo multipart en "file".
+// @Tags Sessions
+// @Accept json,mpfd
+// @Produce json
+// @Security BearerAuth
+// @Param body body domain.ImportSessionRequest true "Sesión a importar"
+// @Success 201 {object} handler.Response{data=domain.TelegramSession}
+// @Failure 400 {object} handler.Response
+// @Failure 409 {object} handler.Response
+// @Router /sessions/import [post]
+func (h *SessionHandler) Import(c *fiber.Ctx) error {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		return c.Status(401).JSON(NewErrorResponse("UNAUTHORIZED", "No autenticado"))
+	}
+
+	var req domain.ImportSessionRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(NewErrorResponse("INVALID_BODY", "Body inválido"))
+	}
+	if errs := ValidateStruct(&req); errs != nil {
+		return c.Status(400).JSON(Response{Success: false, Error: &ErrorResponse{Code: "VALIDATION", Details: errs}})
+	}
+
+	raw, err := importPayload(c, &req)
+	if err != nil {
+		return c.Status(400).JSON(NewErrorResponse("INVALID_SESSION", err.Error()))
+	}
+
+	session, err := h.service.ImportSession(c.Context(), userID, &req, raw)
+	if err != nil {
+		return handleSessionError(c, err)
+	}
+
+	return c.Status(201).JSON(NewSuccessResponse(session))
+}
+
+// importPayload obtiene los datos de la sesión del archivo multipart o del campo
+// "session" (base64 para formatos binarios)
+func importPayload(c *fiber.Ctx, req *domain.ImportSessionRequest) ([]byte, error) {
+	if fh, err := c.FormFile("file"); err == nil {
+		f, err := fh.Open()
+		if err != nil {
+			return nil, fmt.Errorf("no se pudo leer el archivo")
+		}
+		defer f.Close()
+		return io.ReadAll(f)
+	}
+
+	if req.Session == "" {
+		return nil, fmt.Errorf("se requiere el campo session o un archivo")
+	}
+
+	switch req.Format {
+	case domain.ImportTelethonSQLite, domain.ImportTDesktop:
+		raw, err := base64.StdEncoding.DecodeString(req.Session)
+		if err != nil {
+			return nil, fmt.Errorf("session debe ir en base64 para el formato %s", req.Format)
+		}
+		return raw, nil
+	}
+	return []byte(req.Session), nil
+}
+
+// Export godoc
+// @Summary Exportar sesión
+// @Description Descarga un bundle cifrado con la passphrase indicada (argon2id + AES-256-GCM) con los datos de sesión, api_id, api_hash, metadatos y configuración de webhook. Cada exportación queda auditada.
+// @Tags Sessions
+// @Produce octet-stream
+// @Security BearerAuth
+// @Param id path string true "Session ID"
+// @Param X-Export-Passphrase header string true "Passphrase (mínimo 12 caracteres)"
+// @Success 200 {file} file
+// @Failure 400 {object} handler.Response
+// @Failure 403 {object} handler.Response
+// @Router /sessions/{id}/export [get]
+func (h *SessionHandler) Export(c *fiber.Ctx) error {
+	sessionID, userID, ok := parseSessionRoute(c)
+	if !ok {
+		return nil
+	}
+
+	passphrase := c.Get("X-Export-Passphrase")
+	if len(passphrase) < domain.MinExportPassphrase {
+		return c.Status(400).JSON(NewErrorResponse("INVALID_PASSPHRASE",
+			fmt.Sprintf("X-Export-Passphrase debe tener al menos %d caracteres", domain.MinExportPassphrase)))
+	}
+
+	bundle, _, err := h.service.ExportSession(c.Context(), userID, sessionID, passphrase)
+	if err != nil {
+		return handleSessionError(c, err)
+	}
+
+	c.Set(fiber.HeaderContentType, fiber.MIMEOctetStream)
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="session-%s.tgbundle"`, sessionID))
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	return c.Send(bundle)
+}
+
+// ImportBundle godoc
+// @Summary Importar bundle de sesión
+// @Description Restaura un bundle generado por GET /sessions/{id}/export. La sesión se valida con users.getSelf, se re-cifra con la clave local y se recrea el webhook si venía incluido. El bundle se envía en base64 en "bundle" o como archivo multipart en "file".
+// @Tags Sessions
+// @Accept json,mpfd
+// @Produce json
+// @Security BearerAuth
+// @Param body body domain.ImportBundleRequest true "Bundle y passphrase"
+// @Success 201 {object} handler.Response{data=domain.TelegramSession}
+// @Failure 400 {object} handler.Response
+// @Failure 409 {object} handler.Response
+// @Router /sessions/import/bundle [post]
+func (h *SessionHandler) ImportBundle(c *fiber.Ctx) error {
+	userID, err := middleware.GetUserID(c)
+	if err != nil {
+		return c.Status(401).JSON(NewErrorResponse("UNAUTHORIZED", "No autenticado"))
+	}
+
+	var req domain.ImportBundleRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(NewErrorResponse("INVALID_BODY", "Body inválido"))
+	}
+	if errs := ValidateStruct(&req); errs != nil {
+		return c.Status(400).JSON(Response{Success: false, Error: &ErrorResponse{Code: "VALIDATION", Details: errs}})
+	}
+
+	var raw []byte
+	if fh, err := c.FormFile("file"); err == nil {
+		f, err := fh.Open()
+		if err != nil {
+			return c.Status(400).JSON(NewErrorResponse("INVALID_SESSION", "No se pudo leer el archivo"))
+		}
+		defer f.Close()
+		if raw, err = io.ReadAll(f); err != nil {
+			return c.Status(400).JSON(NewErrorResponse("INVALID_SESSION", "No se pudo leer el archivo"))
+		}
+	} else {
+		if raw, err = base64.StdEncoding.DecodeString(req.Bundle); err != nil || len(raw) == 0 {
+			return c.Status(400).JSON(NewErrorResponse("INVALID_SESSION", "Se requiere bundle en base64 o un archivo"))
+		}
+	}
+
+	session, err := h.service.ImportBundle(c.Context(), userID, raw, req.Passphrase, req.SessionName)
+	if err != nil {
+		return handleSessionError(c, err)
+	}
+
+	return c.Status(201).JSON(NewSuccessResponse(session))
+}
+
 // VerifyCode godoc
 // @Summary Verificar código SMS
 // @Description Completa autenticación con el código recibido por SMS
@@ -99,9 +261,9 @@ func (h *SessionHandler) Create(c *fiber.Ctx) error {
 // @Failure 410 {object} handler.Response
 // @Router /sessions/{id}/verify [post]
 func (h *SessionHandler) VerifyCode(c *fiber.Ctx) error {
-	sessionID, err := uuid.Parse(c.Params("id"))
-	if err != nil {
-		return c.Status(400).JSON(NewErrorResponse("INVALID_ID", "ID inválido"))
+	sessionID, userID, ok := parseSessionRoute(c)
+	if !ok {
+		return nil
 	}
 
 	var req domain.VerifyCodeRequest
@@ -109,7 +271,7 @@ func (h *SessionHandler) VerifyCode(c *fiber.Ctx) error {
 		return c.Status(400).JSON(NewErrorResponse("INVALID_BODY", "JSON inválido"))
 	}
 
-	session, err := h.service.VerifyCode(c.Context(), sessionID, req.Code)
+	session, err := h.service.VerifyCode(c.Context(), userID, sessionID, req.Code)
 	if err != nil {
 		return handleSessionError(c, err)
 	}
@@ -151,12 +313,12 @@ func (h *SessionHandler) List(c *fiber.Ctx) error {
 // @Failure 404 {object} handler.Response
 // @Router /sessions/{id} [get]
 func (h *SessionHandler) Get(c *fiber.Ctx) error {
-	sessionID, err := uuid.Parse(c.Params("id"))
-	if err != nil {
-		return c.Status(400).JSON(NewErrorResponse("INVALID_ID", "ID inválido"))
+	sessionID, userID, ok := parseSessionRoute(c)
+	if !ok {
+		return nil
 	}
 
-	session, err := h.service.GetSession(c.Context(), sessionID)
+	session, err := h.service.GetSession(c.Context(), userID, sessionID)
 	if err != nil {
 		return handleSessionError(c, err)
 	}
@@ -192,12 +354,12 @@ func (h *SessionHandler) Get(c *fiber.Ctx) error {
 // @Failure 404 {object} handler.Response
 // @Router /sessions/{id} [delete]
 func (h *SessionHandler) Delete(c *fiber.Ctx) error {
-	sessionID, err := uuid.Parse(c.Params("id"))
-	if err != nil {
-		return c.Status(400).JSON(NewErrorResponse("INVALID_ID", "ID inválido"))
+	sessionID, userID, ok := parseSessionRoute(c)
+	if !ok {
+		return nil
 	}
 
-	if err := h.service.DeleteSession(c.Context(), sessionID); err != nil {
+	if err := h.service.DeleteSession(c.Context(), userID, sessionID); err != nil {
 		return handleSessionError(c, err)
 	}
 
@@ -217,6 +379,16 @@ func handleSessionError(c *fiber.Ctx, err error) error {
 		return c.Status(400).JSON(NewErrorResponse("INVALID_CODE", "Código incorrecto"))
 	case domain.ErrInvalidPhoneNumber:
 		return c.Status(400).JSON(NewErrorResponse("INVALID_PHONE", "Número de teléfono requerido para SMS"))
+	case domain.ErrInvalidBotToken:
+		return c.Status(400).JSON(NewErrorResponse("INVALID_BOT_TOKEN", "Token de bot inválido"))
+	case domain.ErrSessionRevoked:
+		return c.Status(400).JSON(NewErrorResponse("SESSION_REVOKED", "La sesión fue revocada o cerrada en Telegram"))
+	case domain.ErrUnauthorized:
+		return c.Status(403).JSON(NewErrorResponse("FORBIDDEN", "La sesión no pertenece al usuario"))
+	case domain.ErrInsufficientOrgRole:
+		return c.Status(403).JSON(NewErrorResponse("INSUFFICIENT_ROLE", err.Error()))
+	case domain.ErrSessionInactive:
+		return c.Status(400).JSON(NewErrorResponse("SESSION_INACTIVE", "La sesión no está autenticada"))
 	case domain.ErrDatabase:
 		logger.Error().Err(err).Msg("❌ Error de base de datos en sesión")
 		return c.Status(500).JSON(NewErrorResponse("DATABASE", "Error de base de datos"))
@@ -225,6 +397,14 @@ func handleSessionError(c *fiber.Ctx, err error) error {
 		return c.Status(500).JSON(NewErrorResponse("INTERNAL", "Error interno"))
 	}
 
+	if errors.Is(err, domain.ErrQuotaExceeded) {
+		return handleErr(c, err)
+	}
+
+	if errors.Is(err, domain.ErrInvalidSessionData) {
+		return c.Status(400).JSON(NewErrorResponse("INVALID_SESSION", err.Error()))
+	}
+
 	// Verificar si es AppError
 	if appErr, ok := err.(*domain.AppError); ok {
 		logger.Error().
@@ -256,12 +436,12 @@ func handleSessionError(c *fiber.Ctx, err error) error {
 // @Failure 404 {object} handler.Response
 // @Router /sessions/{id}/qr/regenerate [post]
 func (h *SessionHandler) RegenerateQR(c *fiber.Ctx) error {
-	sessionID, err := uuid.Parse(c.Params("id"))
-	if err != nil {
-		return c.Status(400).JSON(NewErrorResponse("INVALID_ID", "ID inválido"))
+	sessionID, userID, ok := parseSessionRoute(c)
+	if !ok {
+		return nil
 	}
 
-	qrImageB64, err := h.service.RegenerateQR(c.Context(), sessionID)
+	qrImageB64, err := h.service.RegenerateQR(c.Context(), userID, sessionID)
 	if err != nil {
 		return handleSessionError(c, err)
 	}
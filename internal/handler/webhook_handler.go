@@ -1,10 +1,15 @@
 package handler
 
 import (
+	"errors"
 	"time"
 
 	"telegram-api/internal/domain"
+	"telegram-api/internal/middleware"
+	"telegram-api/internal/service"
 	"telegram-api/internal/telegram"
+	"telegram-api/pkg/crypto"
+	"telegram-api/pkg/logger"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
@@ -12,37 +17,44 @@ import (
 
 type WebhookHandler struct {
 	webhookRepo domain.WebhookRepository
-	sessionRepo domain.SessionRepository
+	orgs        *service.OrganizationService
 	pool        *telegram.SessionPool
+	audit       *service.AuditService
 }
 
 func NewWebhookHandler(
 	webhookRepo domain.WebhookRepository,
-	sessionRepo domain.SessionRepository,
+	orgs *service.OrganizationService,
 	pool *telegram.SessionPool,
+	audit *service.AuditService,
 ) *WebhookHandler {
 	return &WebhookHandler{
 		webhookRepo: webhookRepo,
-		sessionRepo: sessionRepo,
+		orgs:        orgs,
 		pool:        pool,
+		audit:       audit,
 	}
 }
 
 func (h *WebhookHandler) RegisterRoutes(r fiber.Router) {
+	read := middleware.RequireScope(domain.ScopeWebhooksRead)
+	write := middleware.RequireScope(domain.ScopeWebhooksWrite)
+
 	wh := r.Group("/sessions/:id/webhook")
-	wh.Post("/", h.Configure)
-	wh.Get("/", h.Get)
-	wh.Delete("/", h.Delete)
-	wh.Post("/start", h.StartListening)
-	wh.Post("/stop", h.StopListening)
+	wh.Post("/", write, h.Configure)
+	wh.Get("/", read, h.Get)
+	wh.Delete("/", write, h.Delete)
+	wh.Post("/rotate-secret", write, h.RotateSecret)
+	wh.Post("/start", write, h.StartListening)
+	wh.Post("/stop", write, h.StopListening)
 
 	// Info del pool
-	r.Get("/pool/status", h.PoolStatus)
+	r.Get("/pool/status", middleware.RequireScope(domain.ScopeSessionsRead), h.PoolStatus)
 }
 
 // Configure godoc
 // @Summary Configurar webhook
-// @Description Configura URL de webhook para recibir eventos de la sesión
+// @Description Configura URL de webhook para recibir eventos de la sesión. El secreto se guarda cifrado y solo se devuelve en esta respuesta.
 // @Tags Webhooks
 // @Accept json
 // @Produce json
@@ -52,16 +64,12 @@ func (h *WebhookHandler) RegisterRoutes(r fiber.Router) {
 // @Success 200 {object} Response{data=domain.WebhookResponse}
 // @Router /sessions/{id}/webhook [post]
 func (h *WebhookHandler) Configure(c *fiber.Ctx) error {
-	sessionID, err := uuid.Parse(c.Params("id"))
-	if err != nil {
-		return c.Status(400).JSON(NewErrorResponse("INVALID_ID", "ID inválido"))
-	}
-
-	// Verificar que la sesión existe y está activa
-	sess, err := h.sessionRepo.GetByID(c.Context(), sessionID)
-	if err != nil {
-		return c.Status(404).JSON(NewErrorResponse("NOT_FOUND", "Sesión no encontrada"))
+	// Solo un owner decide adónde se envían los eventos de la cuenta
+	sess, ok := h.authorizeSession(c, domain.OrgRoleOwner)
+	if !ok {
+		return nil
 	}
+	sessionID := sess.ID
 	if !sess.IsActive {
 		return c.Status(400).JSON(NewErrorResponse("SESSION_INACTIVE", "La sesión no está autenticada"))
 	}
@@ -97,16 +105,101 @@ func (h *WebhookHandler) Configure(c *fiber.Ctx) error {
 		UpdatedAt:  now,
 	}
 
-	if err := h.webhookRepo.Create(c.Context(), webhook); err != nil {
+	err := h.webhookRepo.Create(c.Context(), webhook)
+	h.audit.RecordAction(c.Context(), domain.AuditWebhookConfigure, &sessionID, err, map[string]any{
+		"url":    webhook.URL,
+		"events": webhook.Events,
+	})
+	if err != nil {
 		return c.Status(500).JSON(NewErrorResponse("INTERNAL", "Error guardando webhook"))
 	}
 
+	// El secreto solo se devuelve en esta respuesta; después solo la pista
 	return c.JSON(NewSuccessResponse(domain.WebhookResponse{
-		ID:        webhook.ID,
-		SessionID: sessionID,
-		URL:       webhook.URL,
-		Events:    webhook.Events,
-		IsActive:  webhook.IsActive,
+		ID:         webhook.ID,
+		SessionID:  sessionID,
+		URL:        webhook.URL,
+		Secret:     webhook.Secret,
+		SecretHint: webhook.SecretHint,
+		Events:     webhook.Events,
+		IsActive:   webhook.IsActive,
+	}))
+}
+
+// RotateSecret godoc
+// @Summary Rotar secreto del webhook
+// @Description Reemplaza el secreto de firma (generado si no se envía). Durante overlap_seconds (default 24h) cada entrega se firma también con el secreto anterior en X-Telegram-Signature-Previous. El nuevo secreto solo se muestra en esta respuesta.
+// @Tags Webhooks
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Session ID"
+// @Param body body domain.RotateSecretRequest false "Nuevo secreto y ventana de solapamiento"
+// @Success 200 {object} Response{data=domain.RotateSecretResponse}
+// @Failure 404 {object} Response
+// @Router /sessions/{id}/webhook/rotate-secret [post]
+func (h *WebhookHandler) RotateSecret(c *fiber.Ctx) error {
+	sess, ok := h.authorizeSession(c, domain.OrgRoleOwner)
+	if !ok {
+		return nil
+	}
+	sessionID := sess.ID
+
+	var req domain.RotateSecretRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(400).JSON(NewErrorResponse("INVALID_BODY", "JSON inválido"))
+		}
+	}
+	if errs := ValidateStruct(&req); errs != nil {
+		return c.Status(400).JSON(Response{Success: false, Error: &ErrorResponse{Code: "VALIDATION", Details: errs}})
+	}
+
+	var err error
+	secret := req.Secret
+	if secret == "" {
+		if secret, err = crypto.GenerateRandomHex(64); err != nil {
+			return c.Status(500).JSON(NewErrorResponse("INTERNAL", "Error generando secreto"))
+		}
+	}
+
+	overlap := domain.DefaultSecretOverlap
+	if req.OverlapSeconds != nil {
+		overlap = time.Duration(*req.OverlapSeconds) * time.Second
+	}
+	var previousExpiresAt *time.Time
+	if overlap > 0 {
+		t := time.Now().Add(overlap)
+		previousExpiresAt = &t
+	}
+
+	err = h.webhookRepo.RotateSecret(c.Context(), sessionID, secret, previousExpiresAt)
+	h.audit.RecordAction(c.Context(), domain.AuditWebhookRotate, &sessionID, err, map[string]any{
+		"overlap_seconds": int(overlap.Seconds()),
+	})
+	if errors.Is(err, domain.ErrWebhookNotFound) {
+		return c.Status(404).JSON(NewErrorResponse("NOT_FOUND", "Webhook no configurado"))
+	}
+	if err != nil {
+		logger.Error().Err(err).Str("session_id", sessionID.String()).Msg("❌ Error rotando secreto de webhook")
+		return c.Status(500).JSON(NewErrorResponse("INTERNAL", "Error rotando secreto"))
+	}
+
+	// Releer para devolver la expiración real (sin secreto previo no hay solapamiento)
+	webhook, err := h.webhookRepo.GetBySessionID(c.Context(), sessionID)
+	if err != nil || webhook == nil {
+		return c.Status(500).JSON(NewErrorResponse("INTERNAL", "Error obteniendo webhook"))
+	}
+
+	logger.Info().
+		Str("session_id", sessionID.String()).
+		Dur("overlap", overlap).
+		Msg("🔑 Secreto de webhook rotado")
+
+	return c.JSON(NewSuccessResponse(domain.RotateSecretResponse{
+		Secret:                  secret,
+		SecretHint:              webhook.SecretHint,
+		PreviousSecretExpiresAt: webhook.PreviousSecretExpiresAt,
 	}))
 }
 
@@ -120,10 +213,11 @@ func (h *WebhookHandler) Configure(c *fiber.Ctx) error {
 // @Success 200 {object} Response{data=domain.WebhookConfig}
 // @Router /sessions/{id}/webhook [get]
 func (h *WebhookHandler) Get(c *fiber.Ctx) error {
-	sessionID, err := uuid.Parse(c.Params("id"))
-	if err != nil {
-		return c.Status(400).JSON(NewErrorResponse("INVALID_ID", "ID inválido"))
+	sess, ok := h.authorizeSession(c, domain.OrgRoleViewer)
+	if !ok {
+		return nil
 	}
+	sessionID := sess.ID
 
 	webhook, err := h.webhookRepo.GetBySessionID(c.Context(), sessionID)
 	if err != nil {
@@ -146,15 +240,18 @@ func (h *WebhookHandler) Get(c *fiber.Ctx) error {
 // @Success 200 {object} Response
 // @Router /sessions/{id}/webhook [delete]
 func (h *WebhookHandler) Delete(c *fiber.Ctx) error {
-	sessionID, err := uuid.Parse(c.Params("id"))
-	if err != nil {
-		return c.Status(400).JSON(NewErrorResponse("INVALID_ID", "ID inválido"))
+	sess, ok := h.authorizeSession(c, domain.OrgRoleOwner)
+	if !ok {
+		return nil
 	}
+	sessionID := sess.ID
 
 	// Detener escucha si está activa
 	h.pool.StopSession(sessionID)
 
-	if err := h.webhookRepo.Delete(c.Context(), sessionID); err != nil {
+	err := h.webhookRepo.Delete(c.Context(), sessionID)
+	h.audit.RecordAction(c.Context(), domain.AuditWebhookDelete, &sessionID, err, nil)
+	if err != nil {
 		return c.Status(500).JSON(NewErrorResponse("INTERNAL", "Error eliminando webhook"))
 	}
 
@@ -171,15 +268,11 @@ func (h *WebhookHandler) Delete(c *fiber.Ctx) error {
 // @Success 200 {object} Response
 // @Router /sessions/{id}/webhook/start [post]
 func (h *WebhookHandler) StartListening(c *fiber.Ctx) error {
-	sessionID, err := uuid.Parse(c.Params("id"))
-	if err != nil {
-		return c.Status(400).JSON(NewErrorResponse("INVALID_ID", "ID inválido"))
-	}
-
-	sess, err := h.sessionRepo.GetByID(c.Context(), sessionID)
-	if err != nil {
-		return c.Status(404).JSON(NewErrorResponse("NOT_FOUND", "Sesión no encontrada"))
+	sess, ok := h.authorizeSession(c, domain.OrgRoleOperator)
+	if !ok {
+		return nil
 	}
+	sessionID := sess.ID
 	if !sess.IsActive {
 		return c.Status(400).JSON(NewErrorResponse("SESSION_INACTIVE", "La sesión no está autenticada"))
 	}
@@ -190,7 +283,11 @@ func (h *WebhookHandler) StartListening(c *fiber.Ctx) error {
 		return c.Status(400).JSON(NewErrorResponse("NO_WEBHOOK", "Configura un webhook primero"))
 	}
 
-	if err := h.pool.StartSession(c.Context(), sess); err != nil {
+	err := h.pool.StartSession(c.Context(), sess)
+	h.audit.RecordAction(c.Context(), domain.AuditWebhookStart, &sessionID, err, map[string]any{
+		"url": webhook.URL,
+	})
+	if err != nil {
 		return c.Status(500).JSON(NewErrorResponse("INTERNAL", err.Error()))
 	}
 
@@ -211,12 +308,14 @@ func (h *WebhookHandler) StartListening(c *fiber.Ctx) error {
 // @Success 200 {object} Response
 // @Router /sessions/{id}/webhook/stop [post]
 func (h *WebhookHandler) StopListening(c *fiber.Ctx) error {
-	sessionID, err := uuid.Parse(c.Params("id"))
-	if err != nil {
-		return c.Status(400).JSON(NewErrorResponse("INVALID_ID", "ID inválido"))
+	sess, ok := h.authorizeSession(c, domain.OrgRoleOperator)
+	if !ok {
+		return nil
 	}
+	sessionID := sess.ID
 
 	h.pool.StopSession(sessionID)
+	h.audit.RecordAction(c.Context(), domain.AuditWebhookStop, &sessionID, nil, nil)
 
 	return c.JSON(NewSuccessResponse(fiber.Map{
 		"status":     "stopped",
@@ -224,6 +323,28 @@ func (h *WebhookHandler) StopListening(c *fiber.Ctx) error {
 	}))
 }
 
+// authorizeSession carga la sesión de la ruta si el usuario alcanza el rol
+// need; si no, escribe la respuesta de error y retorna false
+func (h *WebhookHandler) authorizeSession(c *fiber.Ctx, need domain.OrgRole) (*domain.TelegramSession, bool) {
+	sessionID, userID, ok := parseSessionRoute(c)
+	if !ok {
+		return nil, false
+	}
+
+	sess, err := h.orgs.AuthorizeSession(c.Context(), userID, sessionID, need)
+	switch {
+	case err == nil:
+		return sess, true
+	case errors.Is(err, domain.ErrInsufficientOrgRole):
+		_ = c.Status(403).JSON(NewErrorResponse("INSUFFICIENT_ROLE", err.Error()))
+	case errors.Is(err, domain.ErrDatabase):
+		_ = c.Status(500).JSON(NewErrorResponse("DATABASE", "Error de base de datos"))
+	default:
+		_ = c.Status(404).JSON(NewErrorResponse("NOT_FOUND", "Sesión no encontrada"))
+	}
+	return nil, false
+}
+
 // PoolStatus godoc
 // @Summary Estado del pool
 // @Description Retorna información de sesiones activas escuchando
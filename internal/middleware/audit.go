@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"telegram-api/internal/domain"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// ContextKeyRequestID Locals donde el middleware requestid guarda el ID
+const ContextKeyRequestID = "requestid"
+
+// AuditContext deja en Locals el actor de auditoría con la IP y el request ID;
+// los middlewares de autenticación completan el usuario. Debe registrarse
+// después de requestid.New().
+func AuditContext() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		c.Locals(domain.AuditContextKey, &domain.AuditActor{
+			IPAddress: c.IP(),
+			RequestID: GetRequestID(c),
+		})
+		return c.Next()
+	}
+}
+
+// GetRequestID retorna el ID de la petición (header X-Request-ID)
+func GetRequestID(c *fiber.Ctx) string {
+	id, _ := c.Locals(ContextKeyRequestID).(string)
+	return id
+}
+
+// setAuditActor registra el usuario autenticado en el actor de auditoría
+func setAuditActor(c *fiber.Ctx, userID uuid.UUID, username string, key *domain.APIKey) {
+	actor, ok := c.Locals(domain.AuditContextKey).(*domain.AuditActor)
+	if !ok {
+		return
+	}
+	actor.UserID = userID
+	actor.Username = username
+	if key != nil {
+		actor.APIKeyID = &key.ID
+	}
+}
@@ -17,8 +17,8 @@ func CORSConfig() cors.Config {
 	return cors.Config{
 		AllowOrigins:     allowedOrigins,
 		AllowMethods:     "GET,POST,PUT,PATCH,DELETE,OPTIONS",
-		AllowHeaders:     "Origin,Content-Type,Accept,Authorization,X-Requested-With",
-		ExposeHeaders:    "Content-Length,Content-Type",
+		AllowHeaders:     "Origin,Content-Type,Accept,Authorization,X-Requested-With,X-Request-ID",
+		ExposeHeaders:    "Content-Length,Content-Type,X-Request-ID",
 		AllowCredentials: false,
 		MaxAge:           86400,
 	}
@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"regexp"
 	"strings"
 
 	"telegram-api/internal/domain"
@@ -14,10 +15,23 @@ const (
 	ContextKeyUserID   = "userID"
 	ContextKeyUsername = "username"
 	ContextKeyRole     = "userRole"
+	ContextKeyAPIKey   = "apiKey"
+	ContextKeyScopes   = "scopes"
+	ContextKeyClaims   = "jwtClaims"
 )
 
+// HeaderAPIKey header para autenticar con API key en lugar de JWT
+const HeaderAPIKey = "X-API-Key"
+
+// sessionPathRe extrae el ID de sesión de rutas /sessions/:id/...
+var sessionPathRe = regexp.MustCompile(`/sessions/([0-9a-fA-F-]{36})(?:/|$)`)
+
 func JWTMiddleware(authService *service.AuthService) fiber.Handler {
 	return func(c *fiber.Ctx) error {
+		if apiKey := c.Get(HeaderAPIKey); apiKey != "" {
+			return authenticateAPIKey(c, authService, apiKey)
+		}
+
 		authHeader := c.Get("Authorization")
 		if authHeader == "" {
 			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
@@ -59,14 +73,101 @@ func JWTMiddleware(authService *service.AuthService) fiber.Handler {
 			})
 		}
 
+		// Blacklist (logout, reutilización de refresh token). Si Redis falla
+		// se rechaza, salvo JWT_REVOCATION_FAIL_OPEN.
+		revoked, err := authService.IsAccessTokenRevoked(c.Context(), claims)
+		if err != nil {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+				"success": false,
+				"error": fiber.Map{
+					"code":    "REVOCATION_UNAVAILABLE",
+					"message": "No se pudo verificar el token, reintente más tarde",
+				},
+			})
+		}
+		if revoked {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"success": false,
+				"error": fiber.Map{
+					"code":    "TOKEN_REVOKED",
+					"message": "Token revocado",
+				},
+			})
+		}
+
 		c.Locals(ContextKeyUserID, userID)
 		c.Locals(ContextKeyUsername, claims.Username)
 		c.Locals(ContextKeyRole, claims.Role)
+		c.Locals(ContextKeyScopes, claims.EffectiveScopes())
+		c.Locals(ContextKeyClaims, claims)
+		setAuditActor(c, userID, claims.Username, nil)
 
 		return c.Next()
 	}
 }
 
+// authenticateAPIKey valida X-API-Key y, si la key está limitada a sesiones,
+// solo permite rutas /sessions/:id de esas sesiones y consultas de jobs
+func authenticateAPIKey(c *fiber.Ctx, authService *service.AuthService, rawKey string) error {
+	key, user, err := authService.AuthenticateAPIKey(c.Context(), rawKey, c.IP())
+	if err != nil {
+		status, code, message := fiber.StatusUnauthorized, "INVALID_API_KEY", "API key inválida, revocada o expirada"
+		switch err {
+		case domain.ErrForbidden:
+			status, code, message = fiber.StatusForbidden, "IP_NOT_ALLOWED", "IP no permitida para esta API key"
+		case domain.ErrUserInactive:
+			status, code, message = fiber.StatusForbidden, "FORBIDDEN", "Usuario desactivado"
+		}
+		return c.Status(status).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    code,
+				"message": message,
+			},
+		})
+	}
+
+	if key.IsSessionScoped() && !apiKeyAllowsPath(key, c.Path()) {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "SESSION_NOT_ALLOWED",
+				"message": "La API key no tiene acceso a este recurso",
+			},
+		})
+	}
+
+	c.Locals(ContextKeyUserID, user.ID)
+	c.Locals(ContextKeyUsername, user.Username)
+	c.Locals(ContextKeyRole, user.Role)
+	c.Locals(ContextKeyAPIKey, key)
+	c.Locals(ContextKeyScopes, key.EffectiveScopes(user))
+	setAuditActor(c, user.ID, user.Username, key)
+
+	return c.Next()
+}
+
+func apiKeyAllowsPath(key *domain.APIKey, path string) bool {
+	if m := sessionPathRe.FindStringSubmatch(path); m != nil {
+		id, err := uuid.Parse(m[1])
+		return err == nil && key.AllowsSession(id)
+	}
+	// La sesión del job solo se conoce al leerlo: la valida GetJobStatus
+	return strings.Contains(path, "/messages/") && strings.HasSuffix(path, "/status")
+}
+
+// GetClaims retorna los claims del JWT del request (nil si se autenticó con API key)
+func GetClaims(c *fiber.Ctx) *service.JWTClaims {
+	claims, _ := c.Locals(ContextKeyClaims).(*service.JWTClaims)
+	return claims
+}
+
+// GetAPIKey retorna la API key usada en el request (nil si se autenticó con JWT)
+func GetAPIKey(c *fiber.Ctx) *domain.APIKey {
+	key, _ := c.Locals(ContextKeyAPIKey).(*domain.APIKey)
+	return key
+}
+
 func RequireRole(roles ...domain.Role) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		userRole, ok := c.Locals(ContextKeyRole).(domain.Role)
@@ -96,6 +197,31 @@ func RequireRole(roles ...domain.Role) fiber.Handler {
 	}
 }
 
+// RequireScope exige que el token o la API key tenga todos los scopes indicados
+func RequireScope(scopes ...domain.Scope) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		granted := GetScopes(c)
+		for _, scope := range scopes {
+			if !domain.HasScope(granted, scope) {
+				return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+					"success": false,
+					"error": fiber.Map{
+						"code":    "INSUFFICIENT_SCOPE",
+						"message": "Falta el permiso " + string(scope),
+					},
+				})
+			}
+		}
+		return c.Next()
+	}
+}
+
+// GetScopes retorna los scopes del request autenticado
+func GetScopes(c *fiber.Ctx) []domain.Scope {
+	scopes, _ := c.Locals(ContextKeyScopes).([]domain.Scope)
+	return scopes
+}
+
 func GetUserID(c *fiber.Ctx) (uuid.UUID, error) {
 	userID, ok := c.Locals(ContextKeyUserID).(uuid.UUID)
 	if !ok {
@@ -135,11 +261,45 @@ func OptionalJWT(authService *service.AuthService) fiber.Handler {
 		if err != nil {
 			return c.Next()
 		}
+		if revoked, err := authService.IsAccessTokenRevoked(c.Context(), claims); err != nil || revoked {
+			return c.Next()
+		}
+
+		c.Locals(ContextKeyUserID, userID)
+		c.Locals(ContextKeyUsername, claims.Username)
+		c.Locals(ContextKeyRole, claims.Role)
+		c.Locals(ContextKeyScopes, claims.EffectiveScopes())
+		c.Locals(ContextKeyClaims, claims)
+		setAuditActor(c, userID, claims.Username, nil)
+
+		return c.Next()
+	}
+}
+
+// MFAEnrollmentMiddleware acepta el token de enrolamiento que da el login
+// cuando la política exige MFA, y si no, un JWT normal. Con el token de
+// enrolamiento GetClaims retorna claims con Purpose "mfa_enroll".
+func MFAEnrollmentMiddleware(authService *service.AuthService) fiber.Handler {
+	jwtMiddleware := JWTMiddleware(authService)
+	return func(c *fiber.Ctx) error {
+		token := c.Get("Authorization")
+		if strings.HasPrefix(strings.ToLower(token), "bearer ") {
+			token = token[7:]
+		}
 
+		claims, err := authService.ValidateEnrollmentToken(c.Context(), token)
+		if err != nil {
+			return jwtMiddleware(c)
+		}
+
+		userID, _ := uuid.Parse(claims.UserID)
 		c.Locals(ContextKeyUserID, userID)
 		c.Locals(ContextKeyUsername, claims.Username)
 		c.Locals(ContextKeyRole, claims.Role)
+		c.Locals(ContextKeyScopes, []domain.Scope{})
+		c.Locals(ContextKeyClaims, claims)
+		setAuditActor(c, userID, claims.Username, nil)
 
 		return c.Next()
 	}
-}
\ No newline at end of file
+}
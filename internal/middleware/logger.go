@@ -20,6 +20,7 @@ func RequestLogger() fiber.Handler {
 			Int("status", c.Response().StatusCode()).
 			Dur("latency", time.Since(start)).
 			Str("ip", c.IP()).
+			Str("request_id", GetRequestID(c)).
 			Msg("request")
 
 		return err
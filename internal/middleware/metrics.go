@@ -0,0 +1,56 @@
+package middleware
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	httpRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "http_requests_total",
+		Help: "Peticiones HTTP por método, ruta y status",
+	}, []string{"method", "route", "status"})
+	httpDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "http_request_duration_seconds",
+		Help:    "Latencia de las peticiones HTTP por método y ruta",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method", "route"})
+)
+
+// unmatchedRoute etiqueta de las peticiones que no corresponden a ninguna
+// ruta, para no crear una serie por cada URL desconocida
+const unmatchedRoute = "unmatched"
+
+// Metrics registra latencia y status de cada petición etiquetadas con la
+// plantilla de la ruta (/api/v1/sessions/:id), no con la URL concreta
+func Metrics() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		start := time.Now()
+
+		err := c.Next()
+
+		status := c.Response().StatusCode()
+		route := c.Route().Path
+		var fe *fiber.Error
+		if errors.As(err, &fe) {
+			// El error handler escribirá el status después de este middleware
+			status = fe.Code
+			if fe.Code == fiber.StatusNotFound {
+				route = unmatchedRoute
+			}
+		} else if err != nil {
+			status = fiber.StatusInternalServerError
+		}
+
+		method := c.Method()
+		httpRequests.WithLabelValues(method, route, strconv.Itoa(status)).Inc()
+		httpDuration.WithLabelValues(method, route).Observe(time.Since(start).Seconds())
+
+		return err
+	}
+}
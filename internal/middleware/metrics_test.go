@@ -0,0 +1,107 @@
+package middleware
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+	"github.com/prometheus/common/model"
+)
+
+// scrape pide /metrics y parsea la respuesta con el parser de Prometheus
+func scrape(t *testing.T, app *fiber.App) map[string]*dto.MetricFamily {
+	t.Helper()
+	resp, err := app.Test(httptest.NewRequest("GET", "/metrics", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	parser := expfmt.NewTextParser(model.UTF8Validation)
+	families, err := parser.TextToMetricFamilies(resp.Body)
+	if err != nil {
+		t.Fatalf("salida de /metrics no parseable: %v", err)
+	}
+	return families
+}
+
+// sample busca la serie de una familia con exactamente esos labels
+func sample(family *dto.MetricFamily, labels map[string]string) *dto.Metric {
+	for _, m := range family.GetMetric() {
+		if len(m.GetLabel()) != len(labels) {
+			continue
+		}
+		match := true
+		for _, l := range m.GetLabel() {
+			if labels[l.GetName()] != l.GetValue() {
+				match = false
+				break
+			}
+		}
+		if match {
+			return m
+		}
+	}
+	return nil
+}
+
+func TestMetricsExposition(t *testing.T) {
+	app := fiber.New()
+	app.Use(Metrics())
+	app.Get("/metrics", adaptor.HTTPHandler(promhttp.Handler()))
+	app.Get("/sessions/:id", func(c *fiber.Ctx) error { return c.SendString("ok") })
+
+	for _, path := range []string{"/sessions/a", "/sessions/b", "/no-existe"} {
+		resp, err := app.Test(httptest.NewRequest("GET", path, nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+	}
+
+	families := scrape(t, app)
+
+	requests, ok := families["http_requests_total"]
+	if !ok {
+		t.Fatal("falta http_requests_total")
+	}
+	if requests.GetType() != dto.MetricType_COUNTER {
+		t.Errorf("http_requests_total tipo = %v, want COUNTER", requests.GetType())
+	}
+
+	tests := []struct {
+		route  string
+		status string
+		want   float64
+	}{
+		// Las dos URLs comparten la plantilla de ruta
+		{"/sessions/:id", "200", 2},
+		{unmatchedRoute, "404", 1},
+	}
+	for _, tt := range tests {
+		m := sample(requests, map[string]string{"method": "GET", "route": tt.route, "status": tt.status})
+		if m == nil {
+			t.Errorf("sin serie para route=%s status=%s", tt.route, tt.status)
+			continue
+		}
+		if got := m.GetCounter().GetValue(); got != tt.want {
+			t.Errorf("route=%s status=%s = %v, want %v", tt.route, tt.status, got, tt.want)
+		}
+	}
+
+	duration, ok := families["http_request_duration_seconds"]
+	if !ok {
+		t.Fatal("falta http_request_duration_seconds")
+	}
+	m := sample(duration, map[string]string{"method": "GET", "route": "/sessions/:id"})
+	if m == nil {
+		t.Fatal("sin histograma para /sessions/:id")
+	}
+	if got := m.GetHistogram().GetSampleCount(); got != 2 {
+		t.Errorf("sample_count = %d, want 2", got)
+	}
+}
@@ -1 +1,134 @@
 package middleware
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"telegram-api/internal/domain"
+	"telegram-api/pkg/logger"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// RateLimitRule define un límite de peticiones por ventana deslizante
+type RateLimitRule struct {
+	Name   string                    // Parte de la clave en Redis, p.ej. "auth", "api", "send"
+	Limit  int                       // Peticiones permitidas por ventana
+	Window time.Duration             // Tamaño de la ventana
+	Key    func(c *fiber.Ctx) string // Identidad a limitar; "" = no limitar
+}
+
+// RateLimit aplica la regla con un contador de ventana deslizante en Redis:
+// se estima el uso de los últimos Window combinando el contador de la
+// ventana fija actual con la parte proporcional de la anterior. Si Redis
+// falla, la petición se deja pasar.
+func RateLimit(cache domain.CacheRepository, rule RateLimitRule) fiber.Handler {
+	window := int64(rule.Window / time.Second)
+	if window < 1 {
+		window = 1
+	}
+
+	return func(c *fiber.Ctx) error {
+		if rule.Limit <= 0 {
+			return c.Next()
+		}
+		id := rule.Key(c)
+		if id == "" {
+			return c.Next()
+		}
+
+		now := time.Now()
+		bucket := now.Unix() / window
+		elapsed := float64(now.UnixNano()-bucket*window*int64(time.Second)) / float64(window*int64(time.Second))
+
+		// La ventana actual vive 2 ventanas para poder usarse como "anterior"
+		current, err := cache.IncrementRateLimit(c.Context(), rateLimitKey(rule.Name, id, bucket), int(window*2))
+		if err != nil {
+			logger.Warn().Err(err).Str("rule", rule.Name).Msg("⚠️ Rate limit no disponible, se permite la petición")
+			return c.Next()
+		}
+		var previous int64
+		if raw, err := cache.Get(c.Context(), rateLimitKey(rule.Name, id, bucket-1)); err == nil {
+			previous, _ = strconv.ParseInt(raw, 10, 64)
+		}
+
+		estimate := float64(previous)*(1-elapsed) + float64(current)
+		remaining := int(math.Max(0, math.Floor(float64(rule.Limit)-estimate)))
+		reset := int(math.Ceil(float64(window) * (1 - elapsed)))
+
+		c.Set("X-RateLimit-Limit", strconv.Itoa(rule.Limit))
+		c.Set("X-RateLimit-Remaining", strconv.Itoa(remaining))
+		c.Set("X-RateLimit-Reset", strconv.Itoa(reset))
+
+		if estimate <= float64(rule.Limit) {
+			return c.Next()
+		}
+
+		retryAfter := retryAfterSeconds(rule.Limit, previous, current, elapsed, window)
+		c.Set(fiber.HeaderRetryAfter, strconv.Itoa(retryAfter))
+
+		logger.Warn().
+			Str("rule", rule.Name).
+			Str("key", id).
+			Str("path", c.Path()).
+			Int("retry_after", retryAfter).
+			Msg("Rate limit excedido")
+
+		return c.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{
+			"success": false,
+			"error": fiber.Map{
+				"code":    "RATE_LIMITED",
+				"message": domain.ErrRateLimitExceeded.Error(),
+			},
+		})
+	}
+}
+
+// retryAfterSeconds calcula cuándo la estimación vuelve a estar bajo el
+// límite. Si la ventana actual no lo supera basta con que decaiga la
+// anterior; si lo supera, hay que esperar a la siguiente ventana y a que
+// la actual decaiga en ella.
+func retryAfterSeconds(limit int, previous, current int64, elapsed float64, window int64) int {
+	var wait float64
+	if current <= int64(limit) {
+		// previous*(1-e) + current <= limit  =>  e >= 1 - (limit-current)/previous
+		target := 1 - float64(int64(limit)-current)/float64(previous)
+		wait = float64(window) * (target - elapsed)
+	} else {
+		wait = float64(window)*(1-elapsed) + float64(window)*(1-float64(limit)/float64(current))
+	}
+	if wait < 1 {
+		return 1
+	}
+	return int(math.Ceil(wait))
+}
+
+func rateLimitKey(rule, id string, bucket int64) string {
+	return fmt.Sprintf("rate:%s:%s:%d", rule, id, bucket)
+}
+
+// RateLimitByIP limita por IP del cliente
+func RateLimitByIP(c *fiber.Ctx) string {
+	return "ip:" + c.IP()
+}
+
+// RateLimitByCaller limita por API key si se usó una, si no por usuario
+func RateLimitByCaller(c *fiber.Ctx) string {
+	if key := GetAPIKey(c); key != nil {
+		return "key:" + key.ID.String()
+	}
+	if userID, err := GetUserID(c); err == nil {
+		return "user:" + userID.String()
+	}
+	return RateLimitByIP(c)
+}
+
+// RateLimitBySession limita por sesión de Telegram (parámetro :id)
+func RateLimitBySession(c *fiber.Ctx) string {
+	if id := c.Params("id"); id != "" {
+		return "session:" + id
+	}
+	return ""
+}
@@ -0,0 +1,142 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"telegram-api/internal/domain"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Queries SQL para API keys
+const (
+	queryCreateAPIKey = `
+		INSERT INTO api_keys (id, user_id, name, prefix, key_hash, session_ids, allowed_ips, scopes, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`
+
+	querySelectAPIKey = `
+		SELECT id, user_id, name, prefix, key_hash, COALESCE(session_ids, '{}'), COALESCE(allowed_ips, '{}'),
+			COALESCE(scopes, '{}'),
+			expires_at, last_used_at, COALESCE(last_used_ip, ''), revoked_at, created_at
+		FROM api_keys`
+
+	queryRevokeAPIKey = `
+		UPDATE api_keys SET revoked_at = $3 WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL`
+
+	queryTouchAPIKey = `
+		UPDATE api_keys SET last_used_at = $2, last_used_ip = $3 WHERE id = $1`
+)
+
+// APIKeyRepository implementa domain.APIKeyRepository
+type APIKeyRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewAPIKeyRepository crea una nueva instancia del repositorio
+func NewAPIKeyRepository(pool *pgxpool.Pool) *APIKeyRepository {
+	return &APIKeyRepository{pool: pool}
+}
+
+// Create guarda una nueva API key (solo el hash)
+func (r *APIKeyRepository) Create(ctx context.Context, key *domain.APIKey) error {
+	sessionIDs := make([]string, len(key.SessionIDs))
+	for i, id := range key.SessionIDs {
+		sessionIDs[i] = id.String()
+	}
+	allowedIPs := key.AllowedIPs
+	if allowedIPs == nil {
+		allowedIPs = []string{}
+	}
+	scopes := make([]string, len(key.Scopes))
+	for i, scope := range key.Scopes {
+		scopes[i] = string(scope)
+	}
+
+	_, err := r.pool.Exec(ctx, queryCreateAPIKey,
+		key.ID, key.UserID, key.Name, key.Prefix, key.KeyHash,
+		sessionIDs, allowedIPs, scopes, key.ExpiresAt, key.CreatedAt,
+	)
+	return wrapDBError(err, "crear API key")
+}
+
+// GetByHash obtiene una API key por el hash de la clave
+func (r *APIKeyRepository) GetByHash(ctx context.Context, keyHash string) (*domain.APIKey, error) {
+	key, err := scanAPIKey(r.pool.QueryRow(ctx, querySelectAPIKey+` WHERE key_hash = $1`, keyHash))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrAPIKeyNotFound
+	}
+	if err != nil {
+		return nil, wrapDBError(err, "obtener API key")
+	}
+	return key, nil
+}
+
+// ListByUserID lista las API keys del usuario (incluidas revocadas)
+func (r *APIKeyRepository) ListByUserID(ctx context.Context, userID uuid.UUID) ([]*domain.APIKey, error) {
+	rows, err := r.pool.Query(ctx, querySelectAPIKey+` WHERE user_id = $1 ORDER BY created_at DESC`, userID)
+	if err != nil {
+		return nil, wrapDBError(err, "listar API keys")
+	}
+	defer rows.Close()
+
+	var keys []*domain.APIKey
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, wrapDBError(err, "escanear API key")
+		}
+		keys = append(keys, key)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, wrapDBError(err, "iterar API keys")
+	}
+	return keys, nil
+}
+
+// Revoke revoca una API key del usuario
+func (r *APIKeyRepository) Revoke(ctx context.Context, id, userID uuid.UUID) error {
+	result, err := r.pool.Exec(ctx, queryRevokeAPIKey, id, userID, time.Now())
+	if err != nil {
+		return wrapDBError(err, "revocar API key")
+	}
+	if result.RowsAffected() == 0 {
+		return domain.ErrAPIKeyNotFound
+	}
+	return nil
+}
+
+// TouchLastUsed registra el último uso
+func (r *APIKeyRepository) TouchLastUsed(ctx context.Context, id uuid.UUID, ip string) error {
+	_, err := r.pool.Exec(ctx, queryTouchAPIKey, id, time.Now(), nullableString(ip))
+	return wrapDBError(err, "actualizar uso de API key")
+}
+
+func scanAPIKey(row pgx.Row) (*domain.APIKey, error) {
+	key := &domain.APIKey{}
+	var sessionIDs, scopes []string
+
+	err := row.Scan(
+		&key.ID, &key.UserID, &key.Name, &key.Prefix, &key.KeyHash, &sessionIDs, &key.AllowedIPs, &scopes,
+		&key.ExpiresAt, &key.LastUsedAt, &key.LastUsedIP, &key.RevokedAt, &key.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(scopes) > 0 {
+		key.Scopes = toScopes(scopes)
+	}
+	for _, s := range sessionIDs {
+		if id, err := uuid.Parse(s); err == nil {
+			key.SessionIDs = append(key.SessionIDs, id)
+		}
+	}
+	return key, nil
+}
+
+// Verificación en tiempo de compilación
+var _ domain.APIKeyRepository = (*APIKeyRepository)(nil)
\ No newline at end of file
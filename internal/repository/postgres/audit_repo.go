@@ -0,0 +1,124 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"telegram-api/internal/domain"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Queries SQL para auditoría
+const (
+	queryInsertAuditEvent = `
+		INSERT INTO audit_events (id, actor_id, actor_name, api_key_id, action, session_id,
+			ip_address, request_id, outcome, error, details, created_at)
+		VALUES ($1, $2, NULLIF($3, ''), $4, $5, $6, NULLIF($7, ''), NULLIF($8, ''), $9, NULLIF($10, ''), $11, $12)`
+
+	querySelectAuditEvent = `
+		SELECT id, actor_id, COALESCE(actor_name, ''), api_key_id, action, session_id,
+			COALESCE(ip_address, ''), COALESCE(request_id, ''), outcome, COALESCE(error, ''), details, created_at
+		FROM audit_events`
+
+	// $3: acción exacta, o prefijo si termina en "."
+	queryAuditFilter = `
+		WHERE ($1::uuid IS NULL OR actor_id = $1)
+			AND ($2::uuid IS NULL OR session_id = $2)
+			AND ($3 = '' OR action = $3 OR (right($3, 1) = '.' AND starts_with(action, $3)))
+			AND ($4 = '' OR outcome = $4)
+			AND ($5::timestamptz IS NULL OR created_at >= $5)
+			AND ($6::timestamptz IS NULL OR created_at < $6)`
+
+	queryDeleteAuditBefore = `
+		DELETE FROM audit_events WHERE created_at < $1`
+)
+
+// AuditRepository implementa domain.AuditRepository
+type AuditRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewAuditRepository crea una nueva instancia del repositorio
+func NewAuditRepository(pool *pgxpool.Pool) *AuditRepository {
+	return &AuditRepository{pool: pool}
+}
+
+// Insert guarda un evento de auditoría
+func (r *AuditRepository) Insert(ctx context.Context, event *domain.AuditEvent) error {
+	var details []byte
+	if len(event.Details) > 0 {
+		var err error
+		if details, err = json.Marshal(event.Details); err != nil {
+			return err
+		}
+	}
+
+	_, err := r.pool.Exec(ctx, queryInsertAuditEvent,
+		event.ID, event.ActorID, event.ActorName, event.APIKeyID, event.Action, event.SessionID,
+		event.IPAddress, event.RequestID, string(event.Outcome), event.Error, details, event.CreatedAt,
+	)
+	return wrapDBError(err, "guardar evento de auditoría")
+}
+
+// List lista los eventos que cumplen el filtro, del más reciente al más antiguo
+func (r *AuditRepository) List(ctx context.Context, filter domain.AuditFilter) ([]*domain.AuditEvent, int64, error) {
+	args := []any{filter.ActorID, filter.SessionID, filter.Action, string(filter.Outcome), filter.From, filter.To}
+
+	var total int64
+	if err := r.pool.QueryRow(ctx, `SELECT COUNT(*) FROM audit_events `+queryAuditFilter, args...).Scan(&total); err != nil {
+		return nil, 0, wrapDBError(err, "contar eventos de auditoría")
+	}
+
+	rows, err := r.pool.Query(ctx, querySelectAuditEvent+queryAuditFilter+`
+		ORDER BY created_at DESC LIMIT $7 OFFSET $8`,
+		append(args, filter.Limit, filter.Offset)...,
+	)
+	if err != nil {
+		return nil, 0, wrapDBError(err, "listar eventos de auditoría")
+	}
+	defer rows.Close()
+
+	events := []*domain.AuditEvent{}
+	for rows.Next() {
+		event, err := scanAuditEvent(rows)
+		if err != nil {
+			return nil, 0, wrapDBError(err, "escanear evento de auditoría")
+		}
+		events = append(events, event)
+	}
+	return events, total, rows.Err()
+}
+
+// DeleteBefore borra los eventos anteriores a before (política de retención)
+func (r *AuditRepository) DeleteBefore(ctx context.Context, before time.Time) (int64, error) {
+	result, err := r.pool.Exec(ctx, queryDeleteAuditBefore, before)
+	if err != nil {
+		return 0, wrapDBError(err, "purgar eventos de auditoría")
+	}
+	return result.RowsAffected(), nil
+}
+
+func scanAuditEvent(row pgx.Row) (*domain.AuditEvent, error) {
+	var (
+		event   domain.AuditEvent
+		outcome string
+		details []byte
+	)
+	err := row.Scan(
+		&event.ID, &event.ActorID, &event.ActorName, &event.APIKeyID, &event.Action, &event.SessionID,
+		&event.IPAddress, &event.RequestID, &outcome, &event.Error, &details, &event.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	event.Outcome = domain.AuditOutcome(outcome)
+	if len(details) > 0 {
+		if err := json.Unmarshal(details, &event.Details); err != nil {
+			return nil, err
+		}
+	}
+	return &event, nil
+}
@@ -33,6 +33,9 @@ func wrapDBError(err error, op string) error {
 			if pgErr.ConstraintName == "users_email_key" {
 				return domain.ErrEmailAlreadyExists
 			}
+			if pgErr.ConstraintName == "telegram_sessions_user_id_phone_number_key" {
+				return domain.ErrSessionAlreadyExists
+			}
 			// Cualquier otra violación única
 			logger.Warn().
 				Str("constraint", pgErr.ConstraintName).
@@ -0,0 +1,135 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"telegram-api/internal/domain"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Queries SQL para invitaciones
+const (
+	queryCreateInvitation = `
+		INSERT INTO invitations (id, token_hash, email, role, created_by, expires_at, created_at)
+		VALUES ($1, $2, NULLIF($3, ''), $4, $5, $6, $7)`
+
+	querySelectInvitation = `
+		SELECT id, token_hash, COALESCE(email, ''), role, COALESCE(created_by, '00000000-0000-0000-0000-000000000000'),
+			expires_at, used_at, used_by, created_at
+		FROM invitations`
+
+	// Consumo atómico: una invitación solo registra una cuenta
+	queryConsumeInvitation = `
+		UPDATE invitations SET used_at = $2
+		WHERE token_hash = $1 AND used_at IS NULL AND expires_at > $2
+		RETURNING id, token_hash, COALESCE(email, ''), role, COALESCE(created_by, '00000000-0000-0000-0000-000000000000'),
+			expires_at, used_at, used_by, created_at`
+
+	queryReleaseInvitation = `
+		UPDATE invitations SET used_at = NULL WHERE id = $1 AND used_by IS NULL`
+
+	querySetInvitationUsedBy = `
+		UPDATE invitations SET used_by = $2 WHERE id = $1`
+
+	queryDeleteInvitation = `
+		DELETE FROM invitations WHERE id = $1 AND used_at IS NULL`
+)
+
+// InvitationRepository implementa domain.InvitationRepository
+type InvitationRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewInvitationRepository crea una nueva instancia del repositorio
+func NewInvitationRepository(pool *pgxpool.Pool) *InvitationRepository {
+	return &InvitationRepository{pool: pool}
+}
+
+// Create guarda una invitación nueva (solo el hash del token)
+func (r *InvitationRepository) Create(ctx context.Context, inv *domain.Invitation) error {
+	_, err := r.pool.Exec(ctx, queryCreateInvitation,
+		inv.ID, inv.TokenHash, inv.Email, inv.Role, inv.CreatedBy, inv.ExpiresAt, inv.CreatedAt,
+	)
+	return wrapDBError(err, "crear invitación")
+}
+
+// ListPending lista las invitaciones sin usar y sin expirar
+func (r *InvitationRepository) ListPending(ctx context.Context) ([]*domain.Invitation, error) {
+	rows, err := r.pool.Query(ctx, querySelectInvitation+`
+		WHERE used_at IS NULL AND expires_at > $1
+		ORDER BY created_at DESC`, time.Now())
+	if err != nil {
+		return nil, wrapDBError(err, "listar invitaciones")
+	}
+	defer rows.Close()
+
+	invitations := []*domain.Invitation{}
+	for rows.Next() {
+		inv, err := scanInvitation(rows)
+		if err != nil {
+			return nil, wrapDBError(err, "escanear invitación")
+		}
+		invitations = append(invitations, inv)
+	}
+	return invitations, rows.Err()
+}
+
+// Consume marca la invitación como usada
+func (r *InvitationRepository) Consume(ctx context.Context, tokenHash string) (*domain.Invitation, error) {
+	inv, err := scanInvitation(r.pool.QueryRow(ctx, queryConsumeInvitation, tokenHash, time.Now()))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrInvalidToken
+	}
+	if err != nil {
+		return nil, wrapDBError(err, "consumir invitación")
+	}
+	return inv, nil
+}
+
+// Release vuelve a dejar disponible una invitación consumida sin registro
+func (r *InvitationRepository) Release(ctx context.Context, id uuid.UUID) error {
+	_, err := r.pool.Exec(ctx, queryReleaseInvitation, id)
+	return wrapDBError(err, "liberar invitación")
+}
+
+// SetUsedBy registra el usuario creado con la invitación
+func (r *InvitationRepository) SetUsedBy(ctx context.Context, id, userID uuid.UUID) error {
+	_, err := r.pool.Exec(ctx, querySetInvitationUsedBy, id, userID)
+	return wrapDBError(err, "registrar uso de invitación")
+}
+
+// Delete revoca una invitación pendiente
+func (r *InvitationRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	result, err := r.pool.Exec(ctx, queryDeleteInvitation, id)
+	if err != nil {
+		return wrapDBError(err, "eliminar invitación")
+	}
+	if result.RowsAffected() == 0 {
+		return domain.ErrInvitationNotFound
+	}
+	return nil
+}
+
+func scanInvitation(row pgx.Row) (*domain.Invitation, error) {
+	inv := &domain.Invitation{}
+	err := row.Scan(
+		&inv.ID,
+		&inv.TokenHash,
+		&inv.Email,
+		&inv.Role,
+		&inv.CreatedBy,
+		&inv.ExpiresAt,
+		&inv.UsedAt,
+		&inv.UsedBy,
+		&inv.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return inv, nil
+}
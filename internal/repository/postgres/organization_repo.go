@@ -0,0 +1,319 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"telegram-api/internal/domain"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Queries SQL para organizaciones
+const (
+	queryCreateOrganization = `
+		INSERT INTO organizations (id, name, created_by, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $4)`
+
+	querySelectOrganization = `
+		SELECT id, name, COALESCE(created_by, '00000000-0000-0000-0000-000000000000'), created_at, updated_at
+		FROM organizations WHERE id = $1`
+
+	queryListOrganizationsByUser = `
+		SELECT o.id, o.name, COALESCE(o.created_by, '00000000-0000-0000-0000-000000000000'), m.role, o.created_at, o.updated_at
+		FROM organizations o
+		JOIN organization_members m ON m.organization_id = o.id
+		WHERE m.user_id = $1
+		ORDER BY o.name`
+
+	queryUpdateOrganization = `
+		UPDATE organizations SET name = $2, updated_at = NOW() WHERE id = $1`
+
+	queryDeleteOrganization = `
+		DELETE FROM organizations WHERE id = $1`
+
+	queryAddMember = `
+		INSERT INTO organization_members (organization_id, user_id, role, created_at)
+		VALUES ($1, $2, $3, $4)`
+
+	querySelectMember = `
+		SELECT m.organization_id, m.user_id, u.username, u.email, m.role, m.created_at
+		FROM organization_members m
+		JOIN users u ON u.id = m.user_id`
+
+	// Bloquea la organización para que dos cambios concurrentes no dejen
+	// ambos la organización sin owners
+	queryLockOrganization = `
+		SELECT id FROM organizations WHERE id = $1 FOR UPDATE`
+
+	queryMemberRole = `
+		SELECT role FROM organization_members WHERE organization_id = $1 AND user_id = $2`
+
+	queryCountOwners = `
+		SELECT COUNT(*) FROM organization_members WHERE organization_id = $1 AND role = 'owner'`
+
+	queryUpdateMemberRole = `
+		UPDATE organization_members SET role = $3 WHERE organization_id = $1 AND user_id = $2`
+
+	queryRemoveMember = `
+		DELETE FROM organization_members WHERE organization_id = $1 AND user_id = $2`
+
+	querySetSessionOwner = `
+		UPDATE telegram_sessions SET user_id = $2, organization_id = $3, updated_at = NOW()
+		WHERE id = $1`
+
+	// Donde el usuario es el único owner asciende al miembro más antiguo,
+	// prefiriendo operators
+	queryPromoteHeirs = `
+		UPDATE organization_members m SET role = 'owner'
+		FROM (
+			SELECT DISTINCT ON (om.organization_id) om.organization_id, om.user_id
+			FROM organization_members om
+			WHERE om.user_id <> $1
+				AND om.organization_id IN (
+					SELECT organization_id FROM organization_members WHERE user_id = $1 AND role = 'owner')
+				AND NOT EXISTS (
+					SELECT 1 FROM organization_members o2
+					WHERE o2.organization_id = om.organization_id AND o2.role = 'owner' AND o2.user_id <> $1)
+			ORDER BY om.organization_id, CASE om.role WHEN 'operator' THEN 0 ELSE 1 END, om.created_at
+		) heir
+		WHERE m.organization_id = heir.organization_id AND m.user_id = heir.user_id`
+
+	// Organizaciones en las que el usuario es el único miembro
+	queryDeleteSoloOrganizations = `
+		DELETE FROM organizations o
+		WHERE EXISTS (SELECT 1 FROM organization_members WHERE organization_id = o.id AND user_id = $1)
+			AND NOT EXISTS (SELECT 1 FROM organization_members WHERE organization_id = o.id AND user_id <> $1)`
+
+	queryReassignSharedSessions = `
+		UPDATE telegram_sessions s SET user_id = (
+			SELECT om.user_id FROM organization_members om
+			WHERE om.organization_id = s.organization_id AND om.user_id <> $1 AND om.role = 'owner'
+			ORDER BY om.created_at LIMIT 1
+		), updated_at = NOW()
+		WHERE s.user_id = $1 AND s.organization_id IS NOT NULL`
+)
+
+// OrganizationRepository implementa domain.OrganizationRepository
+type OrganizationRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewOrganizationRepository crea una nueva instancia del repositorio
+func NewOrganizationRepository(pool *pgxpool.Pool) *OrganizationRepository {
+	return &OrganizationRepository{pool: pool}
+}
+
+// Create guarda la organización y su primer owner en una transacción
+func (r *OrganizationRepository) Create(ctx context.Context, org *domain.Organization, ownerID uuid.UUID) error {
+	err := pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, queryCreateOrganization, org.ID, org.Name, org.CreatedBy, org.CreatedAt); err != nil {
+			return err
+		}
+		_, err := tx.Exec(ctx, queryAddMember, org.ID, ownerID, domain.OrgRoleOwner, org.CreatedAt)
+		return err
+	})
+	return wrapDBError(err, "crear organización")
+}
+
+// GetByID obtiene una organización
+func (r *OrganizationRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Organization, error) {
+	org := &domain.Organization{}
+	err := r.pool.QueryRow(ctx, querySelectOrganization, id).Scan(
+		&org.ID, &org.Name, &org.CreatedBy, &org.CreatedAt, &org.UpdatedAt,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrOrganizationNotFound
+	}
+	if err != nil {
+		return nil, wrapDBError(err, "obtener organización")
+	}
+	return org, nil
+}
+
+// ListByUser organizaciones del usuario con su rol en cada una
+func (r *OrganizationRepository) ListByUser(ctx context.Context, userID uuid.UUID) ([]*domain.Organization, error) {
+	rows, err := r.pool.Query(ctx, queryListOrganizationsByUser, userID)
+	if err != nil {
+		return nil, wrapDBError(err, "listar organizaciones")
+	}
+	defer rows.Close()
+
+	orgs := []*domain.Organization{}
+	for rows.Next() {
+		org := &domain.Organization{}
+		if err := rows.Scan(&org.ID, &org.Name, &org.CreatedBy, &org.Role, &org.CreatedAt, &org.UpdatedAt); err != nil {
+			return nil, wrapDBError(err, "escanear organización")
+		}
+		orgs = append(orgs, org)
+	}
+	return orgs, wrapDBError(rows.Err(), "listar organizaciones")
+}
+
+// Update renombra la organización
+func (r *OrganizationRepository) Update(ctx context.Context, org *domain.Organization) error {
+	result, err := r.pool.Exec(ctx, queryUpdateOrganization, org.ID, org.Name)
+	if err != nil {
+		return wrapDBError(err, "actualizar organización")
+	}
+	if result.RowsAffected() == 0 {
+		return domain.ErrOrganizationNotFound
+	}
+	return nil
+}
+
+// Delete elimina la organización (en cascada sus miembros); sus sesiones
+// quedan como personales de su user_id
+func (r *OrganizationRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	result, err := r.pool.Exec(ctx, queryDeleteOrganization, id)
+	if err != nil {
+		return wrapDBError(err, "eliminar organización")
+	}
+	if result.RowsAffected() == 0 {
+		return domain.ErrOrganizationNotFound
+	}
+	return nil
+}
+
+// AddMember añade un miembro; ErrAlreadyMember si ya pertenece
+func (r *OrganizationRepository) AddMember(ctx context.Context, m *domain.OrganizationMember) error {
+	_, err := r.pool.Exec(ctx, queryAddMember, m.OrganizationID, m.UserID, m.Role, m.CreatedAt)
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return domain.ErrAlreadyMember
+	}
+	return wrapDBError(err, "añadir miembro")
+}
+
+// GetMember obtiene la pertenencia de un usuario; ErrMemberNotFound si no es miembro
+func (r *OrganizationRepository) GetMember(ctx context.Context, orgID, userID uuid.UUID) (*domain.OrganizationMember, error) {
+	m, err := scanMember(r.pool.QueryRow(ctx, querySelectMember+`
+		WHERE m.organization_id = $1 AND m.user_id = $2`, orgID, userID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrMemberNotFound
+	}
+	if err != nil {
+		return nil, wrapDBError(err, "obtener miembro")
+	}
+	return m, nil
+}
+
+// ListMembers miembros de la organización, owners primero
+func (r *OrganizationRepository) ListMembers(ctx context.Context, orgID uuid.UUID) ([]*domain.OrganizationMember, error) {
+	rows, err := r.pool.Query(ctx, querySelectMember+`
+		WHERE m.organization_id = $1
+		ORDER BY CASE m.role WHEN 'owner' THEN 0 WHEN 'operator' THEN 1 ELSE 2 END, u.username`, orgID)
+	if err != nil {
+		return nil, wrapDBError(err, "listar miembros")
+	}
+	defer rows.Close()
+
+	members := []*domain.OrganizationMember{}
+	for rows.Next() {
+		m, err := scanMember(rows)
+		if err != nil {
+			return nil, wrapDBError(err, "escanear miembro")
+		}
+		members = append(members, m)
+	}
+	return members, wrapDBError(rows.Err(), "listar miembros")
+}
+
+// UpdateMemberRole cambia el rol; no permite degradar al último owner
+func (r *OrganizationRepository) UpdateMemberRole(ctx context.Context, orgID, userID uuid.UUID, role domain.OrgRole) error {
+	return r.changeMember(ctx, orgID, userID, role != domain.OrgRoleOwner, func(tx pgx.Tx) error {
+		_, err := tx.Exec(ctx, queryUpdateMemberRole, orgID, userID, role)
+		return err
+	})
+}
+
+// RemoveMember saca a un usuario; no permite quitar al último owner
+func (r *OrganizationRepository) RemoveMember(ctx context.Context, orgID, userID uuid.UUID) error {
+	return r.changeMember(ctx, orgID, userID, true, func(tx pgx.Tx) error {
+		_, err := tx.Exec(ctx, queryRemoveMember, orgID, userID)
+		return err
+	})
+}
+
+// changeMember aplica fn con la organización bloqueada. Si dropsOwner y el
+// miembro es owner, exige que quede algún otro.
+func (r *OrganizationRepository) changeMember(ctx context.Context, orgID, userID uuid.UUID, dropsOwner bool, fn func(tx pgx.Tx) error) error {
+	err := pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		var id uuid.UUID
+		if err := tx.QueryRow(ctx, queryLockOrganization, orgID).Scan(&id); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return domain.ErrOrganizationNotFound
+			}
+			return err
+		}
+
+		var current domain.OrgRole
+		if err := tx.QueryRow(ctx, queryMemberRole, orgID, userID).Scan(&current); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return domain.ErrMemberNotFound
+			}
+			return err
+		}
+
+		if dropsOwner && current == domain.OrgRoleOwner {
+			var owners int
+			if err := tx.QueryRow(ctx, queryCountOwners, orgID).Scan(&owners); err != nil {
+				return err
+			}
+			if owners <= 1 {
+				return domain.ErrLastOwner
+			}
+		}
+		return fn(tx)
+	})
+
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, domain.ErrOrganizationNotFound), errors.Is(err, domain.ErrMemberNotFound),
+		errors.Is(err, domain.ErrLastOwner):
+		return err
+	default:
+		return wrapDBError(err, "modificar miembro")
+	}
+}
+
+// SetSessionOwner fija el responsable y la organización de una sesión
+func (r *OrganizationRepository) SetSessionOwner(ctx context.Context, sessionID, userID uuid.UUID, orgID *uuid.UUID) error {
+	result, err := r.pool.Exec(ctx, querySetSessionOwner, sessionID, userID, orgID)
+	if err != nil {
+		return wrapDBError(err, "transferir sesión")
+	}
+	if result.RowsAffected() == 0 {
+		return domain.ErrSessionNotFound
+	}
+	return nil
+}
+
+// ReleaseUser deja las organizaciones del usuario con owner y sus sesiones
+// compartidas con otro responsable antes de eliminar la cuenta
+func (r *OrganizationRepository) ReleaseUser(ctx context.Context, userID uuid.UUID) error {
+	err := pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		for _, query := range []string{queryPromoteHeirs, queryDeleteSoloOrganizations, queryReassignSharedSessions} {
+			if _, err := tx.Exec(ctx, query, userID); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return wrapDBError(err, "liberar organizaciones del usuario")
+}
+
+func scanMember(row pgx.Row) (*domain.OrganizationMember, error) {
+	m := &domain.OrganizationMember{}
+	err := row.Scan(&m.OrganizationID, &m.UserID, &m.Username, &m.Email, &m.Role, &m.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+var _ domain.OrganizationRepository = (*OrganizationRepository)(nil)
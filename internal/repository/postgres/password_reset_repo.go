@@ -0,0 +1,66 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"telegram-api/internal/domain"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Queries SQL para tokens de restablecimiento de contraseña
+const (
+	queryCreatePasswordReset = `
+		INSERT INTO password_reset_tokens (id, user_id, token_hash, created_by, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)`
+
+	// Consumo atómico: dos peticiones con el mismo token no pueden usarlo ambas
+	queryConsumePasswordReset = `
+		UPDATE password_reset_tokens SET used_at = $2
+		WHERE token_hash = $1 AND used_at IS NULL AND expires_at > $2
+		RETURNING user_id`
+
+	queryRevokePasswordResets = `
+		UPDATE password_reset_tokens SET used_at = $2 WHERE user_id = $1 AND used_at IS NULL`
+)
+
+// PasswordResetRepository implementa domain.PasswordResetRepository
+type PasswordResetRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewPasswordResetRepository crea una nueva instancia del repositorio
+func NewPasswordResetRepository(pool *pgxpool.Pool) *PasswordResetRepository {
+	return &PasswordResetRepository{pool: pool}
+}
+
+// Create guarda un token nuevo (solo el hash)
+func (r *PasswordResetRepository) Create(ctx context.Context, token *domain.PasswordResetToken) error {
+	_, err := r.pool.Exec(ctx, queryCreatePasswordReset,
+		token.ID, token.UserID, token.TokenHash, token.CreatedBy, token.ExpiresAt, token.CreatedAt,
+	)
+	return wrapDBError(err, "crear token de restablecimiento")
+}
+
+// Consume marca el token como usado y retorna el usuario al que pertenece
+func (r *PasswordResetRepository) Consume(ctx context.Context, tokenHash string) (uuid.UUID, error) {
+	var userID uuid.UUID
+	err := r.pool.QueryRow(ctx, queryConsumePasswordReset, tokenHash, time.Now()).Scan(&userID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return uuid.Nil, domain.ErrInvalidToken
+	}
+	if err != nil {
+		return uuid.Nil, wrapDBError(err, "consumir token de restablecimiento")
+	}
+	return userID, nil
+}
+
+// RevokeForUser invalida los tokens pendientes del usuario
+func (r *PasswordResetRepository) RevokeForUser(ctx context.Context, userID uuid.UUID) error {
+	_, err := r.pool.Exec(ctx, queryRevokePasswordResets, userID, time.Now())
+	return wrapDBError(err, "revocar tokens de restablecimiento")
+}
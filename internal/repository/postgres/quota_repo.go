@@ -0,0 +1,162 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"telegram-api/internal/domain"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Queries SQL para cuotas
+const (
+	querySelectQuotaOverrides = `
+		SELECT max_sessions, daily_sends, monthly_sends, bulk_recipients, monthly_media_mb
+		FROM user_quotas WHERE user_id = $1`
+
+	queryUpsertQuotaOverrides = `
+		INSERT INTO user_quotas (user_id, max_sessions, daily_sends, monthly_sends, bulk_recipients, monthly_media_mb, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, NOW())
+		ON CONFLICT (user_id) DO UPDATE SET
+			max_sessions = EXCLUDED.max_sessions,
+			daily_sends = EXCLUDED.daily_sends,
+			monthly_sends = EXCLUDED.monthly_sends,
+			bulk_recipients = EXCLUDED.bulk_recipients,
+			monthly_media_mb = EXCLUDED.monthly_media_mb,
+			updated_at = NOW()`
+
+	queryDeleteQuotaOverrides = `
+		DELETE FROM user_quotas WHERE user_id = $1`
+
+	// Solo suma si el resultado no supera $5 (0 = sin límite); sin fila
+	// retornada = límite alcanzado
+	queryConsumeUsage = `
+		INSERT INTO usage_counters (user_id, metric, period_start, amount)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (user_id, metric, period_start) DO UPDATE
+			SET amount = usage_counters.amount + EXCLUDED.amount
+			WHERE $5 = 0 OR usage_counters.amount + EXCLUDED.amount <= $5
+		RETURNING amount`
+
+	// Nunca deja un contador en negativo
+	queryRefundUsage = `
+		UPDATE usage_counters SET amount = GREATEST(amount - $4, 0)
+		WHERE user_id = $1 AND metric = $2 AND period_start = $3`
+
+	querySelectUsage = `
+		SELECT amount FROM usage_counters
+		WHERE user_id = $1 AND metric = $2 AND period_start = $3`
+
+	queryDeleteUsageBefore = `
+		DELETE FROM usage_counters WHERE period_start < $1`
+)
+
+// QuotaRepository implementa domain.QuotaRepository
+type QuotaRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewQuotaRepository crea una nueva instancia del repositorio
+func NewQuotaRepository(pool *pgxpool.Pool) *QuotaRepository {
+	return &QuotaRepository{pool: pool}
+}
+
+// GetOverrides cuotas propias del usuario; nil si usa las globales
+func (r *QuotaRepository) GetOverrides(ctx context.Context, userID uuid.UUID) (*domain.QuotaOverrides, error) {
+	o := &domain.QuotaOverrides{}
+	err := r.pool.QueryRow(ctx, querySelectQuotaOverrides, userID).Scan(
+		&o.MaxSessions, &o.DailySends, &o.MonthlySends, &o.BulkRecipients, &o.MonthlyMediaMB,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, wrapDBError(err, "obtener cuotas")
+	}
+	return o, nil
+}
+
+// SetOverrides guarda las cuotas propias del usuario; nil las elimina
+func (r *QuotaRepository) SetOverrides(ctx context.Context, userID uuid.UUID, o *domain.QuotaOverrides) error {
+	if o == nil {
+		_, err := r.pool.Exec(ctx, queryDeleteQuotaOverrides, userID)
+		return wrapDBError(err, "eliminar cuotas")
+	}
+	_, err := r.pool.Exec(ctx, queryUpsertQuotaOverrides,
+		userID, o.MaxSessions, o.DailySends, o.MonthlySends, o.BulkRecipients, o.MonthlyMediaMB,
+	)
+	return wrapDBError(err, "guardar cuotas")
+}
+
+// errUsageLimit aborta la transacción de Consume
+type errUsageLimit struct {
+	metric domain.UsageMetric
+}
+
+func (e *errUsageLimit) Error() string { return "límite de " + string(e.metric) }
+
+// Consume aplica todos los cargos en una transacción; si alguno supera su
+// límite no se aplica ninguno y se retorna su métrica
+func (r *QuotaRepository) Consume(ctx context.Context, userID uuid.UUID, charges []domain.UsageCharge) (domain.UsageMetric, error) {
+	err := pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		for _, c := range charges {
+			if c.Limit > 0 && c.Amount > c.Limit {
+				return &errUsageLimit{metric: c.Metric}
+			}
+			var amount int64
+			err := tx.QueryRow(ctx, queryConsumeUsage, userID, string(c.Metric), c.Period, c.Amount, c.Limit).Scan(&amount)
+			if errors.Is(err, pgx.ErrNoRows) {
+				return &errUsageLimit{metric: c.Metric}
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+
+	var limitErr *errUsageLimit
+	if errors.As(err, &limitErr) {
+		return limitErr.metric, nil
+	}
+	return "", wrapDBError(err, "consumir cuota")
+}
+
+// Refund descuenta los cargos de sus contadores; los periodos ya purgados se ignoran
+func (r *QuotaRepository) Refund(ctx context.Context, userID uuid.UUID, charges []domain.UsageCharge) error {
+	err := pgx.BeginFunc(ctx, r.pool, func(tx pgx.Tx) error {
+		for _, c := range charges {
+			if _, err := tx.Exec(ctx, queryRefundUsage, userID, string(c.Metric), c.Period, c.Amount); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return wrapDBError(err, "devolver cuota")
+}
+
+// Usage valor de un contador; 0 si aún no existe
+func (r *QuotaRepository) Usage(ctx context.Context, userID uuid.UUID, metric domain.UsageMetric, period time.Time) (int64, error) {
+	var amount int64
+	err := r.pool.QueryRow(ctx, querySelectUsage, userID, string(metric), period).Scan(&amount)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, wrapDBError(err, "obtener consumo")
+	}
+	return amount, nil
+}
+
+// DeleteBefore elimina los contadores de periodos anteriores a before
+func (r *QuotaRepository) DeleteBefore(ctx context.Context, before time.Time) (int64, error) {
+	tag, err := r.pool.Exec(ctx, queryDeleteUsageBefore, before)
+	if err != nil {
+		return 0, wrapDBError(err, "purgar contadores de consumo")
+	}
+	return tag.RowsAffected(), nil
+}
@@ -20,17 +20,55 @@ func NewSessionRepository(db *pgxpool.Pool) *SessionRepository {
 	return &SessionRepository{db: db}
 }
 
-func (r *SessionRepository) Create(ctx context.Context, s *domain.TelegramSession) error {
-	query := `
+const queryInsertSession = `
 		INSERT INTO telegram_sessions (
 			id, user_id, phone_number, api_id, api_hash_encrypted,
-			session_name, session_data, auth_state, is_active, created_at, updated_at
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+			session_name, session_data, auth_state, telegram_user_id, telegram_username,
+			is_bot, is_active, organization_id, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
 	`
-	_, err := r.db.Exec(ctx, query,
+
+func insertSessionArgs(s *domain.TelegramSession) []any {
+	return []any{
 		s.ID, s.UserID, s.PhoneNumber, s.ApiID, s.ApiHashEncrypted,
-		s.SessionName, s.SessionData, s.AuthState, s.IsActive, s.CreatedAt, s.UpdatedAt,
-	)
+		s.SessionName, s.SessionData, s.AuthState, nullableInt64(s.TelegramUserID), nullableString(s.TelegramUsername),
+		s.IsBot, s.IsActive, s.OrganizationID, s.CreatedAt, s.UpdatedAt,
+	}
+}
+
+func (r *SessionRepository) Create(ctx context.Context, s *domain.TelegramSession) error {
+	_, err := r.db.Exec(ctx, queryInsertSession, insertSessionArgs(s)...)
+	return wrapDBError(err, "crear sesión")
+}
+
+// CreateWithinLimit inserta la sesión solo si el usuario es responsable de
+// menos de limit sesiones (0 = sin límite). Un advisory lock por usuario
+// serializa las altas concurrentes, así el conteo y el insert son atómicos.
+func (r *SessionRepository) CreateWithinLimit(ctx context.Context, s *domain.TelegramSession, limit int) error {
+	if limit <= 0 {
+		return r.Create(ctx, s)
+	}
+
+	err := pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock(hashtextextended($1, 0))`,
+			"telegram_sessions:"+s.UserID.String()); err != nil {
+			return err
+		}
+
+		var n int
+		if err := tx.QueryRow(ctx, `SELECT COUNT(*) FROM telegram_sessions WHERE user_id = $1`, s.UserID).Scan(&n); err != nil {
+			return err
+		}
+		if n >= limit {
+			return domain.ErrSessionQuotaExceeded
+		}
+
+		_, err := tx.Exec(ctx, queryInsertSession, insertSessionArgs(s)...)
+		return err
+	})
+	if errors.Is(err, domain.ErrSessionQuotaExceeded) {
+		return err
+	}
 	return wrapDBError(err, "crear sesión")
 }
 
@@ -52,13 +90,13 @@ func (r *SessionRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.
 	query := `
 		SELECT id, user_id, phone_number, api_id, api_hash_encrypted, session_name, 
 			session_data, auth_state, COALESCE(telegram_user_id, 0), COALESCE(telegram_username, ''), 
-			is_active, created_at, updated_at
+			COALESCE(is_bot, false), is_active, organization_id, created_at, updated_at
 		FROM telegram_sessions WHERE id = $1
 	`
 	var s domain.TelegramSession
 	err := r.db.QueryRow(ctx, query, id).Scan(
 		&s.ID, &s.UserID, &s.PhoneNumber, &s.ApiID, &s.ApiHashEncrypted, &s.SessionName,
-		&s.SessionData, &s.AuthState, &s.TelegramUserID, &s.TelegramUsername, &s.IsActive, &s.CreatedAt, &s.UpdatedAt,
+		&s.SessionData, &s.AuthState, &s.TelegramUserID, &s.TelegramUsername, &s.IsBot, &s.IsActive, &s.OrganizationID, &s.CreatedAt, &s.UpdatedAt,
 	)
 	if errors.Is(err, pgx.ErrNoRows) {
 		return nil, domain.ErrSessionNotFound
@@ -73,13 +111,13 @@ func (r *SessionRepository) GetByPhone(ctx context.Context, phone string) (*doma
 	query := `
 		SELECT id, user_id, phone_number, api_id, api_hash_encrypted, session_name,
 			session_data, auth_state, COALESCE(telegram_user_id, 0), COALESCE(telegram_username, ''),
-			is_active, created_at, updated_at
+			COALESCE(is_bot, false), is_active, organization_id, created_at, updated_at
 		FROM telegram_sessions WHERE phone_number = $1
 	`
 	var s domain.TelegramSession
 	err := r.db.QueryRow(ctx, query, phone).Scan(
 		&s.ID, &s.UserID, &s.PhoneNumber, &s.ApiID, &s.ApiHashEncrypted, &s.SessionName,
-		&s.SessionData, &s.AuthState, &s.TelegramUserID, &s.TelegramUsername, &s.IsActive, &s.CreatedAt, &s.UpdatedAt,
+		&s.SessionData, &s.AuthState, &s.TelegramUserID, &s.TelegramUsername, &s.IsBot, &s.IsActive, &s.OrganizationID, &s.CreatedAt, &s.UpdatedAt,
 	)
 	if errors.Is(err, pgx.ErrNoRows) {
 		return nil, domain.ErrSessionNotFound
@@ -91,13 +129,13 @@ func (r *SessionRepository) GetByUserAndPhone(ctx context.Context, userID uuid.U
 	query := `
 		SELECT id, user_id, phone_number, api_id, api_hash_encrypted, session_name,
 			session_data, auth_state, COALESCE(telegram_user_id, 0), COALESCE(telegram_username, ''),
-			is_active, created_at, updated_at
+			COALESCE(is_bot, false), is_active, organization_id, created_at, updated_at
 		FROM telegram_sessions WHERE user_id = $1 AND phone_number = $2
 	`
 	var s domain.TelegramSession
 	err := r.db.QueryRow(ctx, query, userID, phone).Scan(
 		&s.ID, &s.UserID, &s.PhoneNumber, &s.ApiID, &s.ApiHashEncrypted, &s.SessionName,
-		&s.SessionData, &s.AuthState, &s.TelegramUserID, &s.TelegramUsername, &s.IsActive, &s.CreatedAt, &s.UpdatedAt,
+		&s.SessionData, &s.AuthState, &s.TelegramUserID, &s.TelegramUsername, &s.IsBot, &s.IsActive, &s.OrganizationID, &s.CreatedAt, &s.UpdatedAt,
 	)
 	if errors.Is(err, pgx.ErrNoRows) {
 		return nil, domain.ErrSessionNotFound
@@ -109,7 +147,7 @@ func (r *SessionRepository) ListByUserID(ctx context.Context, userID uuid.UUID)
 	query := `
 		SELECT id, user_id, phone_number, api_id, api_hash_encrypted, session_name,
 			session_data, auth_state, COALESCE(telegram_user_id, 0), COALESCE(telegram_username, ''),
-			is_active, created_at, updated_at
+			COALESCE(is_bot, false), is_active, organization_id, created_at, updated_at
 		FROM telegram_sessions WHERE user_id = $1 ORDER BY created_at DESC
 	`
 	rows, err := r.db.Query(ctx, query, userID)
@@ -117,6 +155,37 @@ func (r *SessionRepository) ListByUserID(ctx context.Context, userID uuid.UUID)
 		logger.Error().Err(err).Str("user_id", userID.String()).Msg("Error query ListByUserID")
 		return nil, wrapDBError(err, "listar sesiones")
 	}
+	return scanSessions(rows)
+}
+
+// CountByUserID sesiones de las que el usuario es responsable
+func (r *SessionRepository) CountByUserID(ctx context.Context, userID uuid.UUID) (int, error) {
+	var n int
+	err := r.db.QueryRow(ctx, `SELECT COUNT(*) FROM telegram_sessions WHERE user_id = $1`, userID).Scan(&n)
+	return n, wrapDBError(err, "contar sesiones")
+}
+
+// ListAccessible sesiones personales del usuario más las de las
+// organizaciones de las que es miembro
+func (r *SessionRepository) ListAccessible(ctx context.Context, userID uuid.UUID) ([]domain.TelegramSession, error) {
+	query := `
+		SELECT id, user_id, phone_number, api_id, api_hash_encrypted, session_name,
+			session_data, auth_state, COALESCE(telegram_user_id, 0), COALESCE(telegram_username, ''),
+			COALESCE(is_bot, false), is_active, organization_id, created_at, updated_at
+		FROM telegram_sessions
+		WHERE (organization_id IS NULL AND user_id = $1)
+			OR organization_id IN (SELECT organization_id FROM organization_members WHERE user_id = $1)
+		ORDER BY created_at DESC
+	`
+	rows, err := r.db.Query(ctx, query, userID)
+	if err != nil {
+		logger.Error().Err(err).Str("user_id", userID.String()).Msg("Error query ListAccessible")
+		return nil, wrapDBError(err, "listar sesiones")
+	}
+	return scanSessions(rows)
+}
+
+func scanSessions(rows pgx.Rows) ([]domain.TelegramSession, error) {
 	defer rows.Close()
 
 	var sessions []domain.TelegramSession
@@ -124,9 +193,9 @@ func (r *SessionRepository) ListByUserID(ctx context.Context, userID uuid.UUID)
 		var s domain.TelegramSession
 		if err := rows.Scan(
 			&s.ID, &s.UserID, &s.PhoneNumber, &s.ApiID, &s.ApiHashEncrypted, &s.SessionName,
-			&s.SessionData, &s.AuthState, &s.TelegramUserID, &s.TelegramUsername, &s.IsActive, &s.CreatedAt, &s.UpdatedAt,
+			&s.SessionData, &s.AuthState, &s.TelegramUserID, &s.TelegramUsername, &s.IsBot, &s.IsActive, &s.OrganizationID, &s.CreatedAt, &s.UpdatedAt,
 		); err != nil {
-			logger.Error().Err(err).Msg("Error scan ListByUserID")
+			logger.Error().Err(err).Msg("Error scan sesiones")
 			return nil, wrapDBError(err, "scan sesión")
 		}
 		sessions = append(sessions, s)
@@ -151,4 +220,46 @@ func (r *SessionRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	return nil
 }
 
-var _ domain.SessionRepository = (*SessionRepository)(nil)
\ No newline at end of file
+func (r *SessionRepository) ListCiphertexts(ctx context.Context, after uuid.UUID, limit int) ([]domain.TelegramSession, error) {
+	query := `
+		SELECT id, api_hash_encrypted, session_data
+		FROM telegram_sessions WHERE id > $1 ORDER BY id LIMIT $2
+	`
+	rows, err := r.db.Query(ctx, query, after, limit)
+	if err != nil {
+		return nil, wrapDBError(err, "listar datos cifrados")
+	}
+	defer rows.Close()
+
+	var sessions []domain.TelegramSession
+	for rows.Next() {
+		var s domain.TelegramSession
+		if err := rows.Scan(&s.ID, &s.ApiHashEncrypted, &s.SessionData); err != nil {
+			return nil, wrapDBError(err, "scan datos cifrados")
+		}
+		sessions = append(sessions, s)
+	}
+	return sessions, wrapDBError(rows.Err(), "rows error")
+}
+
+func (r *SessionRepository) UpdateCiphertexts(ctx context.Context, id uuid.UUID, oldApiHash, oldSessionData, apiHash, sessionData []byte) (bool, error) {
+	query := `
+		UPDATE telegram_sessions SET api_hash_encrypted = $1, session_data = $2
+		WHERE id = $3 AND api_hash_encrypted = $4 AND session_data IS NOT DISTINCT FROM $5
+	`
+	result, err := r.db.Exec(ctx, query, apiHash, sessionData, id, oldApiHash, oldSessionData)
+	if err != nil {
+		return false, wrapDBError(err, "actualizar datos cifrados")
+	}
+	return result.RowsAffected() == 1, nil
+}
+
+var _ domain.SessionRepository = (*SessionRepository)(nil)
+
+// nullableInt64 convierte 0 a nil para campos nullable
+func nullableInt64(n int64) *int64 {
+	if n == 0 {
+		return nil
+	}
+	return &n
+}
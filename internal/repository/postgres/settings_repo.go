@@ -0,0 +1,51 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Queries SQL para ajustes globales
+const (
+	queryGetSetting = `
+		SELECT value FROM app_settings WHERE key = $1`
+
+	querySetSetting = `
+		INSERT INTO app_settings (key, value, updated_at) VALUES ($1, $2, $3)
+		ON CONFLICT (key) DO UPDATE SET value = EXCLUDED.value, updated_at = EXCLUDED.updated_at`
+)
+
+// SettingsRepository implementa domain.SettingsRepository
+type SettingsRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewSettingsRepository crea una nueva instancia del repositorio
+func NewSettingsRepository(pool *pgxpool.Pool) *SettingsRepository {
+	return &SettingsRepository{pool: pool}
+}
+
+// Get retorna el valor del ajuste; false si no está definido
+func (r *SettingsRepository) Get(ctx context.Context, key string) (string, bool, error) {
+	var value string
+	err := r.pool.QueryRow(ctx, queryGetSetting, key).Scan(&value)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, wrapDBError(err, "obtener ajuste")
+	}
+	return value, true, nil
+}
+
+// Set crea o actualiza un ajuste
+func (r *SettingsRepository) Set(ctx context.Context, key, value string) error {
+	if _, err := r.pool.Exec(ctx, querySetSetting, key, value, time.Now()); err != nil {
+		return wrapDBError(err, "guardar ajuste")
+	}
+	return nil
+}
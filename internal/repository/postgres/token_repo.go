@@ -15,11 +15,11 @@ import (
 // Queries SQL para refresh tokens
 const (
 	queryCreateToken = `
-		INSERT INTO refresh_tokens (id, user_id, token_hash, device_info, ip_address, expires_at, created_at)
-		VALUES ($1, $2, $3, $4, $5::inet, $6, $7)`
+		INSERT INTO refresh_tokens (id, user_id, family_id, token_hash, device_info, ip_address, expires_at, login_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6::inet, $7, $8, $9)`
 
 	queryGetTokenByHash = `
-		SELECT id, user_id, token_hash, device_info, ip_address, expires_at, revoked_at, created_at
+		SELECT id, user_id, COALESCE(family_id, id), token_hash, device_info, host(ip_address), expires_at, revoked_at, COALESCE(login_at, created_at), created_at
 		FROM refresh_tokens 
 		WHERE token_hash = $1`
 
@@ -29,11 +29,15 @@ const (
 	queryRevokeAllUserTokens = `
 		UPDATE refresh_tokens SET revoked_at = $2 WHERE user_id = $1 AND revoked_at IS NULL`
 
+	queryRevokeTokenFamily = `
+		UPDATE refresh_tokens SET revoked_at = $2 WHERE family_id = $1 AND revoked_at IS NULL`
+
+	// Los revocados se conservan hasta expirar para detectar reutilización
 	queryDeleteExpiredTokens = `
-		DELETE FROM refresh_tokens WHERE expires_at < $1 OR revoked_at IS NOT NULL`
+		DELETE FROM refresh_tokens WHERE expires_at < $1`
 
 	queryGetActiveTokensByUser = `
-		SELECT id, user_id, token_hash, device_info, ip_address, expires_at, revoked_at, created_at
+		SELECT id, user_id, COALESCE(family_id, id), token_hash, device_info, host(ip_address), expires_at, revoked_at, COALESCE(login_at, created_at), created_at
 		FROM refresh_tokens 
 		WHERE user_id = $1 AND revoked_at IS NULL AND expires_at > $2
 		ORDER BY created_at DESC`
@@ -59,10 +63,12 @@ func (r *RefreshTokenRepository) Create(ctx context.Context, token *domain.Refre
 	_, err := r.pool.Exec(ctx, queryCreateToken,
 		token.ID,
 		token.UserID,
+		token.FamilyID,
 		token.TokenHash,
 		token.DeviceInfo,
 		nullableString(token.IPAddress),
 		token.ExpiresAt,
+		token.LoginAt,
 		token.CreatedAt,
 	)
 	if err != nil {
@@ -79,11 +85,13 @@ func (r *RefreshTokenRepository) GetByTokenHash(ctx context.Context, tokenHash s
 	err := r.pool.QueryRow(ctx, queryGetTokenByHash, tokenHash).Scan(
 		&token.ID,
 		&token.UserID,
+		&token.FamilyID,
 		&token.TokenHash,
 		&token.DeviceInfo,
 		&ipAddr,
 		&token.ExpiresAt,
 		&token.RevokedAt,
+		&token.LoginAt,
 		&token.CreatedAt,
 	)
 	if err != nil {
@@ -121,7 +129,16 @@ func (r *RefreshTokenRepository) RevokeAllForUser(ctx context.Context, userID uu
 	return nil
 }
 
-// DeleteExpired elimina tokens expirados o revocados
+// RevokeFamily revoca todos los tokens activos de una familia de rotación
+func (r *RefreshTokenRepository) RevokeFamily(ctx context.Context, familyID uuid.UUID) (int64, error) {
+	result, err := r.pool.Exec(ctx, queryRevokeTokenFamily, familyID, time.Now())
+	if err != nil {
+		return 0, wrapDBError(err, "revocar familia de tokens")
+	}
+	return result.RowsAffected(), nil
+}
+
+// DeleteExpired elimina tokens expirados
 func (r *RefreshTokenRepository) DeleteExpired(ctx context.Context) (int64, error) {
 	result, err := r.pool.Exec(ctx, queryDeleteExpiredTokens, time.Now())
 	if err != nil {
@@ -146,11 +163,13 @@ func (r *RefreshTokenRepository) GetActiveByUserID(ctx context.Context, userID u
 		err := rows.Scan(
 			&token.ID,
 			&token.UserID,
+			&token.FamilyID,
 			&token.TokenHash,
 			&token.DeviceInfo,
 			&ipAddr,
 			&token.ExpiresAt,
 			&token.RevokedAt,
+			&token.LoginAt,
 			&token.CreatedAt,
 		)
 		if err != nil {
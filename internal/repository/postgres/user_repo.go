@@ -3,6 +3,8 @@ package postgres
 import (
 	"context"
 	"errors"
+	"fmt"
+	"strings"
 	"time"
 
 	"telegram-api/internal/domain"
@@ -12,6 +14,11 @@ import (
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+// userColumns columnas leídas por scanUser
+const userColumns = `id, username, email, password_hash, is_active, role, scopes, last_login_at, created_at, updated_at,
+		mfa_enabled, mfa_secret_encrypted, mfa_pending_secret_encrypted, mfa_recovery_codes,
+		failed_login_attempts, locked_until, password_changed_at`
+
 // Queries SQL como constantes privadas (Single Responsibility: solo SQL de usuarios)
 const (
 	queryCreateUser = `
@@ -19,15 +26,15 @@ const (
 		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`
 
 	queryGetUserByID = `
-		SELECT id, username, email, password_hash, is_active, role, last_login_at, created_at, updated_at
+		SELECT ` + userColumns + `
 		FROM users WHERE id = $1`
 
 	queryGetUserByUsername = `
-		SELECT id, username, email, password_hash, is_active, role, last_login_at, created_at, updated_at
+		SELECT ` + userColumns + `
 		FROM users WHERE username = $1`
 
 	queryGetUserByEmail = `
-		SELECT id, username, email, password_hash, is_active, role, last_login_at, created_at, updated_at
+		SELECT ` + userColumns + `
 		FROM users WHERE email = $1`
 
 	queryUpdateUser = `
@@ -38,8 +45,15 @@ const (
 	queryUpdateLastLogin = `
 		UPDATE users SET last_login_at = $2, updated_at = $2 WHERE id = $1`
 
+	// Borra en cascada sesiones, tokens y API keys del usuario
 	queryDeleteUser = `
-		UPDATE users SET is_active = false, updated_at = $2 WHERE id = $1`
+		DELETE FROM users WHERE id = $1`
+
+	// $1 patrón ILIKE ('' = todos), $2 rol ('' = todos), $3 activo (NULL = todos)
+	queryUserFilter = `
+		WHERE ($1 = '' OR username ILIKE $1 OR email ILIKE $1)
+			AND ($2 = '' OR role = $2)
+			AND ($3::boolean IS NULL OR is_active = $3)`
 
 	queryExistsByUsername = `
 		SELECT EXISTS(SELECT 1 FROM users WHERE username = $1)`
@@ -48,19 +62,60 @@ const (
 		SELECT EXISTS(SELECT 1 FROM users WHERE email = $1)`
 
 	queryUpdatePassword = `
-		UPDATE users SET password_hash = $2, updated_at = $3 WHERE id = $1`
+		UPDATE users SET password_hash = $2, password_changed_at = $3, updated_at = $3 WHERE id = $1`
+
+	queryRecordFailedLogin = `
+		UPDATE users SET failed_login_attempts = failed_login_attempts + 1 WHERE id = $1
+		RETURNING failed_login_attempts`
+
+	queryLockUser = `
+		UPDATE users SET locked_until = $2 WHERE id = $1`
+
+	queryResetFailedLogins = `
+		UPDATE users SET failed_login_attempts = 0, locked_until = NULL WHERE id = $1`
+
+	queryUpdateScopes = `
+		UPDATE users SET scopes = $2, updated_at = $3 WHERE id = $1`
+
+	querySetMFAPendingSecret = `
+		UPDATE users SET mfa_pending_secret_encrypted = $2, updated_at = $3 WHERE id = $1`
+
+	queryEnableMFA = `
+		UPDATE users SET
+			mfa_enabled = TRUE, mfa_secret_encrypted = mfa_pending_secret_encrypted,
+			mfa_pending_secret_encrypted = NULL, mfa_recovery_codes = $2, mfa_last_step = NULL, updated_at = $3
+		WHERE id = $1 AND mfa_pending_secret_encrypted IS NOT NULL`
+
+	queryDisableMFA = `
+		UPDATE users SET
+			mfa_enabled = FALSE, mfa_secret_encrypted = NULL, mfa_pending_secret_encrypted = NULL,
+			mfa_recovery_codes = NULL, mfa_last_step = NULL, updated_at = $2
+		WHERE id = $1`
+
+	querySetMFARecoveryCodes = `
+		UPDATE users SET mfa_recovery_codes = $2, updated_at = $3 WHERE id = $1 AND mfa_enabled`
+
+	// Consumir un código es atómico: solo una petición puede quitarlo del array
+	queryConsumeMFARecoveryCode = `
+		UPDATE users SET mfa_recovery_codes = array_remove(mfa_recovery_codes, $2)
+		WHERE id = $1 AND $2 = ANY(mfa_recovery_codes)`
+
+	queryUseMFAStep = `
+		UPDATE users SET mfa_last_step = $2
+		WHERE id = $1 AND (mfa_last_step IS NULL OR mfa_last_step < $2)`
 )
 
 // UserRepository implementa domain.UserRepository usando PostgreSQL
 // Principio: Single Responsibility - Solo maneja operaciones de usuarios
 type UserRepository struct {
-	pool *pgxpool.Pool
+	pool   *pgxpool.Pool
+	cipher SecretCipher // Cifra los secretos TOTP en reposo
 }
 
 // NewUserRepository crea una nueva instancia del repositorio
 // Dependency Inversion: Recibe el pool como dependencia
-func NewUserRepository(pool *pgxpool.Pool) *UserRepository {
-	return &UserRepository{pool: pool}
+func NewUserRepository(pool *pgxpool.Pool, cipher SecretCipher) *UserRepository {
+	return &UserRepository{pool: pool, cipher: cipher}
 }
 
 // Create crea un nuevo usuario en la base de datos
@@ -83,18 +138,7 @@ func (r *UserRepository) Create(ctx context.Context, user *domain.User) error {
 
 // GetByID obtiene un usuario por su ID
 func (r *UserRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.User, error) {
-	user := &domain.User{}
-	err := r.pool.QueryRow(ctx, queryGetUserByID, id).Scan(
-		&user.ID,
-		&user.Username,
-		&user.Email,
-		&user.PasswordHash,
-		&user.IsActive,
-		&user.Role,
-		&user.LastLoginAt,
-		&user.CreatedAt,
-		&user.UpdatedAt,
-	)
+	user, err := r.scanUser(r.pool.QueryRow(ctx, queryGetUserByID, id))
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, domain.ErrUserNotFound
@@ -106,18 +150,7 @@ func (r *UserRepository) GetByID(ctx context.Context, id uuid.UUID) (*domain.Use
 
 // GetByUsername obtiene un usuario por su nombre de usuario
 func (r *UserRepository) GetByUsername(ctx context.Context, username string) (*domain.User, error) {
-	user := &domain.User{}
-	err := r.pool.QueryRow(ctx, queryGetUserByUsername, username).Scan(
-		&user.ID,
-		&user.Username,
-		&user.Email,
-		&user.PasswordHash,
-		&user.IsActive,
-		&user.Role,
-		&user.LastLoginAt,
-		&user.CreatedAt,
-		&user.UpdatedAt,
-	)
+	user, err := r.scanUser(r.pool.QueryRow(ctx, queryGetUserByUsername, username))
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, domain.ErrUserNotFound
@@ -129,18 +162,7 @@ func (r *UserRepository) GetByUsername(ctx context.Context, username string) (*d
 
 // GetByEmail obtiene un usuario por su email
 func (r *UserRepository) GetByEmail(ctx context.Context, email string) (*domain.User, error) {
-	user := &domain.User{}
-	err := r.pool.QueryRow(ctx, queryGetUserByEmail, email).Scan(
-		&user.ID,
-		&user.Username,
-		&user.Email,
-		&user.PasswordHash,
-		&user.IsActive,
-		&user.Role,
-		&user.LastLoginAt,
-		&user.CreatedAt,
-		&user.UpdatedAt,
-	)
+	user, err := r.scanUser(r.pool.QueryRow(ctx, queryGetUserByEmail, email))
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, domain.ErrUserNotFound
@@ -178,9 +200,9 @@ func (r *UserRepository) UpdateLastLogin(ctx context.Context, id uuid.UUID) erro
 	return nil
 }
 
-// Delete realiza un soft delete del usuario
+// Delete elimina el usuario y, en cascada, sus datos
 func (r *UserRepository) Delete(ctx context.Context, id uuid.UUID) error {
-	result, err := r.pool.Exec(ctx, queryDeleteUser, id, time.Now())
+	result, err := r.pool.Exec(ctx, queryDeleteUser, id)
 	if err != nil {
 		return wrapDBError(err, "eliminar usuario")
 	}
@@ -190,6 +212,44 @@ func (r *UserRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	return nil
 }
 
+// List retorna una página de usuarios (más recientes primero) y el total filtrado
+func (r *UserRepository) List(ctx context.Context, filter domain.UserFilter) ([]*domain.User, int64, error) {
+	pattern := ""
+	if filter.Search != "" {
+		pattern = "%" + escapeLike(filter.Search) + "%"
+	}
+	args := []any{pattern, string(filter.Role), filter.IsActive}
+
+	var total int64
+	if err := r.pool.QueryRow(ctx, `SELECT COUNT(*) FROM users `+queryUserFilter, args...).Scan(&total); err != nil {
+		return nil, 0, wrapDBError(err, "contar usuarios")
+	}
+
+	rows, err := r.pool.Query(ctx, `SELECT `+userColumns+` FROM users `+queryUserFilter+`
+		ORDER BY created_at DESC LIMIT $4 OFFSET $5`,
+		append(args, filter.Limit, filter.Offset)...,
+	)
+	if err != nil {
+		return nil, 0, wrapDBError(err, "listar usuarios")
+	}
+	defer rows.Close()
+
+	users := []*domain.User{}
+	for rows.Next() {
+		user, err := r.scanUser(rows)
+		if err != nil {
+			return nil, 0, wrapDBError(err, "escanear usuario")
+		}
+		users = append(users, user)
+	}
+	return users, total, rows.Err()
+}
+
+// escapeLike escapa los comodines de LIKE en la búsqueda
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
+
 // ExistsByUsername verifica si existe un usuario con ese username
 func (r *UserRepository) ExistsByUsername(ctx context.Context, username string) (bool, error) {
 	var exists bool
@@ -222,5 +282,237 @@ func (r *UserRepository) UpdatePassword(ctx context.Context, id uuid.UUID, passw
 	return nil
 }
 
+// RecordFailedLogin suma un intento fallido y retorna el total acumulado
+func (r *UserRepository) RecordFailedLogin(ctx context.Context, id uuid.UUID) (int, error) {
+	var attempts int
+	err := r.pool.QueryRow(ctx, queryRecordFailedLogin, id).Scan(&attempts)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, domain.ErrUserNotFound
+	}
+	if err != nil {
+		return 0, wrapDBError(err, "registrar intento fallido")
+	}
+	return attempts, nil
+}
+
+// LockUntil bloquea el login del usuario hasta until
+func (r *UserRepository) LockUntil(ctx context.Context, id uuid.UUID, until time.Time) error {
+	if _, err := r.pool.Exec(ctx, queryLockUser, id, until); err != nil {
+		return wrapDBError(err, "bloquear usuario")
+	}
+	return nil
+}
+
+// ResetFailedLogins pone a cero los intentos fallidos y levanta el bloqueo
+func (r *UserRepository) ResetFailedLogins(ctx context.Context, id uuid.UUID) error {
+	result, err := r.pool.Exec(ctx, queryResetFailedLogins, id)
+	if err != nil {
+		return wrapDBError(err, "desbloquear usuario")
+	}
+	if result.RowsAffected() == 0 {
+		return domain.ErrUserNotFound
+	}
+	return nil
+}
+
+// UpdateScopes fija los scopes del usuario (nil = todos)
+func (r *UserRepository) UpdateScopes(ctx context.Context, id uuid.UUID, scopes []domain.Scope) error {
+	var values []string
+	if scopes != nil {
+		values = make([]string, len(scopes))
+		for i, scope := range scopes {
+			values[i] = string(scope)
+		}
+	}
+
+	result, err := r.pool.Exec(ctx, queryUpdateScopes, id, values, time.Now())
+	if err != nil {
+		return wrapDBError(err, "actualizar scopes")
+	}
+	if result.RowsAffected() == 0 {
+		return domain.ErrUserNotFound
+	}
+	return nil
+}
+
+// SetMFAPendingSecret guarda (cifrado) el secreto de un enrolamiento sin confirmar
+func (r *UserRepository) SetMFAPendingSecret(ctx context.Context, id uuid.UUID, secret string) error {
+	encrypted, err := r.cipher.Encrypt([]byte(secret))
+	if err != nil {
+		return err
+	}
+	result, err := r.pool.Exec(ctx, querySetMFAPendingSecret, id, encrypted, time.Now())
+	if err != nil {
+		return wrapDBError(err, "guardar secreto MFA pendiente")
+	}
+	if result.RowsAffected() == 0 {
+		return domain.ErrUserNotFound
+	}
+	return nil
+}
+
+// EnableMFA activa el secreto pendiente con los hashes de recuperación
+func (r *UserRepository) EnableMFA(ctx context.Context, id uuid.UUID, recoveryCodeHashes []string) error {
+	result, err := r.pool.Exec(ctx, queryEnableMFA, id, recoveryCodeHashes, time.Now())
+	if err != nil {
+		return wrapDBError(err, "activar MFA")
+	}
+	if result.RowsAffected() == 0 {
+		return domain.ErrMFANotEnrolled
+	}
+	return nil
+}
+
+// DisableMFA elimina secreto, enrolamiento pendiente y códigos de recuperación
+func (r *UserRepository) DisableMFA(ctx context.Context, id uuid.UUID) error {
+	result, err := r.pool.Exec(ctx, queryDisableMFA, id, time.Now())
+	if err != nil {
+		return wrapDBError(err, "desactivar MFA")
+	}
+	if result.RowsAffected() == 0 {
+		return domain.ErrUserNotFound
+	}
+	return nil
+}
+
+// SetMFARecoveryCodes reemplaza los códigos de recuperación
+func (r *UserRepository) SetMFARecoveryCodes(ctx context.Context, id uuid.UUID, recoveryCodeHashes []string) error {
+	result, err := r.pool.Exec(ctx, querySetMFARecoveryCodes, id, recoveryCodeHashes, time.Now())
+	if err != nil {
+		return wrapDBError(err, "guardar códigos de recuperación")
+	}
+	if result.RowsAffected() == 0 {
+		return domain.ErrMFANotEnabled
+	}
+	return nil
+}
+
+// ConsumeMFARecoveryCode invalida un código de recuperación; false si no existía
+func (r *UserRepository) ConsumeMFARecoveryCode(ctx context.Context, id uuid.UUID, codeHash string) (bool, error) {
+	result, err := r.pool.Exec(ctx, queryConsumeMFARecoveryCode, id, codeHash)
+	if err != nil {
+		return false, wrapDBError(err, "consumir código de recuperación")
+	}
+	return result.RowsAffected() == 1, nil
+}
+
+// UseMFAStep registra el paso TOTP usado; false si ya se usó ese paso o uno posterior
+func (r *UserRepository) UseMFAStep(ctx context.Context, id uuid.UUID, step int64) (bool, error) {
+	result, err := r.pool.Exec(ctx, queryUseMFAStep, id, step)
+	if err != nil {
+		return false, wrapDBError(err, "registrar paso TOTP")
+	}
+	return result.RowsAffected() == 1, nil
+}
+
+// ReencryptMFASecrets re-cifra con la clave primaria los secretos TOTP
+// cifrados con claves anteriores
+func (r *UserRepository) ReencryptMFASecrets(ctx context.Context) (int, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT id, mfa_secret_encrypted, mfa_pending_secret_encrypted FROM users
+		WHERE mfa_secret_encrypted IS NOT NULL OR mfa_pending_secret_encrypted IS NOT NULL
+	`)
+	if err != nil {
+		return 0, wrapDBError(err, "listar secretos MFA")
+	}
+	type stored struct {
+		id              uuid.UUID
+		secret, pending []byte
+	}
+	var all []stored
+	for rows.Next() {
+		var s stored
+		if err := rows.Scan(&s.id, &s.secret, &s.pending); err != nil {
+			rows.Close()
+			return 0, wrapDBError(err, "escanear secreto MFA")
+		}
+		all = append(all, s)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, wrapDBError(err, "iterar secretos MFA")
+	}
+
+	rotated := 0
+	for _, s := range all {
+		secret, changedSecret, err := reencryptWith(r.cipher, s.secret)
+		if err != nil {
+			return rotated, fmt.Errorf("usuario %s: %w", s.id, err)
+		}
+		pending, changedPending, err := reencryptWith(r.cipher, s.pending)
+		if err != nil {
+			return rotated, fmt.Errorf("usuario %s: %w", s.id, err)
+		}
+		if !changedSecret && !changedPending {
+			continue
+		}
+
+		result, err := r.pool.Exec(ctx, `
+			UPDATE users SET mfa_secret_encrypted = $1, mfa_pending_secret_encrypted = $2
+			WHERE id = $3
+				AND mfa_secret_encrypted IS NOT DISTINCT FROM $4
+				AND mfa_pending_secret_encrypted IS NOT DISTINCT FROM $5
+		`, secret, pending, s.id, s.secret, s.pending)
+		if err != nil {
+			return rotated, wrapDBError(err, "re-cifrar secreto MFA")
+		}
+		if result.RowsAffected() == 1 {
+			rotated++
+		}
+	}
+	return rotated, nil
+}
+
+func (r *UserRepository) scanUser(row pgx.Row) (*domain.User, error) {
+	user := &domain.User{}
+	var (
+		scopes          []string
+		secret, pending []byte
+	)
+	err := row.Scan(
+		&user.ID,
+		&user.Username,
+		&user.Email,
+		&user.PasswordHash,
+		&user.IsActive,
+		&user.Role,
+		&scopes,
+		&user.LastLoginAt,
+		&user.CreatedAt,
+		&user.UpdatedAt,
+		&user.MFAEnabled,
+		&secret,
+		&pending,
+		&user.MFARecoveryCodes,
+		&user.FailedLoginAttempts,
+		&user.LockedUntil,
+		&user.PasswordChangedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	user.Scopes = toScopes(scopes)
+
+	if user.MFASecret, err = decryptString(r.cipher, secret); err != nil {
+		return nil, fmt.Errorf("descifrar secreto MFA: %w", err)
+	}
+	if user.MFAPendingSecret, err = decryptString(r.cipher, pending); err != nil {
+		return nil, fmt.Errorf("descifrar secreto MFA pendiente: %w", err)
+	}
+	return user, nil
+}
+
+// toScopes convierte un TEXT[] a scopes conservando NULL como nil
+func toScopes(values []string) []domain.Scope {
+	if values == nil {
+		return nil
+	}
+	scopes := make([]domain.Scope, len(values))
+	for i, v := range values {
+		scopes[i] = domain.Scope(v)
+	}
+	return scopes
+}
+
 // Verificación en tiempo de compilación de que implementa la interfaz
 var _ domain.UserRepository = (*UserRepository)(nil)
\ No newline at end of file
@@ -3,6 +3,8 @@ package postgres
 import (
 	"context"
 	"errors"
+	"fmt"
+	"time"
 
 	"telegram-api/internal/domain"
 
@@ -11,41 +13,68 @@ import (
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+// SecretCipher cifra los secretos de webhook en reposo (implementado por telegram.ClientManager)
+type SecretCipher interface {
+	Encrypt(data []byte) ([]byte, error)
+	Decrypt(data []byte) ([]byte, error)
+	NeedsReencrypt(data []byte) bool
+	Reencrypt(data []byte) ([]byte, error)
+}
+
 type WebhookRepository struct {
-	db *pgxpool.Pool
+	db     *pgxpool.Pool
+	cipher SecretCipher
 }
 
-func NewWebhookRepository(db *pgxpool.Pool) *WebhookRepository {
-	return &WebhookRepository{db: db}
+func NewWebhookRepository(db *pgxpool.Pool, cipher SecretCipher) *WebhookRepository {
+	return &WebhookRepository{db: db, cipher: cipher}
 }
 
+const webhookColumns = `
+	id, session_id, url, secret_encrypted, COALESCE(secret_hint, ''), events, is_active,
+	max_retries, timeout_ms, created_at, updated_at,
+	COALESCE(last_error, ''), last_error_at,
+	previous_secret_encrypted, previous_secret_expires_at
+`
+
+// Create crea o reemplaza el webhook de la sesión. Reconfigurar descarta el
+// secreto anterior de una rotación en curso.
 func (r *WebhookRepository) Create(ctx context.Context, wh *domain.WebhookConfig) error {
+	secret, err := r.encryptSecret(wh.Secret)
+	if err != nil {
+		return err
+	}
+	wh.SecretHint = domain.MaskSecret(wh.Secret)
+
 	query := `
 		INSERT INTO webhooks (
-			id, session_id, url, secret, events, is_active, 
+			id, session_id, url, secret_encrypted, secret_hint, events, is_active,
 			max_retries, timeout_ms, created_at, updated_at
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
 		ON CONFLICT (session_id) DO UPDATE SET
-			url = $3, secret = $4, events = $5, is_active = $6,
-			max_retries = $7, timeout_ms = $8, updated_at = $10
+			url = $3, secret = NULL, secret_encrypted = $4, secret_hint = $5, events = $6, is_active = $7,
+			max_retries = $8, timeout_ms = $9, updated_at = $11,
+			previous_secret_encrypted = NULL, previous_secret_expires_at = NULL
 	`
-	_, err := r.db.Exec(ctx, query,
-		wh.ID, wh.SessionID, wh.URL, wh.Secret, wh.Events,
+	_, err = r.db.Exec(ctx, query,
+		wh.ID, wh.SessionID, wh.URL, secret, nullableString(wh.SecretHint), wh.Events,
 		wh.IsActive, wh.MaxRetries, wh.TimeoutMs, wh.CreatedAt, wh.UpdatedAt,
 	)
 	return err
 }
 
+// Update actualiza la configuración y el último error. Los secretos solo
+// cambian con Create y RotateSecret.
 func (r *WebhookRepository) Update(ctx context.Context, wh *domain.WebhookConfig) error {
 	query := `
-		UPDATE webhooks SET 
-			url = $1, secret = $2, events = $3, is_active = $4,
-			max_retries = $5, timeout_ms = $6, updated_at = $7,
-			last_error = $8, last_error_at = $9
-		WHERE session_id = $10
+		UPDATE webhooks SET
+			url = $1, events = $2, is_active = $3,
+			max_retries = $4, timeout_ms = $5, updated_at = $6,
+			last_error = $7, last_error_at = $8
+		WHERE session_id = $9
 	`
 	_, err := r.db.Exec(ctx, query,
-		wh.URL, wh.Secret, wh.Events, wh.IsActive,
+		wh.URL, wh.Events, wh.IsActive,
 		wh.MaxRetries, wh.TimeoutMs, wh.UpdatedAt,
 		wh.LastError, wh.LastErrorAt, wh.SessionID,
 	)
@@ -53,25 +82,16 @@ func (r *WebhookRepository) Update(ctx context.Context, wh *domain.WebhookConfig
 }
 
 func (r *WebhookRepository) GetBySessionID(ctx context.Context, sessionID uuid.UUID) (*domain.WebhookConfig, error) {
-	query := `
-		SELECT id, session_id, url, COALESCE(secret, ''), events, is_active,
-			max_retries, timeout_ms, created_at, updated_at,
-			COALESCE(last_error, ''), last_error_at
-		FROM webhooks WHERE session_id = $1
-	`
-	var wh domain.WebhookConfig
-	err := r.db.QueryRow(ctx, query, sessionID).Scan(
-		&wh.ID, &wh.SessionID, &wh.URL, &wh.Secret, &wh.Events,
-		&wh.IsActive, &wh.MaxRetries, &wh.TimeoutMs, &wh.CreatedAt, &wh.UpdatedAt,
-		&wh.LastError, &wh.LastErrorAt,
-	)
+	query := `SELECT ` + webhookColumns + ` FROM webhooks WHERE session_id = $1`
+
+	wh, err := r.scanWebhook(r.db.QueryRow(ctx, query, sessionID))
 	if errors.Is(err, pgx.ErrNoRows) {
 		return nil, nil
 	}
 	if err != nil {
 		return nil, err
 	}
-	return &wh, nil
+	return wh, nil
 }
 
 func (r *WebhookRepository) Delete(ctx context.Context, sessionID uuid.UUID) error {
@@ -81,12 +101,8 @@ func (r *WebhookRepository) Delete(ctx context.Context, sessionID uuid.UUID) err
 }
 
 func (r *WebhookRepository) ListActive(ctx context.Context) ([]domain.WebhookConfig, error) {
-	query := `
-		SELECT id, session_id, url, COALESCE(secret, ''), events, is_active,
-			max_retries, timeout_ms, created_at, updated_at,
-			COALESCE(last_error, ''), last_error_at
-		FROM webhooks WHERE is_active = true
-	`
+	query := `SELECT ` + webhookColumns + ` FROM webhooks WHERE is_active = true`
+
 	rows, err := r.db.Query(ctx, query)
 	if err != nil {
 		return nil, err
@@ -95,18 +111,194 @@ func (r *WebhookRepository) ListActive(ctx context.Context) ([]domain.WebhookCon
 
 	var webhooks []domain.WebhookConfig
 	for rows.Next() {
-		var wh domain.WebhookConfig
-		if err := rows.Scan(
-			&wh.ID, &wh.SessionID, &wh.URL, &wh.Secret, &wh.Events,
-			&wh.IsActive, &wh.MaxRetries, &wh.TimeoutMs, &wh.CreatedAt, &wh.UpdatedAt,
-			&wh.LastError, &wh.LastErrorAt,
-		); err != nil {
+		wh, err := r.scanWebhook(rows)
+		if err != nil {
 			return nil, err
 		}
-		webhooks = append(webhooks, wh)
+		webhooks = append(webhooks, *wh)
 	}
 
 	return webhooks, rows.Err()
 }
 
+func (r *WebhookRepository) RotateSecret(ctx context.Context, sessionID uuid.UUID, secret string, previousExpiresAt *time.Time) error {
+	encrypted, err := r.encryptSecret(secret)
+	if err != nil {
+		return err
+	}
+
+	// Sin ventana de solapamiento el secreto anterior se descarta
+	query := `
+		UPDATE webhooks SET
+			previous_secret_encrypted = CASE WHEN $1::timestamptz IS NULL THEN NULL ELSE secret_encrypted END,
+			previous_secret_expires_at = CASE WHEN secret_encrypted IS NULL THEN NULL ELSE $1::timestamptz END,
+			secret_encrypted = $2, secret_hint = $3, updated_at = NOW()
+		WHERE session_id = $4
+	`
+	result, err := r.db.Exec(ctx, query, previousExpiresAt, encrypted, domain.MaskSecret(secret), sessionID)
+	if err != nil {
+		return err
+	}
+	if result.RowsAffected() == 0 {
+		return domain.ErrWebhookNotFound
+	}
+	return nil
+}
+
+// EncryptLegacySecrets cifra los secretos que aún están en la columna secret
+// (texto plano) y la deja en NULL. Idempotente.
+func (r *WebhookRepository) EncryptLegacySecrets(ctx context.Context) (int, error) {
+	rows, err := r.db.Query(ctx, `SELECT id, secret FROM webhooks WHERE secret IS NOT NULL`)
+	if err != nil {
+		return 0, err
+	}
+	type legacy struct {
+		id     uuid.UUID
+		secret string
+	}
+	var pending []legacy
+	for rows.Next() {
+		var l legacy
+		if err := rows.Scan(&l.id, &l.secret); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		pending = append(pending, l)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	for i, l := range pending {
+		encrypted, err := r.encryptSecret(l.secret)
+		if err != nil {
+			return i, err
+		}
+		_, err = r.db.Exec(ctx, `
+			UPDATE webhooks SET secret_encrypted = $1, secret_hint = $2, secret = NULL
+			WHERE id = $3 AND secret = $4
+		`, encrypted, nullableString(domain.MaskSecret(l.secret)), l.id, l.secret)
+		if err != nil {
+			return i, err
+		}
+	}
+	return len(pending), nil
+}
+
+// ReencryptSecrets re-cifra con la clave primaria los secretos (actual y anterior)
+// cifrados con claves anteriores
+func (r *WebhookRepository) ReencryptSecrets(ctx context.Context) (int, error) {
+	rows, err := r.db.Query(ctx, `
+		SELECT id, secret_encrypted, previous_secret_encrypted FROM webhooks
+		WHERE secret_encrypted IS NOT NULL OR previous_secret_encrypted IS NOT NULL
+	`)
+	if err != nil {
+		return 0, err
+	}
+	type stored struct {
+		id               uuid.UUID
+		secret, previous []byte
+	}
+	var all []stored
+	for rows.Next() {
+		var s stored
+		if err := rows.Scan(&s.id, &s.secret, &s.previous); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		all = append(all, s)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	rotated := 0
+	for _, s := range all {
+		secret, changedSecret, err := r.reencrypt(s.secret)
+		if err != nil {
+			return rotated, fmt.Errorf("webhook %s: %w", s.id, err)
+		}
+		previous, changedPrevious, err := r.reencrypt(s.previous)
+		if err != nil {
+			return rotated, fmt.Errorf("webhook %s: %w", s.id, err)
+		}
+		if !changedSecret && !changedPrevious {
+			continue
+		}
+
+		result, err := r.db.Exec(ctx, `
+			UPDATE webhooks SET secret_encrypted = $1, previous_secret_encrypted = $2
+			WHERE id = $3
+				AND secret_encrypted IS NOT DISTINCT FROM $4
+				AND previous_secret_encrypted IS NOT DISTINCT FROM $5
+		`, secret, previous, s.id, s.secret, s.previous)
+		if err != nil {
+			return rotated, err
+		}
+		if result.RowsAffected() == 1 {
+			rotated++
+		}
+	}
+	return rotated, nil
+}
+
+func (r *WebhookRepository) scanWebhook(row pgx.Row) (*domain.WebhookConfig, error) {
+	var (
+		wh               domain.WebhookConfig
+		secret, previous []byte
+	)
+	err := row.Scan(
+		&wh.ID, &wh.SessionID, &wh.URL, &secret, &wh.SecretHint, &wh.Events,
+		&wh.IsActive, &wh.MaxRetries, &wh.TimeoutMs, &wh.CreatedAt, &wh.UpdatedAt,
+		&wh.LastError, &wh.LastErrorAt,
+		&previous, &wh.PreviousSecretExpiresAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if wh.Secret, err = r.decryptSecret(secret); err != nil {
+		return nil, fmt.Errorf("descifrar secreto de webhook: %w", err)
+	}
+	if wh.PreviousSecret, err = r.decryptSecret(previous); err != nil {
+		return nil, fmt.Errorf("descifrar secreto anterior de webhook: %w", err)
+	}
+	return &wh, nil
+}
+
+func (r *WebhookRepository) encryptSecret(secret string) ([]byte, error) {
+	if secret == "" {
+		return nil, nil
+	}
+	return r.cipher.Encrypt([]byte(secret))
+}
+
+func (r *WebhookRepository) decryptSecret(data []byte) (string, error) {
+	return decryptString(r.cipher, data)
+}
+
+func (r *WebhookRepository) reencrypt(data []byte) ([]byte, bool, error) {
+	return reencryptWith(r.cipher, data)
+}
+
+// decryptString descifra un secreto opcional (vacío si la columna es NULL)
+func decryptString(cipher SecretCipher, data []byte) (string, error) {
+	if len(data) == 0 {
+		return "", nil
+	}
+	plain, err := cipher.Decrypt(data)
+	return string(plain), err
+}
+
+// reencryptWith re-cifra con la clave primaria si hace falta; el bool indica si cambió
+func reencryptWith(cipher SecretCipher, data []byte) ([]byte, bool, error) {
+	if len(data) == 0 || !cipher.NeedsReencrypt(data) {
+		return data, false, nil
+	}
+	out, err := cipher.Reencrypt(data)
+	return out, err == nil, err
+}
+
 var _ domain.WebhookRepository = (*WebhookRepository)(nil)
\ No newline at end of file
@@ -0,0 +1,125 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"telegram-api/internal/config"
+	"telegram-api/internal/domain"
+	"telegram-api/pkg/logger"
+
+	"github.com/google/uuid"
+)
+
+// auditWriteTimeout tiempo máximo para guardar un evento
+const auditWriteTimeout = 5 * time.Second
+
+// AuditService registra y consulta los eventos de auditoría. Un *AuditService
+// nil no registra nada, para que los servicios funcionen sin auditoría.
+type AuditService struct {
+	repo   domain.AuditRepository
+	config *config.Config
+}
+
+// NewAuditService crea el servicio de auditoría
+func NewAuditService(repo domain.AuditRepository, cfg *config.Config) *AuditService {
+	return &AuditService{repo: repo, config: cfg}
+}
+
+// RecordAction registra action con el resultado de err sobre la sesión
+// indicada (nil si no aplica). El actor sale del contexto de la petición.
+func (s *AuditService) RecordAction(ctx context.Context, action string, sessionID *uuid.UUID, err error, details map[string]any) {
+	event := &domain.AuditEvent{
+		Action:    action,
+		SessionID: sessionID,
+		Outcome:   domain.AuditOutcomeOf(err),
+		Details:   details,
+	}
+	if err != nil {
+		event.Error = err.Error()
+	}
+	s.Record(ctx, event)
+}
+
+// Record guarda el evento completando con el actor de la petición (usuario,
+// API key, IP y request ID) los campos que no traiga. Un fallo al guardar no
+// interrumpe la operación auditada: solo se registra en el log.
+func (s *AuditService) Record(ctx context.Context, event *domain.AuditEvent) {
+	if s == nil {
+		return
+	}
+
+	if actor := domain.AuditActorFromContext(ctx); actor != nil {
+		if event.ActorID == nil && actor.UserID != uuid.Nil {
+			id := actor.UserID
+			event.ActorID = &id
+			event.ActorName = actor.Username
+			event.APIKeyID = actor.APIKeyID
+		}
+		if event.IPAddress == "" {
+			event.IPAddress = actor.IPAddress
+		}
+		if event.RequestID == "" {
+			event.RequestID = actor.RequestID
+		}
+	}
+	if len(event.RequestID) > 64 {
+		event.RequestID = event.RequestID[:64]
+	}
+	if event.Outcome == "" {
+		event.Outcome = domain.AuditSuccess
+	}
+	event.ID = uuid.New()
+	event.CreatedAt = time.Now()
+
+	// El evento se guarda aunque la petición se haya cancelado
+	writeCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), auditWriteTimeout)
+	defer cancel()
+
+	if err := s.repo.Insert(writeCtx, event); err != nil {
+		log := logger.Error().Err(err).
+			Str("action", event.Action).
+			Str("outcome", string(event.Outcome))
+		if event.ActorID != nil {
+			log = log.Str("actor_id", event.ActorID.String())
+		}
+		if event.SessionID != nil {
+			log = log.Str("session_id", event.SessionID.String())
+		}
+		log.Msg("❌ No se pudo guardar el evento de auditoría")
+	}
+}
+
+// List consulta los eventos con filtros y paginación
+func (s *AuditService) List(ctx context.Context, filter domain.AuditFilter) ([]*domain.AuditEvent, int64, error) {
+	events, total, err := s.repo.List(ctx, filter)
+	if err != nil {
+		logger.Error().Err(err).Msg("Error listando eventos de auditoría")
+		return nil, 0, domain.ErrDatabase
+	}
+	return events, total, nil
+}
+
+// RunRetention purga periódicamente los eventos más antiguos que
+// AUDIT_RETENTION_DAYS hasta que se cancele ctx
+func (s *AuditService) RunRetention(ctx context.Context, interval time.Duration) {
+	retention := time.Duration(s.config.Audit.RetentionDays) * 24 * time.Hour
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			deleted, err := s.repo.DeleteBefore(ctx, time.Now().Add(-retention))
+			if err != nil {
+				logger.Error().Err(err).Msg("Error purgando eventos de auditoría")
+				continue
+			}
+			if deleted > 0 {
+				logger.Info().Int64("deleted", deleted).Msg("🧹 Eventos de auditoría antiguos eliminados")
+			}
+		}
+	}
+}
@@ -2,35 +2,56 @@ package service
 
 import (
 	"context"
+	"strconv"
+	"strings"
 	"time"
 
 	"telegram-api/internal/config"
 	"telegram-api/internal/domain"
 	"telegram-api/pkg/crypto"
 	"telegram-api/pkg/logger"
+	"telegram-api/pkg/utils"
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
 )
 
 type AuthService struct {
-	userRepo  domain.UserRepository
-	tokenRepo domain.RefreshTokenRepository
-	cacheRepo domain.CacheRepository
-	config    *config.Config
+	userRepo   domain.UserRepository
+	tokenRepo  domain.RefreshTokenRepository
+	apiKeyRepo domain.APIKeyRepository
+	resetRepo  domain.PasswordResetRepository
+	inviteRepo domain.InvitationRepository
+	orgs       *OrganizationService
+	cacheRepo  domain.CacheRepository
+	settings   domain.SettingsRepository
+	audit      *AuditService
+	config     *config.Config
 }
 
 func NewAuthService(
 	userRepo domain.UserRepository,
 	tokenRepo domain.RefreshTokenRepository,
+	apiKeyRepo domain.APIKeyRepository,
+	resetRepo domain.PasswordResetRepository,
+	inviteRepo domain.InvitationRepository,
+	orgs *OrganizationService,
 	cacheRepo domain.CacheRepository,
+	settings domain.SettingsRepository,
+	audit *AuditService,
 	cfg *config.Config,
 ) *AuthService {
 	return &AuthService{
-		userRepo:  userRepo,
-		tokenRepo: tokenRepo,
-		cacheRepo: cacheRepo,
-		config:    cfg,
+		userRepo:   userRepo,
+		tokenRepo:  tokenRepo,
+		apiKeyRepo: apiKeyRepo,
+		resetRepo:  resetRepo,
+		inviteRepo: inviteRepo,
+		orgs:       orgs,
+		cacheRepo:  cacheRepo,
+		settings:   settings,
+		audit:      audit,
+		config:     cfg,
 	}
 }
 
@@ -38,36 +59,109 @@ type JWTClaims struct {
 	UserID   string      `json:"uid"`
 	Username string      `json:"username"`
 	Role     domain.Role `json:"role"`
+	// Scopes sin omitempty: una lista vacía (sin permisos) no debe confundirse
+	// con un token anterior a los scopes (nil = todos)
+	Scopes []domain.Scope `json:"scopes"`
+	// FamilyID familia de refresh tokens que emitió el token; al detectar
+	// reutilización se revocan también sus access tokens
+	FamilyID string `json:"fid,omitempty"`
+	// Purpose tokens de un solo uso del login con MFA; ValidateToken los rechaza
+	Purpose string `json:"pur,omitempty"`
 	jwt.RegisteredClaims
 }
 
 func (s *AuthService) Register(ctx context.Context, req *domain.CreateUserRequest) (*domain.User, error) {
+	user, err := s.register(ctx, req)
+	s.auditUser(ctx, domain.AuditAuthRegister, user, req.Username, err, map[string]any{
+		"invited": req.InviteToken != "",
+	})
+	return user, err
+}
+
+func (s *AuthService) register(ctx context.Context, req *domain.CreateUserRequest) (*domain.User, error) {
 	logger.Debug().Str("username", req.Username).Str("email", req.Email).Msg("Iniciando registro")
 
+	var invitation *domain.Invitation
+	switch {
+	case s.config.Registration.Mode == domain.RegistrationDisabled:
+		return nil, domain.ErrRegistrationDisabled
+	case req.InviteToken != "":
+		inv, err := s.inviteRepo.Consume(ctx, crypto.HashToken(req.InviteToken))
+		if err != nil {
+			logger.Warn().Str("username", req.Username).Msg("Invitación inválida")
+			return nil, domain.ErrInvalidInvitation
+		}
+		if inv.Email != "" && !strings.EqualFold(inv.Email, req.Email) {
+			_ = s.inviteRepo.Release(ctx, inv.ID)
+			logger.Warn().Str("username", req.Username).Msg("Invitación para otro email")
+			return nil, domain.ErrInvalidInvitation
+		}
+		invitation = inv
+	case s.config.Registration.Mode == domain.RegistrationInvite:
+		return nil, domain.ErrInvitationRequired
+	}
+
+	role := domain.RoleUser
+	if invitation != nil && invitation.Role != "" {
+		role = invitation.Role
+	}
+
+	user, err := s.createUser(ctx, req.Username, req.Email, req.Password, role)
+	if err != nil {
+		if invitation != nil {
+			_ = s.inviteRepo.Release(ctx, invitation.ID)
+		}
+		return nil, err
+	}
+	if invitation != nil {
+		if err := s.inviteRepo.SetUsedBy(ctx, invitation.ID, user.ID); err != nil {
+			logger.Warn().Err(err).Str("invitation_id", invitation.ID.String()).Msg("Error registrando uso de invitación")
+		}
+	}
+	return user, nil
+}
+
+// CreateUser crea una cuenta directamente (admin); no depende de REGISTRATION_MODE
+func (s *AuthService) CreateUser(ctx context.Context, req *domain.AdminCreateUserRequest) (*domain.User, error) {
+	role := req.Role
+	if role == "" {
+		role = domain.RoleUser
+	}
+	user, err := s.createUser(ctx, req.Username, req.Email, req.Password, role)
+
+	details := map[string]any{"username": req.Username, "role": string(role)}
+	if user != nil {
+		details["user_id"] = user.ID.String()
+	}
+	s.audit.RecordAction(ctx, domain.AuditAdminUserCreate, nil, err, details)
+	return user, err
+}
+
+func (s *AuthService) createUser(ctx context.Context, username, email, password string, role domain.Role) (*domain.User, error) {
 	// Verificar si username existe
-	exists, err := s.userRepo.ExistsByUsername(ctx, req.Username)
+	exists, err := s.userRepo.ExistsByUsername(ctx, username)
 	if err != nil {
-		logger.Error().Err(err).Str("username", req.Username).Msg("Error verificando username")
+		logger.Error().Err(err).Str("username", username).Msg("Error verificando username")
 		return nil, domain.ErrDatabase
 	}
 	if exists {
-		logger.Warn().Str("username", req.Username).Msg("Username ya existe")
+		logger.Warn().Str("username", username).Msg("Username ya existe")
 		return nil, domain.ErrUserAlreadyExists
 	}
 
 	// Verificar si email existe
-	exists, err = s.userRepo.ExistsByEmail(ctx, req.Email)
+	exists, err = s.userRepo.ExistsByEmail(ctx, email)
 	if err != nil {
-		logger.Error().Err(err).Str("email", req.Email).Msg("Error verificando email")
+		logger.Error().Err(err).Str("email", email).Msg("Error verificando email")
 		return nil, domain.ErrDatabase
 	}
 	if exists {
-		logger.Warn().Str("email", req.Email).Msg("Email ya existe")
+		logger.Warn().Str("email", email).Msg("Email ya existe")
 		return nil, domain.ErrEmailAlreadyExists
 	}
 
 	// Hash de la contraseña
-	passwordHash, err := crypto.HashPassword(req.Password)
+	passwordHash, err := crypto.HashPassword(password)
 	if err != nil {
 		logger.Error().Err(err).Msg("Error hasheando contraseña")
 		return nil, domain.ErrInternal
@@ -76,56 +170,124 @@ func (s *AuthService) Register(ctx context.Context, req *domain.CreateUserReques
 	// Crear usuario
 	user := &domain.User{
 		ID:           uuid.New(),
-		Username:     req.Username,
-		Email:        req.Email,
+		Username:     username,
+		Email:        email,
 		PasswordHash: passwordHash,
 		IsActive:     true,
-		Role:         domain.RoleUser,
+		Role:         role,
 		CreatedAt:    time.Now(),
 		UpdatedAt:    time.Now(),
 	}
 
 	if err := s.userRepo.Create(ctx, user); err != nil {
-		logger.Error().Err(err).Str("username", req.Username).Msg("Error creando usuario en DB")
+		logger.Error().Err(err).Str("username", username).Msg("Error creando usuario en DB")
 		return nil, domain.ErrDatabase
 	}
 
-	logger.Info().Str("id", user.ID.String()).Str("username", user.Username).Msg("Usuario registrado")
+	logger.Info().Str("id", user.ID.String()).Str("username", user.Username).Str("role", string(role)).Msg("Usuario registrado")
 	return user, nil
 }
 
 func (s *AuthService) Login(ctx context.Context, req *domain.LoginRequest, ipAddr, userAgent string) (*domain.LoginResponse, error) {
+	resp, user, err := s.login(ctx, req, ipAddr, userAgent)
+	s.auditLogin(ctx, user, req.Username, resp, err, "password")
+	return resp, err
+}
+
+func (s *AuthService) login(ctx context.Context, req *domain.LoginRequest, ipAddr, userAgent string) (*domain.LoginResponse, *domain.User, error) {
 	logger.Debug().Str("username", req.Username).Str("ip", ipAddr).Msg("Intento de login")
 
 	user, err := s.userRepo.GetByUsername(ctx, req.Username)
 	if err != nil {
 		logger.Warn().Err(err).Str("username", req.Username).Msg("Usuario no encontrado")
-		return nil, domain.ErrInvalidCredentials
+		return nil, nil, domain.ErrInvalidCredentials
 	}
 
 	if !user.IsActive {
 		logger.Warn().Str("username", req.Username).Msg("Usuario inactivo")
-		return nil, domain.ErrUserInactive
+		return nil, user, domain.ErrUserInactive
+	}
+
+	// Bloqueada: no se comprueba la contraseña para no dar pistas
+	if user.IsLocked(time.Now()) {
+		logger.Warn().Str("username", req.Username).Str("ip", ipAddr).Msg("Login en cuenta bloqueada")
+		return nil, user, domain.ErrAccountLocked
 	}
 
 	if !crypto.CheckPassword(req.Password, user.PasswordHash) {
 		logger.Warn().Str("username", req.Username).Msg("Contraseña incorrecta")
-		return nil, domain.ErrInvalidCredentials
+		s.registerFailedLogin(ctx, user, ipAddr)
+		return nil, user, domain.ErrInvalidCredentials
 	}
 
-	accessToken, err := s.generateAccessToken(user)
+	// Segundo factor: la contraseña solo da un token de desafío para /auth/mfa/verify
+	if user.MFAEnabled {
+		resp, err := s.mfaStep(user, tokenPurposeMFAChallenge)
+		return resp, user, err
+	}
+	required, err := s.mfaRequired(ctx)
+	if err != nil {
+		return nil, user, err
+	}
+	if required {
+		logger.Info().Str("username", req.Username).Msg("Login pendiente de enrolamiento MFA (política)")
+		resp, err := s.mfaStep(user, tokenPurposeMFAEnroll)
+		return resp, user, err
+	}
+
+	resp, err := s.issueTokens(ctx, user, ipAddr, userAgent)
+	return resp, user, err
+}
+
+// auditUser registra una acción cuyo actor es user, que aún no está en el
+// contexto de la petición (registro, login). Si no se llegó a identificar,
+// queda solo el nombre con el que se intentó.
+func (s *AuthService) auditUser(ctx context.Context, action string, user *domain.User, username string, err error, details map[string]any) {
+	event := &domain.AuditEvent{
+		Action:    action,
+		ActorName: username,
+		Outcome:   domain.AuditOutcomeOf(err),
+		Details:   details,
+	}
+	if user != nil {
+		event.ActorID = &user.ID
+		event.ActorName = user.Username
+	}
+	if err != nil {
+		event.Error = err.Error()
+	}
+	s.audit.Record(ctx, event)
+}
+
+// auditLogin registra un intento de login; si queda pendiente del segundo
+// factor se indica en los detalles
+func (s *AuthService) auditLogin(ctx context.Context, user *domain.User, username string, resp *domain.LoginResponse, err error, method string) {
+	details := map[string]any{"method": method}
+	if resp != nil && resp.MFAToken != "" {
+		details["mfa_pending"] = true
+	}
+	s.auditUser(ctx, domain.AuditAuthLogin, user, username, err, details)
+}
+
+// issueTokens abre un login nuevo (nueva familia de refresh tokens)
+func (s *AuthService) issueTokens(ctx context.Context, user *domain.User, ipAddr, userAgent string) (*domain.LoginResponse, error) {
+	familyID := uuid.New()
+	accessToken, err := s.generateAccessToken(user, familyID)
 	if err != nil {
 		logger.Error().Err(err).Msg("Error generando access token")
 		return nil, domain.ErrInternal
 	}
 
-	refreshToken, err := s.generateRefreshToken(ctx, user.ID, ipAddr, userAgent)
+	refreshToken, err := s.generateRefreshToken(ctx, user.ID, familyID, time.Now(), ipAddr, userAgent)
 	if err != nil {
 		logger.Error().Err(err).Msg("Error generando refresh token")
 		return nil, domain.ErrInternal
 	}
 
 	_ = s.userRepo.UpdateLastLogin(ctx, user.ID)
+	if user.FailedLoginAttempts > 0 || user.LockedUntil != nil {
+		_ = s.userRepo.ResetFailedLogins(ctx, user.ID)
+	}
 
 	logger.Info().Str("id", user.ID.String()).Str("username", user.Username).Msg("Login exitoso")
 
@@ -147,9 +309,11 @@ func (s *AuthService) RefreshTokens(ctx context.Context, refreshTokenStr, ipAddr
 		return nil, domain.ErrInvalidToken
 	}
 
+	// Un token ya rotado o revocado que vuelve a presentarse indica que se
+	// filtró: se revoca toda la familia (incluidos sus access tokens)
 	if token.RevokedAt != nil {
-		logger.Warn().Str("token_id", token.ID.String()).Msg("Token revocado")
-		return nil, domain.ErrTokenRevoked
+		s.revokeFamily(ctx, token)
+		return nil, domain.ErrTokenReused
 	}
 
 	if time.Now().After(token.ExpiresAt) {
@@ -167,15 +331,34 @@ func (s *AuthService) RefreshTokens(ctx context.Context, refreshTokenStr, ipAddr
 		return nil, domain.ErrUserInactive
 	}
 
-	_ = s.tokenRepo.Revoke(ctx, token.ID)
+	// Con la política activa, los logins previos al enrolamiento no se renuevan
+	if !user.MFAEnabled {
+		required, err := s.mfaRequired(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if required {
+			return nil, domain.ErrMFAEnrollmentRequired
+		}
+	}
+
+	// Revocación atómica: si otra petición lo rotó en paralelo, es reutilización
+	if err := s.tokenRepo.Revoke(ctx, token.ID); err != nil {
+		if err == domain.ErrInvalidToken {
+			s.revokeFamily(ctx, token)
+			return nil, domain.ErrTokenReused
+		}
+		logger.Error().Err(err).Str("token_id", token.ID.String()).Msg("Error rotando refresh token")
+		return nil, domain.ErrDatabase
+	}
 
-	accessToken, err := s.generateAccessToken(user)
+	accessToken, err := s.generateAccessToken(user, token.FamilyID)
 	if err != nil {
 		logger.Error().Err(err).Msg("Error generando nuevo access token")
 		return nil, domain.ErrInternal
 	}
 
-	newRefreshToken, err := s.generateRefreshToken(ctx, user.ID, ipAddr, userAgent)
+	newRefreshToken, err := s.generateRefreshToken(ctx, user.ID, token.FamilyID, token.LoginAt, ipAddr, userAgent)
 	if err != nil {
 		logger.Error().Err(err).Msg("Error generando nuevo refresh token")
 		return nil, domain.ErrInternal
@@ -192,7 +375,22 @@ func (s *AuthService) RefreshTokens(ctx context.Context, refreshTokenStr, ipAddr
 	}, nil
 }
 
-func (s *AuthService) Logout(ctx context.Context, refreshTokenStr string) error {
+// Logout revoca el refresh token y añade a la blacklist el access token con
+// el que se hizo la petición (claims nil si se autenticó con API key)
+func (s *AuthService) Logout(ctx context.Context, refreshTokenStr string, claims *JWTClaims) error {
+	err := s.logout(ctx, refreshTokenStr, claims)
+	s.audit.RecordAction(ctx, domain.AuditAuthLogout, nil, err, nil)
+	return err
+}
+
+func (s *AuthService) logout(ctx context.Context, refreshTokenStr string, claims *JWTClaims) error {
+	if claims != nil {
+		s.RevokeAccessToken(ctx, claims)
+	}
+
+	if refreshTokenStr == "" {
+		return nil
+	}
 	tokenHash := crypto.HashToken(refreshTokenStr)
 	token, err := s.tokenRepo.GetByTokenHash(ctx, tokenHash)
 	if err != nil {
@@ -202,9 +400,178 @@ func (s *AuthService) Logout(ctx context.Context, refreshTokenStr string) error
 	return s.tokenRepo.Revoke(ctx, token.ID)
 }
 
+// RevokeAccessToken añade el JWT a la blacklist hasta su expiración
+func (s *AuthService) RevokeAccessToken(ctx context.Context, claims *JWTClaims) {
+	if claims.ID == "" || claims.ExpiresAt == nil {
+		return // Tokens anteriores a JTI: no se pueden revocar individualmente
+	}
+	ttl := int(time.Until(claims.ExpiresAt.Time).Seconds()) + 1
+	if ttl <= 1 {
+		return
+	}
+	if err := s.cacheRepo.BlacklistToken(ctx, blacklistJTI+claims.ID, ttl); err != nil {
+		logger.Error().Err(err).Str("jti", claims.ID).Msg("Error añadiendo access token a la blacklist")
+	}
+}
+
+// IsAccessTokenRevoked verifica si el JWT o su familia están en la blacklist.
+// Si no se puede consultar retorna ErrRevocationUnavailable, salvo que
+// JWT_REVOCATION_FAIL_OPEN permita aceptar el token.
+func (s *AuthService) IsAccessTokenRevoked(ctx context.Context, claims *JWTClaims) (bool, error) {
+	revoked, err := s.isAccessTokenBlacklisted(ctx, claims)
+	if err != nil {
+		logger.Warn().Err(err).Bool("fail_open", s.config.JWT.RevocationFailOpen).Msg("⚠️ No se pudo consultar la blacklist de tokens")
+		if s.config.JWT.RevocationFailOpen {
+			return false, nil
+		}
+		return false, domain.ErrRevocationUnavailable
+	}
+	return revoked, nil
+}
+
+func (s *AuthService) isAccessTokenBlacklisted(ctx context.Context, claims *JWTClaims) (bool, error) {
+	if claims.ID != "" {
+		revoked, err := s.cacheRepo.IsTokenBlacklisted(ctx, blacklistJTI+claims.ID)
+		if err != nil || revoked {
+			return revoked, err
+		}
+	}
+	if claims.FamilyID != "" {
+		return s.cacheRepo.IsTokenBlacklisted(ctx, blacklistFamily+claims.FamilyID)
+	}
+	return false, nil
+}
+
+// revokeFamily revoca todos los refresh tokens de la familia y bloquea los
+// access tokens emitidos por ella durante su vida máxima
+func (s *AuthService) revokeFamily(ctx context.Context, token *domain.RefreshToken) {
+	revoked, err := s.tokenRepo.RevokeFamily(ctx, token.FamilyID)
+	if err != nil {
+		logger.Error().Err(err).Str("family_id", token.FamilyID.String()).Msg("Error revocando familia de tokens")
+	}
+	s.blacklistFamily(ctx, token.FamilyID)
+
+	logger.Warn().
+		Str("user_id", token.UserID.String()).
+		Str("token_id", token.ID.String()).
+		Str("family_id", token.FamilyID.String()).
+		Int64("revoked", revoked).
+		Msg("🚨 Reutilización de refresh token detectada, familia revocada")
+
+	s.audit.Record(ctx, &domain.AuditEvent{
+		ActorID: &token.UserID,
+		Action:  domain.AuditAuthTokenReuse,
+		Outcome: domain.AuditDenied,
+		Error:   domain.ErrTokenReused.Error(),
+		Details: map[string]any{
+			"family_id": token.FamilyID.String(),
+			"revoked":   revoked,
+		},
+	})
+}
+
+// LogoutAll revoca todos los refresh tokens del usuario y bloquea los access
+// tokens de cada login activo
 func (s *AuthService) LogoutAll(ctx context.Context, userID uuid.UUID) error {
+	err := s.logoutAll(ctx, userID)
+	s.audit.RecordAction(ctx, domain.AuditAuthLogoutAll, nil, err, map[string]any{
+		"user_id": userID.String(),
+	})
+	return err
+}
+
+func (s *AuthService) logoutAll(ctx context.Context, userID uuid.UUID) error {
 	logger.Info().Str("user_id", userID.String()).Msg("Logout all devices")
-	return s.tokenRepo.RevokeAllForUser(ctx, userID)
+
+	active, err := s.tokenRepo.GetActiveByUserID(ctx, userID)
+	if err != nil {
+		logger.Error().Err(err).Str("user_id", userID.String()).Msg("Error obteniendo logins activos")
+		return domain.ErrDatabase
+	}
+	if err := s.tokenRepo.RevokeAllForUser(ctx, userID); err != nil {
+		return domain.ErrDatabase
+	}
+	for _, token := range active {
+		s.blacklistFamily(ctx, token.FamilyID)
+	}
+	return nil
+}
+
+// ListDevices lista los logins activos del usuario; currentFamily marca el
+// de la petición (uuid.Nil si no se conoce)
+func (s *AuthService) ListDevices(ctx context.Context, userID, currentFamily uuid.UUID) ([]*domain.Device, error) {
+	tokens, err := s.tokenRepo.GetActiveByUserID(ctx, userID)
+	if err != nil {
+		logger.Error().Err(err).Str("user_id", userID.String()).Msg("Error listando dispositivos")
+		return nil, domain.ErrDatabase
+	}
+
+	devices := make([]*domain.Device, 0, len(tokens))
+	for _, t := range tokens {
+		devices = append(devices, &domain.Device{
+			ID:         t.FamilyID,
+			UserAgent:  t.DeviceInfo,
+			IPAddress:  t.IPAddress,
+			CreatedAt:  t.LoginAt,
+			LastUsedAt: t.CreatedAt,
+			ExpiresAt:  t.ExpiresAt,
+			Current:    t.FamilyID == currentFamily,
+		})
+	}
+	return devices, nil
+}
+
+// RevokeDevice cierra un login: revoca su familia de refresh tokens y bloquea
+// sus access tokens
+func (s *AuthService) RevokeDevice(ctx context.Context, userID, deviceID uuid.UUID) error {
+	err := s.revokeDevice(ctx, userID, deviceID)
+	s.audit.RecordAction(ctx, domain.AuditAuthDeviceRevoke, nil, err, map[string]any{
+		"device_id": deviceID.String(),
+	})
+	return err
+}
+
+func (s *AuthService) revokeDevice(ctx context.Context, userID, deviceID uuid.UUID) error {
+	tokens, err := s.tokenRepo.GetActiveByUserID(ctx, userID)
+	if err != nil {
+		return domain.ErrDatabase
+	}
+
+	for _, t := range tokens {
+		if t.FamilyID != deviceID {
+			continue
+		}
+		if _, err := s.tokenRepo.RevokeFamily(ctx, deviceID); err != nil {
+			logger.Error().Err(err).Str("device_id", deviceID.String()).Msg("Error revocando dispositivo")
+			return domain.ErrDatabase
+		}
+		s.blacklistFamily(ctx, deviceID)
+		logger.Info().Str("user_id", userID.String()).Str("device_id", deviceID.String()).Msg("Dispositivo revocado")
+		return nil
+	}
+	return domain.ErrDeviceNotFound
+}
+
+// CleanupExpiredTokens borra periódicamente los refresh tokens expirados
+// hasta que se cancele ctx
+func (s *AuthService) CleanupExpiredTokens(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			deleted, err := s.tokenRepo.DeleteExpired(ctx)
+			if err != nil {
+				logger.Error().Err(err).Msg("Error limpiando refresh tokens expirados")
+				continue
+			}
+			if deleted > 0 {
+				logger.Info().Int64("deleted", deleted).Msg("🧹 Refresh tokens expirados eliminados")
+			}
+		}
+	}
 }
 
 func (s *AuthService) ValidateToken(tokenStr string) (*JWTClaims, error) {
@@ -221,6 +588,11 @@ func (s *AuthService) ValidateToken(tokenStr string) (*JWTClaims, error) {
 		return nil, domain.ErrInvalidToken
 	}
 
+	// Los tokens del paso MFA no dan acceso a la API
+	if claims.Purpose != "" {
+		return nil, domain.ErrInvalidToken
+	}
+
 	return claims, nil
 }
 
@@ -228,14 +600,912 @@ func (s *AuthService) GetUserByID(ctx context.Context, id uuid.UUID) (*domain.Us
 	return s.userRepo.GetByID(ctx, id)
 }
 
-func (s *AuthService) generateAccessToken(user *domain.User) (string, error) {
+// EffectiveScopes retorna los scopes concedidos por el token (todos en tokens
+// emitidos antes de existir los scopes)
+func (c *JWTClaims) EffectiveScopes() []domain.Scope {
+	if c.Scopes == nil {
+		return domain.AllScopes
+	}
+	return c.Scopes
+}
+
+// SetUserScopes fija los scopes de un usuario (nil = todos). Aplica a sus
+// API keys de inmediato; sus JWT se revocan para que el próximo login lleve
+// los scopes nuevos.
+func (s *AuthService) SetUserScopes(ctx context.Context, userID uuid.UUID, scopes []domain.Scope) (*domain.User, error) {
+	user, err := s.setUserScopes(ctx, userID, scopes)
+	s.audit.RecordAction(ctx, domain.AuditAdminUserScopes, nil, err, map[string]any{
+		"user_id": userID.String(),
+		"scopes":  scopes,
+	})
+	return user, err
+}
+
+func (s *AuthService) setUserScopes(ctx context.Context, userID uuid.UUID, scopes []domain.Scope) (*domain.User, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.IsAdmin() {
+		return nil, domain.ErrForbidden
+	}
+
+	if err := s.userRepo.UpdateScopes(ctx, userID, scopes); err != nil {
+		logger.Error().Err(err).Str("user_id", userID.String()).Msg("Error actualizando scopes")
+		return nil, domain.ErrDatabase
+	}
+	user.Scopes = scopes
+	if err := s.logoutAll(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	logger.Info().
+		Str("user_id", userID.String()).
+		Interface("scopes", user.EffectiveScopes()).
+		Msg("Scopes de usuario actualizados")
+	return user, nil
+}
+
+// Prefijos de las entradas de la blacklist de access tokens
+const (
+	blacklistJTI    = "jti:"
+	blacklistFamily = "family:"
+)
+
+// blacklistFamily bloquea los access tokens de una familia durante su vida máxima
+func (s *AuthService) blacklistFamily(ctx context.Context, familyID uuid.UUID) {
+	ttl := s.config.JWT.ExpiryHours * 3600
+	if err := s.cacheRepo.BlacklistToken(ctx, blacklistFamily+familyID.String(), ttl); err != nil {
+		logger.Error().Err(err).Str("family_id", familyID.String()).Msg("Error bloqueando access tokens de la familia")
+	}
+}
+
+// ==================== INVITACIONES ====================
+
+// CreateInvitation genera una invitación de registro de un solo uso. El token
+// solo se retorna aquí.
+func (s *AuthService) CreateInvitation(ctx context.Context, adminID uuid.UUID, req *domain.CreateInvitationRequest) (*domain.CreateInvitationResponse, error) {
+	resp, err := s.createInvitation(ctx, adminID, req)
+
+	details := map[string]any{"email": req.Email, "role": string(req.Role)}
+	if resp != nil {
+		details["invitation_id"] = resp.ID.String()
+		details["role"] = string(resp.Role)
+	}
+	s.audit.RecordAction(ctx, domain.AuditAdminInvitationCreate, nil, err, details)
+	return resp, err
+}
+
+func (s *AuthService) createInvitation(ctx context.Context, adminID uuid.UUID, req *domain.CreateInvitationRequest) (*domain.CreateInvitationResponse, error) {
+	tokenStr, err := crypto.GenerateRandomHex(48)
+	if err != nil {
+		return nil, domain.ErrInternal
+	}
+
+	hours := req.ExpiresInHours
+	if hours == 0 {
+		hours = s.config.Registration.InviteTTLHours
+	}
+	role := req.Role
+	if role == "" {
+		role = domain.RoleUser
+	}
+
+	inv := &domain.Invitation{
+		ID:        uuid.New(),
+		TokenHash: crypto.HashToken(tokenStr),
+		Email:     req.Email,
+		Role:      role,
+		CreatedBy: adminID,
+		ExpiresAt: time.Now().Add(time.Duration(hours) * time.Hour),
+		CreatedAt: time.Now(),
+	}
+	if err := s.inviteRepo.Create(ctx, inv); err != nil {
+		logger.Error().Err(err).Msg("Error guardando invitación")
+		return nil, domain.ErrDatabase
+	}
+
+	logger.Info().
+		Str("invitation_id", inv.ID.String()).
+		Str("admin_id", adminID.String()).
+		Str("email", inv.Email).
+		Str("role", string(inv.Role)).
+		Msg("Invitación creada")
+	return &domain.CreateInvitationResponse{Token: tokenStr, Invitation: inv}, nil
+}
+
+func (s *AuthService) ListInvitations(ctx context.Context) ([]*domain.Invitation, error) {
+	invitations, err := s.inviteRepo.ListPending(ctx)
+	if err != nil {
+		return nil, domain.ErrDatabase
+	}
+	return invitations, nil
+}
+
+func (s *AuthService) RevokeInvitation(ctx context.Context, id uuid.UUID) error {
+	err := s.revokeInvitation(ctx, id)
+	s.audit.RecordAction(ctx, domain.AuditAdminInvitationRevoke, nil, err, map[string]any{
+		"invitation_id": id.String(),
+	})
+	return err
+}
+
+func (s *AuthService) revokeInvitation(ctx context.Context, id uuid.UUID) error {
+	if err := s.inviteRepo.Delete(ctx, id); err != nil {
+		if err == domain.ErrInvitationNotFound {
+			return err
+		}
+		return domain.ErrDatabase
+	}
+	logger.Info().Str("invitation_id", id.String()).Msg("Invitación revocada")
+	return nil
+}
+
+// ==================== CONTRASEÑAS ====================
+
+// registerFailedLogin cuenta un fallo (contraseña o código MFA) y, alcanzado
+// el umbral, bloquea la cuenta
+func (s *AuthService) registerFailedLogin(ctx context.Context, user *domain.User, ipAddr string) {
+	attempts, err := s.userRepo.RecordFailedLogin(ctx, user.ID)
+	if err != nil {
+		logger.Error().Err(err).Str("user_id", user.ID.String()).Msg("Error registrando intento fallido")
+		return
+	}
+
+	cfg := s.config.Password
+	if cfg.LockoutThreshold <= 0 || attempts < cfg.LockoutThreshold {
+		return
+	}
+
+	duration := lockoutDuration(attempts, cfg)
+	if err := s.userRepo.LockUntil(ctx, user.ID, time.Now().Add(duration)); err != nil {
+		logger.Error().Err(err).Str("user_id", user.ID.String()).Msg("Error bloqueando cuenta")
+		return
+	}
+	logger.Warn().
+		Str("user_id", user.ID.String()).
+		Str("username", user.Username).
+		Str("ip", ipAddr).
+		Int("attempts", attempts).
+		Dur("duration", duration).
+		Msg("🔒 Cuenta bloqueada por intentos fallidos")
+}
+
+// lockoutDuration duplica el bloqueo por cada fallo a partir del umbral, con tope
+func lockoutDuration(attempts int, cfg config.PasswordConfig) time.Duration {
+	duration := time.Duration(cfg.LockoutMinutes) * time.Minute
+	limit := time.Duration(cfg.LockoutMaxMinutes) * time.Minute
+	for i := cfg.LockoutThreshold; i < attempts && duration < limit; i++ {
+		duration *= 2
+	}
+	if limit > 0 && duration > limit {
+		duration = limit
+	}
+	return duration
+}
+
+// UnlockUser levanta el bloqueo por intentos fallidos (admin)
+func (s *AuthService) UnlockUser(ctx context.Context, userID uuid.UUID) error {
+	err := s.unlockUser(ctx, userID)
+	s.audit.RecordAction(ctx, domain.AuditAdminUserUnlock, nil, err, map[string]any{
+		"user_id": userID.String(),
+	})
+	return err
+}
+
+func (s *AuthService) unlockUser(ctx context.Context, userID uuid.UUID) error {
+	if err := s.userRepo.ResetFailedLogins(ctx, userID); err != nil {
+		if err == domain.ErrUserNotFound {
+			return err
+		}
+		return domain.ErrDatabase
+	}
+	logger.Info().Str("user_id", userID.String()).Msg("Cuenta desbloqueada por un administrador")
+	return nil
+}
+
+// ChangePassword cambia la contraseña con la actual y cierra los demás
+// logins del usuario; el de la petición (currentFamily) se conserva.
+// Retorna cuántos logins se cerraron.
+func (s *AuthService) ChangePassword(ctx context.Context, userID, currentFamily uuid.UUID, req *domain.ChangePasswordRequest) (int, error) {
+	revoked, err := s.changePassword(ctx, userID, currentFamily, req)
+	s.audit.RecordAction(ctx, domain.AuditAuthPasswordChange, nil, err, map[string]any{
+		"revoked_devices": revoked,
+	})
+	return revoked, err
+}
+
+func (s *AuthService) changePassword(ctx context.Context, userID, currentFamily uuid.UUID, req *domain.ChangePasswordRequest) (int, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return 0, domain.ErrUserNotFound
+	}
+	if !crypto.CheckPassword(req.CurrentPassword, user.PasswordHash) {
+		logger.Warn().Str("user_id", userID.String()).Msg("Cambio de contraseña con contraseña actual incorrecta")
+		return 0, domain.ErrInvalidCredentials
+	}
+	if req.CurrentPassword == req.NewPassword {
+		return 0, domain.ErrPasswordUnchanged
+	}
+
+	if err := s.setPassword(ctx, userID, req.NewPassword); err != nil {
+		return 0, err
+	}
+
+	active, err := s.tokenRepo.GetActiveByUserID(ctx, userID)
+	if err != nil {
+		logger.Error().Err(err).Str("user_id", userID.String()).Msg("Error obteniendo logins activos")
+		return 0, domain.ErrDatabase
+	}
+	revoked := 0
+	for _, token := range active {
+		if token.FamilyID == currentFamily {
+			continue
+		}
+		if _, err := s.tokenRepo.RevokeFamily(ctx, token.FamilyID); err != nil {
+			logger.Error().Err(err).Str("family_id", token.FamilyID.String()).Msg("Error revocando login")
+			continue
+		}
+		s.blacklistFamily(ctx, token.FamilyID)
+		revoked++
+	}
+
+	logger.Info().Str("user_id", userID.String()).Int("revoked_devices", revoked).Msg("🔑 Contraseña cambiada")
+	return revoked, nil
+}
+
+// CreatePasswordReset emite un token de restablecimiento de un solo uso para
+// userID (admin). Invalida los emitidos antes.
+func (s *AuthService) CreatePasswordReset(ctx context.Context, adminID, userID uuid.UUID) (*domain.PasswordResetResponse, error) {
+	resp, err := s.createPasswordReset(ctx, adminID, userID)
+	s.audit.RecordAction(ctx, domain.AuditAdminPasswordReset, nil, err, map[string]any{
+		"user_id": userID.String(),
+	})
+	return resp, err
+}
+
+func (s *AuthService) createPasswordReset(ctx context.Context, adminID, userID uuid.UUID) (*domain.PasswordResetResponse, error) {
+	if _, err := s.userRepo.GetByID(ctx, userID); err != nil {
+		return nil, domain.ErrUserNotFound
+	}
+	if err := s.resetRepo.RevokeForUser(ctx, userID); err != nil {
+		return nil, domain.ErrDatabase
+	}
+
+	tokenStr, err := crypto.GenerateRandomHex(64)
+	if err != nil {
+		return nil, domain.ErrInternal
+	}
+	token := &domain.PasswordResetToken{
+		ID:        uuid.New(),
+		UserID:    userID,
+		TokenHash: crypto.HashToken(tokenStr),
+		CreatedBy: adminID,
+		ExpiresAt: time.Now().Add(time.Duration(s.config.Password.ResetTTLHours) * time.Hour),
+		CreatedAt: time.Now(),
+	}
+	if err := s.resetRepo.Create(ctx, token); err != nil {
+		logger.Error().Err(err).Str("user_id", userID.String()).Msg("Error guardando token de restablecimiento")
+		return nil, domain.ErrDatabase
+	}
+
+	logger.Info().
+		Str("user_id", userID.String()).
+		Str("admin_id", adminID.String()).
+		Time("expires_at", token.ExpiresAt).
+		Msg("Token de restablecimiento de contraseña emitido")
+	return &domain.PasswordResetResponse{Token: tokenStr, ExpiresAt: token.ExpiresAt}, nil
+}
+
+// ResetPassword fija una contraseña nueva con un token de restablecimiento,
+// levanta el bloqueo y cierra todos los logins del usuario
+func (s *AuthService) ResetPassword(ctx context.Context, req *domain.ResetPasswordRequest) error {
+	userID, err := s.resetPassword(ctx, req)
+
+	event := &domain.AuditEvent{Action: domain.AuditAuthPasswordReset, Outcome: domain.AuditOutcomeOf(err)}
+	if userID != uuid.Nil {
+		event.ActorID = &userID
+	}
+	if err != nil {
+		event.Error = err.Error()
+	}
+	s.audit.Record(ctx, event)
+	return err
+}
+
+func (s *AuthService) resetPassword(ctx context.Context, req *domain.ResetPasswordRequest) (uuid.UUID, error) {
+	userID, err := s.resetRepo.Consume(ctx, crypto.HashToken(req.Token))
+	if err != nil {
+		if err == domain.ErrInvalidToken {
+			logger.Warn().Msg("Token de restablecimiento inválido, usado o expirado")
+			return uuid.Nil, err
+		}
+		return uuid.Nil, domain.ErrDatabase
+	}
+
+	if err := s.setPassword(ctx, userID, req.NewPassword); err != nil {
+		return userID, err
+	}
+	if err := s.userRepo.ResetFailedLogins(ctx, userID); err != nil {
+		logger.Warn().Err(err).Str("user_id", userID.String()).Msg("Error desbloqueando cuenta")
+	}
+	if err := s.logoutAll(ctx, userID); err != nil {
+		return userID, err
+	}
+
+	logger.Info().Str("user_id", userID.String()).Msg("🔑 Contraseña restablecida")
+	return userID, nil
+}
+
+func (s *AuthService) setPassword(ctx context.Context, userID uuid.UUID, password string) error {
+	passwordHash, err := crypto.HashPassword(password)
+	if err != nil {
+		logger.Error().Err(err).Msg("Error hasheando contraseña")
+		return domain.ErrInternal
+	}
+	if err := s.userRepo.UpdatePassword(ctx, userID, passwordHash); err != nil {
+		if err == domain.ErrUserNotFound {
+			return err
+		}
+		logger.Error().Err(err).Str("user_id", userID.String()).Msg("Error actualizando contraseña")
+		return domain.ErrDatabase
+	}
+	return nil
+}
+
+// ==================== MFA ====================
+
+// Propósitos de los tokens del login con MFA
+const (
+	tokenPurposeMFAChallenge = "mfa_challenge" // Contraseña correcta, falta el código
+	tokenPurposeMFAEnroll    = "mfa_enroll"    // La política exige enrolarse antes de entrar
+)
+
+const (
+	mfaTokenTTL    = 5 * time.Minute
+	mfaMaxAttempts = 5 // Códigos erróneos por token de desafío
+	mfaIssuer      = "Telegram API"
+)
+
+// mfaStep responde al login con un token de desafío o de enrolamiento en
+// lugar de los tokens de acceso
+func (s *AuthService) mfaStep(user *domain.User, purpose string) (*domain.LoginResponse, error) {
+	token, err := s.generatePurposeToken(user, purpose)
+	if err != nil {
+		logger.Error().Err(err).Msg("Error generando token MFA")
+		return nil, domain.ErrInternal
+	}
+	return &domain.LoginResponse{
+		ExpiresIn:             int(mfaTokenTTL.Seconds()),
+		MFARequired:           purpose == tokenPurposeMFAChallenge,
+		MFAEnrollmentRequired: purpose == tokenPurposeMFAEnroll,
+		MFAToken:              token,
+	}, nil
+}
+
+// VerifyMFA completa el login con un código TOTP o de recuperación
+func (s *AuthService) VerifyMFA(ctx context.Context, req *domain.MFAVerifyRequest, ipAddr, userAgent string) (*domain.LoginResponse, error) {
+	resp, user, err := s.verifyMFA(ctx, req, ipAddr, userAgent)
+	s.auditLogin(ctx, user, "", resp, err, "mfa")
+	return resp, err
+}
+
+func (s *AuthService) verifyMFA(ctx context.Context, req *domain.MFAVerifyRequest, ipAddr, userAgent string) (*domain.LoginResponse, *domain.User, error) {
+	claims, err := s.validatePurposeToken(ctx, req.MFAToken, tokenPurposeMFAChallenge)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Límite de intentos por desafío: agotado, hay que volver a hacer login
+	attempts, err := s.cacheRepo.IncrementRateLimit(ctx, "rate:mfa:"+claims.ID, int(mfaTokenTTL.Seconds()))
+	if err != nil {
+		logger.Warn().Err(err).Msg("⚠️ No se pudo contar el intento MFA")
+	}
+	if attempts > mfaMaxAttempts {
+		s.RevokeAccessToken(ctx, claims)
+		logger.Warn().Str("username", claims.Username).Msg("Demasiados intentos MFA, desafío invalidado")
+		return nil, nil, domain.ErrTokenRevoked
+	}
+
+	user, err := s.userRepo.GetByID(ctx, uuid.MustParse(claims.UserID))
+	if err != nil {
+		return nil, nil, domain.ErrInvalidToken
+	}
+	if !user.IsActive {
+		return nil, user, domain.ErrUserInactive
+	}
+	if !user.MFAEnabled {
+		return nil, user, domain.ErrMFANotEnabled
+	}
+	if user.IsLocked(time.Now()) {
+		return nil, user, domain.ErrAccountLocked
+	}
+
+	if err := s.checkMFACode(ctx, user, req.Code); err != nil {
+		logger.Warn().Str("username", user.Username).Str("ip", ipAddr).Msg("Código MFA incorrecto")
+		if err == domain.ErrInvalidMFACode {
+			s.registerFailedLogin(ctx, user, ipAddr)
+		}
+		return nil, user, err
+	}
+
+	s.RevokeAccessToken(ctx, claims) // El desafío es de un solo uso
+	resp, err := s.issueTokens(ctx, user, ipAddr, userAgent)
+	return resp, user, err
+}
+
+// ValidateEnrollmentToken valida el token que da el login cuando la política
+// exige MFA y el usuario aún no lo tiene; solo sirve para enrolarse
+func (s *AuthService) ValidateEnrollmentToken(ctx context.Context, tokenStr string) (*JWTClaims, error) {
+	return s.validatePurposeToken(ctx, tokenStr, tokenPurposeMFAEnroll)
+}
+
+// EnrollMFA genera un secreto TOTP pendiente de confirmar. Repetirlo antes de
+// confirmar reemplaza el secreto anterior.
+func (s *AuthService) EnrollMFA(ctx context.Context, userID uuid.UUID) (*domain.MFAEnrollResponse, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, domain.ErrUserNotFound
+	}
+	if user.MFAEnabled {
+		return nil, domain.ErrMFAAlreadyEnabled
+	}
+
+	secret, err := crypto.GenerateTOTPSecret()
+	if err != nil {
+		return nil, domain.ErrInternal
+	}
+	if err := s.userRepo.SetMFAPendingSecret(ctx, userID, secret); err != nil {
+		logger.Error().Err(err).Str("user_id", userID.String()).Msg("Error guardando secreto MFA")
+		return nil, domain.ErrDatabase
+	}
+
+	uri := crypto.TOTPURI(mfaIssuer, user.Username, secret)
+	qr, err := utils.GenerateQRBase64(uri)
+	if err != nil {
+		logger.Warn().Err(err).Msg("Error generando QR de MFA")
+	}
+
+	logger.Info().Str("user_id", userID.String()).Msg("Enrolamiento MFA iniciado")
+	return &domain.MFAEnrollResponse{Secret: secret, URI: uri, QRImageBase64: qr}, nil
+}
+
+// ConfirmMFA activa MFA con el primer código de la app y retorna los códigos
+// de recuperación. Con enrollClaims (enrolamiento exigido en el login) además
+// abre el login.
+func (s *AuthService) ConfirmMFA(ctx context.Context, userID uuid.UUID, code string, enrollClaims *JWTClaims, ipAddr, userAgent string) (*domain.MFAConfirmResponse, error) {
+	resp, err := s.confirmMFA(ctx, userID, code, enrollClaims, ipAddr, userAgent)
+	s.audit.RecordAction(ctx, domain.AuditAuthMFAEnable, nil, err, nil)
+	return resp, err
+}
+
+func (s *AuthService) confirmMFA(ctx context.Context, userID uuid.UUID, code string, enrollClaims *JWTClaims, ipAddr, userAgent string) (*domain.MFAConfirmResponse, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, domain.ErrUserNotFound
+	}
+	if user.MFAEnabled {
+		return nil, domain.ErrMFAAlreadyEnabled
+	}
+	if user.MFAPendingSecret == "" {
+		return nil, domain.ErrMFANotEnrolled
+	}
+
+	step, ok := crypto.ValidateTOTP(user.MFAPendingSecret, code, time.Now())
+	if !ok {
+		return nil, domain.ErrInvalidMFACode
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, domain.ErrInternal
+	}
+	if err := s.userRepo.EnableMFA(ctx, userID, hashes); err != nil {
+		if err == domain.ErrMFANotEnrolled {
+			return nil, err
+		}
+		logger.Error().Err(err).Str("user_id", userID.String()).Msg("Error activando MFA")
+		return nil, domain.ErrDatabase
+	}
+	// El código de confirmación no puede volver a usarse en /auth/mfa/verify
+	if _, err := s.userRepo.UseMFAStep(ctx, userID, step); err != nil {
+		logger.Warn().Err(err).Str("user_id", userID.String()).Msg("Error registrando paso TOTP")
+	}
+	user.MFAEnabled = true
+
+	logger.Info().Str("user_id", userID.String()).Msg("🔐 MFA activado")
+
+	resp := &domain.MFAConfirmResponse{RecoveryCodes: codes}
+	if enrollClaims != nil {
+		s.RevokeAccessToken(ctx, enrollClaims)
+		if resp.Login, err = s.issueTokens(ctx, user, ipAddr, userAgent); err != nil {
+			return nil, err
+		}
+	}
+	return resp, nil
+}
+
+// DisableMFA desactiva MFA con contraseña y un código vigente. No se permite
+// mientras la política lo exija.
+func (s *AuthService) DisableMFA(ctx context.Context, userID uuid.UUID, req *domain.MFADisableRequest) error {
+	err := s.disableMFA(ctx, userID, req)
+	s.audit.RecordAction(ctx, domain.AuditAuthMFADisable, nil, err, nil)
+	return err
+}
+
+func (s *AuthService) disableMFA(ctx context.Context, userID uuid.UUID, req *domain.MFADisableRequest) error {
+	required, err := s.mfaRequired(ctx)
+	if err != nil {
+		return err
+	}
+	if required {
+		return domain.ErrMFAEnrollmentRequired
+	}
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return domain.ErrUserNotFound
+	}
+	if !user.MFAEnabled {
+		return domain.ErrMFANotEnabled
+	}
+	if !crypto.CheckPassword(req.Password, user.PasswordHash) {
+		return domain.ErrInvalidCredentials
+	}
+	if err := s.checkMFACode(ctx, user, req.Code); err != nil {
+		return err
+	}
+
+	if err := s.userRepo.DisableMFA(ctx, userID); err != nil {
+		logger.Error().Err(err).Str("user_id", userID.String()).Msg("Error desactivando MFA")
+		return domain.ErrDatabase
+	}
+	logger.Info().Str("user_id", userID.String()).Msg("MFA desactivado")
+	return nil
+}
+
+// RegenerateRecoveryCodes invalida los códigos de recuperación y genera otros
+func (s *AuthService) RegenerateRecoveryCodes(ctx context.Context, userID uuid.UUID, code string) ([]string, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, domain.ErrUserNotFound
+	}
+	if !user.MFAEnabled {
+		return nil, domain.ErrMFANotEnabled
+	}
+	if err := s.checkMFACode(ctx, user, code); err != nil {
+		return nil, err
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, domain.ErrInternal
+	}
+	if err := s.userRepo.SetMFARecoveryCodes(ctx, userID, hashes); err != nil {
+		logger.Error().Err(err).Str("user_id", userID.String()).Msg("Error guardando códigos de recuperación")
+		return nil, domain.ErrDatabase
+	}
+	logger.Info().Str("user_id", userID.String()).Msg("Códigos de recuperación regenerados")
+	return codes, nil
+}
+
+// ResetUserMFA quita el MFA de un usuario que perdió su dispositivo (admin)
+func (s *AuthService) ResetUserMFA(ctx context.Context, userID uuid.UUID) error {
+	err := s.resetUserMFA(ctx, userID)
+	s.audit.RecordAction(ctx, domain.AuditAdminMFAReset, nil, err, map[string]any{
+		"user_id": userID.String(),
+	})
+	return err
+}
+
+func (s *AuthService) resetUserMFA(ctx context.Context, userID uuid.UUID) error {
+	if _, err := s.userRepo.GetByID(ctx, userID); err != nil {
+		return domain.ErrUserNotFound
+	}
+	if err := s.userRepo.DisableMFA(ctx, userID); err != nil {
+		logger.Error().Err(err).Str("user_id", userID.String()).Msg("Error reseteando MFA")
+		return domain.ErrDatabase
+	}
+	logger.Warn().Str("user_id", userID.String()).Msg("MFA reseteado por un administrador")
+	return nil
+}
+
+// GetSecurityPolicy retorna las políticas de seguridad vigentes
+func (s *AuthService) GetSecurityPolicy(ctx context.Context) (*domain.SecurityPolicy, error) {
+	required, err := s.mfaRequired(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.SecurityPolicy{MFARequired: required}, nil
+}
+
+// UpdateSecurityPolicy aplica los campos presentes en req
+func (s *AuthService) UpdateSecurityPolicy(ctx context.Context, req *domain.UpdateSecurityPolicyRequest) (*domain.SecurityPolicy, error) {
+	policy, err := s.updateSecurityPolicy(ctx, req)
+
+	details := map[string]any{}
+	if req.MFARequired != nil {
+		details["mfa_required"] = *req.MFARequired
+	}
+	s.audit.RecordAction(ctx, domain.AuditAdminPolicy, nil, err, details)
+	return policy, err
+}
+
+func (s *AuthService) updateSecurityPolicy(ctx context.Context, req *domain.UpdateSecurityPolicyRequest) (*domain.SecurityPolicy, error) {
+	if req.MFARequired != nil {
+		if err := s.settings.Set(ctx, domain.SettingMFARequired, strconv.FormatBool(*req.MFARequired)); err != nil {
+			logger.Error().Err(err).Msg("Error guardando política MFA")
+			return nil, domain.ErrDatabase
+		}
+		logger.Info().Bool("mfa_required", *req.MFARequired).Msg("Política MFA actualizada")
+	}
+	return s.GetSecurityPolicy(ctx)
+}
+
+func (s *AuthService) mfaRequired(ctx context.Context) (bool, error) {
+	value, ok, err := s.settings.Get(ctx, domain.SettingMFARequired)
+	if err != nil {
+		logger.Error().Err(err).Msg("Error leyendo política MFA")
+		return false, domain.ErrDatabase
+	}
+	if !ok {
+		return false, nil
+	}
+	required, _ := strconv.ParseBool(value)
+	return required, nil
+}
+
+// checkMFACode acepta un código TOTP (una sola vez por paso) o un código de
+// recuperación (se consume)
+func (s *AuthService) checkMFACode(ctx context.Context, user *domain.User, code string) error {
+	code = normalizeMFACode(code)
+
+	if len(code) == crypto.TOTPDigits {
+		step, ok := crypto.ValidateTOTP(user.MFASecret, code, time.Now())
+		if !ok {
+			return domain.ErrInvalidMFACode
+		}
+		fresh, err := s.userRepo.UseMFAStep(ctx, user.ID, step)
+		if err != nil {
+			return domain.ErrDatabase
+		}
+		if !fresh {
+			return domain.ErrInvalidMFACode
+		}
+		return nil
+	}
+
+	consumed, err := s.userRepo.ConsumeMFARecoveryCode(ctx, user.ID, crypto.HashToken(code))
+	if err != nil {
+		return domain.ErrDatabase
+	}
+	if !consumed {
+		return domain.ErrInvalidMFACode
+	}
+	logger.Warn().
+		Str("user_id", user.ID.String()).
+		Int("remaining", len(user.MFARecoveryCodes)-1).
+		Msg("Código de recuperación MFA usado")
+	return nil
+}
+
+// generateRecoveryCodes genera los códigos (xxxxx-xxxxx) y sus hashes
+func generateRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, domain.MFARecoveryCodeCount)
+	hashes := make([]string, domain.MFARecoveryCodeCount)
+	for i := range codes {
+		random, err := crypto.GenerateRandomHex(10)
+		if err != nil {
+			return nil, nil, err
+		}
+		codes[i] = random[:5] + "-" + random[5:]
+		hashes[i] = crypto.HashToken(random)
+	}
+	return codes, hashes, nil
+}
+
+// normalizeMFACode ignora espacios, guiones y mayúsculas
+func normalizeMFACode(code string) string {
+	return strings.ToLower(strings.NewReplacer(" ", "", "-", "").Replace(strings.TrimSpace(code)))
+}
+
+func (s *AuthService) generatePurposeToken(user *domain.User, purpose string) (string, error) {
+	claims := &JWTClaims{
+		UserID:   user.ID.String(),
+		Username: user.Username,
+		Role:     user.Role,
+		Scopes:   []domain.Scope{},
+		Purpose:  purpose,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(mfaTokenTTL)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			Issuer:    "telegram-api",
+			Subject:   user.ID.String(),
+		},
+	}
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(s.config.JWT.Secret))
+}
+
+func (s *AuthService) validatePurposeToken(ctx context.Context, tokenStr, purpose string) (*JWTClaims, error) {
+	token, err := jwt.ParseWithClaims(tokenStr, &JWTClaims{}, func(t *jwt.Token) (interface{}, error) {
+		return []byte(s.config.JWT.Secret), nil
+	})
+	if err != nil {
+		return nil, domain.ErrInvalidToken
+	}
+	claims, ok := token.Claims.(*JWTClaims)
+	if !ok || !token.Valid || claims.Purpose != purpose {
+		return nil, domain.ErrInvalidToken
+	}
+	if _, err := uuid.Parse(claims.UserID); err != nil {
+		return nil, domain.ErrInvalidToken
+	}
+
+	revoked, err := s.IsAccessTokenRevoked(ctx, claims)
+	if err != nil {
+		return nil, err
+	}
+	if revoked {
+		return nil, domain.ErrTokenRevoked
+	}
+	return claims, nil
+}
+
+// ==================== API KEYS ====================
+
+// apiKeyTouchInterval evita escribir last_used_at en cada request
+const apiKeyTouchInterval = time.Minute
+
+// CreateAPIKey genera una API key. La clave completa solo se retorna aquí;
+// se guarda su hash SHA-256, igual que los refresh tokens.
+func (s *AuthService) CreateAPIKey(ctx context.Context, userID uuid.UUID, req *domain.CreateAPIKeyRequest) (*domain.CreateAPIKeyResponse, error) {
+	resp, err := s.createAPIKey(ctx, userID, req)
+
+	details := map[string]any{"name": req.Name, "scopes": req.Scopes, "session_ids": req.SessionIDs}
+	if resp != nil {
+		details["key_id"] = resp.APIKey.ID.String()
+		details["prefix"] = resp.APIKey.Prefix
+	}
+	s.audit.RecordAction(ctx, domain.AuditAPIKeyCreate, nil, err, details)
+	return resp, err
+}
+
+func (s *AuthService) createAPIKey(ctx context.Context, userID uuid.UUID, req *domain.CreateAPIKeyRequest) (*domain.CreateAPIKeyResponse, error) {
+	// Una key no puede tener más permisos que su usuario
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, domain.ErrUserNotFound
+	}
+	for _, scope := range req.Scopes {
+		if !domain.HasScope(user.EffectiveScopes(), scope) {
+			return nil, domain.ErrForbidden
+		}
+	}
+
+	// Solo se puede limitar a sesiones a las que el usuario tiene acceso
+	// (propias o de sus organizaciones); cada uso de la key vuelve a
+	// verificar su rol
+	for _, sessionID := range req.SessionIDs {
+		if _, err := s.orgs.AuthorizeSession(ctx, userID, sessionID, domain.OrgRoleViewer); err != nil {
+			return nil, domain.ErrSessionNotFound
+		}
+	}
+
+	random, err := crypto.GenerateRandomHex(40)
+	if err != nil {
+		return nil, domain.ErrInternal
+	}
+	keyStr := domain.APIKeyPrefix + random
+
+	key := &domain.APIKey{
+		ID:         uuid.New(),
+		UserID:     userID,
+		Name:       req.Name,
+		Prefix:     keyStr[:len(domain.APIKeyPrefix)+6],
+		KeyHash:    crypto.HashToken(keyStr),
+		SessionIDs: req.SessionIDs,
+		AllowedIPs: req.AllowedIPs,
+		Scopes:     req.Scopes,
+		CreatedAt:  time.Now(),
+	}
+	if req.ExpiresInDays > 0 {
+		expiresAt := time.Now().AddDate(0, 0, req.ExpiresInDays)
+		key.ExpiresAt = &expiresAt
+	}
+
+	if err := s.apiKeyRepo.Create(ctx, key); err != nil {
+		logger.Error().Err(err).Str("user_id", userID.String()).Msg("Error guardando API key")
+		return nil, domain.ErrDatabase
+	}
+
+	logger.Info().
+		Str("user_id", userID.String()).
+		Str("key_id", key.ID.String()).
+		Str("prefix", key.Prefix).
+		Int("sessions", len(key.SessionIDs)).
+		Interface("scopes", key.Scopes).
+		Msg("API key creada")
+
+	return &domain.CreateAPIKeyResponse{Key: keyStr, APIKey: key}, nil
+}
+
+func (s *AuthService) ListAPIKeys(ctx context.Context, userID uuid.UUID) ([]*domain.APIKey, error) {
+	keys, err := s.apiKeyRepo.ListByUserID(ctx, userID)
+	if err != nil {
+		return nil, domain.ErrDatabase
+	}
+	if keys == nil {
+		keys = []*domain.APIKey{}
+	}
+	return keys, nil
+}
+
+func (s *AuthService) RevokeAPIKey(ctx context.Context, userID, keyID uuid.UUID) error {
+	err := s.revokeAPIKey(ctx, userID, keyID)
+	s.audit.RecordAction(ctx, domain.AuditAPIKeyRevoke, nil, err, map[string]any{
+		"key_id": keyID.String(),
+	})
+	return err
+}
+
+func (s *AuthService) revokeAPIKey(ctx context.Context, userID, keyID uuid.UUID) error {
+	if err := s.apiKeyRepo.Revoke(ctx, keyID, userID); err != nil {
+		return err
+	}
+	logger.Info().Str("user_id", userID.String()).Str("key_id", keyID.String()).Msg("API key revocada")
+	return nil
+}
+
+// AuthenticateAPIKey valida una API key recibida en X-API-Key: existencia,
+// revocación, expiración, allowlist de IPs y que el usuario siga activo
+func (s *AuthService) AuthenticateAPIKey(ctx context.Context, keyStr, ip string) (*domain.APIKey, *domain.User, error) {
+	if !strings.HasPrefix(keyStr, domain.APIKeyPrefix) {
+		return nil, nil, domain.ErrInvalidToken
+	}
+
+	key, err := s.apiKeyRepo.GetByHash(ctx, crypto.HashToken(keyStr))
+	if err != nil {
+		return nil, nil, domain.ErrInvalidToken
+	}
+
+	now := time.Now()
+	if !key.IsValid(now) {
+		return nil, nil, domain.ErrTokenExpired
+	}
+	if !key.AllowsIP(ip) {
+		logger.Warn().Str("key_id", key.ID.String()).Str("ip", ip).Msg("API key usada desde IP no permitida")
+		return nil, nil, domain.ErrForbidden
+	}
+
+	user, err := s.userRepo.GetByID(ctx, key.UserID)
+	if err != nil {
+		return nil, nil, domain.ErrInvalidToken
+	}
+	if !user.IsActive {
+		return nil, nil, domain.ErrUserInactive
+	}
+
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) > apiKeyTouchInterval {
+		if err := s.apiKeyRepo.TouchLastUsed(ctx, key.ID, ip); err != nil {
+			logger.Warn().Err(err).Str("key_id", key.ID.String()).Msg("Error registrando uso de API key")
+		}
+	}
+
+	return key, user, nil
+}
+
+func (s *AuthService) generateAccessToken(user *domain.User, familyID uuid.UUID) (string, error) {
 	expiresAt := time.Now().Add(time.Duration(s.config.JWT.ExpiryHours) * time.Hour)
 
 	claims := &JWTClaims{
 		UserID:   user.ID.String(),
 		Username: user.Username,
 		Role:     user.Role,
+		Scopes:   user.EffectiveScopes(),
+		FamilyID: familyID.String(),
 		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(),
 			ExpiresAt: jwt.NewNumericDate(expiresAt),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			Issuer:    "telegram-api",
@@ -247,7 +1517,7 @@ func (s *AuthService) generateAccessToken(user *domain.User) (string, error) {
 	return token.SignedString([]byte(s.config.JWT.Secret))
 }
 
-func (s *AuthService) generateRefreshToken(ctx context.Context, userID uuid.UUID, ipAddr, userAgent string) (string, error) {
+func (s *AuthService) generateRefreshToken(ctx context.Context, userID, familyID uuid.UUID, loginAt time.Time, ipAddr, userAgent string) (string, error) {
 	tokenBytes, err := crypto.GenerateRandomBytes(32)
 	if err != nil {
 		return "", err
@@ -257,10 +1527,12 @@ func (s *AuthService) generateRefreshToken(ctx context.Context, userID uuid.UUID
 	refreshToken := &domain.RefreshToken{
 		ID:         uuid.New(),
 		UserID:     userID,
+		FamilyID:   familyID,
 		TokenHash:  crypto.HashToken(tokenStr),
 		DeviceInfo: userAgent,
 		IPAddress:  ipAddr,
 		ExpiresAt:  time.Now().Add(30 * 24 * time.Hour),
+		LoginAt:    loginAt,
 		CreatedAt:  time.Now(),
 	}
 
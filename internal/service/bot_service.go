@@ -0,0 +1,60 @@
+package service
+
+import (
+	"context"
+
+	"telegram-api/internal/domain"
+	"telegram-api/internal/telegram"
+	"telegram-api/pkg/logger"
+
+	"github.com/google/uuid"
+	tgClient "github.com/gotd/td/telegram"
+)
+
+// BotService agrupa las operaciones exclusivas de sesiones autenticadas con
+// token de BotFather (callback queries de teclados inline)
+type BotService struct {
+	chats     *ChatService
+	tgManager *telegram.ClientManager
+	pool      *telegram.SessionPool
+}
+
+func NewBotService(chats *ChatService, tgManager *telegram.ClientManager, pool *telegram.SessionPool) *BotService {
+	return &BotService{
+		chats:     chats,
+		tgManager: tgManager,
+		pool:      pool,
+	}
+}
+
+// AnswerCallbackQuery responde a un callback query. Usa el cliente del pool
+// (que es quien recibió el evento) y, si la sesión no está conectada, abre uno temporal.
+func (s *BotService) AnswerCallbackQuery(ctx context.Context, userID, sessionID uuid.UUID, queryID int64, req domain.AnswerCallbackRequest) error {
+	sess, err := s.chats.getValidSession(ctx, userID, sessionID, domain.OrgRoleOperator)
+	if err != nil {
+		return err
+	}
+	if !sess.IsBot {
+		return domain.ErrBotOnly
+	}
+
+	if api, ok := s.pool.ConnectedAPI(sessionID); ok {
+		if err := s.tgManager.AnswerCallbackQuery(ctx, api, queryID, req); err != nil {
+			return mapTelegramError(err)
+		}
+	} else {
+		err := s.chats.withClient(ctx, userID, sessionID, domain.OrgRoleOperator, func(ctx context.Context, client *tgClient.Client) error {
+			return s.tgManager.AnswerCallbackQuery(ctx, client.API(), queryID, req)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	logger.Debug().
+		Str("session_id", sessionID.String()).
+		Int64("query_id", queryID).
+		Msg("callback query respondido")
+
+	return nil
+}
@@ -0,0 +1,141 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"telegram-api/internal/domain"
+	"telegram-api/internal/telegram"
+	"telegram-api/pkg/logger"
+
+	"github.com/google/uuid"
+	tgClient "github.com/gotd/td/telegram"
+)
+
+// ChatActionService gestiona lectura de mensajes, reacciones, acciones de chat
+// ("escribiendo...") y presencia en línea de la sesión
+type ChatActionService struct {
+	chats     *ChatService
+	tgManager *telegram.ClientManager
+	pool      *telegram.SessionPool
+}
+
+func NewChatActionService(chats *ChatService, tgManager *telegram.ClientManager, pool *telegram.SessionPool) *ChatActionService {
+	return &ChatActionService{
+		chats:     chats,
+		tgManager: tgManager,
+		pool:      pool,
+	}
+}
+
+// MarkAsRead marca el chat como leído hasta maxID e invalida la lista de chats
+func (s *ChatActionService) MarkAsRead(ctx context.Context, userID, sessionID uuid.UUID, chatID int64, req domain.MarkReadRequest) error {
+	err := s.chats.withClient(ctx, userID, sessionID, domain.OrgRoleOperator, func(ctx context.Context, client *tgClient.Client) error {
+		return s.tgManager.MarkAsRead(ctx, client, chatID, req.MaxID)
+	})
+	if err != nil {
+		return err
+	}
+
+	_ = s.chats.InvalidateCache(ctx, sessionID, "chats")
+	return nil
+}
+
+// SendReaction añade o quita una reacción y retorna los conteos actualizados
+func (s *ChatActionService) SendReaction(ctx context.Context, userID, sessionID uuid.UUID, chatID int64, msgID int, req domain.SendReactionRequest) ([]domain.MessageReaction, error) {
+	var result []domain.MessageReaction
+	err := s.chats.withClient(ctx, userID, sessionID, domain.OrgRoleOperator, func(ctx context.Context, client *tgClient.Client) error {
+		var runErr error
+		result, runErr = s.tgManager.SendReaction(ctx, client, chatID, msgID, req)
+		return runErr
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// SendChatAction muestra la acción durante req.Duration segundos. Retorna tras
+// el primer envío; el refresco sigue en segundo plano con el cliente del pool
+// si la sesión está conectada o, si no, con un cliente propio. Una acción
+// posterior en el mismo chat (incluido cancel) detiene la anterior.
+func (s *ChatActionService) SendChatAction(ctx context.Context, userID, sessionID uuid.UUID, chatID int64, req domain.ChatActionRequest) error {
+	if _, err := s.chats.getValidSession(ctx, userID, sessionID, domain.OrgRoleOperator); err != nil {
+		return err
+	}
+
+	// El bucle no puede depender del contexto de la petición HTTP
+	parent := context.Background()
+	sessCtx, api, pooled := s.pool.ConnectedContext(sessionID)
+	if pooled {
+		parent = sessCtx
+	}
+	runCtx, release := s.tgManager.StartChatAction(telegram.WithSessionID(parent, sessionID), sessionID, chatID)
+
+	duration := time.Duration(req.Duration) * time.Second
+	started := make(chan error, 1)
+	go func() {
+		defer release()
+
+		var err error
+		if pooled {
+			err = s.tgManager.KeepChatAction(runCtx, api, chatID, req.Action, duration, started)
+		} else {
+			err = s.chats.withClient(runCtx, userID, sessionID, domain.OrgRoleOperator, func(ctx context.Context, client *tgClient.Client) error {
+				return s.tgManager.KeepChatAction(ctx, client.API(), chatID, req.Action, duration, started)
+			})
+		}
+
+		// Si falló antes del primer envío nadie publicó en started
+		select {
+		case started <- err:
+		default:
+		}
+		if err != nil && runCtx.Err() == nil {
+			logger.Warn().Err(err).
+				Str("session_id", sessionID.String()).
+				Int64("chat_id", chatID).
+				Msg("acción de chat interrumpida")
+		}
+	}()
+
+	select {
+	case err := <-started:
+		return mapTelegramError(err)
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// SetPresence aparece en línea o desconectado. Si la sesión está en el pool el
+// estado en línea se mantiene; si no, se aplica una sola vez con un cliente temporal.
+func (s *ChatActionService) SetPresence(ctx context.Context, userID, sessionID uuid.UUID, req domain.PresenceRequest) (*domain.PresenceStatus, error) {
+	if _, err := s.chats.getValidSession(ctx, userID, sessionID, domain.OrgRoleOperator); err != nil {
+		return nil, err
+	}
+
+	applied, err := s.pool.SetPresence(ctx, sessionID, req.Online)
+	if err != nil {
+		return nil, mapTelegramError(err)
+	}
+
+	if !applied {
+		err := s.chats.withClient(ctx, userID, sessionID, domain.OrgRoleOperator, func(ctx context.Context, client *tgClient.Client) error {
+			return s.tgManager.SetOnline(ctx, client.API(), req.Online)
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	logger.Info().
+		Str("session_id", sessionID.String()).
+		Bool("online", req.Online).
+		Bool("keep_alive", applied).
+		Msg("presencia actualizada")
+
+	return &domain.PresenceStatus{
+		Online:    req.Online,
+		KeepAlive: applied && req.Online,
+	}, nil
+}
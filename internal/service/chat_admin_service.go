@@ -0,0 +1,221 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"telegram-api/internal/domain"
+	"telegram-api/internal/telegram"
+	"telegram-api/pkg/logger"
+
+	"github.com/google/uuid"
+	tgClient "github.com/gotd/td/telegram"
+)
+
+// ChatAdminService gestiona la administración de grupos, supergrupos y canales.
+// Reutiliza la validación de sesión y la creación de clientes de ChatService.
+type ChatAdminService struct {
+	chats     *ChatService
+	tgManager *telegram.ClientManager
+}
+
+func NewChatAdminService(chats *ChatService, tgManager *telegram.ClientManager) *ChatAdminService {
+	return &ChatAdminService{
+		chats:     chats,
+		tgManager: tgManager,
+	}
+}
+
+// ==================== CREATE ====================
+
+func (s *ChatAdminService) CreateChat(ctx context.Context, userID, sessionID uuid.UUID, req domain.CreateChatRequest) (*domain.CreatedChat, error) {
+	var result *domain.CreatedChat
+	err := s.run(ctx, userID, sessionID, func(ctx context.Context, client *tgClient.Client) error {
+		var runErr error
+		result, runErr = s.tgManager.CreateChat(ctx, client, req)
+		return runErr
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	_ = s.chats.InvalidateCache(ctx, sessionID, "chats")
+
+	logger.Info().
+		Str("session_id", sessionID.String()).
+		Int64("chat_id", result.ID).
+		Str("type", string(result.Type)).
+		Msg("chat creado")
+
+	return result, nil
+}
+
+// ==================== MEMBERS ====================
+
+func (s *ChatAdminService) InviteMembers(ctx context.Context, userID, sessionID uuid.UUID, chatID int64, req domain.InviteMembersRequest) (*domain.InviteMembersResult, error) {
+	var result *domain.InviteMembersResult
+	err := s.run(ctx, userID, sessionID, func(ctx context.Context, client *tgClient.Client) error {
+		var runErr error
+		result, runErr = s.tgManager.InviteMembers(ctx, client, chatID, req.Users)
+		return runErr
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.invalidateChat(ctx, sessionID, chatID)
+	return result, nil
+}
+
+func (s *ChatAdminService) KickMember(ctx context.Context, userID, sessionID uuid.UUID, chatID int64, user string) error {
+	return s.mutate(ctx, userID, sessionID, chatID, func(ctx context.Context, client *tgClient.Client) error {
+		return s.tgManager.KickMember(ctx, client, chatID, user)
+	})
+}
+
+func (s *ChatAdminService) BanMember(ctx context.Context, userID, sessionID uuid.UUID, chatID int64, req domain.BanMemberRequest) error {
+	return s.mutate(ctx, userID, sessionID, chatID, func(ctx context.Context, client *tgClient.Client) error {
+		return s.tgManager.BanMember(ctx, client, chatID, req)
+	})
+}
+
+func (s *ChatAdminService) UnbanMember(ctx context.Context, userID, sessionID uuid.UUID, chatID int64, user string) error {
+	return s.mutate(ctx, userID, sessionID, chatID, func(ctx context.Context, client *tgClient.Client) error {
+		return s.tgManager.UnbanMember(ctx, client, chatID, user)
+	})
+}
+
+// ==================== ADMINS ====================
+
+func (s *ChatAdminService) PromoteAdmin(ctx context.Context, userID, sessionID uuid.UUID, chatID int64, req domain.PromoteAdminRequest) error {
+	return s.mutate(ctx, userID, sessionID, chatID, func(ctx context.Context, client *tgClient.Client) error {
+		return s.tgManager.PromoteAdmin(ctx, client, chatID, req)
+	})
+}
+
+func (s *ChatAdminService) DemoteAdmin(ctx context.Context, userID, sessionID uuid.UUID, chatID int64, user string) error {
+	return s.mutate(ctx, userID, sessionID, chatID, func(ctx context.Context, client *tgClient.Client) error {
+		return s.tgManager.DemoteAdmin(ctx, client, chatID, user)
+	})
+}
+
+// ==================== SETTINGS ====================
+
+func (s *ChatAdminService) EditChat(ctx context.Context, userID, sessionID uuid.UUID, chatID int64, req domain.EditChatRequest) error {
+	return s.mutate(ctx, userID, sessionID, chatID, func(ctx context.Context, client *tgClient.Client) error {
+		return s.tgManager.EditChat(ctx, client, chatID, req)
+	})
+}
+
+func (s *ChatAdminService) EditChatPhoto(ctx context.Context, userID, sessionID uuid.UUID, chatID int64, req domain.EditChatPhotoRequest) error {
+	return s.mutate(ctx, userID, sessionID, chatID, func(ctx context.Context, client *tgClient.Client) error {
+		return s.tgManager.EditChatPhoto(ctx, client, chatID, req.PhotoURL)
+	})
+}
+
+func (s *ChatAdminService) SetSlowMode(ctx context.Context, userID, sessionID uuid.UUID, chatID int64, req domain.SlowModeRequest) error {
+	return s.mutate(ctx, userID, sessionID, chatID, func(ctx context.Context, client *tgClient.Client) error {
+		return s.tgManager.SetSlowMode(ctx, client, chatID, req.Seconds)
+	})
+}
+
+func (s *ChatAdminService) SetDefaultPermissions(ctx context.Context, userID, sessionID uuid.UUID, chatID int64, perms domain.ChatPermissions) error {
+	return s.mutate(ctx, userID, sessionID, chatID, func(ctx context.Context, client *tgClient.Client) error {
+		return s.tgManager.SetDefaultPermissions(ctx, client, chatID, perms)
+	})
+}
+
+// ==================== JOIN / LEAVE ====================
+
+func (s *ChatAdminService) JoinChat(ctx context.Context, userID, sessionID uuid.UUID, req domain.JoinChatRequest) (*domain.JoinedChat, error) {
+	var result *domain.JoinedChat
+	err := s.run(ctx, userID, sessionID, func(ctx context.Context, client *tgClient.Client) error {
+		var runErr error
+		result, runErr = s.tgManager.JoinChat(ctx, client, req.Link)
+		return runErr
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	_ = s.chats.InvalidateCache(ctx, sessionID, "chats")
+
+	logger.Info().
+		Str("session_id", sessionID.String()).
+		Int64("chat_id", result.ID).
+		Bool("pending", result.Pending).
+		Msg("unido a chat")
+
+	return result, nil
+}
+
+func (s *ChatAdminService) LeaveChat(ctx context.Context, userID, sessionID uuid.UUID, chatID int64) error {
+	return s.mutate(ctx, userID, sessionID, chatID, func(ctx context.Context, client *tgClient.Client) error {
+		return s.tgManager.LeaveChat(ctx, client, chatID)
+	})
+}
+
+// ==================== INVITE LINKS ====================
+
+func (s *ChatAdminService) CreateInviteLink(ctx context.Context, userID, sessionID uuid.UUID, chatID int64, req domain.CreateInviteLinkRequest) (*domain.InviteLink, error) {
+	var result *domain.InviteLink
+	err := s.run(ctx, userID, sessionID, func(ctx context.Context, client *tgClient.Client) error {
+		var runErr error
+		result, runErr = s.tgManager.CreateInviteLink(ctx, client, chatID, req)
+		return runErr
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (s *ChatAdminService) ListInviteLinks(ctx context.Context, userID, sessionID uuid.UUID, chatID int64, revoked bool) (*domain.InviteLinksResponse, error) {
+	var result *domain.InviteLinksResponse
+	err := s.run(ctx, userID, sessionID, func(ctx context.Context, client *tgClient.Client) error {
+		var runErr error
+		result, runErr = s.tgManager.ListInviteLinks(ctx, client, chatID, revoked)
+		return runErr
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (s *ChatAdminService) RevokeInviteLink(ctx context.Context, userID, sessionID uuid.UUID, chatID int64, req domain.RevokeInviteLinkRequest) (*domain.InviteLink, error) {
+	var result *domain.InviteLink
+	err := s.run(ctx, userID, sessionID, func(ctx context.Context, client *tgClient.Client) error {
+		var runErr error
+		result, runErr = s.tgManager.RevokeInviteLink(ctx, client, chatID, req.Link)
+		return runErr
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// ==================== HELPERS ====================
+
+// run ejecuta fn con un cliente Telegram de la sesión validada
+func (s *ChatAdminService) run(ctx context.Context, userID, sessionID uuid.UUID, fn func(ctx context.Context, client *tgClient.Client) error) error {
+	return s.chats.withClient(ctx, userID, sessionID, domain.OrgRoleOperator, fn)
+}
+
+// mutate ejecuta una operación que modifica el chat e invalida su cache
+func (s *ChatAdminService) mutate(ctx context.Context, userID, sessionID uuid.UUID, chatID int64, fn func(ctx context.Context, client *tgClient.Client) error) error {
+	if err := s.run(ctx, userID, sessionID, fn); err != nil {
+		return err
+	}
+	s.invalidateChat(ctx, sessionID, chatID)
+	return nil
+}
+
+func (s *ChatAdminService) invalidateChat(ctx context.Context, sessionID uuid.UUID, chatID int64) {
+	keys := []string{fmt.Sprintf("tg:chat:%s:%d", sessionID.String(), chatID)}
+	if err := s.chats.cacheRepo.Delete(ctx, keys...); err != nil {
+		logger.Warn().Err(err).Int64("chat_id", chatID).Msg("error invalidando cache de chat")
+	}
+	_ = s.chats.InvalidateCache(ctx, sessionID, "chats")
+}
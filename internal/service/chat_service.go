@@ -19,6 +19,7 @@ type ChatService struct {
 	sessionRepo domain.SessionRepository
 	cacheRepo   domain.CacheRepository
 	tgManager   *telegram.ClientManager
+	orgs        *OrganizationService
 	cacheCfg    config.CacheConfig
 }
 
@@ -26,12 +27,14 @@ func NewChatService(
 	sessionRepo domain.SessionRepository,
 	cacheRepo domain.CacheRepository,
 	tgManager *telegram.ClientManager,
+	orgs *OrganizationService,
 	cfg *config.Config,
 ) *ChatService {
 	return &ChatService{
 		sessionRepo: sessionRepo,
 		cacheRepo:   cacheRepo,
 		tgManager:   tgManager,
+		orgs:        orgs,
 		cacheCfg:    cfg.Cache,
 	}
 }
@@ -39,7 +42,7 @@ func NewChatService(
 // ==================== CONTACTS CON CACHE + PAGINACIÓN ====================
 
 func (s *ChatService) GetContacts(ctx context.Context, userID, sessionID uuid.UUID, req domain.GetContactsRequest) (*domain.ContactsResponse, error) {
-	sess, err := s.getValidSession(ctx, userID, sessionID)
+	sess, err := s.getValidSession(ctx, userID, sessionID, domain.OrgRoleViewer)
 	if err != nil {
 		return nil, err
 	}
@@ -68,7 +71,7 @@ func (s *ChatService) GetContacts(ctx context.Context, userID, sessionID uuid.UU
 		}
 
 		var result *domain.ContactsResponse
-		err = client.Run(ctx, func(ctx context.Context) error {
+		err = client.Run(telegram.WithSessionID(ctx, sess.ID), func(ctx context.Context) error {
 			var runErr error
 			result, runErr = s.tgManager.GetContacts(ctx, client)
 			return runErr
@@ -108,7 +111,7 @@ func (s *ChatService) GetContacts(ctx context.Context, userID, sessionID uuid.UU
 // ==================== CHATS/DIALOGS CON CACHE ====================
 
 func (s *ChatService) GetDialogs(ctx context.Context, userID, sessionID uuid.UUID, req domain.GetChatsRequest) (*domain.ChatsResponse, error) {
-	sess, err := s.getValidSession(ctx, userID, sessionID)
+	sess, err := s.getValidSession(ctx, userID, sessionID, domain.OrgRoleViewer)
 	if err != nil {
 		return nil, err
 	}
@@ -137,7 +140,7 @@ func (s *ChatService) GetDialogs(ctx context.Context, userID, sessionID uuid.UUI
 		}
 
 		var result *domain.ChatsResponse
-		err = client.Run(ctx, func(ctx context.Context) error {
+		err = client.Run(telegram.WithSessionID(ctx, sess.ID), func(ctx context.Context) error {
 			var runErr error
 			tempReq := domain.GetChatsRequest{Limit: 100, Archived: req.Archived}
 			result, runErr = s.tgManager.GetDialogs(ctx, client, tempReq)
@@ -178,7 +181,7 @@ func (s *ChatService) GetDialogs(ctx context.Context, userID, sessionID uuid.UUI
 // ==================== CHAT INFO CON CACHE ====================
 
 func (s *ChatService) GetChatInfo(ctx context.Context, userID, sessionID uuid.UUID, chatID int64) (*domain.Chat, error) {
-	sess, err := s.getValidSession(ctx, userID, sessionID)
+	sess, err := s.getValidSession(ctx, userID, sessionID, domain.OrgRoleViewer)
 	if err != nil {
 		return nil, err
 	}
@@ -197,7 +200,7 @@ func (s *ChatService) GetChatInfo(ctx context.Context, userID, sessionID uuid.UU
 	}
 
 	var result *domain.Chat
-	err = client.Run(ctx, func(ctx context.Context) error {
+	err = client.Run(telegram.WithSessionID(ctx, sess.ID), func(ctx context.Context) error {
 		var runErr error
 		result, runErr = s.tgManager.GetChatInfo(ctx, client, chatID)
 		return runErr
@@ -216,7 +219,7 @@ func (s *ChatService) GetChatInfo(ctx context.Context, userID, sessionID uuid.UU
 // ==================== HISTORY (SIN CACHE) ====================
 
 func (s *ChatService) GetChatHistory(ctx context.Context, userID, sessionID uuid.UUID, chatID int64, req domain.GetHistoryRequest) (*domain.HistoryResponse, error) {
-	sess, err := s.getValidSession(ctx, userID, sessionID)
+	sess, err := s.getValidSession(ctx, userID, sessionID, domain.OrgRoleViewer)
 	if err != nil {
 		return nil, err
 	}
@@ -227,7 +230,7 @@ func (s *ChatService) GetChatHistory(ctx context.Context, userID, sessionID uuid
 	}
 
 	var result *domain.HistoryResponse
-	err = client.Run(ctx, func(ctx context.Context) error {
+	err = client.Run(telegram.WithSessionID(ctx, sess.ID), func(ctx context.Context) error {
 		var runErr error
 		result, runErr = s.tgManager.GetChatHistory(ctx, client, chatID, req)
 		return runErr
@@ -242,7 +245,7 @@ func (s *ChatService) GetChatHistory(ctx context.Context, userID, sessionID uuid
 // ==================== RESOLVE CON CACHE ====================
 
 func (s *ChatService) ResolvePeer(ctx context.Context, userID, sessionID uuid.UUID, req domain.ResolveRequest) (*domain.ResolvedPeer, error) {
-	sess, err := s.getValidSession(ctx, userID, sessionID)
+	sess, err := s.getValidSession(ctx, userID, sessionID, domain.OrgRoleViewer)
 	if err != nil {
 		return nil, err
 	}
@@ -251,10 +254,12 @@ func (s *ChatService) ResolvePeer(ctx context.Context, userID, sessionID uuid.UU
 	if identifier == "" {
 		identifier = req.Phone
 	}
+	// Las vistas previas de invitación no se cachean: la membresía cambia al unirse
+	cacheable := identifier != ""
 	cacheKey := fmt.Sprintf("tg:resolve:%s:%s", sessionID.String(), identifier)
 
 	var cached domain.ResolvedPeer
-	if err := s.cacheRepo.GetJSON(ctx, cacheKey, &cached); err == nil && cached.ID != 0 {
+	if err := s.cacheRepo.GetJSON(ctx, cacheKey, &cached); cacheable && err == nil && cached.ID != 0 {
 		logger.Debug().Str("identifier", identifier).Msg("peer de cache")
 		return &cached, nil
 	}
@@ -265,7 +270,7 @@ func (s *ChatService) ResolvePeer(ctx context.Context, userID, sessionID uuid.UU
 	}
 
 	var result *domain.ResolvedPeer
-	err = client.Run(ctx, func(ctx context.Context) error {
+	err = client.Run(telegram.WithSessionID(ctx, sess.ID), func(ctx context.Context) error {
 		var runErr error
 		result, runErr = s.tgManager.ResolveUsername(ctx, client, req)
 		return runErr
@@ -274,7 +279,7 @@ func (s *ChatService) ResolvePeer(ctx context.Context, userID, sessionID uuid.UU
 		return nil, fmt.Errorf("resolve peer: %w", err)
 	}
 
-	if result != nil {
+	if result != nil && cacheable {
 		_ = s.cacheRepo.SetJSON(ctx, cacheKey, result, s.cacheCfg.ResolveTTL)
 	}
 
@@ -322,13 +327,12 @@ func (s *ChatService) InvalidateCache(ctx context.Context, sessionID uuid.UUID,
 
 // ==================== HELPERS ====================
 
-func (s *ChatService) getValidSession(ctx context.Context, userID, sessionID uuid.UUID) (*domain.TelegramSession, error) {
-	sess, err := s.sessionRepo.GetByID(ctx, sessionID)
+// getValidSession carga la sesión autenticada si el usuario alcanza el rol
+// need: viewer para lecturas, operator para acciones sobre la cuenta
+func (s *ChatService) getValidSession(ctx context.Context, userID, sessionID uuid.UUID, need domain.OrgRole) (*domain.TelegramSession, error) {
+	sess, err := s.orgs.AuthorizeSession(ctx, userID, sessionID, need)
 	if err != nil {
-		return nil, domain.ErrSessionNotFound
-	}
-	if sess.UserID != userID {
-		return nil, domain.ErrUnauthorized
+		return nil, err
 	}
 	if !sess.IsActive {
 		return nil, domain.ErrSessionInactive
@@ -336,6 +340,25 @@ func (s *ChatService) getValidSession(ctx context.Context, userID, sessionID uui
 	return sess, nil
 }
 
+// withClient valida la sesión con el rol need, abre un cliente Telegram y
+// ejecuta fn dentro de client.Run, mapeando errores RPC a AppError
+func (s *ChatService) withClient(ctx context.Context, userID, sessionID uuid.UUID, need domain.OrgRole, fn func(ctx context.Context, client *tgClient.Client) error) error {
+	sess, err := s.getValidSession(ctx, userID, sessionID, need)
+	if err != nil {
+		return err
+	}
+
+	client, err := s.createClient(ctx, sess)
+	if err != nil {
+		return fmt.Errorf("create client: %w", err)
+	}
+
+	err = client.Run(telegram.WithSessionID(ctx, sess.ID), func(ctx context.Context) error {
+		return fn(ctx, client)
+	})
+	return mapTelegramError(err)
+}
+
 func (s *ChatService) createClient(ctx context.Context, sess *domain.TelegramSession) (*tgClient.Client, error) {
 	apiHashBytes, err := s.tgManager.Decrypt(sess.ApiHashEncrypted)
 	if err != nil {
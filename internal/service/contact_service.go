@@ -0,0 +1,189 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"telegram-api/internal/domain"
+	"telegram-api/internal/telegram"
+	"telegram-api/pkg/logger"
+
+	"github.com/google/uuid"
+	tgClient "github.com/gotd/td/telegram"
+)
+
+// csvHeader columnas del CSV de contactos (import/export)
+var csvHeader = []string{"phone", "first_name", "last_name", "username", "id"}
+
+// ContactService gestiona la agenda de contactos y la lista de bloqueados
+type ContactService struct {
+	chats     *ChatService
+	tgManager *telegram.ClientManager
+}
+
+func NewContactService(chats *ChatService, tgManager *telegram.ClientManager) *ContactService {
+	return &ContactService{
+		chats:     chats,
+		tgManager: tgManager,
+	}
+}
+
+// ==================== IMPORT / DELETE ====================
+
+func (s *ContactService) ImportContacts(ctx context.Context, userID, sessionID uuid.UUID, req domain.ImportContactsRequest) (*domain.ImportContactsResult, error) {
+	var result *domain.ImportContactsResult
+	err := s.chats.withClient(ctx, userID, sessionID, domain.OrgRoleOperator, func(ctx context.Context, client *tgClient.Client) error {
+		var runErr error
+		result, runErr = s.tgManager.ImportContacts(ctx, client, req.Contacts)
+		return runErr
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	_ = s.chats.InvalidateCache(ctx, sessionID, "contacts")
+
+	logger.Info().
+		Str("session_id", sessionID.String()).
+		Int("requested", len(req.Contacts)).
+		Int("imported", len(result.Imported)).
+		Int("not_on_telegram", len(result.NotOnTelegram)).
+		Msg("contactos importados")
+
+	return result, nil
+}
+
+// ParseContactsCSV lee un CSV con encabezado (phone, first_name, last_name).
+// Los teléfonos sin "+" se normalizan agregándolo.
+func (s *ContactService) ParseContactsCSV(r io.Reader) (*domain.ImportContactsRequest, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("%w: CSV vacío o inválido", domain.ErrInvalidInput)
+	}
+
+	cols := make(map[string]int, len(header))
+	for i, h := range header {
+		cols[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(h, "\ufeff")))] = i
+	}
+	phoneCol, ok := cols["phone"]
+	if !ok {
+		return nil, fmt.Errorf("%w: el CSV requiere la columna phone", domain.ErrInvalidInput)
+	}
+
+	field := func(record []string, name string) string {
+		if i, ok := cols[name]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	req := &domain.ImportContactsRequest{}
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: línea %d: %v", domain.ErrInvalidInput, line, err)
+		}
+		if phoneCol >= len(record) || strings.TrimSpace(record[phoneCol]) == "" {
+			continue
+		}
+
+		phone := strings.NewReplacer(" ", "", "-", "", "(", "", ")", "").Replace(strings.TrimSpace(record[phoneCol]))
+		if !strings.HasPrefix(phone, "+") {
+			phone = "+" + phone
+		}
+
+		firstName := field(record, "first_name")
+		if firstName == "" {
+			firstName = phone
+		}
+
+		req.Contacts = append(req.Contacts, domain.ImportContact{
+			Phone:     phone,
+			FirstName: firstName,
+			LastName:  field(record, "last_name"),
+		})
+	}
+
+	return req, nil
+}
+
+func (s *ContactService) DeleteContacts(ctx context.Context, userID, sessionID uuid.UUID, req domain.DeleteContactsRequest) error {
+	err := s.chats.withClient(ctx, userID, sessionID, domain.OrgRoleOperator, func(ctx context.Context, client *tgClient.Client) error {
+		return s.tgManager.DeleteContacts(ctx, client, req.Users)
+	})
+	if err != nil {
+		return err
+	}
+
+	_ = s.chats.InvalidateCache(ctx, sessionID, "contacts")
+	return nil
+}
+
+// ExportCSV genera el CSV de la agenda completa (sin cache)
+func (s *ContactService) ExportCSV(ctx context.Context, userID, sessionID uuid.UUID) ([]byte, error) {
+	var contacts *domain.ContactsResponse
+	err := s.chats.withClient(ctx, userID, sessionID, domain.OrgRoleViewer, func(ctx context.Context, client *tgClient.Client) error {
+		var runErr error
+		contacts, runErr = s.tgManager.GetContacts(ctx, client)
+		return runErr
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	_ = w.Write(csvHeader)
+	for _, c := range contacts.Contacts {
+		phone := c.Phone
+		if phone != "" && !strings.HasPrefix(phone, "+") {
+			phone = "+" + phone
+		}
+		_ = w.Write([]string{phone, c.FirstName, c.LastName, c.Username, strconv.FormatInt(c.ID, 10)})
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return nil, fmt.Errorf("write csv: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// ==================== BLOCK ====================
+
+func (s *ContactService) SetBlocked(ctx context.Context, userID, sessionID uuid.UUID, user string, blocked bool) error {
+	err := s.chats.withClient(ctx, userID, sessionID, domain.OrgRoleOperator, func(ctx context.Context, client *tgClient.Client) error {
+		return s.tgManager.SetBlocked(ctx, client, user, blocked)
+	})
+	if err != nil {
+		return err
+	}
+
+	_ = s.chats.InvalidateCache(ctx, sessionID, "contacts")
+	return nil
+}
+
+func (s *ContactService) GetBlocked(ctx context.Context, userID, sessionID uuid.UUID, offset, limit int) (*domain.BlockedUsersResponse, error) {
+	var result *domain.BlockedUsersResponse
+	err := s.chats.withClient(ctx, userID, sessionID, domain.OrgRoleViewer, func(ctx context.Context, client *tgClient.Client) error {
+		var runErr error
+		result, runErr = s.tgManager.GetBlocked(ctx, client, offset, limit)
+		return runErr
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
@@ -0,0 +1,119 @@
+package service
+
+import (
+	"context"
+
+	"telegram-api/internal/domain"
+	"telegram-api/internal/telegram"
+	"telegram-api/pkg/logger"
+
+	"github.com/google/uuid"
+	tgClient "github.com/gotd/td/telegram"
+)
+
+// DialogService gestiona el estado de los diálogos (fijado, silencio, archivo,
+// no leído), mensajes fijados y carpetas de chats
+type DialogService struct {
+	chats     *ChatService
+	tgManager *telegram.ClientManager
+}
+
+func NewDialogService(chats *ChatService, tgManager *telegram.ClientManager) *DialogService {
+	return &DialogService{
+		chats:     chats,
+		tgManager: tgManager,
+	}
+}
+
+// ==================== DIALOG STATE ====================
+
+func (s *DialogService) ToggleDialogPin(ctx context.Context, userID, sessionID uuid.UUID, chatID int64, req domain.PinDialogRequest) error {
+	return s.mutate(ctx, userID, sessionID, func(ctx context.Context, client *tgClient.Client) error {
+		return s.tgManager.ToggleDialogPin(ctx, client, chatID, req.Pinned)
+	})
+}
+
+func (s *DialogService) MuteDialog(ctx context.Context, userID, sessionID uuid.UUID, chatID int64, req domain.MuteDialogRequest) error {
+	return s.mutate(ctx, userID, sessionID, func(ctx context.Context, client *tgClient.Client) error {
+		return s.tgManager.MuteDialog(ctx, client, chatID, req)
+	})
+}
+
+func (s *DialogService) ArchiveDialog(ctx context.Context, userID, sessionID uuid.UUID, chatID int64, req domain.ArchiveDialogRequest) error {
+	return s.mutate(ctx, userID, sessionID, func(ctx context.Context, client *tgClient.Client) error {
+		return s.tgManager.ArchiveDialog(ctx, client, chatID, req.Archived)
+	})
+}
+
+func (s *DialogService) MarkDialogUnread(ctx context.Context, userID, sessionID uuid.UUID, chatID int64, req domain.UnreadDialogRequest) error {
+	return s.mutate(ctx, userID, sessionID, func(ctx context.Context, client *tgClient.Client) error {
+		return s.tgManager.MarkDialogUnread(ctx, client, chatID, req.Unread)
+	})
+}
+
+// ==================== PINNED MESSAGES ====================
+
+func (s *DialogService) PinMessage(ctx context.Context, userID, sessionID uuid.UUID, chatID int64, msgID int, req domain.PinMessageRequest) error {
+	return s.chats.withClient(ctx, userID, sessionID, domain.OrgRoleOperator, func(ctx context.Context, client *tgClient.Client) error {
+		return s.tgManager.UpdatePinnedMessage(ctx, client, chatID, msgID, false, req)
+	})
+}
+
+func (s *DialogService) UnpinMessage(ctx context.Context, userID, sessionID uuid.UUID, chatID int64, msgID int) error {
+	return s.chats.withClient(ctx, userID, sessionID, domain.OrgRoleOperator, func(ctx context.Context, client *tgClient.Client) error {
+		return s.tgManager.UpdatePinnedMessage(ctx, client, chatID, msgID, true, domain.PinMessageRequest{})
+	})
+}
+
+// ==================== FOLDERS ====================
+
+func (s *DialogService) GetFolders(ctx context.Context, userID, sessionID uuid.UUID) (*domain.ChatFoldersResponse, error) {
+	var result *domain.ChatFoldersResponse
+	err := s.chats.withClient(ctx, userID, sessionID, domain.OrgRoleViewer, func(ctx context.Context, client *tgClient.Client) error {
+		var runErr error
+		result, runErr = s.tgManager.GetChatFolders(ctx, client)
+		return runErr
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// SaveFolder crea una carpeta (folderID = 0) o reemplaza una existente
+func (s *DialogService) SaveFolder(ctx context.Context, userID, sessionID uuid.UUID, folderID int, req domain.ChatFolderRequest) (*domain.ChatFolder, error) {
+	var result *domain.ChatFolder
+	err := s.chats.withClient(ctx, userID, sessionID, domain.OrgRoleOperator, func(ctx context.Context, client *tgClient.Client) error {
+		var runErr error
+		result, runErr = s.tgManager.SaveChatFolder(ctx, client, folderID, req)
+		return runErr
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Info().
+		Str("session_id", sessionID.String()).
+		Int("folder_id", result.ID).
+		Str("title", result.Title).
+		Msg("carpeta guardada")
+
+	return result, nil
+}
+
+func (s *DialogService) DeleteFolder(ctx context.Context, userID, sessionID uuid.UUID, folderID int) error {
+	return s.chats.withClient(ctx, userID, sessionID, domain.OrgRoleOperator, func(ctx context.Context, client *tgClient.Client) error {
+		return s.tgManager.DeleteChatFolder(ctx, client, folderID)
+	})
+}
+
+// ==================== HELPERS ====================
+
+// mutate ejecuta la operación e invalida la lista de chats cacheada
+func (s *DialogService) mutate(ctx context.Context, userID, sessionID uuid.UUID, fn func(ctx context.Context, client *tgClient.Client) error) error {
+	if err := s.chats.withClient(ctx, userID, sessionID, domain.OrgRoleOperator, fn); err != nil {
+		return err
+	}
+	_ = s.chats.InvalidateCache(ctx, sessionID, "chats")
+	return nil
+}
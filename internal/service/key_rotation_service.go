@@ -0,0 +1,139 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"telegram-api/internal/domain"
+	"telegram-api/internal/telegram"
+	"telegram-api/pkg/logger"
+
+	"github.com/google/uuid"
+)
+
+const keyRotationBatchSize = 100
+
+// KeyRotationResult resume una pasada de re-cifrado
+type KeyRotationResult struct {
+	Scanned  int           `json:"scanned"`
+	Rotated  int           `json:"rotated"`
+	Skipped  int           `json:"skipped"` // modificadas concurrentemente, ya usan la clave nueva
+	Failed   int           `json:"failed"`
+	Webhooks int           `json:"webhooks"` // secretos de webhook re-cifrados
+	MFA      int           `json:"mfa"`      // usuarios con secretos TOTP re-cifrados
+	Duration time.Duration `json:"duration"`
+}
+
+// KeyRotationService re-cifra con la clave primaria los datos de sesión
+// (session_data y api_hash_encrypted) y los secretos de webhook cifrados con
+// claves anteriores o en el formato sin key ID, y los secretos TOTP de los usuarios
+type KeyRotationService struct {
+	sessionRepo domain.SessionRepository
+	webhookRepo domain.WebhookRepository
+	userRepo    domain.UserRepository
+	tgManager   *telegram.ClientManager
+}
+
+func NewKeyRotationService(sessionRepo domain.SessionRepository, webhookRepo domain.WebhookRepository, userRepo domain.UserRepository, tgManager *telegram.ClientManager) *KeyRotationService {
+	return &KeyRotationService{
+		sessionRepo: sessionRepo,
+		webhookRepo: webhookRepo,
+		userRepo:    userRepo,
+		tgManager:   tgManager,
+	}
+}
+
+// RotateSessions recorre telegram_sessions por lotes. Es idempotente: las filas
+// que ya usan la clave primaria se ignoran, por lo que puede re-ejecutarse.
+func (s *KeyRotationService) RotateSessions(ctx context.Context) (*KeyRotationResult, error) {
+	start := time.Now()
+	result := &KeyRotationResult{}
+	keyID := s.tgManager.EncryptionKeyID()
+
+	logger.Info().Str("key_id", keyID).Msg("🔑 Iniciando re-cifrado de sesiones")
+
+	after := uuid.Nil
+	for {
+		batch, err := s.sessionRepo.ListCiphertexts(ctx, after, keyRotationBatchSize)
+		if err != nil {
+			return result, err
+		}
+		if len(batch) == 0 {
+			break
+		}
+
+		for _, sess := range batch {
+			result.Scanned++
+			after = sess.ID
+
+			apiHashStale := s.tgManager.NeedsReencrypt(sess.ApiHashEncrypted)
+			dataStale := len(sess.SessionData) > 0 && s.tgManager.NeedsReencrypt(sess.SessionData)
+			if !apiHashStale && !dataStale {
+				continue
+			}
+
+			apiHash, sessionData, err := s.reencrypt(sess, apiHashStale, dataStale)
+			if err != nil {
+				result.Failed++
+				logger.Warn().Err(err).Str("session_id", sess.ID.String()).Msg("⚠️ No se pudo re-cifrar la sesión")
+				continue
+			}
+
+			updated, err := s.sessionRepo.UpdateCiphertexts(ctx, sess.ID, sess.ApiHashEncrypted, sess.SessionData, apiHash, sessionData)
+			if err != nil {
+				return result, err
+			}
+			if updated {
+				result.Rotated++
+			} else {
+				result.Skipped++
+			}
+		}
+
+		if ctx.Err() != nil {
+			return result, ctx.Err()
+		}
+	}
+
+	webhooks, err := s.webhookRepo.ReencryptSecrets(ctx)
+	result.Webhooks = webhooks
+	if err != nil {
+		return result, err
+	}
+
+	mfa, err := s.userRepo.ReencryptMFASecrets(ctx)
+	result.MFA = mfa
+	if err != nil {
+		return result, err
+	}
+
+	result.Duration = time.Since(start)
+	logger.Info().
+		Str("key_id", keyID).
+		Int("scanned", result.Scanned).
+		Int("rotated", result.Rotated).
+		Int("skipped", result.Skipped).
+		Int("failed", result.Failed).
+		Int("webhooks", result.Webhooks).
+		Int("mfa", result.MFA).
+		Dur("duration", result.Duration).
+		Msg("🔑 Re-cifrado de sesiones completado")
+
+	return result, nil
+}
+
+func (s *KeyRotationService) reencrypt(sess domain.TelegramSession, apiHashStale, dataStale bool) ([]byte, []byte, error) {
+	apiHash, sessionData := sess.ApiHashEncrypted, sess.SessionData
+	var err error
+	if apiHashStale {
+		if apiHash, err = s.tgManager.Reencrypt(apiHash); err != nil {
+			return nil, nil, err
+		}
+	}
+	if dataStale {
+		if sessionData, err = s.tgManager.Reencrypt(sessionData); err != nil {
+			return nil, nil, err
+		}
+	}
+	return apiHash, sessionData, nil
+}
@@ -11,36 +11,109 @@ import (
 	"telegram-api/pkg/logger"
 
 	"github.com/google/uuid"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
 )
 
 type MessageService struct {
 	sessionRepo domain.SessionRepository
 	cache       domain.CacheRepository
 	tgManager   *telegram.ClientManager
+	orgs        *OrganizationService
+	quotas      *QuotaService
+	audit       *AuditService
 }
 
 func NewMessageService(
 	sRepo domain.SessionRepository,
 	cache domain.CacheRepository,
 	tgMgr *telegram.ClientManager,
+	orgs *OrganizationService,
+	quotas *QuotaService,
+	audit *AuditService,
 ) *MessageService {
 	return &MessageService{
 		sessionRepo: sRepo,
 		cache:       cache,
 		tgManager:   tgMgr,
+		orgs:        orgs,
+		quotas:      quotas,
+		audit:       audit,
 	}
 }
 
 const (
-	queueKey    = "tg:msg:queue"
-	jobPrefix   = "tg:msg:job:"
-	jobTTL      = 86400 // 24 horas
+	queueKey  = "tg:msg:queue"
+	jobPrefix = "tg:msg:job:"
+	jobTTL    = 86400 // 24 horas
+
+	auditTextPreview = 256 // caracteres del texto que se guardan en la auditoría
+)
+
+var (
+	messageJobs = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "message_jobs_total",
+		Help: "Jobs de mensajes que alcanzaron cada estado",
+	}, []string{"status"})
+	messageJobsInFlight = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "message_jobs_in_flight",
+		Help: "Jobs de mensajes en curso por estado (pending, scheduled, sending)",
+	}, []string{"status"})
 )
 
-func (s *MessageService) SendMessage(ctx context.Context, sessionID uuid.UUID, req *domain.SendMessageRequest) (*domain.MessageResponse, error) {
-	sess, err := s.sessionRepo.GetByID(ctx, sessionID)
+// setJobStatus cambia el estado del job y actualiza las métricas
+func setJobStatus(job *domain.MessageJob, status domain.MessageStatus) {
+	trackJobStatus(job.Status, status)
+	job.Status = status
+}
+
+// trackJobStatus registra el paso de un job de from ("" si es nuevo) a to
+func trackJobStatus(from, to domain.MessageStatus) {
+	if from != "" && !from.IsFinal() {
+		messageJobsInFlight.WithLabelValues(string(from)).Dec()
+	}
+	if !to.IsFinal() {
+		messageJobsInFlight.WithLabelValues(string(to)).Inc()
+	}
+	messageJobs.WithLabelValues(string(to)).Inc()
+}
+
+// SendMessage encola el mensaje y registra en la auditoría quién lo envió,
+// a quién y un extracto del contenido
+func (s *MessageService) SendMessage(ctx context.Context, userID, sessionID uuid.UUID, req *domain.SendMessageRequest) (*domain.MessageResponse, error) {
+	resp, err := s.sendMessage(ctx, userID, sessionID, req)
+
+	details := map[string]any{
+		"to":   req.To,
+		"type": string(req.Type),
+	}
+	if req.Text != "" {
+		details["text"] = truncateRunes(req.Text, auditTextPreview)
+	}
+	if req.MediaURL != "" {
+		details["media_url"] = req.MediaURL
+	}
+	if resp != nil {
+		details["job_id"] = resp.JobID
+	}
+	s.audit.RecordAction(ctx, domain.AuditMessageSend, &sessionID, err, details)
+
+	return resp, err
+}
+
+// truncateRunes recorta s a n caracteres sin partir runas UTF-8
+func truncateRunes(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n]) + "…"
+}
+
+func (s *MessageService) sendMessage(ctx context.Context, userID, sessionID uuid.UUID, req *domain.SendMessageRequest) (*domain.MessageResponse, error) {
+	sess, err := s.orgs.AuthorizeSession(ctx, userID, sessionID, domain.OrgRoleOperator)
 	if err != nil {
-		return nil, domain.ErrSessionNotFound
+		return nil, err
 	}
 
 	if !sess.IsActive || sess.AuthState != domain.SessionAuthenticated {
@@ -51,16 +124,38 @@ func (s *MessageService) SendMessage(ctx context.Context, sessionID uuid.UUID, r
 		req.Type = domain.MessageTypeText
 	}
 
+	if len(req.InlineKeyboard) > 0 {
+		if !sess.IsBot {
+			return nil, domain.ErrBotOnly
+		}
+		if err := validateInlineKeyboard(req.InlineKeyboard); err != nil {
+			return nil, err
+		}
+	}
+
+	if hasMedia(req.Type, req.MediaURL) {
+		if _, err := s.quotas.MediaAllowance(ctx, userID); err != nil {
+			return nil, err
+		}
+	}
+	// El envío se imputa al encolar y se devuelve si el job termina fallando
+	chargedAt := time.Now()
+	if err := s.quotas.ConsumeSends(ctx, userID, 1); err != nil {
+		return nil, err
+	}
+
 	job := &domain.MessageJob{
-		ID:        uuid.New().String(),
-		SessionID: sessionID,
-		To:        req.To,
-		Text:      req.Text,
-		Type:      req.Type,
-		MediaURL:  req.MediaURL,
-		Caption:   req.Caption,
-		Status:    domain.MessageStatusPending,
-		CreatedAt: time.Now(),
+		ID:             uuid.New().String(),
+		SessionID:      sessionID,
+		RequestedBy:    userID,
+		To:             req.To,
+		Text:           req.Text,
+		Type:           req.Type,
+		MediaURL:       req.MediaURL,
+		Caption:        req.Caption,
+		InlineKeyboard: req.InlineKeyboard,
+		Status:         domain.MessageStatusPending,
+		CreatedAt:      chargedAt,
 	}
 
 	if req.DelayMs > 0 {
@@ -72,6 +167,7 @@ func (s *MessageService) SendMessage(ctx context.Context, sessionID uuid.UUID, r
 
 	jobData, _ := json.Marshal(job)
 	_ = s.cache.Set(ctx, jobPrefix+job.ID, string(jobData), jobTTL)
+	trackJobStatus("", job.Status)
 
 	if req.DelayMs > 0 {
 		go s.scheduleJob(job)
@@ -87,16 +183,21 @@ func (s *MessageService) SendMessage(ctx context.Context, sessionID uuid.UUID, r
 	}, nil
 }
 
-func (s *MessageService) SendBulk(ctx context.Context, sessionID uuid.UUID, req *domain.BulkMessageRequest) ([]domain.MessageResponse, error) {
-	sess, err := s.sessionRepo.GetByID(ctx, sessionID)
+func (s *MessageService) SendBulk(ctx context.Context, userID, sessionID uuid.UUID, req *domain.BulkMessageRequest) ([]domain.MessageResponse, error) {
+	sess, err := s.orgs.AuthorizeSession(ctx, userID, sessionID, domain.OrgRoleOperator)
 	if err != nil {
-		return nil, domain.ErrSessionNotFound
+		return nil, err
 	}
 
 	if !sess.IsActive {
 		return nil, domain.ErrSessionNotActive
 	}
 
+	// Todo o nada: no se encola ninguno si no caben todos en la cuota
+	if err := s.quotas.CheckBulk(ctx, userID, len(req.Recipients)); err != nil {
+		return nil, err
+	}
+
 	var responses []domain.MessageResponse
 	delay := req.DelayMs
 
@@ -110,7 +211,7 @@ func (s *MessageService) SendBulk(ctx context.Context, sessionID uuid.UUID, req
 			DelayMs:  delay * i,
 		}
 
-		resp, err := s.SendMessage(ctx, sessionID, singleReq)
+		resp, err := s.SendMessage(ctx, userID, sessionID, singleReq)
 		if err != nil {
 			responses = append(responses, domain.MessageResponse{
 				Status:  domain.MessageStatusFailed,
@@ -124,7 +225,9 @@ func (s *MessageService) SendBulk(ctx context.Context, sessionID uuid.UUID, req
 	return responses, nil
 }
 
-func (s *MessageService) GetJobStatus(ctx context.Context, jobID string) (*domain.MessageJob, error) {
+// GetJobStatus estado de un job; solo visible para quien tiene acceso a su
+// sesión. key es la API key de la petición (nil con JWT).
+func (s *MessageService) GetJobStatus(ctx context.Context, userID uuid.UUID, key *domain.APIKey, jobID string) (*domain.MessageJob, error) {
 	data, err := s.cache.Get(ctx, jobPrefix+jobID)
 	if err != nil || data == "" {
 		return nil, fmt.Errorf("job not found")
@@ -135,6 +238,13 @@ func (s *MessageService) GetJobStatus(ctx context.Context, jobID string) (*domai
 		return nil, err
 	}
 
+	if key != nil && !key.AllowsSession(job.SessionID) {
+		return nil, fmt.Errorf("job not found")
+	}
+	if _, err := s.orgs.AuthorizeSession(ctx, userID, job.SessionID, domain.OrgRoleViewer); err != nil {
+		return nil, fmt.Errorf("job not found")
+	}
+
 	return &job, nil
 }
 
@@ -150,31 +260,50 @@ func (s *MessageService) processJob(job *domain.MessageJob) {
 	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
 	defer cancel()
 
-	job.Status = domain.MessageStatusSending
+	setJobStatus(job, domain.MessageStatusSending)
 	s.updateJob(ctx, job)
 
 	sess, err := s.sessionRepo.GetByID(ctx, job.SessionID)
 	if err != nil {
-		job.Status = domain.MessageStatusFailed
-		job.Error = "session not found"
+		s.failJob(ctx, job, "session not found")
 		s.updateJob(ctx, job)
 		return
 	}
 
 	req := &domain.SendMessageRequest{
-		To:       job.To,
-		Text:     job.Text,
-		Type:     job.Type,
-		MediaURL: job.MediaURL,
-		Caption:  job.Caption,
+		To:             job.To,
+		Text:           job.Text,
+		Type:           job.Type,
+		MediaURL:       job.MediaURL,
+		Caption:        job.Caption,
+		InlineKeyboard: job.InlineKeyboard,
 	}
 
-	if err := s.tgManager.SendMessage(ctx, sess, req); err != nil {
-		job.Status = domain.MessageStatusFailed
-		job.Error = err.Error()
+	// La cuota de media se comprueba de nuevo al enviar: entre el encolado y
+	// el envío otros jobs pudieron agotarla
+	charged := job.RequestedBy != uuid.Nil && hasMedia(req.Type, req.MediaURL)
+	if charged {
+		allowance, err := s.quotas.MediaAllowance(ctx, job.RequestedBy)
+		if err != nil {
+			s.failJob(ctx, job, err.Error())
+			s.updateJob(ctx, job)
+			return
+		}
+		req.MaxMediaBytes = allowance
+	}
+
+	err = s.tgManager.SendMessage(ctx, sess, req)
+	if charged {
+		if rerr := s.quotas.RecordMedia(ctx, job.RequestedBy, req.MediaBytes); rerr != nil {
+			logger.Error().Err(rerr).Str("job", job.ID).Msg("Error imputando media a la cuota")
+		}
+	}
+
+	if err != nil {
+		s.failJob(ctx, job, err.Error())
 		logger.Error().Err(err).Str("job", job.ID).Msg("mensaje fallido")
 	} else {
-		job.Status = domain.MessageStatusSent
+		setJobStatus(job, domain.MessageStatusSent)
 		now := time.Now()
 		job.SentAt = &now
 		logger.Info().Str("job", job.ID).Str("to", job.To).Msg("mensaje enviado")
@@ -183,6 +312,42 @@ func (s *MessageService) processJob(job *domain.MessageJob) {
 	s.updateJob(ctx, job)
 }
 
+// failJob marca el job como fallido y devuelve a la cuota el envío que se le
+// imputó al encolarlo
+func (s *MessageService) failJob(ctx context.Context, job *domain.MessageJob, reason string) {
+	setJobStatus(job, domain.MessageStatusFailed)
+	job.Error = reason
+	if job.RequestedBy == uuid.Nil {
+		return
+	}
+	if err := s.quotas.RefundSends(ctx, job.RequestedBy, 1, job.CreatedAt); err != nil {
+		logger.Error().Err(err).Str("job", job.ID).Msg("Error devolviendo el envío a la cuota")
+	}
+}
+
+// hasMedia indica si el mensaje descarga y sube un archivo
+func hasMedia(t domain.MessageType, mediaURL string) bool {
+	return mediaURL != "" && t != domain.MessageTypeText && t != ""
+}
+
+// validateInlineKeyboard exige que cada botón tenga exactamente callback_data o url
+func validateInlineKeyboard(kb domain.InlineKeyboard) error {
+	for i, row := range kb {
+		if len(row) == 0 {
+			return domain.NewAppError(domain.ErrInvalidInput, fmt.Sprintf("inline_keyboard: fila %d vacía", i), 400).WithCode("INVALID_KEYBOARD")
+		}
+		for j, btn := range row {
+			if btn.Text == "" || (btn.CallbackData == "") == (btn.URL == "") {
+				return domain.NewAppError(domain.ErrInvalidInput, fmt.Sprintf("inline_keyboard[%d][%d]: requiere text y callback_data o url", i, j), 400).WithCode("INVALID_KEYBOARD")
+			}
+			if len(btn.CallbackData) > 64 {
+				return domain.NewAppError(domain.ErrInvalidInput, fmt.Sprintf("inline_keyboard[%d][%d]: callback_data excede 64 bytes", i, j), 400).WithCode("INVALID_KEYBOARD")
+			}
+		}
+	}
+	return nil
+}
+
 func (s *MessageService) updateJob(ctx context.Context, job *domain.MessageJob) {
 	jobData, _ := json.Marshal(job)
 	_ = s.cache.Set(ctx, jobPrefix+job.ID, string(jobData), jobTTL)
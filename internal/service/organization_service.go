@@ -0,0 +1,340 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"telegram-api/internal/domain"
+	"telegram-api/pkg/logger"
+
+	"github.com/google/uuid"
+)
+
+// OrganizationService organizaciones, sus miembros y el control de acceso a
+// las sesiones. Una sesión personal (sin organización) solo la usa su
+// user_id, con todos los permisos; una sesión de organización la usa cada
+// miembro según su rol.
+type OrganizationService struct {
+	orgRepo     domain.OrganizationRepository
+	sessionRepo domain.SessionRepository
+	userRepo    domain.UserRepository
+	audit       *AuditService
+}
+
+func NewOrganizationService(
+	orgRepo domain.OrganizationRepository,
+	sessionRepo domain.SessionRepository,
+	userRepo domain.UserRepository,
+	audit *AuditService,
+) *OrganizationService {
+	return &OrganizationService{
+		orgRepo:     orgRepo,
+		sessionRepo: sessionRepo,
+		userRepo:    userRepo,
+		audit:       audit,
+	}
+}
+
+// ==================== ACCESO A SESIONES ====================
+
+// SessionRole rol efectivo del usuario sobre la sesión. ErrUnauthorized si no
+// es su sesión personal ni miembro de la organización que la posee.
+func (s *OrganizationService) SessionRole(ctx context.Context, userID uuid.UUID, sess *domain.TelegramSession) (domain.OrgRole, error) {
+	if sess.OrganizationID == nil {
+		if sess.UserID != userID {
+			return "", domain.ErrUnauthorized
+		}
+		return domain.OrgRoleOwner, nil
+	}
+
+	member, err := s.orgRepo.GetMember(ctx, *sess.OrganizationID, userID)
+	if errors.Is(err, domain.ErrMemberNotFound) {
+		return "", domain.ErrUnauthorized
+	}
+	if err != nil {
+		logger.Error().Err(err).Str("session_id", sess.ID.String()).Msg("Error verificando pertenencia a la organización")
+		return "", domain.ErrDatabase
+	}
+	return member.Role, nil
+}
+
+// CheckSessionAccess verifica que el usuario alcance el rol need sobre la
+// sesión; ErrInsufficientOrgRole si es miembro con un rol menor
+func (s *OrganizationService) CheckSessionAccess(ctx context.Context, userID uuid.UUID, sess *domain.TelegramSession, need domain.OrgRole) error {
+	role, err := s.SessionRole(ctx, userID, sess)
+	if err != nil {
+		return err
+	}
+	if !role.Allows(need) {
+		return domain.ErrInsufficientOrgRole
+	}
+	return nil
+}
+
+// AuthorizeSession carga la sesión y verifica el acceso del usuario
+func (s *OrganizationService) AuthorizeSession(ctx context.Context, userID, sessionID uuid.UUID, need domain.OrgRole) (*domain.TelegramSession, error) {
+	sess, err := s.sessionRepo.GetByID(ctx, sessionID)
+	if err != nil {
+		return nil, domain.ErrSessionNotFound
+	}
+	if err := s.CheckSessionAccess(ctx, userID, sess, need); err != nil {
+		return nil, err
+	}
+	return sess, nil
+}
+
+// TransferSession mueve la sesión a una organización (o la vuelve personal
+// con organization_id null). Exige ser owner de la sesión y al menos
+// operator en la organización de destino; quien transfiere queda como
+// responsable (user_id).
+func (s *OrganizationService) TransferSession(ctx context.Context, userID, sessionID uuid.UUID, req *domain.TransferSessionRequest) (*domain.TelegramSession, error) {
+	sess, from, err := s.transferSession(ctx, userID, sessionID, req)
+
+	details := map[string]any{}
+	if from != nil {
+		details["from_organization_id"] = from.String()
+	}
+	if req.OrganizationID != nil {
+		details["to_organization_id"] = req.OrganizationID.String()
+	}
+	s.audit.RecordAction(ctx, domain.AuditSessionTransfer, &sessionID, err, details)
+	return sess, err
+}
+
+// transferSession retorna además la organización de origen para la auditoría
+func (s *OrganizationService) transferSession(ctx context.Context, userID, sessionID uuid.UUID, req *domain.TransferSessionRequest) (*domain.TelegramSession, *uuid.UUID, error) {
+	sess, err := s.AuthorizeSession(ctx, userID, sessionID, domain.OrgRoleOwner)
+	if err != nil {
+		return nil, nil, err
+	}
+	from := sess.OrganizationID
+
+	if req.OrganizationID != nil {
+		if _, err := s.requireRole(ctx, userID, *req.OrganizationID, domain.OrgRoleOperator); err != nil {
+			return nil, from, err
+		}
+	}
+
+	if err := s.orgRepo.SetSessionOwner(ctx, sessionID, userID, req.OrganizationID); err != nil {
+		return nil, from, err
+	}
+
+	sess.UserID = userID
+	sess.OrganizationID = req.OrganizationID
+
+	logger.Info().
+		Str("session_id", sessionID.String()).
+		Str("user_id", userID.String()).
+		Interface("organization_id", req.OrganizationID).
+		Msg("🔀 Sesión transferida")
+	return sess, from, nil
+}
+
+// ==================== ORGANIZACIONES ====================
+
+// Create crea la organización con el usuario como owner
+func (s *OrganizationService) Create(ctx context.Context, userID uuid.UUID, req *domain.CreateOrganizationRequest) (*domain.Organization, error) {
+	now := time.Now()
+	org := &domain.Organization{
+		ID:        uuid.New(),
+		Name:      strings.TrimSpace(req.Name),
+		CreatedBy: userID,
+		Role:      domain.OrgRoleOwner,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	err := s.orgRepo.Create(ctx, org, userID)
+	s.audit.RecordAction(ctx, domain.AuditOrgCreate, nil, err, map[string]any{
+		"organization_id": org.ID.String(),
+		"name":            org.Name,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return org, nil
+}
+
+// List organizaciones del usuario con su rol en cada una
+func (s *OrganizationService) List(ctx context.Context, userID uuid.UUID) ([]*domain.Organization, error) {
+	return s.orgRepo.ListByUser(ctx, userID)
+}
+
+// Get detalle de una organización de la que el usuario es miembro
+func (s *OrganizationService) Get(ctx context.Context, userID, orgID uuid.UUID) (*domain.Organization, error) {
+	member, err := s.requireRole(ctx, userID, orgID, domain.OrgRoleViewer)
+	if err != nil {
+		return nil, err
+	}
+	org, err := s.orgRepo.GetByID(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	org.Role = member.Role
+	return org, nil
+}
+
+// Update renombra la organización (solo owners)
+func (s *OrganizationService) Update(ctx context.Context, userID, orgID uuid.UUID, req *domain.UpdateOrganizationRequest) (*domain.Organization, error) {
+	org, err := s.update(ctx, userID, orgID, req)
+	s.audit.RecordAction(ctx, domain.AuditOrgUpdate, nil, err, map[string]any{
+		"organization_id": orgID.String(),
+		"name":            req.Name,
+	})
+	return org, err
+}
+
+func (s *OrganizationService) update(ctx context.Context, userID, orgID uuid.UUID, req *domain.UpdateOrganizationRequest) (*domain.Organization, error) {
+	if _, err := s.requireRole(ctx, userID, orgID, domain.OrgRoleOwner); err != nil {
+		return nil, err
+	}
+	org, err := s.orgRepo.GetByID(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	org.Name = strings.TrimSpace(req.Name)
+	if err := s.orgRepo.Update(ctx, org); err != nil {
+		return nil, err
+	}
+	org.Role = domain.OrgRoleOwner
+	org.UpdatedAt = time.Now()
+	return org, nil
+}
+
+// Delete elimina la organización (solo owners). Sus sesiones vuelven a ser
+// personales de su responsable.
+func (s *OrganizationService) Delete(ctx context.Context, userID, orgID uuid.UUID) error {
+	err := s.delete(ctx, userID, orgID)
+	s.audit.RecordAction(ctx, domain.AuditOrgDelete, nil, err, map[string]any{
+		"organization_id": orgID.String(),
+	})
+	return err
+}
+
+func (s *OrganizationService) delete(ctx context.Context, userID, orgID uuid.UUID) error {
+	if _, err := s.requireRole(ctx, userID, orgID, domain.OrgRoleOwner); err != nil {
+		return err
+	}
+	return s.orgRepo.Delete(ctx, orgID)
+}
+
+// ==================== MIEMBROS ====================
+
+// ListMembers miembros de la organización (cualquier miembro puede verlos)
+func (s *OrganizationService) ListMembers(ctx context.Context, userID, orgID uuid.UUID) ([]*domain.OrganizationMember, error) {
+	if _, err := s.requireRole(ctx, userID, orgID, domain.OrgRoleViewer); err != nil {
+		return nil, err
+	}
+	return s.orgRepo.ListMembers(ctx, orgID)
+}
+
+// AddMember añade un usuario existente, buscado por username o email (solo owners)
+func (s *OrganizationService) AddMember(ctx context.Context, userID, orgID uuid.UUID, req *domain.AddMemberRequest) (*domain.OrganizationMember, error) {
+	member, err := s.addMember(ctx, userID, orgID, req)
+
+	details := map[string]any{
+		"organization_id": orgID.String(),
+		"role":            string(req.Role),
+	}
+	if member != nil {
+		details["user_id"] = member.UserID.String()
+		details["username"] = member.Username
+	}
+	s.audit.RecordAction(ctx, domain.AuditOrgMemberAdd, nil, err, details)
+	return member, err
+}
+
+func (s *OrganizationService) addMember(ctx context.Context, userID, orgID uuid.UUID, req *domain.AddMemberRequest) (*domain.OrganizationMember, error) {
+	if _, err := s.requireRole(ctx, userID, orgID, domain.OrgRoleOwner); err != nil {
+		return nil, err
+	}
+
+	var user *domain.User
+	var err error
+	switch {
+	case req.Username != "":
+		user, err = s.userRepo.GetByUsername(ctx, req.Username)
+	case req.Email != "":
+		user, err = s.userRepo.GetByEmail(ctx, req.Email)
+	default:
+		return nil, domain.ErrInvalidInput
+	}
+	if err != nil || user == nil {
+		return nil, domain.ErrUserNotFound
+	}
+
+	member := &domain.OrganizationMember{
+		OrganizationID: orgID,
+		UserID:         user.ID,
+		Username:       user.Username,
+		Email:          user.Email,
+		Role:           req.Role,
+		CreatedAt:      time.Now(),
+	}
+	if err := s.orgRepo.AddMember(ctx, member); err != nil {
+		return nil, err
+	}
+	return member, nil
+}
+
+// UpdateMemberRole cambia el rol de un miembro (solo owners); la
+// organización no puede quedarse sin owners
+func (s *OrganizationService) UpdateMemberRole(ctx context.Context, userID, orgID, memberID uuid.UUID, role domain.OrgRole) (*domain.OrganizationMember, error) {
+	member, err := s.updateMemberRole(ctx, userID, orgID, memberID, role)
+	s.audit.RecordAction(ctx, domain.AuditOrgMemberRole, nil, err, map[string]any{
+		"organization_id": orgID.String(),
+		"user_id":         memberID.String(),
+		"role":            string(role),
+	})
+	return member, err
+}
+
+func (s *OrganizationService) updateMemberRole(ctx context.Context, userID, orgID, memberID uuid.UUID, role domain.OrgRole) (*domain.OrganizationMember, error) {
+	if _, err := s.requireRole(ctx, userID, orgID, domain.OrgRoleOwner); err != nil {
+		return nil, err
+	}
+	if err := s.orgRepo.UpdateMemberRole(ctx, orgID, memberID, role); err != nil {
+		return nil, err
+	}
+	return s.orgRepo.GetMember(ctx, orgID, memberID)
+}
+
+// RemoveMember saca a un miembro. Los owners pueden sacar a cualquiera;
+// cualquier miembro puede salir por sí mismo. El último owner no puede irse.
+func (s *OrganizationService) RemoveMember(ctx context.Context, userID, orgID, memberID uuid.UUID) error {
+	err := s.removeMember(ctx, userID, orgID, memberID)
+	s.audit.RecordAction(ctx, domain.AuditOrgMemberRemove, nil, err, map[string]any{
+		"organization_id": orgID.String(),
+		"user_id":         memberID.String(),
+	})
+	return err
+}
+
+func (s *OrganizationService) removeMember(ctx context.Context, userID, orgID, memberID uuid.UUID) error {
+	need := domain.OrgRoleOwner
+	if memberID == userID {
+		need = domain.OrgRoleViewer
+	}
+	if _, err := s.requireRole(ctx, userID, orgID, need); err != nil {
+		return err
+	}
+	return s.orgRepo.RemoveMember(ctx, orgID, memberID)
+}
+
+// requireRole pertenencia del usuario con al menos el rol need. A quien no es
+// miembro se le responde como si la organización no existiera.
+func (s *OrganizationService) requireRole(ctx context.Context, userID, orgID uuid.UUID, need domain.OrgRole) (*domain.OrganizationMember, error) {
+	member, err := s.orgRepo.GetMember(ctx, orgID, userID)
+	if errors.Is(err, domain.ErrMemberNotFound) {
+		return nil, domain.ErrOrganizationNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !member.Role.Allows(need) {
+		return nil, domain.ErrInsufficientOrgRole
+	}
+	return member, nil
+}
@@ -0,0 +1,294 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"telegram-api/internal/config"
+	"telegram-api/internal/domain"
+	"telegram-api/pkg/logger"
+
+	"github.com/google/uuid"
+)
+
+// bytesPerMB conversión de QUOTA_MONTHLY_MEDIA_MB
+const bytesPerMB = 1 << 20
+
+// QuotaService aplica las cuotas de cada usuario: sesiones, envíos diarios y
+// mensuales, destinatarios por envío masivo y volumen mensual de media. Los
+// contadores viven en Postgres para que sobrevivan a un reinicio de Redis.
+type QuotaService struct {
+	repo        domain.QuotaRepository
+	sessionRepo domain.SessionRepository
+	userRepo    domain.UserRepository
+	audit       *AuditService
+	config      *config.Config
+}
+
+// NewQuotaService crea el servicio de cuotas
+func NewQuotaService(repo domain.QuotaRepository, sessionRepo domain.SessionRepository, userRepo domain.UserRepository, audit *AuditService, cfg *config.Config) *QuotaService {
+	return &QuotaService{
+		repo:        repo,
+		sessionRepo: sessionRepo,
+		userRepo:    userRepo,
+		audit:       audit,
+		config:      cfg,
+	}
+}
+
+// defaults cuotas globales de la configuración
+func (s *QuotaService) defaults() domain.QuotaLimits {
+	q := s.config.Quota
+	return domain.QuotaLimits{
+		MaxSessions:    q.MaxSessions,
+		DailySends:     q.DailySends,
+		MonthlySends:   q.MonthlySends,
+		BulkRecipients: q.BulkRecipients,
+		MonthlyMediaMB: q.MonthlyMediaMB,
+	}
+}
+
+// Limits cuotas efectivas del usuario: las globales con sus overrides
+func (s *QuotaService) Limits(ctx context.Context, userID uuid.UUID) (domain.QuotaLimits, *domain.QuotaOverrides, error) {
+	o, err := s.repo.GetOverrides(ctx, userID)
+	if err != nil {
+		return domain.QuotaLimits{}, nil, err
+	}
+	return o.Apply(s.defaults()), o, nil
+}
+
+// periods inicio del día y del mes actuales en UTC
+func periods(now time.Time) (day, month time.Time) {
+	now = now.UTC()
+	day = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	month = time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	return day, month
+}
+
+// CheckSessions falla con ErrSessionQuotaExceeded si el usuario ya es
+// responsable del máximo de sesiones
+func (s *QuotaService) CheckSessions(ctx context.Context, userID uuid.UUID) error {
+	limits, _, err := s.Limits(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if limits.MaxSessions == 0 {
+		return nil
+	}
+
+	count, err := s.sessionRepo.CountByUserID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if count >= limits.MaxSessions {
+		return domain.ErrSessionQuotaExceeded
+	}
+	return nil
+}
+
+// CreateSession guarda una sesión nueva respetando MaxSessions de forma
+// atómica. CheckSessions solo adelanta el rechazo antes de hablar con Telegram;
+// el límite lo garantiza esta llamada aunque haya altas concurrentes.
+func (s *QuotaService) CreateSession(ctx context.Context, session *domain.TelegramSession) error {
+	limits, _, err := s.Limits(ctx, session.UserID)
+	if err != nil {
+		return err
+	}
+	return s.sessionRepo.CreateWithinLimit(ctx, session, limits.MaxSessions)
+}
+
+// ConsumeSends imputa n envíos al usuario en los contadores diario y mensual;
+// si alguno se agotaría no imputa nada
+func (s *QuotaService) ConsumeSends(ctx context.Context, userID uuid.UUID, n int) error {
+	limits, _, err := s.Limits(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	day, month := periods(time.Now())
+	exceeded, err := s.repo.Consume(ctx, userID, []domain.UsageCharge{
+		{Metric: domain.UsageSendsDaily, Period: day, Amount: int64(n), Limit: int64(limits.DailySends)},
+		{Metric: domain.UsageSendsMonthly, Period: month, Amount: int64(n), Limit: int64(limits.MonthlySends)},
+	})
+	if err != nil {
+		return err
+	}
+	return quotaError(exceeded)
+}
+
+// RefundSends devuelve n envíos imputados por ConsumeSends en chargedAt, cuando
+// el mensaje finalmente no se pudo enviar
+func (s *QuotaService) RefundSends(ctx context.Context, userID uuid.UUID, n int, chargedAt time.Time) error {
+	day, month := periods(chargedAt)
+	return s.repo.Refund(ctx, userID, []domain.UsageCharge{
+		{Metric: domain.UsageSendsDaily, Period: day, Amount: int64(n)},
+		{Metric: domain.UsageSendsMonthly, Period: month, Amount: int64(n)},
+	})
+}
+
+// CheckBulk valida un envío masivo de n destinatarios: el máximo por petición
+// y que queden envíos suficientes para todos
+func (s *QuotaService) CheckBulk(ctx context.Context, userID uuid.UUID, n int) error {
+	limits, _, err := s.Limits(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if limits.BulkRecipients > 0 && n > limits.BulkRecipients {
+		return domain.ErrBulkQuotaExceeded
+	}
+
+	day, month := periods(time.Now())
+	if limits.DailySends > 0 {
+		used, err := s.repo.Usage(ctx, userID, domain.UsageSendsDaily, day)
+		if err != nil {
+			return err
+		}
+		if used+int64(n) > int64(limits.DailySends) {
+			return domain.ErrDailySendQuotaExceeded
+		}
+	}
+	if limits.MonthlySends > 0 {
+		used, err := s.repo.Usage(ctx, userID, domain.UsageSendsMonthly, month)
+		if err != nil {
+			return err
+		}
+		if used+int64(n) > int64(limits.MonthlySends) {
+			return domain.ErrMonthlySendQuotaExceeded
+		}
+	}
+	return nil
+}
+
+// MediaAllowance bytes de media que el usuario aún puede enviar este mes
+// (0 = sin límite). Falla con ErrMediaQuotaExceeded si ya no le queda nada.
+func (s *QuotaService) MediaAllowance(ctx context.Context, userID uuid.UUID) (int64, error) {
+	limits, _, err := s.Limits(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+	if limits.MonthlyMediaMB == 0 {
+		return 0, nil
+	}
+
+	_, month := periods(time.Now())
+	used, err := s.repo.Usage(ctx, userID, domain.UsageMediaBytesMonthly, month)
+	if err != nil {
+		return 0, err
+	}
+	remaining := int64(limits.MonthlyMediaMB)*bytesPerMB - used
+	if remaining <= 0 {
+		return 0, domain.ErrMediaQuotaExceeded
+	}
+	return remaining, nil
+}
+
+// RecordMedia imputa los bytes de media descargados para un envío. No aplica
+// el límite: el sender ya lo respetó con MediaAllowance.
+func (s *QuotaService) RecordMedia(ctx context.Context, userID uuid.UUID, bytes int64) error {
+	if bytes <= 0 {
+		return nil
+	}
+	_, month := periods(time.Now())
+	_, err := s.repo.Consume(ctx, userID, []domain.UsageCharge{
+		{Metric: domain.UsageMediaBytesMonthly, Period: month, Amount: bytes},
+	})
+	return err
+}
+
+// quotaError traduce la métrica agotada a su error de dominio
+func quotaError(metric domain.UsageMetric) error {
+	switch metric {
+	case "":
+		return nil
+	case domain.UsageSendsDaily:
+		return domain.ErrDailySendQuotaExceeded
+	case domain.UsageSendsMonthly:
+		return domain.ErrMonthlySendQuotaExceeded
+	case domain.UsageMediaBytesMonthly:
+		return domain.ErrMediaQuotaExceeded
+	default:
+		return domain.ErrQuotaExceeded
+	}
+}
+
+// Usage consumo del usuario en los periodos actuales junto a sus límites
+func (s *QuotaService) Usage(ctx context.Context, userID uuid.UUID) (*domain.QuotaUsage, error) {
+	limits, overrides, err := s.Limits(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	sessions, err := s.sessionRepo.CountByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	day, month := periods(time.Now())
+	nextDay := day.AddDate(0, 0, 1)
+	nextMonth := month.AddDate(0, 1, 0)
+
+	counter := func(metric domain.UsageMetric, period time.Time, limit int64, resets *time.Time) (domain.UsageCounter, error) {
+		used, err := s.repo.Usage(ctx, userID, metric, period)
+		return domain.UsageCounter{Used: used, Limit: limit, ResetsAt: resets}, err
+	}
+
+	usage := &domain.QuotaUsage{
+		Limits:     limits,
+		Overridden: overrides != nil,
+		Sessions:   domain.UsageCounter{Used: int64(sessions), Limit: int64(limits.MaxSessions)},
+	}
+	if usage.DailySends, err = counter(domain.UsageSendsDaily, day, int64(limits.DailySends), &nextDay); err != nil {
+		return nil, err
+	}
+	if usage.MonthlySends, err = counter(domain.UsageSendsMonthly, month, int64(limits.MonthlySends), &nextMonth); err != nil {
+		return nil, err
+	}
+	if usage.MonthlyMediaBytes, err = counter(domain.UsageMediaBytesMonthly, month, int64(limits.MonthlyMediaMB)*bytesPerMB, &nextMonth); err != nil {
+		return nil, err
+	}
+	return usage, nil
+}
+
+// SetOverrides fija las cuotas propias de un usuario (nil restablece las
+// globales) y retorna su consumo con los nuevos límites
+func (s *QuotaService) SetOverrides(ctx context.Context, userID uuid.UUID, o *domain.QuotaOverrides) (*domain.QuotaUsage, error) {
+	usage, err := s.setOverrides(ctx, userID, o)
+	s.audit.RecordAction(ctx, domain.AuditAdminUserQuotas, nil, err, map[string]any{
+		"user_id": userID.String(),
+		"quotas":  o,
+	})
+	return usage, err
+}
+
+func (s *QuotaService) setOverrides(ctx context.Context, userID uuid.UUID, o *domain.QuotaOverrides) (*domain.QuotaUsage, error) {
+	if _, err := s.userRepo.GetByID(ctx, userID); err != nil {
+		return nil, err
+	}
+	if err := s.repo.SetOverrides(ctx, userID, o); err != nil {
+		return nil, err
+	}
+	return s.Usage(ctx, userID)
+}
+
+// RunRetention purga periódicamente los contadores de meses anteriores al
+// actual hasta que se cancele ctx
+func (s *QuotaService) RunRetention(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			_, month := periods(time.Now())
+			deleted, err := s.repo.DeleteBefore(ctx, month)
+			if err != nil {
+				logger.Error().Err(err).Msg("Error purgando contadores de consumo")
+				continue
+			}
+			if deleted > 0 {
+				logger.Info().Int64("deleted", deleted).Msg("🧹 Contadores de consumo antiguos eliminados")
+			}
+		}
+	}
+}
@@ -2,37 +2,54 @@ package service
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"telegram-api/internal/config"
 	"telegram-api/internal/domain"
 	"telegram-api/internal/telegram"
+	"telegram-api/pkg/crypto"
 	"telegram-api/pkg/logger"
 
 	"github.com/google/uuid"
+	"github.com/gotd/td/tgerr"
 )
 
 type SessionService struct {
 	sessionRepo domain.SessionRepository
 	userRepo    domain.UserRepository
+	webhookRepo domain.WebhookRepository
 	tgManager   *telegram.ClientManager
 	cache       domain.CacheRepository
+	orgs        *OrganizationService
+	quotas      *QuotaService
+	audit       *AuditService
 	config      *config.Config
 }
 
 func NewSessionService(
 	sRepo domain.SessionRepository,
 	uRepo domain.UserRepository,
+	whRepo domain.WebhookRepository,
 	tgMgr *telegram.ClientManager,
 	cache domain.CacheRepository,
+	orgs *OrganizationService,
+	quotas *QuotaService,
+	audit *AuditService,
 	cfg *config.Config,
 ) *SessionService {
 	return &SessionService{
 		sessionRepo: sRepo,
 		userRepo:    uRepo,
+		webhookRepo: whRepo,
 		tgManager:   tgMgr,
 		cache:       cache,
+		orgs:        orgs,
+		quotas:      quotas,
+		audit:       audit,
 		config:      cfg,
 	}
 }
@@ -51,10 +68,35 @@ func (s *SessionService) CreateSession(ctx context.Context, userID uuid.UUID, re
 		Str("session_name", req.SessionName).
 		Msg("🔄 CreateSession iniciado")
 
-	if req.AuthMethod == domain.AuthMethodQR {
-		return s.createSessionQR(ctx, userID, req)
+	var (
+		session *domain.TelegramSession
+		qr      string
+	)
+	err := s.quotas.CheckSessions(ctx, userID)
+	if err == nil {
+		switch req.AuthMethod {
+		case domain.AuthMethodQR:
+			session, qr, err = s.createSessionQR(ctx, userID, req)
+		case domain.AuthMethodBot:
+			session, qr, err = s.createSessionBot(ctx, userID, req)
+		default:
+			session, qr, err = s.createSessionSMS(ctx, userID, req)
+		}
+	}
+
+	s.audit.RecordAction(ctx, domain.AuditSessionCreate, sessionIDOf(session), err, map[string]any{
+		"auth_method":  string(req.AuthMethod),
+		"session_name": req.SessionName,
+	})
+	return session, qr, err
+}
+
+// sessionIDOf retorna el ID para auditoría (nil si no llegó a crearse)
+func sessionIDOf(session *domain.TelegramSession) *uuid.UUID {
+	if session == nil {
+		return nil
 	}
-	return s.createSessionSMS(ctx, userID, req)
+	return &session.ID
 }
 
 // ==================== SMS AUTH ====================
@@ -114,7 +156,10 @@ func (s *SessionService) createSessionSMS(ctx context.Context, userID uuid.UUID,
 		Str("session_name", session.SessionName).
 		Msg("💾 Guardando sesión en DB...")
 
-	if err := s.sessionRepo.Create(ctx, session); err != nil {
+	if err := s.quotas.CreateSession(ctx, session); err != nil {
+		if errors.Is(err, domain.ErrQuotaExceeded) {
+			return nil, "", err
+		}
 		logger.Error().
 			Err(err).
 			Str("session_id", session.ID.String()).
@@ -135,6 +180,394 @@ func (s *SessionService) createSessionSMS(ctx context.Context, userID uuid.UUID,
 	return session, phoneCodeHash, nil
 }
 
+// ==================== BOT AUTH ====================
+
+// createSessionBot autentica con un token de BotFather. A diferencia de SMS/QR
+// el login es inmediato, por lo que la sesión se crea ya autenticada.
+func (s *SessionService) createSessionBot(ctx context.Context, userID uuid.UUID, req *domain.CreateSessionRequest) (*domain.TelegramSession, string, error) {
+	botID, ok := botIDFromToken(req.BotToken)
+	if !ok {
+		logger.Warn().Msg("⚠️ Token de bot con formato inválido")
+		return nil, "", domain.ErrInvalidBotToken
+	}
+
+	// El ID del bot ocupa el lugar del teléfono (único por usuario)
+	phone := "BOT-" + botID
+	var stale *domain.TelegramSession
+	if existing, err := s.sessionRepo.GetByUserAndPhone(ctx, userID, phone); err == nil {
+		stale = existing
+	}
+	if stale != nil && stale.IsActive {
+		logger.Warn().
+			Str("bot_id", botID).
+			Str("existing_id", stale.ID.String()).
+			Msg("⚠️ Ya existe sesión activa para este bot")
+		return nil, "", domain.ErrSessionAlreadyExists
+	}
+
+	apiHashEncrypted, err := s.tgManager.Encrypt([]byte(req.ApiHash))
+	if err != nil {
+		logger.Error().Err(err).Msg("❌ Error cifrando api_hash en bot auth")
+		return nil, "", domain.ErrInternal
+	}
+
+	logger.Debug().Str("bot_id", botID).Msg("🤖 Importando autorización de bot...")
+	user, sessionData, err := s.tgManager.BotSignIn(ctx, req.ApiID, req.ApiHash, req.BotToken)
+	if err != nil {
+		logger.Error().Err(err).Str("bot_id", botID).Msg("❌ Error importando autorización de bot")
+		if tgerr.Is(err, "ACCESS_TOKEN_INVALID", "ACCESS_TOKEN_EXPIRED") {
+			return nil, "", domain.ErrInvalidBotToken
+		}
+		return nil, "", mapTelegramError(err)
+	}
+
+	encryptedSessionData, err := s.tgManager.Encrypt(sessionData)
+	if err != nil {
+		logger.Error().Err(err).Msg("❌ Error cifrando sesión de bot")
+		return nil, "", domain.ErrInternal
+	}
+
+	// Un registro previo inactivo del mismo bot se reemplaza por el nuevo
+	if stale != nil {
+		_ = s.sessionRepo.Delete(ctx, stale.ID)
+	}
+
+	session := &domain.TelegramSession{
+		ID:               uuid.New(),
+		UserID:           userID,
+		PhoneNumber:      phone,
+		ApiID:            req.ApiID,
+		ApiHashEncrypted: apiHashEncrypted,
+		SessionName:      defaultSessionName(req.SessionName, "@"+user.Username),
+		SessionData:      encryptedSessionData,
+		AuthState:        domain.SessionAuthenticated,
+		TelegramUserID:   user.ID,
+		TelegramUsername: user.Username,
+		IsBot:            true,
+		IsActive:         true,
+		CreatedAt:        time.Now(),
+		UpdatedAt:        time.Now(),
+	}
+
+	if err := s.quotas.CreateSession(ctx, session); err != nil {
+		if errors.Is(err, domain.ErrQuotaExceeded) {
+			return nil, "", err
+		}
+		logger.Error().Err(err).Str("session_id", session.ID.String()).Msg("❌ Error guardando sesión de bot")
+		return nil, "", domain.ErrDatabase
+	}
+
+	logger.Info().
+		Str("session_id", session.ID.String()).
+		Int64("bot_id", user.ID).
+		Str("bot_username", user.Username).
+		Msg("🤖 Sesión de bot autenticada")
+
+	return session, "", nil
+}
+
+// botIDFromToken valida el formato <bot_id>:<secret> y retorna el ID del bot
+func botIDFromToken(token string) (string, bool) {
+	id, secret, ok := strings.Cut(token, ":")
+	if !ok || id == "" || len(secret) < 30 {
+		return "", false
+	}
+	for _, r := range id {
+		if r < '0' || r > '9' {
+			return "", false
+		}
+	}
+	return id, true
+}
+
+// ==================== IMPORT ====================
+
+// ImportSession convierte una sesión de otra librería al formato gotd, la valida
+// con users.getSelf y la guarda cifrada como una sesión autenticada
+func (s *SessionService) ImportSession(ctx context.Context, userID uuid.UUID, req *domain.ImportSessionRequest, raw []byte) (*domain.TelegramSession, error) {
+	session, err := s.importSession(ctx, userID, req, raw)
+	s.audit.RecordAction(ctx, domain.AuditSessionImport, sessionIDOf(session), err, map[string]any{
+		"format": string(req.Format),
+	})
+	return session, err
+}
+
+func (s *SessionService) importSession(ctx context.Context, userID uuid.UUID, req *domain.ImportSessionRequest, raw []byte) (*domain.TelegramSession, error) {
+	logger.Debug().
+		Str("user_id", userID.String()).
+		Str("format", string(req.Format)).
+		Int("size", len(raw)).
+		Msg("📥 Importando sesión...")
+
+	if err := s.quotas.CheckSessions(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	sessionData, err := telegram.DecodeSession(ctx, req.Format, raw, req.Passcode)
+	if err != nil {
+		logger.Warn().Err(err).Str("format", string(req.Format)).Msg("⚠️ Sesión importada inválida")
+		return nil, err
+	}
+
+	user, sessionData, err := s.tgManager.ValidateSession(ctx, req.ApiID, req.ApiHash, sessionData)
+	if err != nil {
+		logger.Warn().Err(err).Str("format", string(req.Format)).Msg("⚠️ Validación de sesión importada fallida")
+		return nil, validationError(err)
+	}
+
+	session, err := s.storeValidated(ctx, userID, req.ApiID, req.ApiHash, req.SessionName, user, sessionData)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Info().
+		Str("session_id", session.ID.String()).
+		Str("format", string(req.Format)).
+		Int64("tg_user_id", user.ID).
+		Str("tg_username", user.Username).
+		Msg("📥 Sesión importada")
+
+	return session, nil
+}
+
+// validationError distingue una sesión revocada de otros errores de Telegram
+func validationError(err error) error {
+	if tgerr.Is(err, "AUTH_KEY_UNREGISTERED", "AUTH_KEY_INVALID", "SESSION_REVOKED", "SESSION_EXPIRED", "USER_DEACTIVATED") {
+		return domain.ErrSessionRevoked
+	}
+	return mapTelegramError(err)
+}
+
+// storeValidated guarda como autenticada una sesión ya validada contra Telegram,
+// cifrando api_hash y session_data con la clave local. Un registro inactivo de la
+// misma cuenta se reemplaza; uno activo produce ErrSessionAlreadyExists.
+func (s *SessionService) storeValidated(ctx context.Context, userID uuid.UUID, apiID int, apiHash, sessionName string, user *telegram.TGUser, sessionData []byte) (*domain.TelegramSession, error) {
+	// Mismas convenciones que SMS (+phone), bot (BOT-id) y QR (TG-id)
+	phone := fmt.Sprintf("TG-%d", user.ID)
+	switch {
+	case user.Bot:
+		phone = fmt.Sprintf("BOT-%d", user.ID)
+	case user.Phone != "":
+		phone = "+" + user.Phone
+	}
+
+	var stale *domain.TelegramSession
+	if existing, err := s.sessionRepo.GetByUserAndPhone(ctx, userID, phone); err == nil {
+		stale = existing
+	}
+	if stale != nil && stale.IsActive {
+		logger.Warn().
+			Str("phone", phone).
+			Str("existing_id", stale.ID.String()).
+			Msg("⚠️ Ya existe sesión activa para esta cuenta")
+		return nil, domain.ErrSessionAlreadyExists
+	}
+
+	apiHashEncrypted, err := s.tgManager.Encrypt([]byte(apiHash))
+	if err != nil {
+		logger.Error().Err(err).Msg("❌ Error cifrando api_hash en import")
+		return nil, domain.ErrInternal
+	}
+	encryptedSessionData, err := s.tgManager.Encrypt(sessionData)
+	if err != nil {
+		logger.Error().Err(err).Msg("❌ Error cifrando sesión importada")
+		return nil, domain.ErrInternal
+	}
+
+	if stale != nil {
+		_ = s.sessionRepo.Delete(ctx, stale.ID)
+	}
+
+	session := &domain.TelegramSession{
+		ID:               uuid.New(),
+		UserID:           userID,
+		PhoneNumber:      phone,
+		ApiID:            apiID,
+		ApiHashEncrypted: apiHashEncrypted,
+		SessionName:      defaultSessionName(sessionName, phone),
+		SessionData:      encryptedSessionData,
+		AuthState:        domain.SessionAuthenticated,
+		TelegramUserID:   user.ID,
+		TelegramUsername: user.Username,
+		IsBot:            user.Bot,
+		IsActive:         true,
+		CreatedAt:        time.Now(),
+		UpdatedAt:        time.Now(),
+	}
+
+	if err := s.quotas.CreateSession(ctx, session); err != nil {
+		if errors.Is(err, domain.ErrQuotaExceeded) {
+			return nil, err
+		}
+		logger.Error().Err(err).Str("session_id", session.ID.String()).Msg("❌ Error guardando sesión importada")
+		return nil, domain.ErrDatabase
+	}
+
+	return session, nil
+}
+
+// ==================== EXPORT / BUNDLE ====================
+
+// ExportSession genera un bundle cifrado con passphrase con todo lo necesario
+// para restaurar la sesión en otro despliegue (sin depender de ENCRYPTION_KEY).
+// Cada intento de exportación queda registrado en la auditoría.
+func (s *SessionService) ExportSession(ctx context.Context, userID, sessionID uuid.UUID, passphrase string) ([]byte, *domain.TelegramSession, error) {
+	sealed, session, err := s.exportSession(ctx, userID, sessionID, passphrase)
+
+	details := map[string]any{}
+	if session != nil {
+		details["tg_user_id"] = session.TelegramUserID
+	}
+	s.audit.RecordAction(ctx, domain.AuditSessionExport, &sessionID, err, details)
+
+	if err == nil {
+		logger.Info().
+			Str("actor_user_id", userID.String()).
+			Str("session_id", sessionID.String()).
+			Msg("🔐 Sesión exportada")
+	}
+	return sealed, session, err
+}
+
+func (s *SessionService) exportSession(ctx context.Context, userID, sessionID uuid.UUID, passphrase string) ([]byte, *domain.TelegramSession, error) {
+	session, err := s.sessionRepo.GetByID(ctx, sessionID)
+	if err != nil {
+		return nil, nil, domain.ErrSessionNotFound
+	}
+
+	if err := s.orgs.CheckSessionAccess(ctx, userID, session, domain.OrgRoleOwner); err != nil {
+		return nil, nil, err
+	}
+	if !session.IsActive || len(session.SessionData) == 0 {
+		return nil, session, domain.ErrSessionInactive
+	}
+
+	apiHash, err := s.tgManager.Decrypt(session.ApiHashEncrypted)
+	if err != nil {
+		logger.Error().Err(err).Str("session_id", sessionID.String()).Msg("❌ Error descifrando api_hash en export")
+		return nil, nil, domain.ErrInternal
+	}
+	sessionData, err := s.tgManager.Decrypt(session.SessionData)
+	if err != nil {
+		logger.Error().Err(err).Str("session_id", sessionID.String()).Msg("❌ Error descifrando sesión en export")
+		return nil, nil, domain.ErrInternal
+	}
+
+	bundle := domain.SessionBundle{
+		Version:     domain.SessionBundleVersion,
+		ExportedAt:  time.Now().UTC(),
+		ApiID:       session.ApiID,
+		ApiHash:     string(apiHash),
+		SessionData: sessionData,
+		Metadata: domain.SessionBundleMeta{
+			SessionName:      session.SessionName,
+			PhoneNumber:      session.PhoneNumber,
+			TelegramUserID:   session.TelegramUserID,
+			TelegramUsername: session.TelegramUsername,
+			IsBot:            session.IsBot,
+			CreatedAt:        session.CreatedAt,
+		},
+	}
+
+	wh, err := s.webhookRepo.GetBySessionID(ctx, sessionID)
+	if err != nil {
+		logger.Warn().Err(err).Str("session_id", sessionID.String()).Msg("⚠️ Error leyendo webhook para export")
+	}
+	if wh != nil {
+		bundle.Webhook = &domain.WebhookBundleConf{
+			URL:        wh.URL,
+			Secret:     wh.Secret,
+			Events:     wh.Events,
+			IsActive:   wh.IsActive,
+			MaxRetries: wh.MaxRetries,
+			TimeoutMs:  wh.TimeoutMs,
+		}
+	}
+
+	plain, err := json.Marshal(bundle)
+	if err != nil {
+		return nil, nil, domain.ErrInternal
+	}
+	sealed, err := crypto.SealWithPassphrase(plain, passphrase)
+	if err != nil {
+		logger.Error().Err(err).Str("session_id", sessionID.String()).Msg("❌ Error cifrando bundle")
+		return nil, nil, domain.ErrInternal
+	}
+
+	return sealed, session, nil
+}
+
+// ImportBundle restaura un bundle de ExportSession: lo descifra con la passphrase,
+// valida la sesión con users.getSelf, la re-cifra con la clave local y recrea el webhook
+func (s *SessionService) ImportBundle(ctx context.Context, userID uuid.UUID, raw []byte, passphrase, sessionName string) (*domain.TelegramSession, error) {
+	session, err := s.importBundle(ctx, userID, raw, passphrase, sessionName)
+	s.audit.RecordAction(ctx, domain.AuditSessionImport, sessionIDOf(session), err, map[string]any{
+		"format": "bundle",
+	})
+	return session, err
+}
+
+func (s *SessionService) importBundle(ctx context.Context, userID uuid.UUID, raw []byte, passphrase, sessionName string) (*domain.TelegramSession, error) {
+	if err := s.quotas.CheckSessions(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	plain, err := crypto.OpenWithPassphrase(raw, passphrase)
+	if err != nil {
+		logger.Warn().Err(err).Str("user_id", userID.String()).Msg("⚠️ Bundle inválido")
+		return nil, fmt.Errorf("%w: %v", domain.ErrInvalidSessionData, err)
+	}
+
+	var bundle domain.SessionBundle
+	if err := json.Unmarshal(plain, &bundle); err != nil {
+		return nil, fmt.Errorf("%w: contenido del bundle ilegible", domain.ErrInvalidSessionData)
+	}
+	if bundle.Version != domain.SessionBundleVersion {
+		return nil, fmt.Errorf("%w: versión de bundle no soportada: %d", domain.ErrInvalidSessionData, bundle.Version)
+	}
+
+	user, sessionData, err := s.tgManager.ValidateSession(ctx, bundle.ApiID, bundle.ApiHash, bundle.SessionData)
+	if err != nil {
+		logger.Warn().Err(err).Str("user_id", userID.String()).Msg("⚠️ Validación de bundle fallida")
+		return nil, validationError(err)
+	}
+
+	if sessionName == "" {
+		sessionName = bundle.Metadata.SessionName
+	}
+	session, err := s.storeValidated(ctx, userID, bundle.ApiID, bundle.ApiHash, sessionName, user, sessionData)
+	if err != nil {
+		return nil, err
+	}
+
+	if wh := bundle.Webhook; wh != nil {
+		err := s.webhookRepo.Create(ctx, &domain.WebhookConfig{
+			ID:         uuid.New(),
+			SessionID:  session.ID,
+			URL:        wh.URL,
+			Secret:     wh.Secret,
+			Events:     wh.Events,
+			IsActive:   wh.IsActive,
+			MaxRetries: wh.MaxRetries,
+			TimeoutMs:  wh.TimeoutMs,
+			CreatedAt:  time.Now(),
+			UpdatedAt:  time.Now(),
+		})
+		if err != nil {
+			logger.Warn().Err(err).Str("session_id", session.ID.String()).Msg("⚠️ Error restaurando webhook del bundle")
+		}
+	}
+
+	logger.Info().
+		Str("session_id", session.ID.String()).
+		Int64("tg_user_id", user.ID).
+		Bool("webhook", bundle.Webhook != nil).
+		Time("exported_at", bundle.ExportedAt).
+		Msg("📥 Bundle de sesión restaurado")
+
+	return session, nil
+}
+
 // ==================== QR AUTH ====================
 
 func (s *SessionService) createSessionQR(ctx context.Context, userID uuid.UUID, req *domain.CreateSessionRequest) (*domain.TelegramSession, string, error) {
@@ -178,7 +611,10 @@ func (s *SessionService) createSessionQR(ctx context.Context, userID uuid.UUID,
 		Str("user_id", userID.String()).
 		Msg("💾 Guardando sesión QR en DB...")
 
-	if err := s.sessionRepo.Create(ctx, session); err != nil {
+	if err := s.quotas.CreateSession(ctx, session); err != nil {
+		if errors.Is(err, domain.ErrQuotaExceeded) {
+			return nil, "", err
+		}
 		logger.Error().
 			Err(err).
 			Str("session_id", session.ID.String()).
@@ -273,10 +709,16 @@ func (s *SessionService) handleQRResult(sessionID uuid.UUID, resultChan <-chan t
 
 // ==================== VERIFY SMS CODE ====================
 
-func (s *SessionService) VerifyCode(ctx context.Context, sessionID uuid.UUID, code string) (*domain.TelegramSession, error) {
-	session, err := s.sessionRepo.GetByID(ctx, sessionID)
+func (s *SessionService) VerifyCode(ctx context.Context, userID, sessionID uuid.UUID, code string) (*domain.TelegramSession, error) {
+	session, err := s.verifyCode(ctx, userID, sessionID, code)
+	s.audit.RecordAction(ctx, domain.AuditSessionVerify, &sessionID, err, nil)
+	return session, err
+}
+
+func (s *SessionService) verifyCode(ctx context.Context, userID, sessionID uuid.UUID, code string) (*domain.TelegramSession, error) {
+	session, err := s.orgs.AuthorizeSession(ctx, userID, sessionID, domain.OrgRoleOperator)
 	if err != nil {
-		return nil, domain.ErrSessionNotFound
+		return nil, err
 	}
 
 	cacheKey := "tg:code:" + sessionID.String()
@@ -339,17 +781,30 @@ func defaultSessionName(name, fallback string) string {
 
 // ==================== CRUD ====================
 
+// ListSessions sesiones personales del usuario y las de sus organizaciones
 func (s *SessionService) ListSessions(ctx context.Context, userID uuid.UUID) ([]domain.TelegramSession, error) {
-	return s.sessionRepo.ListByUserID(ctx, userID)
+	return s.sessionRepo.ListAccessible(ctx, userID)
 }
 
-func (s *SessionService) GetSession(ctx context.Context, sessionID uuid.UUID) (*domain.TelegramSession, error) {
-	return s.sessionRepo.GetByID(ctx, sessionID)
+func (s *SessionService) GetSession(ctx context.Context, userID, sessionID uuid.UUID) (*domain.TelegramSession, error) {
+	return s.orgs.AuthorizeSession(ctx, userID, sessionID, domain.OrgRoleViewer)
 }
 
-func (s *SessionService) DeleteSession(ctx context.Context, sessionID uuid.UUID) error {
+// DeleteSession cierra y elimina la sesión; exige ser su owner
+func (s *SessionService) DeleteSession(ctx context.Context, userID, sessionID uuid.UUID) error {
+	if _, err := s.orgs.AuthorizeSession(ctx, userID, sessionID, domain.OrgRoleOwner); err != nil {
+		s.audit.RecordAction(ctx, domain.AuditSessionDelete, &sessionID, err, nil)
+		return err
+	}
+	return s.deleteSession(ctx, sessionID)
+}
+
+// deleteSession cierra la sesión en Telegram y la elimina sin verificar el
+// acceso; la usa también la baja de usuarios
+func (s *SessionService) deleteSession(ctx context.Context, sessionID uuid.UUID) error {
 	session, err := s.sessionRepo.GetByID(ctx, sessionID)
 	if err != nil {
+		s.audit.RecordAction(ctx, domain.AuditSessionDelete, &sessionID, err, nil)
 		return err
 	}
 
@@ -369,15 +824,23 @@ func (s *SessionService) DeleteSession(ctx context.Context, sessionID uuid.UUID)
 		}
 	}
 
-	return s.sessionRepo.Delete(ctx, sessionID)
+	err = s.sessionRepo.Delete(ctx, sessionID)
+	if err == nil {
+		s.tgManager.ForgetPeers(sessionID)
+	}
+	s.audit.RecordAction(ctx, domain.AuditSessionDelete, &sessionID, err, map[string]any{
+		"owner_id":   session.UserID.String(),
+		"phone":      session.PhoneNumber,
+		"tg_user_id": session.TelegramUserID,
+	})
+	return err
 }
 
-
 // RegenerateQR genera un nuevo QR para una sesión existente
-func (s *SessionService) RegenerateQR(ctx context.Context, sessionID uuid.UUID) (string, error) {
-	session, err := s.sessionRepo.GetByID(ctx, sessionID)
+func (s *SessionService) RegenerateQR(ctx context.Context, userID, sessionID uuid.UUID) (string, error) {
+	session, err := s.orgs.AuthorizeSession(ctx, userID, sessionID, domain.OrgRoleOperator)
 	if err != nil {
-		return "", domain.ErrSessionNotFound
+		return "", err
 	}
 
 	// Si ya está autenticada, no necesita regenerar
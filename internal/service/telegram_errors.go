@@ -0,0 +1,36 @@
+package service
+
+import (
+	"fmt"
+
+	"telegram-api/internal/domain"
+
+	"github.com/gotd/td/tgerr"
+)
+
+// mapTelegramError convierte errores RPC de Telegram en AppError con status HTTP
+// y código legible (ej. CHAT_ADMIN_REQUIRED). Otros errores se retornan sin cambios.
+func mapTelegramError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	if wait, ok := tgerr.AsFloodWait(err); ok {
+		return domain.NewAppError(domain.ErrTelegramFloodWait, fmt.Sprintf("Telegram pide esperar %s", wait), 429).
+			WithCode("FLOOD_WAIT").
+			WithDetails(map[string]interface{}{"retry_after": int(wait.Seconds())})
+	}
+
+	rpcErr, ok := tgerr.As(err)
+	if !ok {
+		return err
+	}
+
+	status := 502
+	switch rpcErr.Code {
+	case 400, 403, 404, 406:
+		status = rpcErr.Code
+	}
+
+	return domain.NewAppError(domain.ErrTelegramError, rpcErr.Message, status).WithCode(rpcErr.Type)
+}
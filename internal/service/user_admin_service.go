@@ -0,0 +1,199 @@
+package service
+
+import (
+	"context"
+
+	"telegram-api/internal/domain"
+	"telegram-api/internal/telegram"
+	"telegram-api/pkg/logger"
+
+	"github.com/google/uuid"
+)
+
+// UserAdminService administración de cuentas: listado, activación, rol y
+// borrado. Los cambios que afectan a los tokens cierran los logins del
+// usuario; el borrado cierra además sus sesiones personales de Telegram.
+type UserAdminService struct {
+	userRepo    domain.UserRepository
+	sessionRepo domain.SessionRepository
+	orgRepo     domain.OrganizationRepository
+	auth        *AuthService
+	sessions    *SessionService
+	pool        *telegram.SessionPool
+	audit       *AuditService
+}
+
+func NewUserAdminService(
+	userRepo domain.UserRepository,
+	sessionRepo domain.SessionRepository,
+	orgRepo domain.OrganizationRepository,
+	auth *AuthService,
+	sessions *SessionService,
+	pool *telegram.SessionPool,
+	audit *AuditService,
+) *UserAdminService {
+	return &UserAdminService{
+		userRepo:    userRepo,
+		sessionRepo: sessionRepo,
+		orgRepo:     orgRepo,
+		auth:        auth,
+		sessions:    sessions,
+		pool:        pool,
+		audit:       audit,
+	}
+}
+
+func (s *UserAdminService) ListUsers(ctx context.Context, filter domain.UserFilter) ([]*domain.User, int64, error) {
+	users, total, err := s.userRepo.List(ctx, filter)
+	if err != nil {
+		logger.Error().Err(err).Msg("Error listando usuarios")
+		return nil, 0, domain.ErrDatabase
+	}
+	return users, total, nil
+}
+
+func (s *UserAdminService) GetUser(ctx context.Context, userID uuid.UUID) (*domain.User, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, domain.ErrUserNotFound
+	}
+	return user, nil
+}
+
+// SetActive activa o desactiva una cuenta; al desactivarla cierra sus logins.
+// Sus API keys dejan de funcionar mientras esté desactivada.
+func (s *UserAdminService) SetActive(ctx context.Context, actorID, userID uuid.UUID, active bool) (*domain.User, error) {
+	user, err := s.setActive(ctx, actorID, userID, active)
+	s.audit.RecordAction(ctx, domain.AuditAdminUserStatus, nil, err, map[string]any{
+		"user_id":   userID.String(),
+		"is_active": active,
+	})
+	return user, err
+}
+
+func (s *UserAdminService) setActive(ctx context.Context, actorID, userID uuid.UUID, active bool) (*domain.User, error) {
+	if actorID == userID {
+		return nil, domain.ErrForbidden
+	}
+	user, err := s.GetUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	user.IsActive = active
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		logger.Error().Err(err).Str("user_id", userID.String()).Msg("Error actualizando estado de usuario")
+		return nil, domain.ErrDatabase
+	}
+	if !active {
+		if err := s.auth.logoutAll(ctx, userID); err != nil {
+			return nil, err
+		}
+	}
+
+	logger.Info().
+		Str("user_id", userID.String()).
+		Str("admin_id", actorID.String()).
+		Bool("is_active", active).
+		Msg("Estado de usuario actualizado")
+	return user, nil
+}
+
+// SetRole cambia el rol. El rol viaja en el JWT, así que se cierran sus logins
+// para que el cambio se aplique de inmediato.
+func (s *UserAdminService) SetRole(ctx context.Context, actorID, userID uuid.UUID, role domain.Role) (*domain.User, error) {
+	user, err := s.setRole(ctx, actorID, userID, role)
+	s.audit.RecordAction(ctx, domain.AuditAdminUserRole, nil, err, map[string]any{
+		"user_id": userID.String(),
+		"role":    string(role),
+	})
+	return user, err
+}
+
+func (s *UserAdminService) setRole(ctx context.Context, actorID, userID uuid.UUID, role domain.Role) (*domain.User, error) {
+	if actorID == userID {
+		return nil, domain.ErrForbidden
+	}
+	user, err := s.GetUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.Role == role {
+		return user, nil
+	}
+
+	user.Role = role
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		logger.Error().Err(err).Str("user_id", userID.String()).Msg("Error actualizando rol de usuario")
+		return nil, domain.ErrDatabase
+	}
+	if err := s.auth.logoutAll(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	logger.Info().
+		Str("user_id", userID.String()).
+		Str("admin_id", actorID.String()).
+		Str("role", string(role)).
+		Msg("Rol de usuario actualizado")
+	return user, nil
+}
+
+// DeleteUser detiene y cierra en Telegram las sesiones personales del
+// usuario, cede sus sesiones compartidas a otro owner de la organización,
+// revoca sus logins y elimina la cuenta (en cascada sus API keys, tokens y
+// membresías). Retorna cuántas sesiones de Telegram se cerraron.
+func (s *UserAdminService) DeleteUser(ctx context.Context, actorID, userID uuid.UUID) (int, error) {
+	closed, err := s.deleteUser(ctx, actorID, userID)
+	s.audit.RecordAction(ctx, domain.AuditAdminUserDelete, nil, err, map[string]any{
+		"user_id":         userID.String(),
+		"closed_sessions": closed,
+	})
+	return closed, err
+}
+
+func (s *UserAdminService) deleteUser(ctx context.Context, actorID, userID uuid.UUID) (int, error) {
+	if actorID == userID {
+		return 0, domain.ErrForbidden
+	}
+	if _, err := s.GetUser(ctx, userID); err != nil {
+		return 0, err
+	}
+
+	// Las sesiones compartidas pasan a otro owner de su organización; solo
+	// se cierran las personales
+	if err := s.orgRepo.ReleaseUser(ctx, userID); err != nil {
+		logger.Error().Err(err).Str("user_id", userID.String()).Msg("Error liberando organizaciones del usuario")
+		return 0, err
+	}
+
+	sessions, err := s.sessionRepo.ListByUserID(ctx, userID)
+	if err != nil {
+		logger.Error().Err(err).Str("user_id", userID.String()).Msg("Error listando sesiones del usuario")
+		return 0, domain.ErrDatabase
+	}
+	closed := 0
+	for _, sess := range sessions {
+		s.pool.StopSession(sess.ID)
+		if err := s.sessions.deleteSession(ctx, sess.ID); err != nil {
+			logger.Warn().Err(err).Str("session_id", sess.ID.String()).Msg("Error cerrando sesión del usuario eliminado")
+			continue
+		}
+		closed++
+	}
+
+	if err := s.auth.logoutAll(ctx, userID); err != nil {
+		return closed, err
+	}
+	if err := s.userRepo.Delete(ctx, userID); err != nil {
+		logger.Error().Err(err).Str("user_id", userID.String()).Msg("Error eliminando usuario")
+		return closed, domain.ErrDatabase
+	}
+
+	logger.Warn().
+		Str("user_id", userID.String()).
+		Str("admin_id", actorID.String()).
+		Int("sessions", closed).
+		Msg("🗑️ Usuario eliminado")
+	return closed, nil
+}
@@ -0,0 +1,86 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"telegram-api/internal/domain"
+
+	"github.com/gotd/td/telegram/message/markup"
+	"github.com/gotd/td/tg"
+)
+
+// ==================== INLINE KEYBOARD ====================
+
+// inlineMarkup convierte el teclado del request en reply markup de Telegram
+func inlineMarkup(kb domain.InlineKeyboard) tg.ReplyMarkupClass {
+	rows := make([]tg.KeyboardButtonRow, 0, len(kb))
+	for _, row := range kb {
+		buttons := make([]tg.KeyboardButtonClass, 0, len(row))
+		for _, btn := range row {
+			if btn.URL != "" {
+				buttons = append(buttons, markup.URL(btn.Text, btn.URL))
+			} else {
+				buttons = append(buttons, markup.Callback(btn.Text, []byte(btn.CallbackData)))
+			}
+		}
+		rows = append(rows, markup.Row(buttons...))
+	}
+	return markup.InlineKeyboard(rows...)
+}
+
+// ==================== CALLBACK QUERIES ====================
+
+// AnswerCallbackQuery responde a la pulsación de un botón inline. Telegram exige
+// responder en pocos segundos; si no se responde, el cliente muestra un spinner.
+func (m *ClientManager) AnswerCallbackQuery(ctx context.Context, api *tg.Client, queryID int64, req domain.AnswerCallbackRequest) error {
+	answer := &tg.MessagesSetBotCallbackAnswerRequest{
+		Alert:     req.ShowAlert,
+		QueryID:   queryID,
+		CacheTime: req.CacheTime,
+	}
+	if req.Text != "" {
+		answer.SetMessage(req.Text)
+	}
+	if req.URL != "" {
+		answer.SetURL(req.URL)
+	}
+
+	if _, err := api.MessagesSetBotCallbackAnswer(ctx, answer); err != nil {
+		return fmt.Errorf("set bot callback answer: %w", err)
+	}
+	return nil
+}
+
+// parseCallbackQuery arma el payload del webhook para un callback query
+func parseCallbackQuery(e tg.Entities, update *tg.UpdateBotCallbackQuery) domain.CallbackQueryEventData {
+	data := domain.CallbackQueryEventData{
+		QueryID:   strconv.FormatInt(update.QueryID, 10),
+		MessageID: int64(update.MsgID),
+		FromID:    update.UserID,
+		Data:      string(update.Data),
+	}
+
+	if user, ok := e.Users[update.UserID]; ok {
+		data.FromName = user.FirstName
+		if user.LastName != "" {
+			data.FromName += " " + user.LastName
+		}
+		data.Username = user.Username
+	}
+
+	switch peer := update.Peer.(type) {
+	case *tg.PeerUser:
+		data.ChatID = peer.UserID
+		data.ChatType = "private"
+	case *tg.PeerChat:
+		data.ChatID = peer.ChatID
+		data.ChatType = "group"
+	case *tg.PeerChannel:
+		data.ChatID = peer.ChannelID
+		data.ChatType = "channel"
+	}
+
+	return data
+}
@@ -0,0 +1,170 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"telegram-api/internal/domain"
+
+	"github.com/google/uuid"
+	"github.com/gotd/td/telegram"
+	"github.com/gotd/td/tg"
+)
+
+// chatActionRefresh intervalo de reenvío: los clientes ocultan la acción tras ~6s
+const chatActionRefresh = 5 * time.Second
+
+// MarkAsRead marca el historial como leído hasta maxID (0 = todo), incluyendo
+// menciones y reacciones pendientes
+func (m *ClientManager) MarkAsRead(ctx context.Context, client *telegram.Client, chatID int64, maxID int) error {
+	api := client.API()
+
+	peer, err := m.resolvePeerByID(ctx, api, chatID)
+	if err != nil {
+		return err
+	}
+
+	if channel, ok := peer.(*tg.InputPeerChannel); ok {
+		_, err = api.ChannelsReadHistory(ctx, &tg.ChannelsReadHistoryRequest{
+			Channel: inputChannel(channel),
+			MaxID:   maxID,
+		})
+	} else {
+		_, err = api.MessagesReadHistory(ctx, &tg.MessagesReadHistoryRequest{
+			Peer:  peer,
+			MaxID: maxID,
+		})
+	}
+	if err != nil {
+		return fmt.Errorf("read history: %w", err)
+	}
+
+	if _, err := api.MessagesReadMentions(ctx, &tg.MessagesReadMentionsRequest{Peer: peer}); err != nil {
+		return fmt.Errorf("read mentions: %w", err)
+	}
+	if _, err := api.MessagesReadReactions(ctx, &tg.MessagesReadReactionsRequest{Peer: peer}); err != nil {
+		return fmt.Errorf("read reactions: %w", err)
+	}
+
+	return nil
+}
+
+// chatActionKey identifica la acción en curso de un chat: una por sesión y chat
+type chatActionKey struct {
+	sessionID uuid.UUID
+	chatID    int64
+}
+
+// chatActions registra los bucles de refresco en curso para poder detenerlos
+// cuando llega otra acción (o cancel) al mismo chat
+type chatActions struct {
+	mu      sync.Mutex
+	running map[chatActionKey]*runningAction
+}
+
+type runningAction struct {
+	cancel context.CancelFunc
+}
+
+// StartChatAction detiene la acción en curso del chat y registra una nueva
+// derivada de parent. release la cancela y la quita del registro.
+func (m *ClientManager) StartChatAction(parent context.Context, sessionID uuid.UUID, chatID int64) (ctx context.Context, release func()) {
+	key := chatActionKey{sessionID: sessionID, chatID: chatID}
+	ctx, cancel := context.WithCancel(parent)
+	entry := &runningAction{cancel: cancel}
+
+	m.actions.mu.Lock()
+	if m.actions.running == nil {
+		m.actions.running = make(map[chatActionKey]*runningAction)
+	}
+	if prev, ok := m.actions.running[key]; ok {
+		prev.cancel()
+	}
+	m.actions.running[key] = entry
+	m.actions.mu.Unlock()
+
+	return ctx, func() {
+		cancel()
+		m.actions.mu.Lock()
+		if m.actions.running[key] == entry {
+			delete(m.actions.running, key)
+		}
+		m.actions.mu.Unlock()
+	}
+}
+
+// KeepChatAction muestra una acción (escribiendo, grabando audio, etc.) durante
+// duration, reenviándola periódicamente y cancelándola al terminar. El resultado
+// del primer envío se publica en started; el bucle sigue hasta duration o hasta
+// que se cancele ctx (sin enviar cancel: la acción que la reemplaza ya lo hace).
+func (m *ClientManager) KeepChatAction(ctx context.Context, api *tg.Client, chatID int64, action domain.ChatActionType, duration time.Duration, started chan<- error) error {
+	peer, err := m.resolvePeerByID(ctx, api, chatID)
+	if err != nil {
+		started <- err
+		return err
+	}
+
+	setTyping := func(a tg.SendMessageActionClass) error {
+		if _, err := api.MessagesSetTyping(ctx, &tg.MessagesSetTypingRequest{Peer: peer, Action: a}); err != nil {
+			return fmt.Errorf("set typing: %w", err)
+		}
+		return nil
+	}
+
+	err = setTyping(toTGChatAction(action))
+	started <- err
+	if err != nil || action == domain.ChatActionCancel || duration <= 0 {
+		return err
+	}
+
+	ticker := time.NewTicker(chatActionRefresh)
+	defer ticker.Stop()
+	deadline := time.NewTimer(duration)
+	defer deadline.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-deadline.C:
+			return setTyping(&tg.SendMessageCancelAction{})
+		case <-ticker.C:
+			if err := setTyping(toTGChatAction(action)); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// SetOnline actualiza el estado de presencia de la cuenta (account.updateStatus)
+func (m *ClientManager) SetOnline(ctx context.Context, api *tg.Client, online bool) error {
+	if _, err := api.AccountUpdateStatus(ctx, !online); err != nil {
+		return fmt.Errorf("update status: %w", err)
+	}
+	return nil
+}
+
+func toTGChatAction(action domain.ChatActionType) tg.SendMessageActionClass {
+	switch action {
+	case domain.ChatActionUploadPhoto:
+		return &tg.SendMessageUploadPhotoAction{}
+	case domain.ChatActionUploadVideo:
+		return &tg.SendMessageUploadVideoAction{}
+	case domain.ChatActionUploadDocument:
+		return &tg.SendMessageUploadDocumentAction{}
+	case domain.ChatActionUploadAudio:
+		return &tg.SendMessageUploadAudioAction{}
+	case domain.ChatActionRecordAudio:
+		return &tg.SendMessageRecordAudioAction{}
+	case domain.ChatActionRecordVideo:
+		return &tg.SendMessageRecordVideoAction{}
+	case domain.ChatActionChooseSticker:
+		return &tg.SendMessageChooseStickerAction{}
+	case domain.ChatActionCancel:
+		return &tg.SendMessageCancelAction{}
+	default:
+		return &tg.SendMessageTypingAction{}
+	}
+}
@@ -0,0 +1,584 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"telegram-api/internal/domain"
+
+	"github.com/gotd/td/telegram"
+	"github.com/gotd/td/telegram/query"
+	"github.com/gotd/td/telegram/uploader"
+	"github.com/gotd/td/tg"
+)
+
+// channelIDOffset convierte IDs MTProto de canales al formato Bot API (-100ID)
+const channelIDOffset = 1000000000000
+
+// ==================== CREATE ====================
+
+// CreateChat crea un grupo básico, supergrupo o canal e invita a los miembros indicados
+func (m *ClientManager) CreateChat(ctx context.Context, client *telegram.Client, req domain.CreateChatRequest) (*domain.CreatedChat, error) {
+	api := client.API()
+
+	users, err := m.resolveInputUsers(ctx, api, req.Members)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Type == domain.ChatTypeGroup {
+		invited, err := api.MessagesCreateChat(ctx, &tg.MessagesCreateChatRequest{
+			Users: users,
+			Title: req.Title,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("create chat: %w", err)
+		}
+
+		for _, c := range updatesChats(invited.Updates) {
+			if chat, ok := c.(*tg.Chat); ok {
+				if req.About != "" {
+					_, _ = api.MessagesEditChatAbout(ctx, &tg.MessagesEditChatAboutRequest{
+						Peer:  &tg.InputPeerChat{ChatID: chat.ID},
+						About: req.About,
+					})
+				}
+				return &domain.CreatedChat{
+					ID:              -chat.ID,
+					Type:            domain.ChatTypeGroup,
+					Title:           chat.Title,
+					MissingInvitees: missingInviteeIDs(invited.MissingInvitees),
+				}, nil
+			}
+		}
+		return nil, fmt.Errorf("create chat: chat no encontrado en respuesta")
+	}
+
+	updates, err := api.ChannelsCreateChannel(ctx, &tg.ChannelsCreateChannelRequest{
+		Broadcast: req.Type == domain.ChatTypeChannel,
+		Megagroup: req.Type == domain.ChatTypeSupergroup,
+		Title:     req.Title,
+		About:     req.About,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("create channel: %w", err)
+	}
+
+	for _, c := range updatesChats(updates) {
+		ch, ok := c.(*tg.Channel)
+		if !ok {
+			continue
+		}
+
+		created := &domain.CreatedChat{
+			ID:    -(channelIDOffset + ch.ID),
+			Type:  req.Type,
+			Title: ch.Title,
+		}
+
+		if len(users) > 0 {
+			invited, err := api.ChannelsInviteToChannel(ctx, &tg.ChannelsInviteToChannelRequest{
+				Channel: &tg.InputChannel{ChannelID: ch.ID, AccessHash: ch.AccessHash},
+				Users:   users,
+			})
+			if err != nil {
+				return nil, fmt.Errorf("invite to channel: %w", err)
+			}
+			created.MissingInvitees = missingInviteeIDs(invited.MissingInvitees)
+		}
+
+		return created, nil
+	}
+
+	return nil, fmt.Errorf("create channel: canal no encontrado en respuesta")
+}
+
+// ==================== MEMBERS ====================
+
+// InviteMembers añade usuarios a un grupo, supergrupo o canal
+func (m *ClientManager) InviteMembers(ctx context.Context, client *telegram.Client, chatID int64, members []string) (*domain.InviteMembersResult, error) {
+	api := client.API()
+
+	peer, err := m.resolveChatPeer(ctx, api, chatID)
+	if err != nil {
+		return nil, err
+	}
+
+	users, err := m.resolveInputUsers(ctx, api, members)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &domain.InviteMembersResult{}
+
+	switch p := peer.(type) {
+	case *tg.InputPeerChat:
+		for _, user := range users {
+			invited, err := api.MessagesAddChatUser(ctx, &tg.MessagesAddChatUserRequest{
+				ChatID:   p.ChatID,
+				UserID:   user,
+				FwdLimit: 100,
+			})
+			if err != nil {
+				return nil, fmt.Errorf("add chat user: %w", err)
+			}
+			result.MissingInvitees = append(result.MissingInvitees, missingInviteeIDs(invited.MissingInvitees)...)
+		}
+	case *tg.InputPeerChannel:
+		invited, err := api.ChannelsInviteToChannel(ctx, &tg.ChannelsInviteToChannelRequest{
+			Channel: inputChannel(p),
+			Users:   users,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("invite to channel: %w", err)
+		}
+		result.MissingInvitees = missingInviteeIDs(invited.MissingInvitees)
+	}
+
+	result.Invited = len(users) - len(result.MissingInvitees)
+	return result, nil
+}
+
+// KickMember expulsa a un miembro sin impedir que vuelva a unirse
+func (m *ClientManager) KickMember(ctx context.Context, client *telegram.Client, chatID int64, user string) error {
+	api := client.API()
+
+	peer, userPeer, err := m.resolveChatAndUser(ctx, api, chatID, user)
+	if err != nil {
+		return err
+	}
+
+	switch p := peer.(type) {
+	case *tg.InputPeerChat:
+		_, err = api.MessagesDeleteChatUser(ctx, &tg.MessagesDeleteChatUserRequest{
+			ChatID: p.ChatID,
+			UserID: inputUser(userPeer),
+		})
+		if err != nil {
+			return fmt.Errorf("delete chat user: %w", err)
+		}
+	case *tg.InputPeerChannel:
+		// En supergrupos/canales expulsar = banear y levantar el ban
+		if err := m.editBanned(ctx, api, p, userPeer, tg.ChatBannedRights{ViewMessages: true}); err != nil {
+			return err
+		}
+		if err := m.editBanned(ctx, api, p, userPeer, tg.ChatBannedRights{}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// BanMember banea a un miembro (en grupos básicos equivale a expulsarlo)
+func (m *ClientManager) BanMember(ctx context.Context, client *telegram.Client, chatID int64, req domain.BanMemberRequest) error {
+	api := client.API()
+
+	peer, userPeer, err := m.resolveChatAndUser(ctx, api, chatID, req.User)
+	if err != nil {
+		return err
+	}
+
+	switch p := peer.(type) {
+	case *tg.InputPeerChat:
+		_, err = api.MessagesDeleteChatUser(ctx, &tg.MessagesDeleteChatUserRequest{
+			ChatID:        p.ChatID,
+			UserID:        inputUser(userPeer),
+			RevokeHistory: req.RevokeHistory,
+		})
+		if err != nil {
+			return fmt.Errorf("delete chat user: %w", err)
+		}
+		return nil
+	case *tg.InputPeerChannel:
+		return m.editBanned(ctx, api, p, userPeer, tg.ChatBannedRights{
+			ViewMessages: true,
+			UntilDate:    int(req.UntilDate),
+		})
+	}
+
+	return nil
+}
+
+// UnbanMember levanta el ban de un miembro (solo supergrupos/canales)
+func (m *ClientManager) UnbanMember(ctx context.Context, client *telegram.Client, chatID int64, user string) error {
+	api := client.API()
+
+	peer, userPeer, err := m.resolveChatAndUser(ctx, api, chatID, user)
+	if err != nil {
+		return err
+	}
+
+	channel, ok := peer.(*tg.InputPeerChannel)
+	if !ok {
+		return fmt.Errorf("%w: los grupos básicos no tienen lista de baneados", domain.ErrInvalidInput)
+	}
+
+	return m.editBanned(ctx, api, channel, userPeer, tg.ChatBannedRights{})
+}
+
+// ==================== ADMINS ====================
+
+// PromoteAdmin promueve a un miembro como administrador con los permisos indicados
+func (m *ClientManager) PromoteAdmin(ctx context.Context, client *telegram.Client, chatID int64, req domain.PromoteAdminRequest) error {
+	api := client.API()
+
+	peer, userPeer, err := m.resolveChatAndUser(ctx, api, chatID, req.User)
+	if err != nil {
+		return err
+	}
+
+	switch p := peer.(type) {
+	case *tg.InputPeerChat:
+		// Los grupos básicos no soportan permisos granulares
+		_, err = api.MessagesEditChatAdmin(ctx, &tg.MessagesEditChatAdminRequest{
+			ChatID:  p.ChatID,
+			UserID:  inputUser(userPeer),
+			IsAdmin: true,
+		})
+		if err != nil {
+			return fmt.Errorf("edit chat admin: %w", err)
+		}
+	case *tg.InputPeerChannel:
+		_, err = api.ChannelsEditAdmin(ctx, &tg.ChannelsEditAdminRequest{
+			Channel:     inputChannel(p),
+			UserID:      inputUser(userPeer),
+			AdminRights: toTGAdminRights(req.Rights),
+			Rank:        req.Rank,
+		})
+		if err != nil {
+			return fmt.Errorf("edit admin: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// DemoteAdmin retira los permisos de administrador a un miembro
+func (m *ClientManager) DemoteAdmin(ctx context.Context, client *telegram.Client, chatID int64, user string) error {
+	api := client.API()
+
+	peer, userPeer, err := m.resolveChatAndUser(ctx, api, chatID, user)
+	if err != nil {
+		return err
+	}
+
+	switch p := peer.(type) {
+	case *tg.InputPeerChat:
+		_, err = api.MessagesEditChatAdmin(ctx, &tg.MessagesEditChatAdminRequest{
+			ChatID:  p.ChatID,
+			UserID:  inputUser(userPeer),
+			IsAdmin: false,
+		})
+		if err != nil {
+			return fmt.Errorf("edit chat admin: %w", err)
+		}
+	case *tg.InputPeerChannel:
+		_, err = api.ChannelsEditAdmin(ctx, &tg.ChannelsEditAdminRequest{
+			Channel:     inputChannel(p),
+			UserID:      inputUser(userPeer),
+			AdminRights: tg.ChatAdminRights{},
+		})
+		if err != nil {
+			return fmt.Errorf("edit admin: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// ==================== SETTINGS ====================
+
+// EditChat actualiza título y/o descripción del chat
+func (m *ClientManager) EditChat(ctx context.Context, client *telegram.Client, chatID int64, req domain.EditChatRequest) error {
+	api := client.API()
+
+	peer, err := m.resolveChatPeer(ctx, api, chatID)
+	if err != nil {
+		return err
+	}
+
+	if req.Title != "" {
+		switch p := peer.(type) {
+		case *tg.InputPeerChat:
+			_, err = api.MessagesEditChatTitle(ctx, &tg.MessagesEditChatTitleRequest{ChatID: p.ChatID, Title: req.Title})
+		case *tg.InputPeerChannel:
+			_, err = api.ChannelsEditTitle(ctx, &tg.ChannelsEditTitleRequest{Channel: inputChannel(p), Title: req.Title})
+		}
+		if err != nil {
+			return fmt.Errorf("edit title: %w", err)
+		}
+	}
+
+	if req.About != nil {
+		if _, err := api.MessagesEditChatAbout(ctx, &tg.MessagesEditChatAboutRequest{
+			Peer:  peer,
+			About: *req.About,
+		}); err != nil {
+			return fmt.Errorf("edit about: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// EditChatPhoto descarga la imagen indicada y la establece como foto del chat
+func (m *ClientManager) EditChatPhoto(ctx context.Context, client *telegram.Client, chatID int64, photoURL string) error {
+	api := client.API()
+
+	peer, err := m.resolveChatPeer(ctx, api, chatID)
+	if err != nil {
+		return err
+	}
+
+	filePath, err := m.downloadFile(photoURL)
+	if err != nil {
+		return fmt.Errorf("download photo: %w", err)
+	}
+	defer os.Remove(filePath)
+
+	upload, err := uploader.NewUploader(api).FromPath(ctx, filePath)
+	if err != nil {
+		return fmt.Errorf("upload photo: %w", err)
+	}
+	photo := &tg.InputChatUploadedPhoto{File: upload}
+
+	switch p := peer.(type) {
+	case *tg.InputPeerChat:
+		_, err = api.MessagesEditChatPhoto(ctx, &tg.MessagesEditChatPhotoRequest{ChatID: p.ChatID, Photo: photo})
+	case *tg.InputPeerChannel:
+		_, err = api.ChannelsEditPhoto(ctx, &tg.ChannelsEditPhotoRequest{Channel: inputChannel(p), Photo: photo})
+	}
+	if err != nil {
+		return fmt.Errorf("edit photo: %w", err)
+	}
+
+	return nil
+}
+
+// SetSlowMode configura el intervalo mínimo entre mensajes (solo supergrupos)
+func (m *ClientManager) SetSlowMode(ctx context.Context, client *telegram.Client, chatID int64, seconds int) error {
+	api := client.API()
+
+	peer, err := m.resolveChatPeer(ctx, api, chatID)
+	if err != nil {
+		return err
+	}
+
+	channel, ok := peer.(*tg.InputPeerChannel)
+	if !ok {
+		return fmt.Errorf("%w: slow mode solo disponible en supergrupos", domain.ErrInvalidInput)
+	}
+
+	if _, err := api.ChannelsToggleSlowMode(ctx, &tg.ChannelsToggleSlowModeRequest{
+		Channel: inputChannel(channel),
+		Seconds: seconds,
+	}); err != nil {
+		return fmt.Errorf("toggle slow mode: %w", err)
+	}
+
+	return nil
+}
+
+// SetDefaultPermissions establece los permisos por defecto de los miembros
+func (m *ClientManager) SetDefaultPermissions(ctx context.Context, client *telegram.Client, chatID int64, perms domain.ChatPermissions) error {
+	api := client.API()
+
+	peer, err := m.resolveChatPeer(ctx, api, chatID)
+	if err != nil {
+		return err
+	}
+
+	if _, err := api.MessagesEditChatDefaultBannedRights(ctx, &tg.MessagesEditChatDefaultBannedRightsRequest{
+		Peer:         peer,
+		BannedRights: toTGBannedRights(perms),
+	}); err != nil {
+		return fmt.Errorf("edit default banned rights: %w", err)
+	}
+
+	return nil
+}
+
+// ==================== HELPERS ====================
+
+// resolveChatPeer convierte un ID en formato Bot API (-ID grupos, -100ID canales)
+// a un InputPeer. Para canales toma el access_hash de la caché de la sesión y,
+// si no está, de los diálogos de la cuenta.
+func (m *ClientManager) resolveChatPeer(ctx context.Context, api *tg.Client, chatID int64) (tg.InputPeerClass, error) {
+	if chatID >= 0 {
+		return nil, fmt.Errorf("%w: %d no es un grupo ni canal", domain.ErrChatNotFound, chatID)
+	}
+
+	id := -chatID
+	if id <= channelIDOffset {
+		return &tg.InputPeerChat{ChatID: id}, nil
+	}
+
+	return m.scanDialogsFor(ctx, api, chatID)
+}
+
+// scanDialogsFor busca un peer en la caché de la sesión o, si no está,
+// recorriendo los diálogos. Todo peer visto en el recorrido queda cacheado,
+// así las búsquedas siguientes no vuelven a paginar.
+func (m *ClientManager) scanDialogsFor(ctx context.Context, api *tg.Client, chatID int64) (tg.InputPeerClass, error) {
+	if peer, ok := m.cachedPeer(ctx, chatID); ok {
+		return peer, nil
+	}
+
+	iter := query.GetDialogs(api).BatchSize(100).Iter()
+	for iter.Next(ctx) {
+		peer := iter.Value().Peer
+		m.cachePeer(ctx, peer)
+		if inputPeerID(peer) == chatID {
+			return peer, nil
+		}
+	}
+	if err := iter.Err(); err != nil {
+		return nil, fmt.Errorf("iterate dialogs: %w", err)
+	}
+
+	return nil, fmt.Errorf("%w: %d", domain.ErrChatNotFound, chatID)
+}
+
+// resolveChatAndUser resuelve el chat y el usuario objetivo de una operación de administración
+func (m *ClientManager) resolveChatAndUser(ctx context.Context, api *tg.Client, chatID int64, user string) (tg.InputPeerClass, *tg.InputPeerUser, error) {
+	peer, err := m.resolveChatPeer(ctx, api, chatID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	userPeer, err := m.resolveUserPeer(ctx, api, user)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return peer, userPeer, nil
+}
+
+// resolveUserPeer resuelve @username, +phone o ID numérico a un usuario
+func (m *ClientManager) resolveUserPeer(ctx context.Context, api *tg.Client, user string) (*tg.InputPeerUser, error) {
+	if isNumeric(user) && !strings.HasPrefix(user, "-") {
+		id, _ := parseUserID(user)
+		userPeer, err := m.resolveUserByID(ctx, api, id)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", domain.ErrPeerNotFound, user)
+		}
+		return userPeer, nil
+	}
+
+	peer, err := m.resolvePeer(ctx, api, user)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", domain.ErrPeerNotFound, user, err)
+	}
+
+	userPeer, ok := peer.(*tg.InputPeerUser)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s no es un usuario", domain.ErrPeerNotFound, user)
+	}
+
+	return userPeer, nil
+}
+
+func (m *ClientManager) resolveInputUsers(ctx context.Context, api *tg.Client, users []string) ([]tg.InputUserClass, error) {
+	result := make([]tg.InputUserClass, 0, len(users))
+	for _, u := range users {
+		userPeer, err := m.resolveUserPeer(ctx, api, u)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, inputUser(userPeer))
+	}
+	return result, nil
+}
+
+func (m *ClientManager) editBanned(ctx context.Context, api *tg.Client, channel *tg.InputPeerChannel, user *tg.InputPeerUser, rights tg.ChatBannedRights) error {
+	if _, err := api.ChannelsEditBanned(ctx, &tg.ChannelsEditBannedRequest{
+		Channel:      inputChannel(channel),
+		Participant:  user,
+		BannedRights: rights,
+	}); err != nil {
+		return fmt.Errorf("edit banned: %w", err)
+	}
+	return nil
+}
+
+func inputChannel(p *tg.InputPeerChannel) *tg.InputChannel {
+	return &tg.InputChannel{ChannelID: p.ChannelID, AccessHash: p.AccessHash}
+}
+
+func inputUser(p *tg.InputPeerUser) *tg.InputUser {
+	return &tg.InputUser{UserID: p.UserID, AccessHash: p.AccessHash}
+}
+
+func updatesChats(u tg.UpdatesClass) []tg.ChatClass {
+	switch v := u.(type) {
+	case *tg.Updates:
+		return v.Chats
+	case *tg.UpdatesCombined:
+		return v.Chats
+	}
+	return nil
+}
+
+func missingInviteeIDs(missing []tg.MissingInvitee) []int64 {
+	var ids []int64
+	for _, mi := range missing {
+		ids = append(ids, mi.UserID)
+	}
+	return ids
+}
+
+func toTGAdminRights(r domain.AdminRights) tg.ChatAdminRights {
+	return tg.ChatAdminRights{
+		ChangeInfo:     r.ChangeInfo,
+		PostMessages:   r.PostMessages,
+		EditMessages:   r.EditMessages,
+		DeleteMessages: r.DeleteMessages,
+		BanUsers:       r.BanUsers,
+		InviteUsers:    r.InviteUsers,
+		PinMessages:    r.PinMessages,
+		AddAdmins:      r.AddAdmins,
+		Anonymous:      r.Anonymous,
+		ManageCall:     r.ManageCall,
+		ManageTopics:   r.ManageTopics,
+	}
+}
+
+// toTGBannedRights invierte los permisos: Telegram expresa lo que está prohibido
+func toTGBannedRights(p domain.ChatPermissions) tg.ChatBannedRights {
+	return tg.ChatBannedRights{
+		SendMessages:    !p.SendMessages,
+		SendPlain:       !p.SendMessages,
+		SendMedia:       !p.SendMedia,
+		SendPhotos:      !p.SendMedia,
+		SendVideos:      !p.SendMedia,
+		SendRoundvideos: !p.SendMedia,
+		SendAudios:      !p.SendMedia,
+		SendVoices:      !p.SendMedia,
+		SendDocs:        !p.SendMedia,
+		SendStickers:    !p.SendStickers,
+		SendGifs:        !p.SendGifs,
+		SendPolls:       !p.SendPolls,
+		EmbedLinks:      !p.EmbedLinks,
+		InviteUsers:     !p.InviteUsers,
+		PinMessages:     !p.PinMessages,
+		ChangeInfo:      !p.ChangeInfo,
+	}
+}
+
+// fromTGBannedRights convierte los derechos prohibidos de Telegram en permisos (true = permitido)
+func fromTGBannedRights(r tg.ChatBannedRights) domain.ChatPermissions {
+	return domain.ChatPermissions{
+		SendMessages: !r.SendMessages && !r.SendPlain,
+		SendMedia:    !r.SendMedia,
+		SendStickers: !r.SendStickers,
+		SendGifs:     !r.SendGifs,
+		SendPolls:    !r.SendPolls,
+		EmbedLinks:   !r.EmbedLinks,
+		InviteUsers:  !r.InviteUsers,
+		PinMessages:  !r.PinMessages,
+		ChangeInfo:   !r.ChangeInfo,
+	}
+}
@@ -0,0 +1,194 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+
+	"telegram-api/internal/domain"
+
+	"github.com/gotd/td/telegram"
+	"github.com/gotd/td/tg"
+)
+
+// GetChatInfo obtiene el detalle completo de un usuario, grupo básico, supergrupo
+// o canal usando users.getFullUser, messages.getFullChat o channels.getFullChannel.
+// chatID va en formato Bot API: ID usuarios, -ID grupos, -100ID canales.
+func (m *ClientManager) GetChatInfo(ctx context.Context, client *telegram.Client, chatID int64) (*domain.Chat, error) {
+	api := client.API()
+
+	peer, err := m.resolvePeerByID(ctx, api, chatID)
+	if err != nil {
+		return nil, err
+	}
+
+	switch p := peer.(type) {
+	case *tg.InputPeerUser:
+		return m.getFullUser(ctx, api, p)
+	case *tg.InputPeerChat:
+		return m.getFullChat(ctx, api, p.ChatID)
+	case *tg.InputPeerChannel:
+		return m.getFullChannel(ctx, api, p)
+	}
+
+	return nil, fmt.Errorf("%w: %d", domain.ErrChatNotFound, chatID)
+}
+
+func (m *ClientManager) getFullUser(ctx context.Context, api *tg.Client, p *tg.InputPeerUser) (*domain.Chat, error) {
+	full, err := api.UsersGetFullUser(ctx, inputUser(p))
+	if err != nil {
+		return nil, fmt.Errorf("get full user: %w", err)
+	}
+
+	chat := &domain.Chat{
+		ID:              p.UserID,
+		Type:            domain.ChatTypePrivate,
+		Bio:             full.FullUser.About,
+		PinnedMessageID: full.FullUser.PinnedMsgID,
+		IsArchived:      full.FullUser.FolderID == 1,
+	}
+
+	if user, ok := buildUserMap(full.Users)[p.UserID]; ok {
+		chat.FirstName = user.FirstName
+		chat.LastName = user.LastName
+		chat.Username = user.Username
+		chat.IsBot = user.Bot
+		chat.IsVerified = user.Verified
+		if photo, ok := user.Photo.(*tg.UserProfilePhoto); ok {
+			chat.PhotoID = photo.PhotoID
+		}
+	}
+
+	return chat, nil
+}
+
+func (m *ClientManager) getFullChat(ctx context.Context, api *tg.Client, id int64) (*domain.Chat, error) {
+	full, err := api.MessagesGetFullChat(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("get full chat: %w", err)
+	}
+
+	chat := &domain.Chat{
+		ID:   -id,
+		Type: domain.ChatTypeGroup,
+	}
+
+	if cf, ok := full.FullChat.(*tg.ChatFull); ok {
+		chat.Bio = cf.About
+		chat.PinnedMessageID = cf.PinnedMsgID
+		chat.IsArchived = cf.FolderID == 1
+		if participants, ok := cf.Participants.(*tg.ChatParticipants); ok {
+			chat.MembersCount = len(participants.Participants)
+			for _, part := range participants.Participants {
+				switch part.(type) {
+				case *tg.ChatParticipantAdmin, *tg.ChatParticipantCreator:
+					chat.AdminsCount++
+				}
+			}
+		}
+	}
+
+	if c, ok := findChat(full.Chats, id).(*tg.Chat); ok {
+		chat.Title = c.Title
+		if chat.MembersCount == 0 {
+			chat.MembersCount = c.ParticipantsCount
+		}
+		if photo, ok := c.Photo.(*tg.ChatPhoto); ok {
+			chat.PhotoID = photo.PhotoID
+		}
+		if rights, ok := c.GetDefaultBannedRights(); ok {
+			perms := fromTGBannedRights(rights)
+			chat.Permissions = &perms
+		}
+	}
+
+	return chat, nil
+}
+
+func (m *ClientManager) getFullChannel(ctx context.Context, api *tg.Client, p *tg.InputPeerChannel) (*domain.Chat, error) {
+	full, err := api.ChannelsGetFullChannel(ctx, inputChannel(p))
+	if err != nil {
+		return nil, fmt.Errorf("get full channel: %w", err)
+	}
+
+	chat := &domain.Chat{
+		ID:   -(channelIDOffset + p.ChannelID),
+		Type: domain.ChatTypeSupergroup,
+	}
+
+	if cf, ok := full.FullChat.(*tg.ChannelFull); ok {
+		chat.Bio = cf.About
+		chat.MembersCount = cf.ParticipantsCount
+		chat.OnlineCount = cf.OnlineCount
+		chat.AdminsCount = cf.AdminsCount
+		chat.PinnedMessageID = cf.PinnedMsgID
+		chat.SlowModeSeconds = cf.SlowmodeSeconds
+		chat.UnreadCount = cf.UnreadCount
+		chat.IsArchived = cf.FolderID == 1
+		if cf.LinkedChatID != 0 {
+			chat.LinkedChatID = -(channelIDOffset + cf.LinkedChatID)
+		}
+	}
+
+	if ch, ok := findChat(full.Chats, p.ChannelID).(*tg.Channel); ok {
+		if ch.Broadcast {
+			chat.Type = domain.ChatTypeChannel
+		}
+		chat.Title = ch.Title
+		chat.Username = ch.Username
+		chat.IsVerified = ch.Verified
+		if photo, ok := ch.Photo.(*tg.ChatPhoto); ok {
+			chat.PhotoID = photo.PhotoID
+		}
+		if rights, ok := ch.GetDefaultBannedRights(); ok && !ch.Broadcast {
+			perms := fromTGBannedRights(rights)
+			chat.Permissions = &perms
+		}
+	}
+
+	return chat, nil
+}
+
+// resolveUserByID busca el access_hash de un usuario en la caché de la sesión,
+// luego en contactos y por último en los diálogos de la cuenta
+func (m *ClientManager) resolveUserByID(ctx context.Context, api *tg.Client, userID int64) (*tg.InputPeerUser, error) {
+	if peer, ok := m.cachedPeer(ctx, userID); ok {
+		if p, ok := peer.(*tg.InputPeerUser); ok {
+			return p, nil
+		}
+	}
+
+	if contacts, err := api.ContactsGetContacts(ctx, 0); err == nil {
+		if c, ok := contacts.(*tg.ContactsContacts); ok {
+			var found *tg.InputPeerUser
+			for _, user := range buildUserMap(c.Users) {
+				p := &tg.InputPeerUser{UserID: user.ID, AccessHash: user.AccessHash}
+				m.cachePeer(ctx, p)
+				if user.ID == userID {
+					found = p
+				}
+			}
+			if found != nil {
+				return found, nil
+			}
+		}
+	}
+
+	peer, err := m.scanDialogsFor(ctx, api, userID)
+	if err != nil {
+		return nil, err
+	}
+	p, ok := peer.(*tg.InputPeerUser)
+	if !ok {
+		return nil, fmt.Errorf("%w: %d", domain.ErrChatNotFound, userID)
+	}
+	return p, nil
+}
+
+func findChat(chats []tg.ChatClass, id int64) tg.ChatClass {
+	for _, c := range chats {
+		if c.GetID() == id {
+			return c
+		}
+	}
+	return nil
+}
@@ -0,0 +1,346 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"telegram-api/internal/domain"
+
+	"github.com/gotd/td/telegram"
+	"github.com/gotd/td/tg"
+	"github.com/gotd/td/tgerr"
+)
+
+// ==================== JOIN / LEAVE ====================
+
+// JoinChat une la cuenta a un chat público (@username, t.me/username) o
+// privado (t.me/+hash, t.me/joinchat/hash)
+func (m *ClientManager) JoinChat(ctx context.Context, client *telegram.Client, link string) (*domain.JoinedChat, error) {
+	api := client.API()
+
+	hash, username, err := parseInviteLink(link)
+	if err != nil {
+		return nil, err
+	}
+
+	if hash != "" {
+		updates, err := api.MessagesImportChatInvite(ctx, hash)
+		switch {
+		case tgerr.Is(err, "INVITE_REQUEST_SENT"):
+			return &domain.JoinedChat{Pending: true}, nil
+		case tgerr.Is(err, "USER_ALREADY_PARTICIPANT"):
+			preview, err := m.checkInvite(ctx, api, hash)
+			if err != nil {
+				return nil, err
+			}
+			return &domain.JoinedChat{ID: preview.ID, Type: preview.Type, Title: preview.Title, AlreadyMember: true}, nil
+		case err != nil:
+			return nil, fmt.Errorf("import chat invite: %w", err)
+		}
+
+		for _, c := range updatesChats(updates) {
+			if joined := joinedFromChat(c); joined != nil {
+				return joined, nil
+			}
+		}
+		return &domain.JoinedChat{}, nil
+	}
+
+	resolved, err := api.ContactsResolveUsername(ctx, &tg.ContactsResolveUsernameRequest{Username: username})
+	if err != nil {
+		return nil, fmt.Errorf("resolve username: %w", err)
+	}
+
+	p, ok := resolved.Peer.(*tg.PeerChannel)
+	if !ok {
+		return nil, fmt.Errorf("%w: @%s no es un grupo ni canal", domain.ErrInvalidInput, username)
+	}
+	_, channels := buildChatMaps(resolved.Chats)
+	ch, ok := channels[p.ChannelID]
+	if !ok {
+		return nil, fmt.Errorf("%w: @%s", domain.ErrChatNotFound, username)
+	}
+
+	if !ch.Left {
+		joined := joinedFromChat(ch)
+		joined.AlreadyMember = true
+		return joined, nil
+	}
+
+	_, err = api.ChannelsJoinChannel(ctx, &tg.InputChannel{ChannelID: ch.ID, AccessHash: ch.AccessHash})
+	if tgerr.Is(err, "INVITE_REQUEST_SENT") {
+		joined := joinedFromChat(ch)
+		joined.Pending = true
+		return joined, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("join channel: %w", err)
+	}
+
+	return joinedFromChat(ch), nil
+}
+
+// LeaveChat abandona un grupo, supergrupo o canal
+func (m *ClientManager) LeaveChat(ctx context.Context, client *telegram.Client, chatID int64) error {
+	api := client.API()
+
+	peer, err := m.resolveChatPeer(ctx, api, chatID)
+	if err != nil {
+		return err
+	}
+
+	switch p := peer.(type) {
+	case *tg.InputPeerChat:
+		_, err = api.MessagesDeleteChatUser(ctx, &tg.MessagesDeleteChatUserRequest{
+			ChatID: p.ChatID,
+			UserID: &tg.InputUserSelf{},
+		})
+	case *tg.InputPeerChannel:
+		_, err = api.ChannelsLeaveChannel(ctx, inputChannel(p))
+	}
+	if err != nil {
+		return fmt.Errorf("leave chat: %w", err)
+	}
+
+	return nil
+}
+
+// ==================== INVITE LINKS ====================
+
+// CreateInviteLink exporta un nuevo enlace de invitación con expiración y límite de uso opcionales
+func (m *ClientManager) CreateInviteLink(ctx context.Context, client *telegram.Client, chatID int64, req domain.CreateInviteLinkRequest) (*domain.InviteLink, error) {
+	api := client.API()
+
+	peer, err := m.resolveChatPeer(ctx, api, chatID)
+	if err != nil {
+		return nil, err
+	}
+
+	exportReq := &tg.MessagesExportChatInviteRequest{
+		Peer:          peer,
+		RequestNeeded: req.RequestNeeded,
+	}
+	if req.Title != "" {
+		exportReq.SetTitle(req.Title)
+	}
+	if req.ExpireDate > 0 {
+		exportReq.SetExpireDate(int(req.ExpireDate))
+	}
+	if req.UsageLimit > 0 && !req.RequestNeeded {
+		exportReq.SetUsageLimit(req.UsageLimit)
+	}
+
+	exported, err := api.MessagesExportChatInvite(ctx, exportReq)
+	if err != nil {
+		return nil, fmt.Errorf("export chat invite: %w", err)
+	}
+
+	invite, ok := exported.(*tg.ChatInviteExported)
+	if !ok {
+		return nil, fmt.Errorf("export chat invite: respuesta inesperada %T", exported)
+	}
+
+	return parseInviteExported(invite), nil
+}
+
+// ListInviteLinks lista los enlaces de invitación creados por la cuenta
+func (m *ClientManager) ListInviteLinks(ctx context.Context, client *telegram.Client, chatID int64, revoked bool) (*domain.InviteLinksResponse, error) {
+	api := client.API()
+
+	peer, err := m.resolveChatPeer(ctx, api, chatID)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := api.MessagesGetExportedChatInvites(ctx, &tg.MessagesGetExportedChatInvitesRequest{
+		Revoked: revoked,
+		Peer:    peer,
+		AdminID: &tg.InputUserSelf{},
+		Limit:   100,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("get exported chat invites: %w", err)
+	}
+
+	links := make([]domain.InviteLink, 0, len(result.Invites))
+	for _, inv := range result.Invites {
+		if exported, ok := inv.(*tg.ChatInviteExported); ok {
+			links = append(links, *parseInviteExported(exported))
+		}
+	}
+
+	return &domain.InviteLinksResponse{
+		Links:      links,
+		TotalCount: result.Count,
+	}, nil
+}
+
+// RevokeInviteLink revoca un enlace de invitación. Si el enlace era el permanente,
+// Telegram genera uno nuevo que se retorna en su lugar.
+func (m *ClientManager) RevokeInviteLink(ctx context.Context, client *telegram.Client, chatID int64, link string) (*domain.InviteLink, error) {
+	api := client.API()
+
+	peer, err := m.resolveChatPeer(ctx, api, chatID)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := api.MessagesEditExportedChatInvite(ctx, &tg.MessagesEditExportedChatInviteRequest{
+		Revoked: true,
+		Peer:    peer,
+		Link:    link,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("revoke chat invite: %w", err)
+	}
+
+	var invite tg.ExportedChatInviteClass
+	switch r := result.(type) {
+	case *tg.MessagesExportedChatInvite:
+		invite = r.Invite
+	case *tg.MessagesExportedChatInviteReplaced:
+		invite = r.NewInvite
+	}
+
+	exported, ok := invite.(*tg.ChatInviteExported)
+	if !ok {
+		return nil, fmt.Errorf("revoke chat invite: respuesta inesperada %T", invite)
+	}
+
+	return parseInviteExported(exported), nil
+}
+
+// ==================== PREVIEW ====================
+
+// checkInvite obtiene la vista previa de un enlace privado sin unirse
+func (m *ClientManager) checkInvite(ctx context.Context, api *tg.Client, hash string) (*domain.ResolvedPeer, error) {
+	invite, err := api.MessagesCheckChatInvite(ctx, hash)
+	if err != nil {
+		return nil, fmt.Errorf("check chat invite: %w", err)
+	}
+
+	switch inv := invite.(type) {
+	case *tg.ChatInvite:
+		chatType := domain.ChatTypeGroup
+		switch {
+		case inv.Broadcast:
+			chatType = domain.ChatTypeChannel
+		case inv.Megagroup:
+			chatType = domain.ChatTypeSupergroup
+		}
+		return &domain.ResolvedPeer{
+			Type:          chatType,
+			Title:         inv.Title,
+			About:         inv.About,
+			MembersCount:  inv.ParticipantsCount,
+			IsVerified:    inv.Verified,
+			RequestNeeded: inv.RequestNeeded,
+		}, nil
+	case *tg.ChatInviteAlready:
+		return resolvedFromChat(inv.Chat, true), nil
+	case *tg.ChatInvitePeek:
+		return resolvedFromChat(inv.Chat, false), nil
+	}
+
+	return nil, fmt.Errorf("check chat invite: respuesta inesperada %T", invite)
+}
+
+// ==================== HELPERS ====================
+
+// parseInviteLink extrae el hash de un enlace privado o el username de uno público
+func parseInviteLink(link string) (hash, username string, err error) {
+	s := strings.TrimSpace(link)
+	for _, prefix := range []string{"https://", "http://"} {
+		s = strings.TrimPrefix(s, prefix)
+	}
+	for _, prefix := range []string{"www.", "t.me/", "telegram.me/", "telegram.dog/"} {
+		s = strings.TrimPrefix(s, prefix)
+	}
+
+	switch {
+	case strings.HasPrefix(s, "tg://join?invite="):
+		hash = strings.TrimPrefix(s, "tg://join?invite=")
+	case strings.HasPrefix(s, "+"):
+		hash = strings.TrimPrefix(s, "+")
+	case strings.HasPrefix(s, "joinchat/"):
+		hash = strings.TrimPrefix(s, "joinchat/")
+	case strings.HasPrefix(s, "tg://resolve?domain="):
+		username = strings.TrimPrefix(s, "tg://resolve?domain=")
+	default:
+		username = strings.TrimPrefix(s, "@")
+	}
+
+	// Descartar query string y segmentos extra (ej. t.me/canal/123)
+	hash = strings.SplitN(strings.SplitN(hash, "?", 2)[0], "/", 2)[0]
+	username = strings.SplitN(strings.SplitN(username, "?", 2)[0], "/", 2)[0]
+
+	if hash == "" && username == "" {
+		return "", "", fmt.Errorf("%w: enlace de invitación inválido: %s", domain.ErrInvalidInput, link)
+	}
+	return hash, username, nil
+}
+
+func joinedFromChat(c tg.ChatClass) *domain.JoinedChat {
+	switch chat := c.(type) {
+	case *tg.Chat:
+		return &domain.JoinedChat{ID: -chat.ID, Type: domain.ChatTypeGroup, Title: chat.Title}
+	case *tg.Channel:
+		chatType := domain.ChatTypeSupergroup
+		if chat.Broadcast {
+			chatType = domain.ChatTypeChannel
+		}
+		return &domain.JoinedChat{ID: -(channelIDOffset + chat.ID), Type: chatType, Title: chat.Title}
+	}
+	return nil
+}
+
+func resolvedFromChat(c tg.ChatClass, isMember bool) *domain.ResolvedPeer {
+	switch chat := c.(type) {
+	case *tg.Chat:
+		return &domain.ResolvedPeer{
+			ID:           -chat.ID,
+			Type:         domain.ChatTypeGroup,
+			Title:        chat.Title,
+			MembersCount: chat.ParticipantsCount,
+			IsMember:     isMember,
+		}
+	case *tg.Channel:
+		chatType := domain.ChatTypeSupergroup
+		if chat.Broadcast {
+			chatType = domain.ChatTypeChannel
+		}
+		count, _ := chat.GetParticipantsCount()
+		return &domain.ResolvedPeer{
+			ID:           -(channelIDOffset + chat.ID),
+			Type:         chatType,
+			Username:     chat.Username,
+			Title:        chat.Title,
+			AccessHash:   chat.AccessHash,
+			IsVerified:   chat.Verified,
+			MembersCount: count,
+			IsMember:     isMember,
+		}
+	}
+	return &domain.ResolvedPeer{IsMember: isMember}
+}
+
+func parseInviteExported(inv *tg.ChatInviteExported) *domain.InviteLink {
+	link := &domain.InviteLink{
+		Link:          inv.Link,
+		Title:         inv.Title,
+		IsPermanent:   inv.Permanent,
+		IsRevoked:     inv.Revoked,
+		RequestNeeded: inv.RequestNeeded,
+		UsageLimit:    inv.UsageLimit,
+		Usage:         inv.Usage,
+		Requested:     inv.Requested,
+		CreatedAt:     time.Unix(int64(inv.Date), 0),
+	}
+	if inv.ExpireDate > 0 {
+		exp := time.Unix(int64(inv.ExpireDate), 0)
+		link.ExpireDate = &exp
+	}
+	return link
+}
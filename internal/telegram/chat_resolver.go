@@ -72,56 +72,6 @@ HasMore:    len(dialogs) == req.Limit,
 }, nil
 }
 
-func (m *ClientManager) GetChatInfo(ctx context.Context, client *telegram.Client, chatID int64) (*domain.Chat, error) {
-api := client.API()
-
-if chatID > 0 {
-users, err := api.UsersGetUsers(ctx, []tg.InputUserClass{
-&tg.InputUser{UserID: chatID},
-})
-if err == nil && len(users) > 0 {
-if user, ok := users[0].(*tg.User); ok {
-return &domain.Chat{
-ID:        user.ID,
-Type:      domain.ChatTypePrivate,
-FirstName: user.FirstName,
-LastName:  user.LastName,
-Username:  user.Username,
-}, nil
-}
-}
-}
-
-if chatID < 0 {
-channelID := -chatID
-if channelID > 1000000000000 {
-channelID = channelID - 1000000000000
-}
-
-result, err := api.ChannelsGetChannels(ctx, []tg.InputChannelClass{
-&tg.InputChannel{ChannelID: channelID},
-})
-if err == nil {
-if chats, ok := result.(*tg.MessagesChats); ok && len(chats.Chats) > 0 {
-if ch, ok := chats.Chats[0].(*tg.Channel); ok {
-chatType := domain.ChatTypeSupergroup
-if ch.Broadcast {
-chatType = domain.ChatTypeChannel
-}
-return &domain.Chat{
-ID:       ch.ID,
-Type:     chatType,
-Title:    ch.Title,
-Username: ch.Username,
-}, nil
-}
-}
-}
-}
-
-return nil, fmt.Errorf("chat not found: %d", chatID)
-}
-
 func (m *ClientManager) GetChatHistory(ctx context.Context, client *telegram.Client, chatID int64, req domain.GetHistoryRequest) (*domain.HistoryResponse, error) {
 api := client.API()
 
@@ -293,7 +243,18 @@ IsBot:      user.Bot,
 return nil, fmt.Errorf("peer not found for phone: %s", phone)
 }
 
-return nil, fmt.Errorf("username or phone required")
+if req.InviteLink != "" {
+hash, username, err := parseInviteLink(req.InviteLink)
+if err != nil {
+return nil, err
+}
+if hash != "" {
+return m.checkInvite(ctx, api, hash)
+}
+return m.ResolveUsername(ctx, client, domain.ResolveRequest{Username: username})
+}
+
+return nil, fmt.Errorf("username, phone or invite_link required")
 }
 
 // ==================== HELPERS ====================
@@ -302,7 +263,11 @@ func (m *ClientManager) parseDialog(dialog *tg.Dialog, users map[int64]*tg.User,
 chat := &domain.Chat{
 UnreadCount: dialog.UnreadCount,
 IsPinned:    dialog.Pinned,
-IsArchived:  dialog.FolderID == 1,
+IsArchived:  dialog.FolderID == archiveFolderID,
+}
+
+if muteUntil, ok := dialog.NotifySettings.GetMuteUntil(); ok {
+chat.IsMuted = int64(muteUntil) > time.Now().Unix()
 }
 
 if msg, ok := messages[dialog.TopMessage]; ok {
@@ -322,13 +287,13 @@ chat.Username = user.Username
 }
 case *tg.PeerChat:
 if c, ok := chats[p.ChatID]; ok {
-chat.ID = c.ID
+chat.ID = -c.ID
 chat.Type = domain.ChatTypeGroup
 chat.Title = c.Title
 }
 case *tg.PeerChannel:
 if ch, ok := channels[p.ChannelID]; ok {
-chat.ID = ch.ID
+chat.ID = -(channelIDOffset + ch.ID)
 if ch.Broadcast {
 chat.Type = domain.ChatTypeChannel
 } else {
@@ -344,17 +309,12 @@ return nil
 return chat
 }
 
+// resolvePeerByID convierte un ID en formato Bot API a un InputPeer con su access_hash
 func (m *ClientManager) resolvePeerByID(ctx context.Context, api *tg.Client, chatID int64) (tg.InputPeerClass, error) {
 if chatID > 0 {
-return &tg.InputPeerUser{UserID: chatID}, nil
+return m.resolveUserByID(ctx, api, chatID)
 }
-
-channelID := -chatID
-if channelID > 1000000000000 {
-channelID = channelID - 1000000000000
-}
-
-return &tg.InputPeerChannel{ChannelID: channelID}, nil
+return m.resolveChatPeer(ctx, api, chatID)
 }
 
 func buildUserMap(users []tg.UserClass) map[int64]*tg.User {
@@ -435,6 +395,10 @@ if fwd, ok := msg.GetFwdFrom(); ok && fwd.FromID != nil {
 cm.ForwardFrom = "forwarded"
 }
 
+if reactions, ok := msg.GetReactions(); ok {
+cm.Reactions = parseReactions(reactions)
+}
+
 return cm
 }
 
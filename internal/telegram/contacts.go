@@ -0,0 +1,258 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"telegram-api/internal/config"
+	"telegram-api/internal/domain"
+	"telegram-api/pkg/logger"
+
+	"github.com/gotd/td/telegram"
+	"github.com/gotd/td/tg"
+)
+
+// importBatchSize máximo de contactos por llamada a contacts.importContacts
+const importBatchSize = 100
+
+// ==================== IMPORT / DELETE ====================
+
+// ImportContacts importa contactos en lote con sus nombres reales
+func (m *ClientManager) ImportContacts(ctx context.Context, client *telegram.Client, contacts []domain.ImportContact) (*domain.ImportContactsResult, error) {
+	api := client.API()
+	result := &domain.ImportContactsResult{Imported: []domain.Contact{}}
+
+	for start := 0; start < len(contacts); start += importBatchSize {
+		end := min(start+importBatchSize, len(contacts))
+		batch := contacts[start:end]
+
+		input := make([]tg.InputPhoneContact, 0, len(batch))
+		for i, c := range batch {
+			input = append(input, tg.InputPhoneContact{
+				ClientID:  int64(start + i),
+				Phone:     c.Phone,
+				FirstName: c.FirstName,
+				LastName:  c.LastName,
+			})
+		}
+
+		imported, err := api.ContactsImportContacts(ctx, input)
+		if err != nil {
+			return nil, fmt.Errorf("import contacts: %w", err)
+		}
+
+		users := buildUserMap(imported.Users)
+		found := make(map[int64]bool, len(imported.Imported))
+		for _, ic := range imported.Imported {
+			found[ic.ClientID] = true
+			if user, ok := users[ic.UserID]; ok {
+				result.Imported = append(result.Imported, contactFromUser(user))
+			}
+		}
+
+		retry := make(map[int64]bool, len(imported.RetryContacts))
+		for _, id := range imported.RetryContacts {
+			retry[id] = true
+			result.RetryLater = append(result.RetryLater, contacts[id].Phone)
+		}
+
+		for i, c := range batch {
+			id := int64(start + i)
+			if !found[id] && !retry[id] {
+				result.NotOnTelegram = append(result.NotOnTelegram, c.Phone)
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// DeleteContacts elimina contactos por teléfono, @username o ID
+func (m *ClientManager) DeleteContacts(ctx context.Context, client *telegram.Client, users []string) error {
+	api := client.API()
+
+	var phones []string
+	var inputUsers []tg.InputUserClass
+	for _, u := range users {
+		if strings.HasPrefix(u, "+") {
+			phones = append(phones, strings.TrimPrefix(u, "+"))
+			continue
+		}
+		userPeer, err := m.resolveUserPeer(ctx, api, u)
+		if err != nil {
+			return err
+		}
+		inputUsers = append(inputUsers, inputUser(userPeer))
+	}
+
+	if len(phones) > 0 {
+		if _, err := api.ContactsDeleteByPhones(ctx, phones); err != nil {
+			return fmt.Errorf("delete by phones: %w", err)
+		}
+	}
+	if len(inputUsers) > 0 {
+		if _, err := api.ContactsDeleteContacts(ctx, inputUsers); err != nil {
+			return fmt.Errorf("delete contacts: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// ==================== BLOCK ====================
+
+// SetBlocked bloquea o desbloquea a un usuario
+func (m *ClientManager) SetBlocked(ctx context.Context, client *telegram.Client, user string, blocked bool) error {
+	api := client.API()
+
+	userPeer, err := m.resolveUserPeer(ctx, api, user)
+	if err != nil {
+		return err
+	}
+
+	if blocked {
+		_, err = api.ContactsBlock(ctx, &tg.ContactsBlockRequest{ID: userPeer})
+	} else {
+		_, err = api.ContactsUnblock(ctx, &tg.ContactsUnblockRequest{ID: userPeer})
+	}
+	if err != nil {
+		return fmt.Errorf("set blocked: %w", err)
+	}
+
+	return nil
+}
+
+// GetBlocked lista los usuarios bloqueados
+func (m *ClientManager) GetBlocked(ctx context.Context, client *telegram.Client, offset, limit int) (*domain.BlockedUsersResponse, error) {
+	if limit <= 0 || limit > 100 {
+		limit = 50
+	}
+
+	result, err := client.API().ContactsGetBlocked(ctx, &tg.ContactsGetBlockedRequest{
+		Offset: offset,
+		Limit:  limit,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("get blocked: %w", err)
+	}
+
+	var blocked []tg.PeerBlocked
+	var userList []tg.UserClass
+	total := 0
+
+	switch b := result.(type) {
+	case *tg.ContactsBlocked:
+		blocked, userList, total = b.Blocked, b.Users, len(b.Blocked)
+	case *tg.ContactsBlockedSlice:
+		blocked, userList, total = b.Blocked, b.Users, b.Count
+	}
+
+	users := buildUserMap(userList)
+	list := make([]domain.BlockedUser, 0, len(blocked))
+	for _, pb := range blocked {
+		p, ok := pb.PeerID.(*tg.PeerUser)
+		if !ok {
+			continue
+		}
+		bu := domain.BlockedUser{
+			ID:        p.UserID,
+			BlockedAt: time.Unix(int64(pb.Date), 0),
+		}
+		if user, ok := users[p.UserID]; ok {
+			bu.FirstName = user.FirstName
+			bu.LastName = user.LastName
+			bu.Username = user.Username
+		}
+		list = append(list, bu)
+	}
+
+	return &domain.BlockedUsersResponse{
+		Users:      list,
+		TotalCount: total,
+	}, nil
+}
+
+// ==================== PHONE RESOLUTION ====================
+
+// resolvePhone obtiene el usuario de un +phone. En modo "resolve" usa
+// contacts.resolvePhone y la agenda actual; solo si no basta importa el número
+// temporalmente y lo elimina después para no ensuciar la agenda.
+func (m *ClientManager) resolvePhone(ctx context.Context, api *tg.Client, phone string) (*tg.InputPeerUser, error) {
+	digits := strings.TrimPrefix(phone, "+")
+
+	if m.cfg.Contacts.PhoneResolveMode == config.PhoneResolveImport {
+		user, err := m.importPhone(ctx, api, phone)
+		if err != nil {
+			return nil, err
+		}
+		return &tg.InputPeerUser{UserID: user.ID, AccessHash: user.AccessHash}, nil
+	}
+
+	if resolved, err := api.ContactsResolvePhone(ctx, digits); err == nil {
+		if p, ok := resolved.Peer.(*tg.PeerUser); ok {
+			if user, ok := buildUserMap(resolved.Users)[p.UserID]; ok {
+				return &tg.InputPeerUser{UserID: user.ID, AccessHash: user.AccessHash}, nil
+			}
+		}
+	}
+
+	// Si ya es contacto no se reimporta (se sobrescribiría su nombre)
+	if contacts, err := api.ContactsGetContacts(ctx, 0); err == nil {
+		if c, ok := contacts.(*tg.ContactsContacts); ok {
+			for _, u := range c.Users {
+				if user, ok := u.(*tg.User); ok && user.Phone == digits {
+					return &tg.InputPeerUser{UserID: user.ID, AccessHash: user.AccessHash}, nil
+				}
+			}
+		}
+	}
+
+	user, err := m.importPhone(ctx, api, phone)
+	if err != nil {
+		return nil, err
+	}
+
+	// El access_hash sigue siendo válido tras eliminar el contacto
+	if _, err := api.ContactsDeleteContacts(ctx, []tg.InputUserClass{
+		&tg.InputUser{UserID: user.ID, AccessHash: user.AccessHash},
+	}); err != nil {
+		logger.Warn().Err(err).Int64("user_id", user.ID).Msg("error eliminando contacto temporal")
+	}
+
+	return &tg.InputPeerUser{UserID: user.ID, AccessHash: user.AccessHash}, nil
+}
+
+func (m *ClientManager) importPhone(ctx context.Context, api *tg.Client, phone string) (*tg.User, error) {
+	imported, err := api.ContactsImportContacts(ctx, []tg.InputPhoneContact{
+		{Phone: phone, FirstName: phone},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("import contact: %w", err)
+	}
+
+	for _, u := range imported.Users {
+		if user, ok := u.(*tg.User); ok {
+			return user, nil
+		}
+	}
+
+	return nil, fmt.Errorf("phone not found: %s", phone)
+}
+
+func contactFromUser(user *tg.User) domain.Contact {
+	contact := domain.Contact{
+		ID:         user.ID,
+		Phone:      user.Phone,
+		FirstName:  user.FirstName,
+		LastName:   user.LastName,
+		Username:   user.Username,
+		IsMutual:   user.MutualContact,
+		AccessHash: user.AccessHash,
+	}
+	if user.Status != nil {
+		contact.Status, contact.LastSeenAt = parseUserStatus(user.Status)
+	}
+	return contact
+}
@@ -0,0 +1,328 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+
+	"telegram-api/internal/domain"
+
+	"github.com/gotd/td/telegram"
+	"github.com/gotd/td/telegram/query"
+	"github.com/gotd/td/tg"
+)
+
+// archiveFolderID carpeta de archivo de Telegram (0 = lista principal)
+const archiveFolderID = 1
+
+// ==================== DIALOG STATE ====================
+
+// ToggleDialogPin fija o desfija un chat en la lista de diálogos
+func (m *ClientManager) ToggleDialogPin(ctx context.Context, client *telegram.Client, chatID int64, pinned bool) error {
+	api := client.API()
+
+	peer, err := m.resolvePeerByID(ctx, api, chatID)
+	if err != nil {
+		return err
+	}
+
+	if _, err := api.MessagesToggleDialogPin(ctx, &tg.MessagesToggleDialogPinRequest{
+		Pinned: pinned,
+		Peer:   &tg.InputDialogPeer{Peer: peer},
+	}); err != nil {
+		return fmt.Errorf("toggle dialog pin: %w", err)
+	}
+
+	return nil
+}
+
+// UpdatePinnedMessage fija o desfija un mensaje dentro del chat
+func (m *ClientManager) UpdatePinnedMessage(ctx context.Context, client *telegram.Client, chatID int64, msgID int, unpin bool, req domain.PinMessageRequest) error {
+	api := client.API()
+
+	peer, err := m.resolvePeerByID(ctx, api, chatID)
+	if err != nil {
+		return err
+	}
+
+	if _, err := api.MessagesUpdatePinnedMessage(ctx, &tg.MessagesUpdatePinnedMessageRequest{
+		Silent:    req.Silent,
+		Unpin:     unpin,
+		PmOneside: req.OnlyForMe,
+		Peer:      peer,
+		ID:        msgID,
+	}); err != nil {
+		return fmt.Errorf("update pinned message: %w", err)
+	}
+
+	return nil
+}
+
+// MuteDialog silencia un chat hasta muteUntil (0 = para siempre) o lo reactiva
+func (m *ClientManager) MuteDialog(ctx context.Context, client *telegram.Client, chatID int64, req domain.MuteDialogRequest) error {
+	api := client.API()
+
+	peer, err := m.resolvePeerByID(ctx, api, chatID)
+	if err != nil {
+		return err
+	}
+
+	settings := tg.InputPeerNotifySettings{}
+	switch {
+	case !req.Muted:
+		settings.SetMuteUntil(0)
+	case req.MuteUntil > 0:
+		settings.SetMuteUntil(int(req.MuteUntil))
+	default:
+		settings.SetMuteUntil(1<<31 - 1)
+	}
+
+	if _, err := api.AccountUpdateNotifySettings(ctx, &tg.AccountUpdateNotifySettingsRequest{
+		Peer:     &tg.InputNotifyPeer{Peer: peer},
+		Settings: settings,
+	}); err != nil {
+		return fmt.Errorf("update notify settings: %w", err)
+	}
+
+	return nil
+}
+
+// ArchiveDialog mueve un chat al archivo o lo devuelve a la lista principal
+func (m *ClientManager) ArchiveDialog(ctx context.Context, client *telegram.Client, chatID int64, archived bool) error {
+	api := client.API()
+
+	peer, err := m.resolvePeerByID(ctx, api, chatID)
+	if err != nil {
+		return err
+	}
+
+	folderID := 0
+	if archived {
+		folderID = archiveFolderID
+	}
+
+	if _, err := api.FoldersEditPeerFolders(ctx, []tg.InputFolderPeer{
+		{Peer: peer, FolderID: folderID},
+	}); err != nil {
+		return fmt.Errorf("edit peer folders: %w", err)
+	}
+
+	return nil
+}
+
+// MarkDialogUnread marca o desmarca un chat como no leído
+func (m *ClientManager) MarkDialogUnread(ctx context.Context, client *telegram.Client, chatID int64, unread bool) error {
+	api := client.API()
+
+	peer, err := m.resolvePeerByID(ctx, api, chatID)
+	if err != nil {
+		return err
+	}
+
+	if _, err := api.MessagesMarkDialogUnread(ctx, &tg.MessagesMarkDialogUnreadRequest{
+		Unread: unread,
+		Peer:   &tg.InputDialogPeer{Peer: peer},
+	}); err != nil {
+		return fmt.Errorf("mark dialog unread: %w", err)
+	}
+
+	return nil
+}
+
+// ==================== FOLDERS ====================
+
+// GetChatFolders lista las carpetas de chats de la cuenta
+func (m *ClientManager) GetChatFolders(ctx context.Context, client *telegram.Client) (*domain.ChatFoldersResponse, error) {
+	result, err := client.API().MessagesGetDialogFilters(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("get dialog filters: %w", err)
+	}
+
+	folders := make([]domain.ChatFolder, 0, len(result.Filters))
+	for _, f := range result.Filters {
+		switch filter := f.(type) {
+		case *tg.DialogFilter:
+			folders = append(folders, domain.ChatFolder{
+				ID:              filter.ID,
+				Title:           filter.Title.Text,
+				Emoticon:        filter.Emoticon,
+				Contacts:        filter.Contacts,
+				NonContacts:     filter.NonContacts,
+				Groups:          filter.Groups,
+				Broadcasts:      filter.Broadcasts,
+				Bots:            filter.Bots,
+				ExcludeMuted:    filter.ExcludeMuted,
+				ExcludeRead:     filter.ExcludeRead,
+				ExcludeArchived: filter.ExcludeArchived,
+				PinnedChats:     inputPeerIDs(filter.PinnedPeers),
+				IncludeChats:    inputPeerIDs(filter.IncludePeers),
+				ExcludeChats:    inputPeerIDs(filter.ExcludePeers),
+			})
+		case *tg.DialogFilterChatlist:
+			folders = append(folders, domain.ChatFolder{
+				ID:           filter.ID,
+				Title:        filter.Title.Text,
+				Emoticon:     filter.Emoticon,
+				IsChatlist:   true,
+				PinnedChats:  inputPeerIDs(filter.PinnedPeers),
+				IncludeChats: inputPeerIDs(filter.IncludePeers),
+			})
+		}
+	}
+
+	return &domain.ChatFoldersResponse{Folders: folders}, nil
+}
+
+// SaveChatFolder crea (folderID = 0) o reemplaza una carpeta de chats
+func (m *ClientManager) SaveChatFolder(ctx context.Context, client *telegram.Client, folderID int, req domain.ChatFolderRequest) (*domain.ChatFolder, error) {
+	api := client.API()
+
+	existing, err := api.MessagesGetDialogFilters(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("get dialog filters: %w", err)
+	}
+
+	used := make(map[int]bool)
+	for _, f := range existing.Filters {
+		if filter, ok := f.(*tg.DialogFilter); ok {
+			used[filter.ID] = true
+		}
+	}
+
+	if folderID == 0 {
+		// 0 y 1 están reservados (todos los chats / archivo)
+		for id := 2; id < 256; id++ {
+			if !used[id] {
+				folderID = id
+				break
+			}
+		}
+		if folderID == 0 {
+			return nil, fmt.Errorf("%w: se alcanzó el máximo de carpetas", domain.ErrInvalidInput)
+		}
+	} else if !used[folderID] {
+		return nil, fmt.Errorf("%w: %d", domain.ErrFolderNotFound, folderID)
+	}
+
+	peers, err := m.dialogPeers(ctx, api)
+	if err != nil {
+		return nil, err
+	}
+
+	pinned, err := lookupPeers(peers, req.PinnedChats)
+	if err != nil {
+		return nil, err
+	}
+	include, err := lookupPeers(peers, req.IncludeChats)
+	if err != nil {
+		return nil, err
+	}
+	exclude, err := lookupPeers(peers, req.ExcludeChats)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := &tg.DialogFilter{
+		ID:              folderID,
+		Title:           tg.TextWithEntities{Text: req.Title},
+		Contacts:        req.Contacts,
+		NonContacts:     req.NonContacts,
+		Groups:          req.Groups,
+		Broadcasts:      req.Broadcasts,
+		Bots:            req.Bots,
+		ExcludeMuted:    req.ExcludeMuted,
+		ExcludeRead:     req.ExcludeRead,
+		ExcludeArchived: req.ExcludeArchived,
+		PinnedPeers:     pinned,
+		IncludePeers:    include,
+		ExcludePeers:    exclude,
+	}
+	if req.Emoticon != "" {
+		filter.SetEmoticon(req.Emoticon)
+	}
+
+	update := &tg.MessagesUpdateDialogFilterRequest{ID: folderID}
+	update.SetFilter(filter)
+	if _, err := api.MessagesUpdateDialogFilter(ctx, update); err != nil {
+		return nil, fmt.Errorf("update dialog filter: %w", err)
+	}
+
+	return &domain.ChatFolder{
+		ID:              folderID,
+		Title:           req.Title,
+		Emoticon:        req.Emoticon,
+		Contacts:        req.Contacts,
+		NonContacts:     req.NonContacts,
+		Groups:          req.Groups,
+		Broadcasts:      req.Broadcasts,
+		Bots:            req.Bots,
+		ExcludeMuted:    req.ExcludeMuted,
+		ExcludeRead:     req.ExcludeRead,
+		ExcludeArchived: req.ExcludeArchived,
+		PinnedChats:     req.PinnedChats,
+		IncludeChats:    req.IncludeChats,
+		ExcludeChats:    req.ExcludeChats,
+	}, nil
+}
+
+// DeleteChatFolder elimina una carpeta (los chats no se modifican)
+func (m *ClientManager) DeleteChatFolder(ctx context.Context, client *telegram.Client, folderID int) error {
+	if _, err := client.API().MessagesUpdateDialogFilter(ctx, &tg.MessagesUpdateDialogFilterRequest{ID: folderID}); err != nil {
+		return fmt.Errorf("delete dialog filter: %w", err)
+	}
+	return nil
+}
+
+// ==================== HELPERS ====================
+
+// dialogPeers indexa los InputPeer de todos los diálogos por ID en formato Bot API
+func (m *ClientManager) dialogPeers(ctx context.Context, api *tg.Client) (map[int64]tg.InputPeerClass, error) {
+	peers := make(map[int64]tg.InputPeerClass)
+
+	iter := query.GetDialogs(api).BatchSize(100).Iter()
+	for iter.Next(ctx) {
+		peer := iter.Value().Peer
+		if id := inputPeerID(peer); id != 0 {
+			peers[id] = peer
+		}
+	}
+	if err := iter.Err(); err != nil {
+		return nil, fmt.Errorf("iterate dialogs: %w", err)
+	}
+
+	return peers, nil
+}
+
+func lookupPeers(peers map[int64]tg.InputPeerClass, ids []int64) ([]tg.InputPeerClass, error) {
+	result := make([]tg.InputPeerClass, 0, len(ids))
+	for _, id := range ids {
+		peer, ok := peers[id]
+		if !ok {
+			return nil, fmt.Errorf("%w: %d", domain.ErrChatNotFound, id)
+		}
+		result = append(result, peer)
+	}
+	return result, nil
+}
+
+// inputPeerID convierte un InputPeer al ID en formato Bot API
+func inputPeerID(peer tg.InputPeerClass) int64 {
+	switch p := peer.(type) {
+	case *tg.InputPeerUser:
+		return p.UserID
+	case *tg.InputPeerChat:
+		return -p.ChatID
+	case *tg.InputPeerChannel:
+		return -(channelIDOffset + p.ChannelID)
+	}
+	return 0
+}
+
+func inputPeerIDs(peers []tg.InputPeerClass) []int64 {
+	var ids []int64
+	for _, p := range peers {
+		if id := inputPeerID(p); id != 0 {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
@@ -59,7 +59,9 @@ func (d *EventDispatcher) Dispatch(sessionID uuid.UUID, eventType domain.EventTy
 
 	select {
 	case d.eventChan <- &dispatchJob{SessionID: sessionID, Event: event}:
+		eventsDispatched.WithLabelValues(string(eventType)).Inc()
 	default:
+		eventsDropped.WithLabelValues(string(eventType)).Inc()
 		logger.Warn().
 			Str("session_id", sessionID.String()).
 			Str("event_type", string(eventType)).
@@ -112,6 +114,10 @@ func (d *EventDispatcher) sendToWebhook(job *dispatchJob) {
 		signature := d.signPayload(payload, webhook.Secret)
 		req.Header.Set("X-Telegram-Signature", signature)
 	}
+	// Durante la ventana de rotación se firma también con el secreto anterior
+	if previous := webhook.ActivePreviousSecret(time.Now()); previous != "" {
+		req.Header.Set("X-Telegram-Signature-Previous", d.signPayload(payload, previous))
+	}
 
 	// Enviar con retries
 	maxRetries := webhook.MaxRetries
@@ -119,17 +125,20 @@ func (d *EventDispatcher) sendToWebhook(job *dispatchJob) {
 		maxRetries = 3
 	}
 
+	start := time.Now()
 	var lastErr error
 	for attempt := 1; attempt <= maxRetries; attempt++ {
 		resp, err := d.httpClient.Do(req)
 		if err != nil {
 			lastErr = err
+			webhookAttemptFailures.WithLabelValues("network").Inc()
 			time.Sleep(time.Duration(attempt) * time.Second) // Backoff
 			continue
 		}
 		resp.Body.Close()
 
 		if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+			webhookDeliveryDuration.WithLabelValues("success").Observe(time.Since(start).Seconds())
 			logger.Debug().
 				Str("session_id", job.SessionID.String()).
 				Str("event_type", string(job.Event.Type)).
@@ -139,9 +148,12 @@ func (d *EventDispatcher) sendToWebhook(job *dispatchJob) {
 		}
 
 		lastErr = fmt.Errorf("webhook returned %d", resp.StatusCode)
+		webhookAttemptFailures.WithLabelValues(fmt.Sprintf("http_%dxx", resp.StatusCode/100)).Inc()
 		time.Sleep(time.Duration(attempt) * time.Second)
 	}
 
+	webhookDeliveryDuration.WithLabelValues("failure").Observe(time.Since(start).Seconds())
+
 	// Falló después de todos los intentos
 	logger.Error().
 		Err(lastErr).
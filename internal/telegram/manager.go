@@ -22,12 +22,16 @@ type ClientManager struct {
 	cfg     *config.Config
 	repo    domain.SessionRepository
 	crypter *crypto.Crypter
+	peers   peerCache
+	actions chatActions
 	mu      sync.RWMutex
 }
 
 type TGUser struct {
 	ID       int64
 	Username string
+	Phone    string
+	Bot      bool
 }
 
 type QRAuthResult struct {
@@ -37,10 +41,14 @@ type QRAuthResult struct {
 }
 
 func NewManager(cfg *config.Config, repo domain.SessionRepository) (*ClientManager, error) {
-	crypter, err := crypto.NewCrypter(cfg.Encryption.Key)
+	crypter, err := crypto.NewCrypter(cfg.Encryption.Key, cfg.Encryption.OldKeys...)
 	if err != nil {
 		return nil, fmt.Errorf("crypto init: %w", err)
 	}
+	logger.Info().
+		Str("key_id", crypter.PrimaryKeyID()).
+		Int("old_keys", len(cfg.Encryption.OldKeys)).
+		Msg("🔑 Claves de cifrado cargadas")
 
 	return &ClientManager{
 		cfg:     cfg,
@@ -52,6 +60,7 @@ func NewManager(cfg *config.Config, repo domain.SessionRepository) (*ClientManag
 func (m *ClientManager) newClient(apiID int, apiHash, sessionName string, storage telegram.SessionStorage) *telegram.Client {
 	return telegram.NewClient(apiID, apiHash, telegram.Options{
 		SessionStorage: storage,
+		Middlewares:    []telegram.Middleware{rpcMetrics},
 		Device: telegram.DeviceConfig{
 			DeviceModel:    sessionName,
 			SystemVersion:  "1.0",
@@ -132,6 +141,49 @@ func (m *ClientManager) SignIn(ctx context.Context, apiID int, apiHash, phone, c
 	return user, sessionData, err
 }
 
+// ==================== BOT AUTH ====================
+
+// BotSignIn inicia sesión con un token de BotFather vía auth.importBotAuthorization
+// y retorna los datos de sesión listos para cifrar
+func (m *ClientManager) BotSignIn(ctx context.Context, apiID int, apiHash, botToken string) (*TGUser, []byte, error) {
+	storage := &session.StorageMemory{}
+	client := m.newClient(apiID, apiHash, "Bot Session", storage)
+
+	var user *TGUser
+	var sessionData []byte
+
+	err := client.Run(ctx, func(ctx context.Context) error {
+		auth, err := client.API().AuthImportBotAuthorization(ctx, &tg.AuthImportBotAuthorizationRequest{
+			APIID:        apiID,
+			APIHash:      apiHash,
+			BotAuthToken: botToken,
+		})
+		if err != nil {
+			return err
+		}
+
+		a, ok := auth.(*tg.AuthAuthorization)
+		if !ok {
+			return fmt.Errorf("unexpected auth response")
+		}
+
+		u, ok := a.User.(*tg.User)
+		if !ok {
+			return fmt.Errorf("unexpected user type")
+		}
+		user = &TGUser{ID: u.ID, Username: u.Username}
+
+		data, err := storage.Bytes(nil)
+		if err == nil {
+			sessionData = data
+		}
+
+		return nil
+	})
+
+	return user, sessionData, err
+}
+
 // ==================== QR AUTH ====================
 
 func (m *ClientManager) StartQRAuth(
@@ -375,4 +427,19 @@ func (m *ClientManager) Encrypt(data []byte) ([]byte, error) {
 
 func (m *ClientManager) Decrypt(data []byte) ([]byte, error) {
 	return m.crypter.Decrypt(data)
+}
+
+// NeedsReencrypt indica si el dato no está cifrado con la clave primaria actual
+func (m *ClientManager) NeedsReencrypt(data []byte) bool {
+	return m.crypter.NeedsReencrypt(data)
+}
+
+// Reencrypt re-cifra un dato con la clave primaria
+func (m *ClientManager) Reencrypt(data []byte) ([]byte, error) {
+	return m.crypter.Reencrypt(data)
+}
+
+// EncryptionKeyID retorna el identificador de la clave primaria
+func (m *ClientManager) EncryptionKeyID() string {
+	return m.crypter.PrimaryKeyID()
 }
\ No newline at end of file
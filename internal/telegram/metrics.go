@@ -0,0 +1,56 @@
+package telegram
+
+import (
+	"context"
+
+	"github.com/gotd/td/bin"
+	"github.com/gotd/td/telegram"
+	"github.com/gotd/td/tg"
+	"github.com/gotd/td/tgerr"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	eventsDispatched = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "telegram_events_dispatched_total",
+		Help: "Eventos encolados para los webhooks por tipo",
+	}, []string{"type"})
+	eventsDropped = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "telegram_events_dropped_total",
+		Help: "Eventos descartados por buffer lleno, por tipo",
+	}, []string{"type"})
+
+	webhookDeliveryDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "webhook_delivery_duration_seconds",
+		Help:    "Duración de la entrega de un evento a su webhook, reintentos incluidos, por resultado",
+		Buckets: []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60},
+	}, []string{"outcome"})
+	webhookAttemptFailures = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "webhook_delivery_attempt_failures_total",
+		Help: "Intentos de entrega fallidos por motivo (network o status HTTP)",
+	}, []string{"reason"})
+
+	rpcErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "telegram_rpc_errors_total",
+		Help: "Errores RPC devueltos por Telegram por tipo (FLOOD_WAIT, PEER_ID_INVALID...)",
+	}, []string{"type"})
+)
+
+// Estados de conexión de una sesión del pool
+const (
+	PoolStateConnecting   = "connecting"
+	PoolStateConnected    = "connected"
+	PoolStateDisconnected = "disconnected"
+)
+
+// rpcMetrics cuenta los errores RPC de todas las llamadas de un cliente
+var rpcMetrics = telegram.MiddlewareFunc(func(next tg.Invoker) telegram.InvokeFunc {
+	return func(ctx context.Context, input bin.Encoder, output bin.Decoder) error {
+		err := next.Invoke(ctx, input, output)
+		if rpcErr, ok := tgerr.As(err); ok {
+			rpcErrors.WithLabelValues(rpcErr.Type).Inc()
+		}
+		return err
+	}
+})
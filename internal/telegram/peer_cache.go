@@ -0,0 +1,75 @@
+package telegram
+
+import (
+	"context"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/gotd/td/tg"
+)
+
+// peerCache guarda por sesión los InputPeer (con su access_hash) vistos al
+// recorrer diálogos o contactos, indexados por ID en formato Bot API. Los
+// access_hash son estables para una cuenta, así que no expiran: se descartan
+// al eliminar la sesión.
+type peerCache struct {
+	mu    sync.RWMutex
+	peers map[uuid.UUID]map[int64]tg.InputPeerClass
+}
+
+type sessionContextKey struct{}
+
+// WithSessionID asocia la sesión al contexto para que los resolvers de peers
+// usen su caché. Sin sesión en el contexto se resuelve siempre contra Telegram.
+func WithSessionID(ctx context.Context, sessionID uuid.UUID) context.Context {
+	return context.WithValue(ctx, sessionContextKey{}, sessionID)
+}
+
+func sessionIDFromContext(ctx context.Context) (uuid.UUID, bool) {
+	id, ok := ctx.Value(sessionContextKey{}).(uuid.UUID)
+	return id, ok
+}
+
+// cachedPeer busca un peer ya resuelto por la sesión del contexto
+func (m *ClientManager) cachedPeer(ctx context.Context, chatID int64) (tg.InputPeerClass, bool) {
+	sessionID, ok := sessionIDFromContext(ctx)
+	if !ok {
+		return nil, false
+	}
+
+	m.peers.mu.RLock()
+	defer m.peers.mu.RUnlock()
+	peer, ok := m.peers.peers[sessionID][chatID]
+	return peer, ok
+}
+
+// cachePeer guarda un peer resuelto para la sesión del contexto
+func (m *ClientManager) cachePeer(ctx context.Context, peer tg.InputPeerClass) {
+	sessionID, ok := sessionIDFromContext(ctx)
+	if !ok {
+		return
+	}
+	id := inputPeerID(peer)
+	if id == 0 {
+		return
+	}
+
+	m.peers.mu.Lock()
+	defer m.peers.mu.Unlock()
+	if m.peers.peers == nil {
+		m.peers.peers = make(map[uuid.UUID]map[int64]tg.InputPeerClass)
+	}
+	byID, ok := m.peers.peers[sessionID]
+	if !ok {
+		byID = make(map[int64]tg.InputPeerClass)
+		m.peers.peers[sessionID] = byID
+	}
+	byID[id] = peer
+}
+
+// ForgetPeers descarta los peers cacheados de una sesión
+func (m *ClientManager) ForgetPeers(sessionID uuid.UUID) {
+	m.peers.mu.Lock()
+	defer m.peers.mu.Unlock()
+	delete(m.peers.peers, sessionID)
+}
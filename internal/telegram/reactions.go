@@ -0,0 +1,156 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+
+	"telegram-api/internal/domain"
+
+	"github.com/gotd/td/telegram"
+	"github.com/gotd/td/tg"
+)
+
+// SendReaction añade o quita una reacción conservando las demás reacciones
+// de la cuenta en el mensaje. Retorna los conteos actualizados.
+func (m *ClientManager) SendReaction(ctx context.Context, client *telegram.Client, chatID int64, msgID int, req domain.SendReactionRequest) ([]domain.MessageReaction, error) {
+	api := client.API()
+
+	peer, err := m.resolvePeerByID(ctx, api, chatID)
+	if err != nil {
+		return nil, err
+	}
+
+	msg, err := getMessage(ctx, api, peer, msgID)
+	if err != nil {
+		return nil, err
+	}
+
+	target := toTGReaction(req)
+	var chosen []tg.ReactionClass
+	for _, rc := range msg.Reactions.Results {
+		if _, ok := rc.GetChosenOrder(); !ok {
+			continue
+		}
+		if target != nil && sameReaction(rc.Reaction, target) {
+			continue
+		}
+		chosen = append(chosen, rc.Reaction)
+	}
+
+	switch {
+	case req.Remove && target == nil:
+		chosen = nil
+	case !req.Remove:
+		chosen = append(chosen, target)
+	}
+
+	updates, err := api.MessagesSendReaction(ctx, &tg.MessagesSendReactionRequest{
+		Big:         req.Big && !req.Remove,
+		AddToRecent: !req.Remove,
+		Peer:        peer,
+		MsgID:       msgID,
+		Reaction:    chosen,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("send reaction: %w", err)
+	}
+
+	for _, u := range updatesList(updates) {
+		if r, ok := u.(*tg.UpdateMessageReactions); ok && r.MsgID == msgID {
+			return parseReactions(r.Reactions), nil
+		}
+	}
+
+	return []domain.MessageReaction{}, nil
+}
+
+// getMessage obtiene un mensaje por ID (messages.getMessages o channels.getMessages)
+func getMessage(ctx context.Context, api *tg.Client, peer tg.InputPeerClass, msgID int) (*tg.Message, error) {
+	ids := []tg.InputMessageClass{&tg.InputMessageID{ID: msgID}}
+
+	var result tg.MessagesMessagesClass
+	var err error
+	if channel, ok := peer.(*tg.InputPeerChannel); ok {
+		result, err = api.ChannelsGetMessages(ctx, &tg.ChannelsGetMessagesRequest{
+			Channel: inputChannel(channel),
+			ID:      ids,
+		})
+	} else {
+		result, err = api.MessagesGetMessages(ctx, ids)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("get messages: %w", err)
+	}
+
+	var messages []tg.MessageClass
+	switch r := result.(type) {
+	case *tg.MessagesMessages:
+		messages = r.Messages
+	case *tg.MessagesMessagesSlice:
+		messages = r.Messages
+	case *tg.MessagesChannelMessages:
+		messages = r.Messages
+	}
+
+	for _, mc := range messages {
+		if msg, ok := mc.(*tg.Message); ok && msg.ID == msgID {
+			return msg, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: %d", domain.ErrMessageNotFound, msgID)
+}
+
+// parseReactions convierte los conteos de reacciones de Telegram
+func parseReactions(r tg.MessageReactions) []domain.MessageReaction {
+	reactions := make([]domain.MessageReaction, 0, len(r.Results))
+	for _, rc := range r.Results {
+		mr := domain.MessageReaction{Count: rc.Count}
+		_, mr.Chosen = rc.GetChosenOrder()
+
+		switch reaction := rc.Reaction.(type) {
+		case *tg.ReactionEmoji:
+			mr.Emoji = reaction.Emoticon
+		case *tg.ReactionCustomEmoji:
+			mr.CustomEmojiID = reaction.DocumentID
+		default:
+			continue
+		}
+		reactions = append(reactions, mr)
+	}
+	return reactions
+}
+
+func toTGReaction(req domain.SendReactionRequest) tg.ReactionClass {
+	switch {
+	case req.CustomEmojiID != 0:
+		return &tg.ReactionCustomEmoji{DocumentID: req.CustomEmojiID}
+	case req.Emoji != "":
+		return &tg.ReactionEmoji{Emoticon: req.Emoji}
+	}
+	return nil
+}
+
+func sameReaction(a, b tg.ReactionClass) bool {
+	switch x := a.(type) {
+	case *tg.ReactionEmoji:
+		y, ok := b.(*tg.ReactionEmoji)
+		return ok && x.Emoticon == y.Emoticon
+	case *tg.ReactionCustomEmoji:
+		y, ok := b.(*tg.ReactionCustomEmoji)
+		return ok && x.DocumentID == y.DocumentID
+	}
+	return false
+}
+
+func updatesList(u tg.UpdatesClass) []tg.UpdateClass {
+	switch v := u.(type) {
+	case *tg.Updates:
+		return v.Updates
+	case *tg.UpdatesCombined:
+		return v.Updates
+	case *tg.UpdateShort:
+		return []tg.UpdateClass{v.Update}
+	}
+	return nil
+}
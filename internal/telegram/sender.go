@@ -33,6 +33,7 @@ func (m *ClientManager) SendMessage(ctx context.Context, sess *domain.TelegramSe
 	storage := &memorySession{data: sessionData}
 	client := telegram.NewClient(sess.ApiID, apiHash, telegram.Options{
 		SessionStorage: storage,
+		Middlewares:    []telegram.Middleware{rpcMetrics},
 	})
 
 	return client.Run(ctx, func(ctx context.Context) error {
@@ -45,6 +46,9 @@ func (m *ClientManager) SendMessage(ctx context.Context, sess *domain.TelegramSe
 		}
 
 		builder := sender.To(peer)
+		if len(req.InlineKeyboard) > 0 {
+			builder.Markup(inlineMarkup(req.InlineKeyboard))
+		}
 
 		switch req.Type {
 		case domain.MessageTypeText, "":
@@ -99,19 +103,11 @@ func (m *ClientManager) resolvePeer(ctx context.Context, api *tg.Client, to stri
 
 	// Handle +phone
 	if strings.HasPrefix(to, "+") {
-		contacts, err := api.ContactsImportContacts(ctx, []tg.InputPhoneContact{
-			{Phone: to, FirstName: "Contact", LastName: ""},
-		})
+		user, err := m.resolvePhone(ctx, api, to)
 		if err != nil {
 			return nil, err
 		}
-		if len(contacts.Users) > 0 {
-			user, ok := contacts.Users[0].(*tg.User)
-			if ok {
-				return &tg.InputPeerUser{UserID: user.ID, AccessHash: user.AccessHash}, nil
-			}
-		}
-		return nil, fmt.Errorf("phone not found: %s", to)
+		return user, nil
 	}
 
 	// Handle numeric ID (user_id or chat_id)
@@ -197,7 +193,7 @@ func parseUserID(s string) (int64, error) {
 }
 
 func (m *ClientManager) sendPhoto(ctx context.Context, api *tg.Client, builder *message.RequestBuilder, req *domain.SendMessageRequest) error {
-	filePath, err := m.downloadFile(req.MediaURL)
+	filePath, err := m.downloadMedia(req)
 	if err != nil {
 		return err
 	}
@@ -220,7 +216,7 @@ func (m *ClientManager) sendPhoto(ctx context.Context, api *tg.Client, builder *
 }
 
 func (m *ClientManager) sendVideo(ctx context.Context, api *tg.Client, builder *message.RequestBuilder, req *domain.SendMessageRequest) error {
-	filePath, err := m.downloadFile(req.MediaURL)
+	filePath, err := m.downloadMedia(req)
 	if err != nil {
 		return err
 	}
@@ -247,7 +243,7 @@ func (m *ClientManager) sendVideo(ctx context.Context, api *tg.Client, builder *
 }
 
 func (m *ClientManager) sendAudio(ctx context.Context, api *tg.Client, builder *message.RequestBuilder, req *domain.SendMessageRequest) error {
-	filePath, err := m.downloadFile(req.MediaURL)
+	filePath, err := m.downloadMedia(req)
 	if err != nil {
 		return err
 	}
@@ -274,7 +270,7 @@ func (m *ClientManager) sendAudio(ctx context.Context, api *tg.Client, builder *
 }
 
 func (m *ClientManager) sendFile(ctx context.Context, api *tg.Client, builder *message.RequestBuilder, req *domain.SendMessageRequest) error {
-	filePath, err := m.downloadFile(req.MediaURL)
+	filePath, err := m.downloadMedia(req)
 	if err != nil {
 		return err
 	}
@@ -299,12 +295,31 @@ func (m *ClientManager) sendFile(ctx context.Context, api *tg.Client, builder *m
 }
 
 func (m *ClientManager) downloadFile(url string) (string, error) {
+	path, _, err := m.downloadLimited(url, 0)
+	return path, err
+}
+
+// downloadMedia descarga la media del mensaje respetando req.MaxMediaBytes
+// y anota en req.MediaBytes lo descargado
+func (m *ClientManager) downloadMedia(req *domain.SendMessageRequest) (string, error) {
+	path, size, err := m.downloadLimited(req.MediaURL, req.MaxMediaBytes)
+	req.MediaBytes = size
+	return path, err
+}
+
+// downloadLimited descarga url a un archivo temporal. Con maxBytes > 0 aborta
+// en cuanto se supera y falla con ErrMediaQuotaExceeded.
+func (m *ClientManager) downloadLimited(url string, maxBytes int64) (string, int64, error) {
 	resp, err := http.Get(url)
 	if err != nil {
-		return "", err
+		return "", 0, err
 	}
 	defer resp.Body.Close()
 
+	if maxBytes > 0 && resp.ContentLength > maxBytes {
+		return "", 0, domain.ErrMediaQuotaExceeded
+	}
+
 	ext := filepath.Ext(url)
 	if ext == "" {
 		ext = ".tmp"
@@ -312,18 +327,25 @@ func (m *ClientManager) downloadFile(url string) (string, error) {
 
 	tmp, err := os.CreateTemp("", "tg-media-*"+ext)
 	if err != nil {
-		return "", err
+		return "", 0, err
 	}
 
-	_, err = io.Copy(tmp, resp.Body)
+	var body io.Reader = resp.Body
+	if maxBytes > 0 {
+		body = io.LimitReader(resp.Body, maxBytes+1)
+	}
+	size, err := io.Copy(tmp, body)
 	tmp.Close()
 
+	if err == nil && maxBytes > 0 && size > maxBytes {
+		err = domain.ErrMediaQuotaExceeded
+	}
 	if err != nil {
 		os.Remove(tmp.Name())
-		return "", err
+		return "", 0, err
 	}
 
-	return tmp.Name(), nil
+	return tmp.Name(), size, nil
 }
 
 type memorySession struct {
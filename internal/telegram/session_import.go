@@ -0,0 +1,236 @@
+package telegram
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io/fs"
+	"net"
+	"strconv"
+	"strings"
+
+	"telegram-api/internal/domain"
+
+	"github.com/gotd/td/crypto"
+	"github.com/gotd/td/session"
+	"github.com/gotd/td/session/tdesktop"
+	"github.com/gotd/td/telegram/dcs"
+	"github.com/gotd/td/tg"
+)
+
+// ==================== DECODE ====================
+
+// DecodeSession convierte una sesión de Telethon, Pyrogram, Telegram Desktop o
+// gotd al formato de almacenamiento de gotd (JSON de session.Loader)
+func DecodeSession(ctx context.Context, format domain.SessionImportFormat, raw []byte, passcode string) ([]byte, error) {
+	var (
+		data *session.Data
+		err  error
+	)
+
+	switch format {
+	case domain.ImportTelethonString:
+		data, err = session.TelethonSession(strings.TrimSpace(string(raw)))
+	case domain.ImportTelethonSQLite:
+		data, err = decodeTelethonSQLite(raw)
+	case domain.ImportPyrogram:
+		data, err = decodePyrogramString(strings.TrimSpace(string(raw)))
+	case domain.ImportTDesktop:
+		data, err = decodeTDesktopZip(raw, passcode)
+	case domain.ImportGotdJSON:
+		data, err = decodeGotdJSON(ctx, raw)
+	default:
+		return nil, fmt.Errorf("%w: formato no soportado: %s", domain.ErrInvalidSessionData, format)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrInvalidSessionData, err)
+	}
+	if len(data.AuthKey) != 256 {
+		return nil, fmt.Errorf("%w: auth_key debe tener 256 bytes", domain.ErrInvalidSessionData)
+	}
+
+	storage := &session.StorageMemory{}
+	if err := (&session.Loader{Storage: storage}).Save(ctx, data); err != nil {
+		return nil, fmt.Errorf("save session: %w", err)
+	}
+	return storage.Bytes(nil)
+}
+
+// decodeTelethonSQLite lee la tabla sessions(dc_id, server_address, port, auth_key, ...)
+func decodeTelethonSQLite(raw []byte) (*session.Data, error) {
+	db, err := openSQLite(raw)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := db.tableRows("sessions")
+	if err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		if len(row) < 4 {
+			continue
+		}
+		dcID, _ := row[0].(int64)
+		addr, _ := row[1].(string)
+		port, _ := row[2].(int64)
+		authKey, _ := row[3].([]byte)
+		if dcID == 0 || len(authKey) != 256 {
+			continue
+		}
+		return sessionData(int(dcID), net.JoinHostPort(addr, strconv.FormatInt(port, 10)), authKey), nil
+	}
+	return nil, fmt.Errorf("el archivo .session no contiene una auth_key")
+}
+
+// decodePyrogramString soporta los formatos de session string de Pyrogram:
+//
+//	v2:        >BI?256sQ?  dc_id, api_id, test_mode, auth_key, user_id, is_bot
+//	v1 (64b):  >B?256sQ?   dc_id, test_mode, auth_key, user_id, is_bot
+//	v1 (32b):  >B?256sI?   dc_id, test_mode, auth_key, user_id, is_bot
+func decodePyrogramString(s string) (*session.Data, error) {
+	raw, err := base64.URLEncoding.DecodeString(s + strings.Repeat("=", (4-len(s)%4)%4))
+	if err != nil {
+		return nil, fmt.Errorf("base64: %w", err)
+	}
+
+	var (
+		dcID     int
+		testMode bool
+		authKey  []byte
+	)
+	switch len(raw) {
+	case 271:
+		dcID, testMode, authKey = int(raw[0]), raw[5] != 0, raw[6:262]
+	case 267, 263:
+		dcID, testMode, authKey = int(raw[0]), raw[1] != 0, raw[2:258]
+	default:
+		return nil, fmt.Errorf("longitud de session string inválida: %d", len(raw))
+	}
+	if testMode {
+		return nil, fmt.Errorf("sesiones de los servidores de prueba no están soportadas")
+	}
+
+	// Pyrogram no guarda la dirección del DC, se toma de la lista de producción
+	opts := dcs.FindPrimaryDCs(dcs.Prod().Options, dcID, false)
+	if len(opts) == 0 {
+		return nil, fmt.Errorf("DC desconocido: %d", dcID)
+	}
+	addr := net.JoinHostPort(opts[0].IPAddress, strconv.Itoa(opts[0].Port))
+
+	return sessionData(dcID, addr, authKey), nil
+}
+
+// Límites del zip de tdata. Solo se leen key_datas y los archivos de cuenta,
+// que ocupan pocos KB; las cachés de media no hacen falta.
+const (
+	tdesktopMaxEntries = 256
+	tdesktopMaxSize    = 16 << 20
+)
+
+// decodeTDesktopZip lee la carpeta tdata comprimida en zip. Si hay varias
+// cuentas se importa la primera.
+func decodeTDesktopZip(raw []byte, passcode string) (*session.Data, error) {
+	zr, err := zip.NewReader(bytes.NewReader(raw), int64(len(raw)))
+	if err != nil {
+		return nil, fmt.Errorf("zip: %w", err)
+	}
+
+	// Validar tamaños declarados antes de descomprimir nada: archive/zip no
+	// entrega más bytes que UncompressedSize64, así que esto acota la memoria
+	if len(zr.File) > tdesktopMaxEntries {
+		return nil, fmt.Errorf("zip con demasiados archivos (máximo %d)", tdesktopMaxEntries)
+	}
+	var total uint64
+	for _, f := range zr.File {
+		total += f.UncompressedSize64
+		if f.UncompressedSize64 > tdesktopMaxSize || total > tdesktopMaxSize {
+			return nil, fmt.Errorf("zip demasiado grande descomprimido (máximo %d MB)", tdesktopMaxSize>>20)
+		}
+	}
+
+	// Permitir tanto el contenido de tdata como la carpeta tdata/ en la raíz
+	root := "."
+	for _, f := range zr.File {
+		if strings.HasPrefix(f.Name, "tdata/") {
+			root = "tdata"
+			break
+		}
+	}
+	fsys, err := fs.Sub(zr, root)
+	if err != nil {
+		return nil, err
+	}
+
+	accounts, err := tdesktop.ReadFS(fsys, []byte(passcode))
+	if err != nil {
+		return nil, fmt.Errorf("tdata: %w", err)
+	}
+	if len(accounts) == 0 {
+		return nil, fmt.Errorf("tdata sin cuentas")
+	}
+	return session.TDesktopSession(accounts[0])
+}
+
+// decodeGotdJSON valida un JSON de session.Loader exportado por gotd
+func decodeGotdJSON(ctx context.Context, raw []byte) (*session.Data, error) {
+	storage := &session.StorageMemory{}
+	if err := storage.StoreSession(ctx, raw); err != nil {
+		return nil, err
+	}
+	return (&session.Loader{Storage: storage}).Load(ctx)
+}
+
+func sessionData(dcID int, addr string, authKey []byte) *session.Data {
+	var key crypto.Key
+	copy(key[:], authKey)
+	id := key.WithID().ID
+
+	return &session.Data{
+		DC:        dcID,
+		Addr:      addr,
+		AuthKey:   key[:],
+		AuthKeyID: id[:],
+	}
+}
+
+// ==================== VALIDATE ====================
+
+// ValidateSession se conecta con la sesión importada y llama a users.getSelf.
+// Retorna la cuenta y los datos de sesión actualizados por el cliente
+// (config y salt del servidor).
+func (m *ClientManager) ValidateSession(ctx context.Context, apiID int, apiHash string, sessionData []byte) (*TGUser, []byte, error) {
+	storage := &session.StorageMemory{}
+	if err := storage.StoreSession(ctx, sessionData); err != nil {
+		return nil, nil, fmt.Errorf("store session: %w", err)
+	}
+	client := m.newClient(apiID, apiHash, "Imported Session", storage)
+
+	var user *TGUser
+	err := client.Run(ctx, func(ctx context.Context) error {
+		users, err := client.API().UsersGetUsers(ctx, []tg.InputUserClass{&tg.InputUserSelf{}})
+		if err != nil {
+			return fmt.Errorf("get self: %w", err)
+		}
+		if len(users) == 0 {
+			return fmt.Errorf("get self: respuesta vacía")
+		}
+		u, ok := users[0].(*tg.User)
+		if !ok {
+			return fmt.Errorf("unexpected user type")
+		}
+		user = &TGUser{ID: u.ID, Username: u.Username, Phone: u.Phone, Bot: u.Bot}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	data, err := storage.Bytes(nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("read session: %w", err)
+	}
+	return user, data, nil
+}
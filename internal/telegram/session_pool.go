@@ -33,12 +33,18 @@ type ActiveSession struct {
 	Client       *telegram.Client
 	API          *tg.Client
 	Cancel       context.CancelFunc
+	ctx          context.Context // Vive mientras la sesión siga en el pool
 	StartedAt    time.Time
 	IsConnected  bool
+	Exited       bool // El cliente terminó (error o desconexión) y sigue en el pool
 	LastActivity time.Time
+	Online       bool // Mantener la cuenta "en línea" mientras la sesión esté en el pool
 	mu           sync.RWMutex
 }
 
+// presenceRefresh intervalo para renovar el estado en línea (Telegram lo expira a los ~5 min)
+const presenceRefresh = 4 * time.Minute
+
 // NewSessionPool crea el pool de sesiones
 func NewSessionPool(
 	manager *ClientManager,
@@ -87,6 +93,7 @@ func (p *SessionPool) StartSession(ctx context.Context, sess *domain.TelegramSes
 	client := telegram.NewClient(sess.ApiID, string(apiHashBytes), telegram.Options{
 		SessionStorage: storage,
 		UpdateHandler:  dispatcher,
+		Middlewares:    []telegram.Middleware{rpcMetrics},
 		Device: telegram.DeviceConfig{
 			DeviceModel:    sess.SessionName,
 			SystemVersion:  "1.0",
@@ -105,6 +112,7 @@ func (p *SessionPool) StartSession(ctx context.Context, sess *domain.TelegramSes
 		TelegramID:  sess.TelegramUserID,
 		Client:      client,
 		Cancel:      cancel,
+		ctx:         sessionCtx,
 		StartedAt:   time.Now(),
 		IsConnected: false,
 	}
@@ -160,6 +168,62 @@ func (p *SessionPool) GetActiveSession(sessionID uuid.UUID) (*ActiveSession, boo
 	return active, exists
 }
 
+// SetPresence cambia el estado en línea de una sesión activa. Si está en línea
+// se renueva periódicamente hasta que se desactive o la sesión se detenga.
+// Retorna false si la sesión no está en el pool o aún no conecta.
+func (p *SessionPool) SetPresence(ctx context.Context, sessionID uuid.UUID, online bool) (bool, error) {
+	active, exists := p.GetActiveSession(sessionID)
+	if !exists {
+		return false, nil
+	}
+
+	active.mu.Lock()
+	api := active.API
+	connected := active.IsConnected
+	active.Online = online
+	active.mu.Unlock()
+
+	if !connected || api == nil {
+		return false, nil
+	}
+
+	if err := p.manager.SetOnline(ctx, api, online); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// ConnectedAPI retorna el cliente RPC de una sesión del pool ya conectada
+func (p *SessionPool) ConnectedAPI(sessionID uuid.UUID) (*tg.Client, bool) {
+	active, exists := p.GetActiveSession(sessionID)
+	if !exists {
+		return nil, false
+	}
+
+	active.mu.Lock()
+	defer active.mu.Unlock()
+	if !active.IsConnected || active.API == nil {
+		return nil, false
+	}
+	return active.API, true
+}
+
+// ConnectedContext es como ConnectedAPI pero retorna además el contexto de la
+// sesión, que se cancela al detenerla; sirve para tareas en segundo plano
+func (p *SessionPool) ConnectedContext(sessionID uuid.UUID) (context.Context, *tg.Client, bool) {
+	active, exists := p.GetActiveSession(sessionID)
+	if !exists {
+		return nil, nil, false
+	}
+
+	active.mu.Lock()
+	defer active.mu.Unlock()
+	if !active.IsConnected || active.API == nil {
+		return nil, nil, false
+	}
+	return active.ctx, active.API, true
+}
+
 // ActiveCount retorna cantidad de sesiones activas
 func (p *SessionPool) ActiveCount() int {
 	p.mu.RLock()
@@ -167,6 +231,31 @@ func (p *SessionPool) ActiveCount() int {
 	return len(p.sessions)
 }
 
+// StateCounts cantidad de sesiones del pool por estado de conexión
+func (p *SessionPool) StateCounts() map[string]int {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	counts := map[string]int{
+		PoolStateConnecting:   0,
+		PoolStateConnected:    0,
+		PoolStateDisconnected: 0,
+	}
+	for _, active := range p.sessions {
+		active.mu.RLock()
+		switch {
+		case active.IsConnected:
+			counts[PoolStateConnected]++
+		case active.Exited:
+			counts[PoolStateDisconnected]++
+		default:
+			counts[PoolStateConnecting]++
+		}
+		active.mu.RUnlock()
+	}
+	return counts
+}
+
 // ListActive retorna IDs de sesiones activas
 func (p *SessionPool) ListActive() []uuid.UUID {
 	p.mu.RLock()
@@ -189,13 +278,29 @@ func (p *SessionPool) runClient(ctx context.Context, active *ActiveSession, clie
 			Str("session_id", active.SessionID.String()).
 			Msg("✅ Cliente Telegram conectado, escuchando eventos...")
 
-		// Mantener conexión activa
-		<-ctx.Done()
-		return ctx.Err()
+		// Mantener conexión activa y renovar presencia si está habilitada
+		ticker := time.NewTicker(presenceRefresh)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-ticker.C:
+				active.mu.RLock()
+				online := active.Online
+				active.mu.RUnlock()
+				if online {
+					if err := p.manager.SetOnline(ctx, client.API(), true); err != nil {
+						logger.Warn().Err(err).Str("session_id", active.SessionID.String()).Msg("error renovando presencia")
+					}
+				}
+			}
+		}
 	})
 
 	active.mu.Lock()
 	active.IsConnected = false
+	active.Exited = true
 	active.mu.Unlock()
 
 	if err != nil && ctx.Err() == nil {
@@ -241,6 +346,35 @@ func (p *SessionPool) registerHandlers(dispatcher tg.UpdateDispatcher, active *A
 		return nil
 	})
 
+	// Reacciones a mensajes
+	dispatcher.OnMessageReactions(func(ctx context.Context, e tg.Entities, update *tg.UpdateMessageReactions) error {
+		data := domain.ReactionEventData{
+			MessageID: int64(update.MsgID),
+			Reactions: parseReactions(update.Reactions),
+		}
+
+		switch peer := update.Peer.(type) {
+		case *tg.PeerUser:
+			data.ChatID = peer.UserID
+			data.ChatType = "private"
+		case *tg.PeerChat:
+			data.ChatID = peer.ChatID
+			data.ChatType = "group"
+		case *tg.PeerChannel:
+			data.ChatID = peer.ChannelID
+			data.ChatType = "channel"
+		}
+
+		p.dispatcher.Dispatch(active.SessionID, domain.EventMessageReaction, data)
+		return nil
+	})
+
+	// Botones inline pulsados (solo bots)
+	dispatcher.OnBotCallbackQuery(func(ctx context.Context, e tg.Entities, update *tg.UpdateBotCallbackQuery) error {
+		p.dispatcher.Dispatch(active.SessionID, domain.EventCallbackQuery, parseCallbackQuery(e, update))
+		return nil
+	})
+
 	// Usuario escribiendo
 	dispatcher.OnUserTyping(func(ctx context.Context, e tg.Entities, update *tg.UpdateUserTyping) error {
 		data := domain.TypingEventData{
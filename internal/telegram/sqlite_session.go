@@ -0,0 +1,334 @@
+package telegram
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// Lector mínimo (solo lectura) del formato de archivo SQLite, suficiente para
+// extraer la tabla `sessions` de un archivo .session de Telethon sin depender
+// de un driver SQLite con cgo.
+//
+// Formato: https://www.sqlite.org/fileformat2.html
+
+var sqliteMagic = []byte("SQLite format 3\x00")
+
+const (
+	sqlitePageInteriorTable = 0x05
+	sqlitePageLeafTable     = 0x0D
+
+	// El archivo viene del usuario: límites para que un b-tree o una cadena de
+	// overflow manipulados no agoten CPU ni memoria
+	sqliteMaxDepth = 32
+	sqliteMaxCells = 10000
+)
+
+type sqliteFile struct {
+	data     []byte
+	pageSize int
+	usable   int
+
+	// Cada página pertenece a una sola estructura: volver a visitarla indica
+	// un ciclo (b-tree u overflow)
+	visited map[int]bool
+	cells   int
+}
+
+func openSQLite(data []byte) (*sqliteFile, error) {
+	if len(data) < 100 || !bytes.HasPrefix(data, sqliteMagic) {
+		return nil, errors.New("no es un archivo SQLite")
+	}
+
+	pageSize := int(binary.BigEndian.Uint16(data[16:18]))
+	if pageSize == 1 {
+		pageSize = 65536
+	}
+	if pageSize < 512 || pageSize&(pageSize-1) != 0 || len(data)%pageSize != 0 {
+		return nil, fmt.Errorf("tamaño de página SQLite inválido: %d", pageSize)
+	}
+
+	// El formato exige al menos 480 bytes útiles por página
+	usable := pageSize - int(data[20])
+	if usable < 480 {
+		return nil, fmt.Errorf("espacio reservado por página inválido: %d", data[20])
+	}
+
+	return &sqliteFile{
+		data:     data,
+		pageSize: pageSize,
+		usable:   usable,
+		visited:  make(map[int]bool),
+	}, nil
+}
+
+// tableRows retorna todas las filas de la tabla indicada
+func (f *sqliteFile) tableRows(name string) ([][]any, error) {
+	// sqlite_master: type, name, tbl_name, rootpage, sql
+	master, err := f.scanTable(1)
+	if err != nil {
+		return nil, fmt.Errorf("leer sqlite_master: %w", err)
+	}
+
+	for _, row := range master {
+		if len(row) < 4 || row[0] != "table" || row[1] != name {
+			continue
+		}
+		root, ok := row[3].(int64)
+		if !ok || root < 1 || root > int64(len(f.data)/f.pageSize) {
+			return nil, fmt.Errorf("rootpage inválido para %s", name)
+		}
+		return f.scanTable(int(root))
+	}
+	return nil, fmt.Errorf("tabla %q no encontrada", name)
+}
+
+// scanTable recorre el b-tree de una tabla desde su página raíz
+func (f *sqliteFile) scanTable(root int) ([][]any, error) {
+	var rows [][]any
+	var walk func(page, depth int) error
+
+	walk = func(page, depth int) error {
+		if depth > sqliteMaxDepth {
+			return errors.New("b-tree demasiado profundo")
+		}
+		buf, hdr, err := f.visit(page)
+		if err != nil {
+			return err
+		}
+
+		kind := buf[hdr]
+		ptrs := hdr + 8
+		if kind == sqlitePageInteriorTable {
+			ptrs = hdr + 12
+		}
+		cells := int(binary.BigEndian.Uint16(buf[hdr+3 : hdr+5]))
+		if ptrs+2*cells > len(buf) {
+			return errors.New("array de celdas fuera de la página")
+		}
+		if f.cells += cells; f.cells > sqliteMaxCells {
+			return errors.New("demasiadas celdas")
+		}
+
+		switch kind {
+		case sqlitePageLeafTable:
+			for i := 0; i < cells; i++ {
+				off := int(binary.BigEndian.Uint16(buf[ptrs+2*i:]))
+				row, err := f.leafCell(buf, off)
+				if err != nil {
+					return err
+				}
+				rows = append(rows, row)
+			}
+		case sqlitePageInteriorTable:
+			for i := 0; i < cells; i++ {
+				off := int(binary.BigEndian.Uint16(buf[ptrs+2*i:]))
+				if off < ptrs || off+4 > len(buf) {
+					return errors.New("celda fuera de la página")
+				}
+				if err := walk(int(binary.BigEndian.Uint32(buf[off:])), depth+1); err != nil {
+					return err
+				}
+			}
+			right := int(binary.BigEndian.Uint32(buf[hdr+8 : hdr+12]))
+			if err := walk(right, depth+1); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("tipo de página no soportado: 0x%02x", kind)
+		}
+		return nil
+	}
+
+	if err := walk(root, 0); err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// visit marca la página como leída y la retorna; falla si ya se visitó
+func (f *sqliteFile) visit(n int) ([]byte, int, error) {
+	if f.visited[n] {
+		return nil, 0, fmt.Errorf("página %d referenciada más de una vez", n)
+	}
+	buf, hdr, err := f.page(n)
+	if err != nil {
+		return nil, 0, err
+	}
+	f.visited[n] = true
+	return buf, hdr, nil
+}
+
+// page retorna el contenido de la página y el offset de su cabecera b-tree
+// (la página 1 incluye la cabecera de 100 bytes del archivo)
+func (f *sqliteFile) page(n int) ([]byte, int, error) {
+	if n < 1 || n > len(f.data)/f.pageSize {
+		return nil, 0, fmt.Errorf("página %d fuera de rango", n)
+	}
+	start := (n - 1) * f.pageSize
+	hdr := 0
+	if n == 1 {
+		hdr = 100
+	}
+	return f.data[start : start+f.pageSize], hdr, nil
+}
+
+// leafCell decodifica una celda de hoja: payload size, rowid y record.
+// Una columna INTEGER PRIMARY KEY se guarda como NULL y su valor es el rowid;
+// en las tablas que se leen aquí (sessions.dc_id) esa columna es la primera.
+func (f *sqliteFile) leafCell(buf []byte, off int) ([]any, error) {
+	if off < 0 || off >= len(buf) {
+		return nil, errors.New("celda fuera de la página")
+	}
+	payloadSize, n := sqliteVarint(buf[off:])
+	if n == 0 || payloadSize < 0 || payloadSize > int64(len(f.data)) {
+		return nil, errors.New("tamaño de payload inválido")
+	}
+	off += n
+	rowid, n := sqliteVarint(buf[off:])
+	if n == 0 {
+		return nil, errors.New("rowid inválido")
+	}
+	off += n
+
+	payload, err := f.payload(buf, off, int(payloadSize))
+	if err != nil {
+		return nil, err
+	}
+	row, err := sqliteRecord(payload)
+	if err != nil {
+		return nil, err
+	}
+	if len(row) > 0 && row[0] == nil {
+		row[0] = rowid
+	}
+	return row, nil
+}
+
+// payload reconstruye el payload completo siguiendo páginas de overflow
+func (f *sqliteFile) payload(buf []byte, off, size int) ([]byte, error) {
+	maxLocal := f.usable - 35
+	if size <= maxLocal {
+		if off+size > len(buf) {
+			return nil, errors.New("celda truncada")
+		}
+		return buf[off : off+size], nil
+	}
+
+	minLocal := (f.usable-12)*32/255 - 23
+	local := minLocal + (size-minLocal)%(f.usable-4)
+	if local > maxLocal {
+		local = minLocal
+	}
+	if off+local+4 > len(buf) {
+		return nil, errors.New("celda truncada")
+	}
+
+	out := make([]byte, 0, size)
+	out = append(out, buf[off:off+local]...)
+	next := int(binary.BigEndian.Uint32(buf[off+local:]))
+
+	for len(out) < size && next != 0 {
+		page, _, err := f.visit(next)
+		if err != nil {
+			return nil, err
+		}
+		next = int(binary.BigEndian.Uint32(page[:4]))
+		chunk := min(size-len(out), f.usable-4)
+		out = append(out, page[4:4+chunk]...)
+	}
+	if len(out) < size {
+		return nil, errors.New("cadena de overflow incompleta")
+	}
+	return out, nil
+}
+
+// sqliteRecord decodifica un registro: cabecera de serial types y valores
+func sqliteRecord(p []byte) ([]any, error) {
+	hdrSize, n := sqliteVarint(p)
+	if n == 0 || hdrSize < int64(n) || hdrSize > int64(len(p)) {
+		return nil, errors.New("registro inválido")
+	}
+
+	var types []int64
+	for pos := n; pos < int(hdrSize); {
+		t, n := sqliteVarint(p[pos:int(hdrSize)])
+		if n == 0 || t < 0 {
+			return nil, errors.New("cabecera de registro inválida")
+		}
+		types = append(types, t)
+		pos += n
+	}
+
+	values := make([]any, 0, len(types))
+	body := p[hdrSize:]
+	for _, t := range types {
+		var size int64
+		switch {
+		case t == 0, t == 8, t == 9:
+			size = 0
+		case t >= 1 && t <= 4:
+			size = t
+		case t == 5:
+			size = 6
+		case t == 6, t == 7:
+			size = 8
+		case t >= 12:
+			size = (t - 12) / 2
+		default:
+			return nil, fmt.Errorf("serial type no soportado: %d", t)
+		}
+		if size > int64(len(body)) {
+			return nil, errors.New("registro truncado")
+		}
+		v := body[:size]
+		body = body[size:]
+
+		switch {
+		case t == 0:
+			values = append(values, nil)
+		case t == 8:
+			values = append(values, int64(0))
+		case t == 9:
+			values = append(values, int64(1))
+		case t >= 1 && t <= 6:
+			values = append(values, sqliteInt(v))
+		case t == 7:
+			values = append(values, v) // REAL: no se usa en sesiones
+		case t%2 == 0:
+			values = append(values, v) // BLOB
+		default:
+			values = append(values, string(v)) // TEXT
+		}
+	}
+	return values, nil
+}
+
+// sqliteInt decodifica un entero big-endian con signo de 1 a 8 bytes
+func sqliteInt(b []byte) int64 {
+	var v int64
+	if len(b) > 0 && b[0]&0x80 != 0 {
+		v = -1
+	}
+	for _, c := range b {
+		v = v<<8 | int64(c)
+	}
+	return v
+}
+
+// sqliteVarint decodifica un varint SQLite (1 a 9 bytes, big-endian).
+// Retorna n = 0 si el buffer termina antes que el varint.
+func sqliteVarint(b []byte) (int64, int) {
+	var v int64
+	for i := 0; i < 9 && i < len(b); i++ {
+		if i == 8 {
+			return v<<8 | int64(b[i]), 9
+		}
+		v = v<<7 | int64(b[i]&0x7f)
+		if b[i]&0x80 == 0 {
+			return v, i + 1
+		}
+	}
+	return 0, 0
+}
@@ -1,6 +1,7 @@
 package crypto
 
 import (
+	"bytes"
 	"crypto/aes"
 	"crypto/cipher"
 	"crypto/rand"
@@ -8,6 +9,7 @@ import (
 	"encoding/hex"
 	"errors"
 	"io"
+	"strings"
 
 	"golang.org/x/crypto/bcrypt"
 )
@@ -16,17 +18,61 @@ var (
 	ErrInvalidKey        = errors.New("clave de encriptación inválida")
 	ErrDecryptionFailed  = errors.New("fallo al desencriptar datos")
 	ErrCiphertextTooShort = errors.New("texto cifrado muy corto")
+	ErrUnknownKeyID      = errors.New("clave de encriptación desconocida para el texto cifrado")
 )
 
-// Crypter maneja operaciones de encriptación/desencriptación
+// Formato versionado de los textos cifrados:
+//
+//	magic "TGK"(3) | versión(1) | key ID(4) | nonce(12) | ciphertext+tag
+//
+// El key ID es el prefijo del SHA-256 de la clave y permite saber con qué
+// clave se cifró cada valor. Los textos cifrados anteriores (nonce|ciphertext,
+// sin cabecera) se siguen descifrando probando todas las claves configuradas.
+var ciphertextMagic = []byte("TGK")
+
+const (
+	ciphertextVersion = 1
+	keyIDSize         = 4
+	versionHeaderSize = 3 + 1 + keyIDSize
+)
+
+type aesKey struct {
+	id  [keyIDSize]byte
+	gcm cipher.AEAD
+}
+
+// Crypter maneja operaciones de encriptación/desencriptación.
+// Cifra siempre con la clave primaria y descifra con cualquiera de las
+// claves configuradas (primaria + anteriores), lo que permite rotarlas.
 type Crypter struct {
-	key []byte
+	primary *aesKey
+	keys    []*aesKey
 }
 
 // NewCrypter crea una nueva instancia de Crypter
-// key debe ser una cadena hexadecimal de 64 caracteres (32 bytes para AES-256)
-func NewCrypter(hexKey string) (*Crypter, error) {
-	key, err := hex.DecodeString(hexKey)
+// key debe ser una cadena hexadecimal de 64 caracteres (32 bytes para AES-256).
+// oldKeys son claves anteriores usadas solo para descifrar.
+func NewCrypter(hexKey string, oldKeys ...string) (*Crypter, error) {
+	primary, err := newAESKey(hexKey)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Crypter{primary: primary, keys: []*aesKey{primary}}
+	for _, k := range oldKeys {
+		old, err := newAESKey(k)
+		if err != nil {
+			return nil, err
+		}
+		if old.id != primary.id {
+			c.keys = append(c.keys, old)
+		}
+	}
+	return c, nil
+}
+
+func newAESKey(hexKey string) (*aesKey, error) {
+	key, err := hex.DecodeString(strings.TrimSpace(hexKey))
 	if err != nil {
 		return nil, ErrInvalidKey
 	}
@@ -35,51 +81,112 @@ func NewCrypter(hexKey string) (*Crypter, error) {
 		return nil, ErrInvalidKey
 	}
 
-	return &Crypter{key: key}, nil
-}
-
-// Encrypt encripta datos usando AES-256-GCM
-func (c *Crypter) Encrypt(plaintext []byte) ([]byte, error) {
-	block, err := aes.NewCipher(c.key)
+	block, err := aes.NewCipher(key)
 	if err != nil {
 		return nil, err
 	}
-
 	gcm, err := cipher.NewGCM(block)
 	if err != nil {
 		return nil, err
 	}
 
+	k := &aesKey{gcm: gcm}
+	sum := sha256.Sum256(key)
+	copy(k.id[:], sum[:keyIDSize])
+	return k, nil
+}
+
+// PrimaryKeyID retorna el identificador (hex) de la clave usada para cifrar
+func (c *Crypter) PrimaryKeyID() string {
+	return hex.EncodeToString(c.primary.id[:])
+}
+
+// Encrypt encripta datos usando AES-256-GCM con la clave primaria
+func (c *Crypter) Encrypt(plaintext []byte) ([]byte, error) {
+	gcm := c.primary.gcm
+
 	// Generar nonce aleatorio
 	nonce := make([]byte, gcm.NonceSize())
 	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
 		return nil, err
 	}
 
-	// Encriptar: nonce + ciphertext + tag
-	ciphertext := gcm.Seal(nonce, nonce, plaintext, nil)
-	return ciphertext, nil
+	// Cabecera (autenticada como additional data) + nonce + ciphertext + tag
+	header := make([]byte, 0, versionHeaderSize)
+	header = append(header, ciphertextMagic...)
+	header = append(header, ciphertextVersion)
+	header = append(header, c.primary.id[:]...)
+
+	out := append(header, nonce...)
+	return gcm.Seal(out, nonce, plaintext, header), nil
 }
 
-// Decrypt desencripta datos encriptados con AES-256-GCM
+// Decrypt desencripta datos encriptados con AES-256-GCM, versionados o en el
+// formato anterior sin cabecera
 func (c *Crypter) Decrypt(ciphertext []byte) ([]byte, error) {
-	block, err := aes.NewCipher(c.key)
-	if err != nil {
-		return nil, err
+	if key, ok := c.versionedKey(ciphertext); ok {
+		if key == nil {
+			return nil, ErrUnknownKeyID
+		}
+		header := ciphertext[:versionHeaderSize]
+		if plaintext, err := gcmOpen(key.gcm, ciphertext[versionHeaderSize:], header); err == nil {
+			return plaintext, nil
+		}
+		// Puede ser un texto cifrado antiguo cuyo nonce coincide con la cabecera
 	}
 
-	gcm, err := cipher.NewGCM(block)
+	if len(ciphertext) < c.primary.gcm.NonceSize() {
+		return nil, ErrCiphertextTooShort
+	}
+	for _, key := range c.keys {
+		if plaintext, err := gcmOpen(key.gcm, ciphertext, nil); err == nil {
+			return plaintext, nil
+		}
+	}
+	return nil, ErrDecryptionFailed
+}
+
+// NeedsReencrypt indica si el texto cifrado no usa el formato versionado con
+// la clave primaria
+func (c *Crypter) NeedsReencrypt(ciphertext []byte) bool {
+	key, ok := c.versionedKey(ciphertext)
+	return !ok || key != c.primary
+}
+
+// Reencrypt descifra con la clave que corresponda y vuelve a cifrar con la primaria
+func (c *Crypter) Reencrypt(ciphertext []byte) ([]byte, error) {
+	plaintext, err := c.Decrypt(ciphertext)
 	if err != nil {
 		return nil, err
 	}
+	return c.Encrypt(plaintext)
+}
+
+// versionedKey detecta la cabecera versionada. Retorna ok=false si no la tiene
+// y key=nil si el key ID no corresponde a ninguna clave configurada.
+func (c *Crypter) versionedKey(ciphertext []byte) (*aesKey, bool) {
+	if len(ciphertext) < versionHeaderSize ||
+		!bytes.HasPrefix(ciphertext, ciphertextMagic) ||
+		ciphertext[3] != ciphertextVersion {
+		return nil, false
+	}
+	id := ciphertext[4:versionHeaderSize]
+	for _, key := range c.keys {
+		if bytes.Equal(key.id[:], id) {
+			return key, true
+		}
+	}
+	return nil, true
+}
 
+func gcmOpen(gcm cipher.AEAD, data, additional []byte) ([]byte, error) {
 	nonceSize := gcm.NonceSize()
-	if len(ciphertext) < nonceSize {
+	if len(data) < nonceSize {
 		return nil, ErrCiphertextTooShort
 	}
 
-	nonce, ciphertext := ciphertext[:nonceSize], ciphertext[nonceSize:]
-	plaintext, err := gcm.Open(nil, nonce, ciphertext, nil)
+	nonce, ciphertext := data[:nonceSize], data[nonceSize:]
+	plaintext, err := gcm.Open(nil, nonce, ciphertext, additional)
 	if err != nil {
 		return nil, ErrDecryptionFailed
 	}
@@ -0,0 +1,200 @@
+package crypto
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/hex"
+	"errors"
+	"strings"
+	"testing"
+)
+
+var (
+	testKeyA = strings.Repeat("a1", 32)
+	testKeyB = strings.Repeat("b2", 32)
+	testKeyC = strings.Repeat("c3", 32)
+)
+
+// legacyEncrypt reproduce el formato anterior sin cabecera: nonce | ciphertext+tag
+func legacyEncrypt(t *testing.T, hexKey string, plaintext []byte) []byte {
+	t.Helper()
+	key, _ := hex.DecodeString(hexKey)
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		t.Fatal(err)
+	}
+	nonce := bytes.Repeat([]byte{7}, gcm.NonceSize())
+	return gcm.Seal(nonce, nonce, plaintext, nil)
+}
+
+func mustCrypter(t *testing.T, key string, oldKeys ...string) *Crypter {
+	t.Helper()
+	c, err := NewCrypter(key, oldKeys...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func mustEncrypt(t *testing.T, c *Crypter, plaintext []byte) []byte {
+	t.Helper()
+	ct, err := c.Encrypt(plaintext)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ct
+}
+
+func TestCrypterDecrypt(t *testing.T) {
+	plaintext := []byte("sesión de telegram")
+
+	tests := []struct {
+		name       string
+		decrypter  *Crypter
+		ciphertext func(t *testing.T) []byte
+		wantErr    error
+		reencrypt  bool // NeedsReencrypt esperado
+	}{
+		{
+			name:      "ida y vuelta",
+			decrypter: mustCrypter(t, testKeyA),
+			ciphertext: func(t *testing.T) []byte {
+				return mustEncrypt(t, mustCrypter(t, testKeyA), plaintext)
+			},
+		},
+		{
+			name:      "formato anterior sin cabecera",
+			decrypter: mustCrypter(t, testKeyA),
+			ciphertext: func(t *testing.T) []byte {
+				return legacyEncrypt(t, testKeyA, plaintext)
+			},
+			reencrypt: true,
+		},
+		{
+			name:      "formato anterior con clave rotada",
+			decrypter: mustCrypter(t, testKeyB, testKeyA),
+			ciphertext: func(t *testing.T) []byte {
+				return legacyEncrypt(t, testKeyA, plaintext)
+			},
+			reencrypt: true,
+		},
+		{
+			name:      "clave anterior tras rotar",
+			decrypter: mustCrypter(t, testKeyB, testKeyA),
+			ciphertext: func(t *testing.T) []byte {
+				return mustEncrypt(t, mustCrypter(t, testKeyA), plaintext)
+			},
+			reencrypt: true,
+		},
+		{
+			name:      "key ID desconocido",
+			decrypter: mustCrypter(t, testKeyB, testKeyC),
+			ciphertext: func(t *testing.T) []byte {
+				return mustEncrypt(t, mustCrypter(t, testKeyA), plaintext)
+			},
+			wantErr:   ErrUnknownKeyID,
+			reencrypt: true,
+		},
+		{
+			name:      "versión alterada",
+			decrypter: mustCrypter(t, testKeyA),
+			ciphertext: func(t *testing.T) []byte {
+				ct := mustEncrypt(t, mustCrypter(t, testKeyA), plaintext)
+				ct[3]++
+				return ct
+			},
+			wantErr:   ErrDecryptionFailed,
+			reencrypt: true,
+		},
+		{
+			name:      "key ID cambiado por otra clave configurada",
+			decrypter: mustCrypter(t, testKeyA, testKeyB),
+			ciphertext: func(t *testing.T) []byte {
+				ct := mustEncrypt(t, mustCrypter(t, testKeyA), plaintext)
+				idB := mustCrypter(t, testKeyB).primary.id
+				copy(ct[4:versionHeaderSize], idB[:])
+				return ct
+			},
+			wantErr:   ErrDecryptionFailed,
+			reencrypt: true,
+		},
+		{
+			name:      "ciphertext alterado",
+			decrypter: mustCrypter(t, testKeyA),
+			ciphertext: func(t *testing.T) []byte {
+				ct := mustEncrypt(t, mustCrypter(t, testKeyA), plaintext)
+				ct[len(ct)-1] ^= 0xff
+				return ct
+			},
+			wantErr: ErrDecryptionFailed,
+		},
+		{
+			name:      "demasiado corto",
+			decrypter: mustCrypter(t, testKeyA),
+			ciphertext: func(t *testing.T) []byte {
+				return []byte("corto")
+			},
+			wantErr:   ErrCiphertextTooShort,
+			reencrypt: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ct := tt.ciphertext(t)
+
+			got, err := tt.decrypter.Decrypt(ct)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && !bytes.Equal(got, plaintext) {
+				t.Fatalf("plaintext = %q, want %q", got, plaintext)
+			}
+			if needs := tt.decrypter.NeedsReencrypt(ct); needs != tt.reencrypt {
+				t.Errorf("NeedsReencrypt = %v, want %v", needs, tt.reencrypt)
+			}
+		})
+	}
+}
+
+func TestCrypterReencrypt(t *testing.T) {
+	plaintext := []byte("secreto de webhook")
+	rotated := mustCrypter(t, testKeyB, testKeyA)
+
+	for name, ct := range map[string][]byte{
+		"versionado":      mustEncrypt(t, mustCrypter(t, testKeyA), plaintext),
+		"sin cabecera":    legacyEncrypt(t, testKeyA, plaintext),
+		"ya con primaria": mustEncrypt(t, rotated, plaintext),
+	} {
+		t.Run(name, func(t *testing.T) {
+			out, err := rotated.Reencrypt(ct)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if rotated.NeedsReencrypt(out) {
+				t.Error("el resultado debería usar la clave primaria")
+			}
+			// Sin la clave anterior debe seguir descifrándose
+			got, err := mustCrypter(t, testKeyB).Decrypt(out)
+			if err != nil || !bytes.Equal(got, plaintext) {
+				t.Fatalf("Decrypt = %q, %v", got, err)
+			}
+		})
+	}
+}
+
+func TestNewCrypterInvalidKey(t *testing.T) {
+	for _, key := range []string{"", "zz", strings.Repeat("a1", 16), strings.Repeat("a1", 33)} {
+		if _, err := NewCrypter(key); !errors.Is(err, ErrInvalidKey) {
+			t.Errorf("NewCrypter(%q) err = %v, want ErrInvalidKey", key, err)
+		}
+	}
+	if _, err := NewCrypter(testKeyA, "zz"); !errors.Is(err, ErrInvalidKey) {
+		t.Errorf("clave anterior inválida: err = %v, want ErrInvalidKey", err)
+	}
+}
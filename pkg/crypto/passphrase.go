@@ -0,0 +1,112 @@
+package crypto
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/binary"
+	"errors"
+
+	"golang.org/x/crypto/argon2"
+)
+
+var (
+	ErrInvalidBundle     = errors.New("formato de bundle inválido")
+	ErrInvalidPassphrase = errors.New("passphrase incorrecta o bundle corrupto")
+)
+
+// Cabecera de un bundle cifrado con passphrase:
+//
+//	magic(4) | argon2 time(1) | argon2 memory KiB(4) | argon2 threads(1) | salt(16) | nonce(12) | ciphertext+tag
+//
+// Los parámetros de argon2id viajan en la cabecera para poder bajarlos sin
+// romper bundles anteriores; al abrir nunca se aceptan valores por encima de
+// los que escribe SealWithPassphrase.
+var bundleMagic = []byte("TGB1")
+
+const (
+	argonTime    = 3
+	argonMemory  = 64 * 1024
+	argonThreads = 4
+	saltSize     = 16
+	headerSize   = 4 + 1 + 4 + 1 + saltSize
+)
+
+// SealWithPassphrase cifra datos con AES-256-GCM usando una clave derivada de
+// la passphrase con argon2id. Independiente de ENCRYPTION_KEY, para mover datos
+// entre despliegues.
+func SealWithPassphrase(plaintext []byte, passphrase string) ([]byte, error) {
+	salt, err := GenerateRandomBytes(saltSize)
+	if err != nil {
+		return nil, err
+	}
+
+	header := make([]byte, 0, headerSize)
+	header = append(header, bundleMagic...)
+	header = append(header, argonTime)
+	header = binary.BigEndian.AppendUint32(header, argonMemory)
+	header = append(header, argonThreads)
+	header = append(header, salt...)
+
+	gcm, err := passphraseGCM(passphrase, salt, argonTime, argonMemory, argonThreads)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce, err := GenerateRandomBytes(gcm.NonceSize())
+	if err != nil {
+		return nil, err
+	}
+
+	// La cabecera se autentica como additional data
+	out := append(header, nonce...)
+	return gcm.Seal(out, nonce, plaintext, header), nil
+}
+
+// OpenWithPassphrase descifra un bundle generado por SealWithPassphrase
+func OpenWithPassphrase(bundle []byte, passphrase string) ([]byte, error) {
+	if len(bundle) < headerSize || !bytes.HasPrefix(bundle, bundleMagic) {
+		return nil, ErrInvalidBundle
+	}
+
+	header := bundle[:headerSize]
+	time := uint32(header[4])
+	memory := binary.BigEndian.Uint32(header[5:9])
+	threads := header[9]
+	salt := header[10:headerSize]
+
+	// La cabecera la controla quien sube el bundle: acotar el costo de la
+	// derivación a los parámetros propios
+	if time == 0 || time > argonTime ||
+		memory == 0 || memory > argonMemory ||
+		threads == 0 || threads > argonThreads {
+		return nil, ErrInvalidBundle
+	}
+
+	gcm, err := passphraseGCM(passphrase, salt, time, memory, threads)
+	if err != nil {
+		return nil, err
+	}
+
+	rest := bundle[headerSize:]
+	if len(rest) < gcm.NonceSize() {
+		return nil, ErrCiphertextTooShort
+	}
+	nonce, ciphertext := rest[:gcm.NonceSize()], rest[gcm.NonceSize():]
+
+	plaintext, err := gcm.Open(nil, nonce, ciphertext, header)
+	if err != nil {
+		return nil, ErrInvalidPassphrase
+	}
+	return plaintext, nil
+}
+
+func passphraseGCM(passphrase string, salt []byte, time, memory uint32, threads uint8) (cipher.AEAD, error) {
+	key := argon2.IDKey([]byte(passphrase), salt, time, memory, threads, 32)
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,106 @@
+package crypto
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"testing"
+)
+
+// sealWithParams arma un bundle con parámetros argon2 arbitrarios en la cabecera
+func sealWithParams(t *testing.T, plaintext []byte, passphrase string, time, memory uint32, threads uint8) []byte {
+	t.Helper()
+	salt := bytes.Repeat([]byte{1}, saltSize)
+
+	header := append([]byte{}, bundleMagic...)
+	header = append(header, byte(time))
+	header = binary.BigEndian.AppendUint32(header, memory)
+	header = append(header, threads)
+	header = append(header, salt...)
+
+	gcm, err := passphraseGCM(passphrase, salt, time, memory, threads)
+	if err != nil {
+		t.Fatal(err)
+	}
+	nonce := bytes.Repeat([]byte{2}, gcm.NonceSize())
+	return gcm.Seal(append(header, nonce...), nonce, plaintext, header)
+}
+
+func TestPassphraseRoundTrip(t *testing.T) {
+	plaintext := []byte(`{"sessions":[]}`)
+	bundle, err := SealWithPassphrase(plaintext, "correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(bundle, bundleMagic) {
+		t.Fatalf("el bundle no empieza por %q", bundleMagic)
+	}
+
+	got, err := OpenWithPassphrase(bundle, "correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, plaintext) {
+		t.Fatalf("plaintext = %q, want %q", got, plaintext)
+	}
+
+	if _, err := OpenWithPassphrase(bundle, "wrong horse"); !errors.Is(err, ErrInvalidPassphrase) {
+		t.Fatalf("passphrase incorrecta: err = %v, want ErrInvalidPassphrase", err)
+	}
+}
+
+func TestOpenWithPassphraseRejects(t *testing.T) {
+	const pass = "pw"
+	plaintext := []byte("datos")
+	// Parámetros bajos para que la tabla sea rápida
+	valid := sealWithParams(t, plaintext, pass, 1, 8*1024, 1)
+
+	mutate := func(f func(b []byte) []byte) []byte {
+		return f(append([]byte{}, valid...))
+	}
+	withParams := func(time, memory uint32, threads uint8) []byte {
+		return mutate(func(b []byte) []byte {
+			b[4] = byte(time)
+			binary.BigEndian.PutUint32(b[5:9], memory)
+			b[9] = threads
+			return b
+		})
+	}
+
+	tests := []struct {
+		name    string
+		bundle  []byte
+		wantErr error
+	}{
+		{"vacío", nil, ErrInvalidBundle},
+		{"cabecera truncada", valid[:headerSize-1], ErrInvalidBundle},
+		{"sin nonce", valid[:headerSize+4], ErrCiphertextTooShort},
+		{"magic incorrecto", mutate(func(b []byte) []byte { b[0] = 'X'; return b }), ErrInvalidBundle},
+		{"salt alterado", mutate(func(b []byte) []byte { b[headerSize-1] ^= 0xff; return b }), ErrInvalidPassphrase},
+		{"ciphertext alterado", mutate(func(b []byte) []byte { b[len(b)-1] ^= 0xff; return b }), ErrInvalidPassphrase},
+		// Parámetros inflados: se rechazan antes de derivar la clave
+		{"time inflado", withParams(argonTime+1, 8*1024, 1), ErrInvalidBundle},
+		{"memory inflada", withParams(1, argonMemory+1, 1), ErrInvalidBundle},
+		{"memory máxima", withParams(1, 1<<32-1, 1), ErrInvalidBundle},
+		{"threads inflados", withParams(1, 8*1024, argonThreads+1), ErrInvalidBundle},
+		{"time cero", withParams(0, 8*1024, 1), ErrInvalidBundle},
+		{"memory cero", withParams(1, 0, 1), ErrInvalidBundle},
+		{"threads cero", withParams(1, 8*1024, 0), ErrInvalidBundle},
+		// Dentro del tope pero distintos de los sellados: la cabecera está autenticada
+		{"parámetros bajados", withParams(2, 8*1024, 1), ErrInvalidPassphrase},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := OpenWithPassphrase(tt.bundle, pass); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	// Bundles con parámetros menores a los actuales siguen abriendo
+	got, err := OpenWithPassphrase(valid, pass)
+	if err != nil || !bytes.Equal(got, plaintext) {
+		t.Fatalf("OpenWithPassphrase = %q, %v", got, err)
+	}
+}
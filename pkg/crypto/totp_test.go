@@ -0,0 +1,79 @@
+package crypto
+
+import (
+	"testing"
+	"time"
+)
+
+// Secreto SHA-1 del apéndice B de RFC 6238 ("12345678901234567890") en base32
+const rfc6238Secret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestTOTPCodeRFC6238(t *testing.T) {
+	// Vectores SHA-1 de RFC 6238, truncados a 6 dígitos
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+		{20000000000, "353130"},
+	}
+
+	for _, tt := range tests {
+		got, err := TOTPCode(rfc6238Secret, TOTPStep(time.Unix(tt.unix, 0)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tt.want {
+			t.Errorf("TOTPCode(t=%d) = %s, want %s", tt.unix, got, tt.want)
+		}
+	}
+}
+
+func TestValidateTOTP(t *testing.T) {
+	now := time.Unix(1234567890, 0)
+	current := TOTPStep(now)
+	codeAt := func(offset int64) string {
+		code, err := TOTPCode(rfc6238Secret, current+offset)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return code
+	}
+
+	tests := []struct {
+		name     string
+		code     string
+		wantStep int64
+		wantOK   bool
+	}{
+		{"paso actual", codeAt(0), current, true},
+		{"un paso antes", codeAt(-1), current - 1, true},
+		{"un paso después", codeAt(1), current + 1, true},
+		{"dos pasos antes", codeAt(-2), 0, false},
+		{"dos pasos después", codeAt(2), 0, false},
+		{"con espacios", codeAt(0)[:3] + " " + codeAt(0)[3:], current, true},
+		{"corto", codeAt(0)[:5], 0, false},
+		{"largo", codeAt(0) + "0", 0, false},
+		{"vacío", "", 0, false},
+		{"incorrecto", "000000", 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			step, ok := ValidateTOTP(rfc6238Secret, tt.code, now)
+			if ok != tt.wantOK || step != tt.wantStep {
+				t.Errorf("ValidateTOTP(%q) = (%d, %v), want (%d, %v)", tt.code, step, ok, tt.wantStep, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestValidateTOTPInvalidSecret(t *testing.T) {
+	if _, ok := ValidateTOTP("no-es-base32!", "123456", time.Now()); ok {
+		t.Error("un secreto inválido no debería validar ningún código")
+	}
+}